@@ -0,0 +1,152 @@
+package avatar
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	errs "user-service/error"
+)
+
+// Sizes lists the edge lengths in pixels of the square thumbnails made of every avatar, from small to large.
+var Sizes = []int{32, 64, 128, 256}
+
+// DefaultSize is served when a request asks for no particular size.
+const DefaultSize = 128
+
+// MaxFileSize is the largest image that is accepted, in bytes.
+const MaxFileSize = 5 << 20
+
+// MaxDimension is the largest width or height an uploaded image may have. It is checked before the image is
+// decoded, so a small file cannot claim a huge canvas and exhaust memory.
+const MaxDimension = 4096
+
+const jpegQuality = 85
+
+// Thumbnail is an encoded square thumbnail.
+type Thumbnail struct {
+	Size        int
+	ContentType string
+	Content     []byte
+}
+
+// IsSize reports whether thumbnails are made in the given size.
+func IsSize(size int) bool {
+	for _, candidate := range Sizes {
+		if candidate == size {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Thumbnails crops an uploaded image to its central square and scales it to every size in Sizes. The type of the
+// image is sniffed from its content rather than taken from the client, and the thumbnails keep it, so JPEG
+// photos stay small and PNG transparency survives. Anything but PNG and JPEG fails with
+// errs.UnsupportedMediaTypeError, and images that cannot be decoded or exceed MaxDimension with
+// errs.InvalidAvatarError.
+func Thumbnails(content []byte) ([]Thumbnail, error) {
+	contentType := http.DetectContentType(content)
+	if contentType != "image/png" && contentType != "image/jpeg" {
+		return nil, errs.UnsupportedMediaTypeError
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil || config.Width == 0 || config.Height == 0 || config.Width > MaxDimension || config.Height > MaxDimension {
+		return nil, errs.InvalidAvatarError
+	}
+
+	source, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, errs.InvalidAvatarError
+	}
+
+	square := crop(source)
+
+	thumbnails := make([]Thumbnail, len(Sizes))
+	for i, size := range Sizes {
+		var encoded bytes.Buffer
+		err = encode(&encoded, scale(square, size), contentType)
+		if err != nil {
+			return nil, err
+		}
+
+		thumbnails[i] = Thumbnail{Size: size, ContentType: contentType, Content: encoded.Bytes()}
+	}
+
+	return thumbnails, nil
+}
+
+// crop copies the central square of an image into premultiplied RGBA, which scale averages without darkening
+// transparent edges.
+func crop(source image.Image) *image.RGBA {
+	bounds := source.Bounds()
+	edge := bounds.Dx()
+	if bounds.Dy() < edge {
+		edge = bounds.Dy()
+	}
+
+	offset := image.Pt(bounds.Min.X+(bounds.Dx()-edge)/2, bounds.Min.Y+(bounds.Dy()-edge)/2)
+
+	square := image.NewRGBA(image.Rect(0, 0, edge, edge))
+	draw.Draw(square, square.Bounds(), source, offset, draw.Src)
+
+	return square
+}
+
+// scale resizes a square image by averaging the source pixels each target pixel covers. Images smaller than the
+// target are enlarged by repeating pixels.
+func scale(source *image.RGBA, size int) *image.RGBA {
+	edge := source.Bounds().Dx()
+	target := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	for y := 0; y < size; y++ {
+		y0, y1 := span(y, edge, size)
+
+		for x := 0; x < size; x++ {
+			x0, x1 := span(x, edge, size)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := source.Pix[sy*source.Stride+x0*4 : sy*source.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			count := (y1 - y0) * (x1 - x0)
+			offset := y*target.Stride + x*4
+			for i := range sum {
+				target.Pix[offset+i] = uint8((sum[i] + count/2) / count)
+			}
+		}
+	}
+
+	return target
+}
+
+// span returns the range of source pixels that target pixel i of size covers, which is never empty.
+func span(i int, edge int, size int) (int, int) {
+	start := i * edge / size
+	end := (i + 1) * edge / size
+	if end <= start {
+		end = start + 1
+	}
+
+	return start, end
+}
+
+func encode(w io.Writer, thumbnail image.Image, contentType string) error {
+	if contentType == "image/png" {
+		return png.Encode(w, thumbnail)
+	}
+
+	return jpeg.Encode(w, thumbnail, &jpeg.Options{Quality: jpegQuality})
+}
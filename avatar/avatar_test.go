@@ -0,0 +1,94 @@
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+	errs "user-service/error"
+)
+
+func encodePNG(img image.Image) []byte {
+	var encoded bytes.Buffer
+	png.Encode(&encoded, img)
+
+	return encoded.Bytes()
+}
+
+func Test_Thumbnails_Should_Crop_To_The_Central_Square_And_Make_Every_Size(t *testing.T) {
+	// A wide image with red margins on the left and right of a blue square.
+	source := image.NewNRGBA(image.Rect(0, 0, 600, 400))
+	for y := 0; y < 400; y++ {
+		for x := 0; x < 600; x++ {
+			if x < 100 || x >= 500 {
+				source.Set(x, y, color.NRGBA{R: 255, A: 255})
+			} else {
+				source.Set(x, y, color.NRGBA{B: 255, A: 255})
+			}
+		}
+	}
+
+	thumbnails, err := Thumbnails(encodePNG(source))
+
+	assert.Nil(t, err)
+	assert.Len(t, thumbnails, len(Sizes))
+	for i, thumbnail := range thumbnails {
+		assert.Equal(t, Sizes[i], thumbnail.Size)
+		assert.Equal(t, "image/png", thumbnail.ContentType)
+
+		decoded, err := png.Decode(bytes.NewReader(thumbnail.Content))
+		assert.Nil(t, err)
+		assert.Equal(t, image.Rect(0, 0, Sizes[i], Sizes[i]), decoded.Bounds())
+
+		r, g, b, a := decoded.At(0, 0).RGBA()
+		assert.Equal(t, [4]uint32{0, 0, 0xffff, 0xffff}, [4]uint32{r, g, b, a})
+	}
+}
+
+func Test_Thumbnails_Should_Keep_JPEG_Images_As_JPEG(t *testing.T) {
+	var encoded bytes.Buffer
+	jpeg.Encode(&encoded, image.NewGray(image.Rect(0, 0, 20, 30)), nil)
+
+	thumbnails, err := Thumbnails(encoded.Bytes())
+
+	assert.Nil(t, err)
+	for _, thumbnail := range thumbnails {
+		assert.Equal(t, "image/jpeg", thumbnail.ContentType)
+
+		config, err := jpeg.DecodeConfig(bytes.NewReader(thumbnail.Content))
+		assert.Nil(t, err)
+		assert.Equal(t, thumbnail.Size, config.Width)
+		assert.Equal(t, thumbnail.Size, config.Height)
+	}
+}
+
+func Test_Thumbnails_Should_Reject_Other_Types(t *testing.T) {
+	_, err := Thumbnails([]byte("GIF89a\x01\x00\x01\x00"))
+
+	assert.True(t, errors.Is(err, errs.UnsupportedMediaTypeError))
+}
+
+func Test_Thumbnails_Should_Reject_Corrupt_And_Oversized_Images(t *testing.T) {
+	corrupt := encodePNG(image.NewGray(image.Rect(0, 0, 10, 10)))[:40]
+	oversized := encodePNG(image.NewGray(image.Rect(0, 0, MaxDimension+1, 1)))
+
+	for _, content := range [][]byte{corrupt, oversized} {
+		_, err := Thumbnails(content)
+
+		assert.True(t, errors.Is(err, errs.InvalidAvatarError))
+	}
+}
+
+func Test_Scale_Should_Average_The_Pixels_Each_Target_Pixel_Covers(t *testing.T) {
+	source := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	source.Set(0, 0, color.RGBA{R: 255, A: 255})
+	source.Set(1, 1, color.RGBA{R: 255, A: 255})
+
+	scaled := scale(source, 1)
+
+	assert.Equal(t, color.RGBA{R: 128, A: 128}, scaled.RGBAAt(0, 0))
+}
@@ -0,0 +1,238 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"user-service/model"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultBaseDelay  = 100 * time.Millisecond
+	DefaultMaxDelay   = 5 * time.Second
+)
+
+// Client is a typed client for the user API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	sleep      func(context.Context, time.Duration) error
+	apiKey     string
+	tenantId   string
+}
+
+type Option func(*Client)
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries configures how often a failed request is retried and the bounds of the exponential backoff
+// between attempts. A Retry-After header sent by the API takes precedence over the backoff.
+func WithRetries(maxRetries int, baseDelay time.Duration, maxDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.baseDelay = baseDelay
+		c.maxDelay = maxDelay
+	}
+}
+
+// WithApiKey authenticates every request with the given API key.
+func WithApiKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+// WithTenant sends every request to the given tenant. Keys bound to a tenant do not need it.
+func WithTenant(tenantId string) Option {
+	return func(c *Client) {
+		c.tenantId = tenantId
+	}
+}
+
+func NewClient(baseURL string, options ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		maxRetries: DefaultMaxRetries,
+		baseDelay:  DefaultBaseDelay,
+		maxDelay:   DefaultMaxDelay,
+		sleep:      sleep,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+// do sends a request, retrying it while the API is unavailable, and decodes the response into responseBody.
+// It returns the headers of the final response.
+func (c *Client) do(ctx context.Context, method string, path string, requestBody interface{}, responseBody interface{}) (http.Header, error) {
+	var payload []byte
+	if requestBody != nil {
+		var err error
+		payload, err = json.Marshal(requestBody)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	url := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		url = c.baseURL + path
+	}
+
+	for attempt := 0; ; attempt++ {
+		request, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Accept", "application/json")
+		if requestBody != nil {
+			request.Header.Set("Content-Type", "application/json")
+		}
+		if c.apiKey != "" {
+			request.Header.Set("Authorization", "ApiKey "+c.apiKey)
+		}
+		if c.tenantId != "" {
+			request.Header.Set("X-Tenant-ID", c.tenantId)
+		}
+		// Passes the trace of ctx on to the API when the application has installed a propagator.
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+		response, err := c.httpClient.Do(request)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if attempt >= c.maxRetries || !isIdempotent(method) {
+				return nil, err
+			}
+
+			if err := c.sleep(ctx, c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if attempt < c.maxRetries && isRetryable(method, response.StatusCode) {
+			delay, ok := retryAfter(response.Header, time.Now())
+			if !ok {
+				delay = c.backoff(attempt)
+			}
+			io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+
+			if err := c.sleep(ctx, delay); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		return response.Header, decodeResponse(response, responseBody)
+	}
+}
+
+func decodeResponse(response *http.Response, responseBody interface{}) error {
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		var problem model.ProblemViewModel
+		json.NewDecoder(response.Body).Decode(&problem)
+
+		return newError(response.StatusCode, problem)
+	}
+
+	if responseBody == nil {
+		return nil
+	}
+
+	return json.NewDecoder(response.Body).Decode(responseBody)
+}
+
+// backoff returns the delay before the given retry, doubling per attempt up to maxDelay with jitter so that
+// clients failing together do not retry together.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.baseDelay << uint(attempt)
+	if delay <= 0 || delay > c.maxDelay {
+		delay = c.maxDelay
+	}
+
+	half := delay / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// isRetryable reports whether a response status is worth retrying. 429 and 503 mean the request was not
+// processed, so they are safe to retry for every method; gateway errors are only retried when repeating the
+// request cannot apply it twice.
+func isRetryable(method string, statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return isIdempotent(method)
+	default:
+		return false
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,393 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"user-service/controller"
+	errs "user-service/error"
+	"user-service/graphqlapi"
+	"user-service/i18n"
+	"user-service/importer"
+	"user-service/logging"
+	"user-service/metrics"
+	"user-service/model"
+	"user-service/openapi"
+	"user-service/repository"
+	"user-service/router"
+	serviceMock "user-service/service/mock"
+)
+
+// newTestServer serves the real router backed by a mocked service. Requests for which unavailable returns
+// true are answered with 503 before they reach the router.
+// testValidator and testCatalog are set up the way main sets them up.
+var testValidator, testCatalog = newTestValidation()
+
+func newTestValidation() (*validator.Validate, *i18n.Catalog) {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(controller.JsonFieldName)
+
+	catalog, err := i18n.NewCatalog(validate)
+	if err != nil {
+		panic(err)
+	}
+
+	return validate, catalog
+}
+
+func newTestServer(userServiceMock *serviceMock.UserServiceInterface, unavailable func(*http.Request) bool) *httptest.Server {
+	return newTestServerWithApiKeys(userServiceMock, new(serviceMock.ApiKeyServiceInterface), unavailable)
+}
+
+func newTestServerWithApiKeys(userServiceMock *serviceMock.UserServiceInterface, apiKeyServiceMock *serviceMock.ApiKeyServiceInterface, unavailable func(*http.Request) bool) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	apiKeyController := controller.NewApiKeyController(apiKeyServiceMock, testValidator, testCatalog, logging.Discard(), []string{model.ScopeUsersRead, model.ScopeUsersWrite})
+	tenantServiceMock := new(serviceMock.TenantServiceInterface)
+	tenantServiceMock.On("Resolve", mock.Anything, mock.Anything).Return(nil)
+	engine.Use(apiKeyController.Authenticate)
+	router.Register(
+		engine,
+		controller.NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard()),
+		controller.NewAvatarController(new(serviceMock.AvatarServiceInterface), testCatalog, logging.Discard()),
+		controller.NewImportController(importer.NewImporter(userServiceMock, testValidator, testCatalog, "", logging.Discard()), testCatalog, logging.Discard()),
+		controller.NewProfileSchemaController(new(serviceMock.ProfileSchemaServiceInterface), testValidator, testCatalog, logging.Discard()),
+		apiKeyController,
+		controller.NewTenantController(tenantServiceMock, testValidator, testCatalog, logging.Discard(), model.DefaultTenantId, ""),
+		controller.NewGroupController(new(serviceMock.GroupServiceInterface), testValidator, testCatalog, logging.Discard()),
+		controller.NewHealthController(new(serviceMock.HealthServiceInterface)),
+		controller.NewDocsController(openapi.NewDocument()),
+		graphqlapi.NewHandler(userServiceMock, testValidator),
+		metrics.Handler(),
+	)
+
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if unavailable != nil && unavailable(request) {
+			writer.Header().Set("Retry-After", "7")
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		engine.ServeHTTP(writer, request)
+	}))
+}
+
+func newTestClient(server *httptest.Server, delays *[]time.Duration) *Client {
+	classUnderTest := NewClient(server.URL, WithRetries(2, time.Millisecond, 10*time.Millisecond))
+	classUnderTest.sleep = func(ctx context.Context, delay time.Duration) error {
+		*delays = append(*delays, delay)
+		return ctx.Err()
+	}
+
+	return classUnderTest
+}
+
+func Test_Create_Should_Return_User_When_Nothing_Fails(t *testing.T) {
+	var createViewModel = model.CreateUserViewModel{Name: "Batuhan", Email: "batuhan@site.com", Password: "123456"}
+	var domainModel = model.UserDomainModel{Id: primitive.NewObjectID().Hex(), Name: createViewModel.Name, Email: createViewModel.Email}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Create", mock.Anything, model.CreateUserDomainModel{
+		Name:     createViewModel.Name,
+		Email:    createViewModel.Email,
+		Password: createViewModel.Password,
+	}).Return(&domainModel, nil).Once()
+
+	server := newTestServer(userServiceMock, nil)
+	defer server.Close()
+
+	var delays []time.Duration
+	user, err := newTestClient(server, &delays).Create(context.Background(), createViewModel)
+
+	assert.Nil(t, err)
+	assert.Equal(t, domainModel.Id, user.Id)
+	assert.Equal(t, domainModel.Email, user.Email)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Create_Should_Return_ErrValidation_With_Field_Errors_When_Payload_Is_Invalid(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+
+	server := newTestServer(userServiceMock, nil)
+	defer server.Close()
+
+	var delays []time.Duration
+	user, err := newTestClient(server, &delays).Create(context.Background(), model.CreateUserViewModel{Name: "Batuhan"})
+
+	var apiError *Error
+	assert.Nil(t, user)
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.True(t, errors.As(err, &apiError))
+	assert.Equal(t, http.StatusBadRequest, apiError.StatusCode)
+	assert.Equal(t, 2, len(apiError.Problem.Errors))
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Get_Should_Return_ErrNotFound_When_User_Does_Not_Exist(t *testing.T) {
+	var id = primitive.NewObjectID().Hex()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetById", mock.Anything, id).Return(nil, errs.NotFoundError).Once()
+
+	server := newTestServer(userServiceMock, nil)
+	defer server.Close()
+
+	var delays []time.Duration
+	user, err := newTestClient(server, &delays).Get(context.Background(), id)
+
+	assert.Nil(t, user)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(err, errs.NotFoundError))
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Update_Should_Return_ErrEmailAlreadyInUse_When_Email_Is_Taken(t *testing.T) {
+	var id = primitive.NewObjectID().Hex()
+	var email = "taken@site.com"
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("UpdateById", mock.Anything, id, model.UpdateUserDomainModel{Email: &email}).Return(nil, errs.EmailAlreadyInUseError).Once()
+
+	server := newTestServer(userServiceMock, nil)
+	defer server.Close()
+
+	var delays []time.Duration
+	user, err := newTestClient(server, &delays).Update(context.Background(), id, model.UpdateUserViewModel{Email: &email})
+
+	assert.Nil(t, user)
+	assert.True(t, errors.Is(err, ErrEmailAlreadyInUse))
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Delete_Should_Not_Return_Error_When_Nothing_Fails(t *testing.T) {
+	var id = primitive.NewObjectID().Hex()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("DeleteById", mock.Anything, id).Return(nil).Once()
+
+	server := newTestServer(userServiceMock, nil)
+	defer server.Close()
+
+	var delays []time.Duration
+	err := newTestClient(server, &delays).Delete(context.Background(), id)
+
+	assert.Nil(t, err)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_List_Should_Iterate_Over_Every_Page(t *testing.T) {
+	var users = []*model.UserDomainModel{
+		{Id: primitive.NewObjectID().Hex()},
+		{Id: primitive.NewObjectID().Hex()},
+		{Id: primitive.NewObjectID().Hex()},
+	}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetAll", mock.Anything, model.PageDomainModel{Limit: 2}).Return(users[:2], users[1].Id, nil).Once()
+	userServiceMock.On("GetAll", mock.Anything, model.PageDomainModel{After: users[1].Id, Limit: 2}).Return(users[2:], "", nil).Once()
+
+	server := newTestServer(userServiceMock, nil)
+	defer server.Close()
+
+	var delays []time.Duration
+	iterator := newTestClient(server, &delays).List(2)
+
+	var ids []string
+	for iterator.Next(context.Background()) {
+		ids = append(ids, iterator.User().Id)
+	}
+
+	assert.Nil(t, iterator.Err())
+	assert.Equal(t, []string{users[0].Id, users[1].Id, users[2].Id}, ids)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_List_Should_Yield_Nothing_When_No_Users_Exist(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetAll", mock.Anything, model.PageDomainModel{Limit: 10}).Return(nil, "", errs.NotFoundError).Once()
+
+	server := newTestServer(userServiceMock, nil)
+	defer server.Close()
+
+	var delays []time.Duration
+	iterator := newTestClient(server, &delays).List(10)
+
+	assert.False(t, iterator.Next(context.Background()))
+	assert.Nil(t, iterator.Err())
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Get_Should_Retry_Honoring_Retry_After_When_Service_Is_Unavailable(t *testing.T) {
+	var id = primitive.NewObjectID().Hex()
+	var attempts = 0
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetById", mock.Anything, id).Return(&model.UserDomainModel{Id: id}, nil).Once()
+
+	server := newTestServer(userServiceMock, func(*http.Request) bool {
+		attempts++
+		return attempts == 1
+	})
+	defer server.Close()
+
+	var delays []time.Duration
+	user, err := newTestClient(server, &delays).Get(context.Background(), id)
+
+	assert.Nil(t, err)
+	assert.Equal(t, id, user.Id)
+	assert.Equal(t, []time.Duration{7 * time.Second}, delays)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Get_Should_Give_Up_After_Max_Retries_When_Service_Stays_Unavailable(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+
+	server := newTestServer(userServiceMock, func(*http.Request) bool { return true })
+	defer server.Close()
+
+	var delays []time.Duration
+	user, err := newTestClient(server, &delays).Get(context.Background(), primitive.NewObjectID().Hex())
+
+	var apiError *Error
+	assert.Nil(t, user)
+	assert.True(t, errors.As(err, &apiError))
+	assert.Equal(t, http.StatusServiceUnavailable, apiError.StatusCode)
+	assert.Equal(t, 2, len(delays))
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Get_Should_Stop_Retrying_When_Context_Is_Cancelled(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+
+	server := newTestServer(userServiceMock, func(*http.Request) bool { return true })
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	classUnderTest := NewClient(server.URL)
+	classUnderTest.sleep = func(ctx context.Context, delay time.Duration) error {
+		cancel()
+		return sleep(ctx, delay)
+	}
+
+	user, err := classUnderTest.Get(ctx, primitive.NewObjectID().Hex())
+
+	assert.Nil(t, user)
+	assert.Equal(t, context.Canceled, err)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Get_Should_Pass_On_The_Trace_Of_The_Context(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	var id = primitive.NewObjectID().Hex()
+	var traceparent string
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetById", mock.Anything, id).Return(&model.UserDomainModel{Id: id}, nil).Once()
+
+	server := newTestServer(userServiceMock, func(request *http.Request) bool {
+		traceparent = request.Header.Get("traceparent")
+		return false
+	})
+	defer server.Close()
+
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceId,
+		SpanID:     spanId,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	_, err := NewClient(server.URL).Get(ctx, id)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", traceparent)
+}
+
+func Test_Get_Should_Authenticate_With_The_Api_Key(t *testing.T) {
+	var id = primitive.NewObjectID().Hex()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetById", mock.Anything, id).Return(&model.UserDomainModel{Id: id}, nil).Once()
+
+	apiKeyServiceMock := new(serviceMock.ApiKeyServiceInterface)
+	apiKeyServiceMock.On("Authenticate", mock.Anything, "usk_0123456789ab_secret").Return(&model.Principal{ApiKeyId: "1", Scopes: []string{model.ScopeUsersRead}}, nil).Once()
+	apiKeyServiceMock.On("Authenticate", mock.Anything, "usk_0123456789ab_wrong").Return(nil, errs.UnauthorizedError).Once()
+
+	server := newTestServerWithApiKeys(userServiceMock, apiKeyServiceMock, nil)
+	defer server.Close()
+
+	_, err := NewClient(server.URL, WithApiKey("usk_0123456789ab_secret")).Get(context.Background(), id)
+	assert.Nil(t, err)
+
+	_, err = NewClient(server.URL, WithApiKey("usk_0123456789ab_wrong")).Get(context.Background(), id)
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+	apiKeyServiceMock.AssertExpectations(t)
+}
+
+func Test_Get_Should_Send_The_Request_To_The_Tenant(t *testing.T) {
+	var id = primitive.NewObjectID().Hex()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetById", mock.MatchedBy(func(ctx context.Context) bool {
+		return repository.TenantFrom(ctx) == "acme"
+	}), id).Return(&model.UserDomainModel{Id: id}, nil).Once()
+
+	apiKeyServiceMock := new(serviceMock.ApiKeyServiceInterface)
+	apiKeyServiceMock.On("Authenticate", mock.Anything, "usk_0123456789ab_admin").Return(&model.Principal{ApiKeyId: "admin", Scopes: []string{model.ScopeUsersRead}}, nil)
+
+	server := newTestServerWithApiKeys(userServiceMock, apiKeyServiceMock, nil)
+	defer server.Close()
+
+	_, err := NewClient(server.URL, WithApiKey("usk_0123456789ab_admin"), WithTenant("acme")).Get(context.Background(), id)
+
+	assert.Nil(t, err)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_RetryAfter_Should_Parse_Seconds_And_Http_Dates(t *testing.T) {
+	var now = time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	seconds, ok := retryAfter(http.Header{"Retry-After": {"3"}}, now)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, seconds)
+
+	date, ok := retryAfter(http.Header{"Retry-After": {now.Add(time.Minute).Format(http.TimeFormat)}}, now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, date)
+
+	_, ok = retryAfter(http.Header{}, now)
+	assert.False(t, ok)
+}
+
+func Test_Get_Should_Return_ErrTooManyRequests_When_Rate_Limited_After_Max_Retries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Retry-After", "1")
+		writer.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	var delays []time.Duration
+	_, err := newTestClient(server, &delays).Get(context.Background(), primitive.NewObjectID().Hex())
+
+	assert.True(t, errors.Is(err, ErrTooManyRequests))
+	assert.Equal(t, 2, len(delays))
+}
+
+func Test_NewError_Should_Map_Too_Many_Requests_By_Problem_Type_And_Status(t *testing.T) {
+	assert.ErrorIs(t, newError(http.StatusTooManyRequests, model.ProblemViewModel{Type: "/problems/too-many-requests"}), ErrTooManyRequests)
+	assert.ErrorIs(t, newError(http.StatusTooManyRequests, model.ProblemViewModel{}), ErrTooManyRequests)
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	errs "user-service/error"
+	"user-service/model"
+)
+
+// The client reports API errors with the same sentinels the service uses, so callers can match them with
+// errors.Is regardless of which side of the wire they are on.
+var (
+	ErrBadRequest         = errs.BadRequestError
+	ErrValidation         = errs.ValidationError
+	ErrNotFound           = errs.NotFoundError
+	ErrEmailAlreadyInUse  = errs.EmailAlreadyInUseError
+	ErrPreconditionFailed = errs.PreconditionFailedError
+	ErrUnauthorized       = errs.UnauthorizedError
+	ErrForbidden          = errs.ForbiddenError
+	ErrTenantNotFound     = errs.TenantNotFoundError
+	ErrTooManyRequests    = errs.TooManyRequestsError
+	ErrServer             = errs.ServerError
+)
+
+// problemTypes maps the problem types the API returns to their sentinels. Problem types are matched instead of
+// details because details are localized.
+var problemTypes = map[string]error{
+	"/problems/bad-request":          ErrBadRequest,
+	"/problems/validation-error":     ErrValidation,
+	"/problems/user-not-found":       ErrNotFound,
+	"/problems/email-already-in-use": ErrEmailAlreadyInUse,
+	"/problems/precondition-failed":  ErrPreconditionFailed,
+	"/problems/unauthorized":         ErrUnauthorized,
+	"/problems/forbidden":            ErrForbidden,
+	"/problems/tenant-not-found":     ErrTenantNotFound,
+	"/problems/too-many-requests":    ErrTooManyRequests,
+	"/problems/server-error":         ErrServer,
+}
+
+var statusCodes = map[int]error{
+	http.StatusBadRequest:         ErrBadRequest,
+	http.StatusUnauthorized:       ErrUnauthorized,
+	http.StatusForbidden:          ErrForbidden,
+	http.StatusNotFound:           ErrNotFound,
+	http.StatusConflict:           ErrEmailAlreadyInUse,
+	http.StatusPreconditionFailed: ErrPreconditionFailed,
+	http.StatusTooManyRequests:    ErrTooManyRequests,
+}
+
+// Error is returned for every response with an error status. It unwraps to the matching sentinel.
+type Error struct {
+	StatusCode int
+	Problem    model.ProblemViewModel
+	sentinel   error
+}
+
+func newError(statusCode int, problem model.ProblemViewModel) *Error {
+	sentinel, ok := problemTypes[problem.Type]
+	if !ok {
+		sentinel, ok = statusCodes[statusCode]
+	}
+	if !ok {
+		sentinel = ErrServer
+	}
+
+	return &Error{StatusCode: statusCode, Problem: problem, sentinel: sentinel}
+}
+
+func (e *Error) Error() string {
+	if e.Problem.Detail != "" {
+		return fmt.Sprintf("user api: %d: %s", e.StatusCode, e.Problem.Detail)
+	}
+
+	return fmt.Sprintf("user api: %d: %s", e.StatusCode, e.sentinel)
+}
+
+func (e *Error) Unwrap() error {
+	return e.sentinel
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"user-service/model"
+)
+
+func (c *Client) Create(ctx context.Context, user model.CreateUserViewModel) (*model.UserViewModel, error) {
+	var created model.UserViewModel
+
+	_, err := c.do(ctx, http.MethodPost, "/users", user, &created)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (c *Client) Get(ctx context.Context, id string) (*model.UserViewModel, error) {
+	var user model.UserViewModel
+
+	_, err := c.do(ctx, http.MethodGet, "/users/"+url.PathEscape(id), nil, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (c *Client) Update(ctx context.Context, id string, update model.UpdateUserViewModel) (*model.UserViewModel, error) {
+	var user model.UserViewModel
+
+	_, err := c.do(ctx, http.MethodPatch, "/users/"+url.PathEscape(id), update, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (c *Client) Delete(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/users/"+url.PathEscape(id), nil, nil)
+
+	return err
+}
+
+// List returns an iterator over every user, fetched pageSize users at a time.
+func (c *Client) List(pageSize int) *UserIterator {
+	return &UserIterator{
+		client: c,
+		next:   "/users?" + url.Values{"limit": {strconv.Itoa(pageSize)}}.Encode(),
+	}
+}
+
+// UserIterator walks the pages of the user list, following the next links the API returns.
+//
+//	users := client.List(50)
+//	for users.Next(ctx) {
+//		fmt.Println(users.User().Name)
+//	}
+//	if err := users.Err(); err != nil {
+//		...
+//	}
+type UserIterator struct {
+	client  *Client
+	next    string
+	page    []model.UserViewModel
+	current *model.UserViewModel
+	err     error
+}
+
+// Next advances to the next user, fetching the next page when the current one is exhausted. It returns false
+// once every user has been visited or a request failed.
+func (it *UserIterator) Next(ctx context.Context) bool {
+	for len(it.page) == 0 {
+		if it.next == "" || it.err != nil {
+			it.current = nil
+			return false
+		}
+
+		it.fetch(ctx)
+	}
+
+	it.current = &it.page[0]
+	it.page = it.page[1:]
+
+	return true
+}
+
+func (it *UserIterator) User() *model.UserViewModel {
+	return it.current
+}
+
+func (it *UserIterator) Err() error {
+	return it.err
+}
+
+func (it *UserIterator) fetch(ctx context.Context) {
+	var page []model.UserViewModel
+
+	header, err := it.client.do(ctx, http.MethodGet, it.next, nil, &page)
+	if errors.Is(err, ErrNotFound) {
+		// The API reports an empty user list as not found.
+		it.next = ""
+		return
+	} else if err != nil {
+		it.err = err
+		return
+	}
+
+	it.page = page
+	it.next = nextLink(header.Get("Link"))
+}
+
+// nextLink extracts the target of the rel="next" link from a Link header.
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		target := strings.TrimSpace(parts[0])
+		if len(target) < 2 || target[0] != '<' || target[len(target)-1] != '>' {
+			continue
+		}
+
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return target[1 : len(target)-1]
+			}
+		}
+	}
+
+	return ""
+}
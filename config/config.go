@@ -0,0 +1,213 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+	"user-service/model"
+)
+
+// Config is the whole configuration of the service. Every setting has a default, can be set in the YAML file
+// under the path of its yaml tags, and can be overridden by the environment variable in its env tag and by the
+// command line flag named after its path, such as --database-user-collection.
+type Config struct {
+	Server    Server    `yaml:"server"`
+	GRPC      GRPC      `yaml:"grpc"`
+	Database  Database  `yaml:"database"`
+	Security  Security  `yaml:"security"`
+	CORS      CORS      `yaml:"cors"`
+	Log       Log       `yaml:"log"`
+	Storage   Storage   `yaml:"storage"`
+	Features  Features  `yaml:"features"`
+	Health    Health    `yaml:"health"`
+	Tracing   Tracing   `yaml:"tracing"`
+	RateLimit RateLimit `yaml:"rateLimit"`
+	Auth      Auth      `yaml:"auth"`
+	Tenancy   Tenancy   `yaml:"tenancy"`
+}
+
+type Server struct {
+	Address           string        `yaml:"address" env:"SERVER_ADDRESS"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"readTimeout" env:"SERVER_READ_TIMEOUT"`
+	// WriteTimeout is off by default because exports stream for as long as the collection takes to read.
+	WriteTimeout time.Duration `yaml:"writeTimeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout  time.Duration `yaml:"idleTimeout" env:"SERVER_IDLE_TIMEOUT"`
+	// ShutdownDelay keeps serving with failing readiness before a shutdown starts, so that load balancers stop
+	// routing to the instance first.
+	ShutdownDelay time.Duration `yaml:"shutdownDelay" env:"SERVER_SHUTDOWN_DELAY"`
+	// ShutdownTimeout bounds how long a shutdown waits for requests, RPCs and imports in flight.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	// TrustedProxies lists the IP addresses and CIDR ranges of the proxies whose X-Forwarded-For header names the
+	// client. Empty trusts none, so clients cannot dodge the rate limits by sending the header themselves.
+	TrustedProxies []string `yaml:"trustedProxies" env:"SERVER_TRUSTED_PROXIES"`
+}
+
+type GRPC struct {
+	Address string `yaml:"address" env:"GRPC_ADDRESS"`
+}
+
+type Database struct {
+	URI            string        `yaml:"uri" env:"MONGO_URI" secret:"url"`
+	Name           string        `yaml:"name" env:"DATABASE_NAME"`
+	UserCollection string        `yaml:"userCollection" env:"DATABASE_USER_COLLECTION"`
+	ConnectTimeout time.Duration `yaml:"connectTimeout" env:"DATABASE_CONNECT_TIMEOUT"`
+	// ConnectAttempts and ConnectBackoff bound the wait for a database that is not reachable yet at startup. The
+	// backoff doubles after every failed attempt.
+	ConnectAttempts  int           `yaml:"connectAttempts" env:"DATABASE_CONNECT_ATTEMPTS"`
+	ConnectBackoff   time.Duration `yaml:"connectBackoff" env:"DATABASE_CONNECT_BACKOFF"`
+	OperationTimeout time.Duration `yaml:"operationTimeout" env:"DATABASE_OPERATION_TIMEOUT"`
+}
+
+type Security struct {
+	BcryptCost int `yaml:"bcryptCost" env:"BCRYPT_COST"`
+}
+
+// CORS is off while AllowedOrigins is empty. An origin of * allows every origin.
+type CORS struct {
+	AllowedOrigins   []string      `yaml:"allowedOrigins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods   []string      `yaml:"allowedMethods" env:"CORS_ALLOWED_METHODS"`
+	AllowedHeaders   []string      `yaml:"allowedHeaders" env:"CORS_ALLOWED_HEADERS"`
+	ExposedHeaders   []string      `yaml:"exposedHeaders" env:"CORS_EXPOSED_HEADERS"`
+	AllowCredentials bool          `yaml:"allowCredentials" env:"CORS_ALLOW_CREDENTIALS"`
+	MaxAge           time.Duration `yaml:"maxAge" env:"CORS_MAX_AGE"`
+}
+
+type Log struct {
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+}
+
+type Storage struct {
+	// ImportDirectory spools import uploads and error reports. Empty means os.TempDir.
+	ImportDirectory string `yaml:"importDirectory" env:"IMPORT_DIRECTORY"`
+	BlobDirectory   string `yaml:"blobDirectory" env:"BLOB_DIRECTORY"`
+}
+
+type Health struct {
+	// Timeout bounds every check of a dependency behind /readyz and /health.
+	Timeout time.Duration `yaml:"timeout" env:"HEALTH_CHECK_TIMEOUT"`
+}
+
+type Tracing struct {
+	// Exporter is one of TracingExporters.
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	// Endpoint is the OTLP/HTTP URL spans are sent to, such as http://collector:4318. Empty falls back to the
+	// standard OTEL_EXPORTER_OTLP_ENDPOINT variables.
+	Endpoint string `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+	// SampleRatio is the share of traces started by the service that are recorded. Traces started by callers
+	// follow their sampling decision.
+	SampleRatio float64 `yaml:"sampleRatio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// RateLimit is off while Policies is empty.
+type RateLimit struct {
+	// Store is one of RateLimitStores. mongodb shares the buckets between the replicas of a deployment.
+	Store string `yaml:"store" env:"RATE_LIMIT_STORE"`
+	// Policies are written as "<route> <requests>/<period> per <key>", such as "POST /users 10/1h per ip". The
+	// route is a method and route template, with the verb for custom methods as in POST /users:batchGet, or * for
+	// every route without a policy of its own. The key is one of RateLimitKeys.
+	Policies []string `yaml:"policies" env:"RATE_LIMIT_POLICIES"`
+}
+
+type Auth struct {
+	// Required rejects the requests that carry no API key, which is the default. Otherwise those may read and write
+	// users, but neither publish profile schemas nor manage API keys and tenants.
+	Required bool `yaml:"required" env:"AUTH_REQUIRED"`
+	// BootstrapKey is accepted as an API key that may only manage API keys, to create the first ones with.
+	// Empty turns it off.
+	BootstrapKey string `yaml:"bootstrapKey" env:"AUTH_BOOTSTRAP_KEY" secret:"key"`
+}
+
+// Tenancy decides which tenant a request is served for when its API key belongs to none.
+type Tenancy struct {
+	// DefaultTenant serves the requests that name no tenant with the X-Tenant-ID header or a subdomain. Empty
+	// rejects them instead. Requests without an API key are always served for it.
+	DefaultTenant string `yaml:"defaultTenant" env:"TENANCY_DEFAULT_TENANT"`
+	// BaseDomain is the domain that tenants are served below, such as users.example.com for
+	// acme.users.example.com. Empty turns subdomains off.
+	BaseDomain string `yaml:"baseDomain" env:"TENANCY_BASE_DOMAIN"`
+}
+
+// Features switches optional APIs off. The REST API is always served.
+type Features struct {
+	GraphQL bool `yaml:"graphql" env:"FEATURE_GRAPHQL"`
+	GRPC    bool `yaml:"grpc" env:"FEATURE_GRPC"`
+	Docs    bool `yaml:"docs" env:"FEATURE_DOCS"`
+	Metrics bool `yaml:"metrics" env:"FEATURE_METRICS"`
+}
+
+// TracingExporters lists the accepted values of Tracing.Exporter.
+var TracingExporters = []string{"none", "stdout", "otlp"}
+
+// RateLimitStores lists the accepted values of RateLimit.Store.
+var RateLimitStores = []string{"memory", "mongodb"}
+
+// LogLevels lists the accepted values of Log.Level from the most to the least verbose.
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// Default returns the configuration used for every setting that no source sets.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Address:           ":8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       time.Minute,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownDelay:     5 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+			TrustedProxies:    []string{},
+		},
+		GRPC: GRPC{
+			Address: ":9090",
+		},
+		Database: Database{
+			URI:              "mongodb://localhost:27017",
+			Name:             "Company",
+			UserCollection:   "User",
+			ConnectTimeout:   10 * time.Second,
+			ConnectAttempts:  10,
+			ConnectBackoff:   time.Second,
+			OperationTimeout: 10 * time.Second,
+		},
+		Security: Security{
+			BcryptCost: 10,
+		},
+		CORS: CORS{
+			AllowedOrigins: []string{},
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+			AllowedHeaders: []string{"Content-Type", "Accept-Language", "If-Match", "If-None-Match", "If-Modified-Since", "Authorization", "traceparent", "tracestate", "X-Request-ID", "X-Tenant-ID"},
+			ExposedHeaders: []string{"ETag", "Location", "Content-Language", "Last-Modified", "X-Request-ID",
+				"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"},
+			MaxAge: 10 * time.Minute,
+		},
+		Log: Log{
+			Level: "info",
+		},
+		Storage: Storage{
+			BlobDirectory: filepath.Join(os.TempDir(), "user-service-blobs"),
+		},
+		Features: Features{
+			GraphQL: true,
+			GRPC:    true,
+			Docs:    true,
+			Metrics: true,
+		},
+		Health: Health{
+			Timeout: 2 * time.Second,
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			SampleRatio: 1,
+		},
+		RateLimit: RateLimit{
+			Store:    "memory",
+			Policies: []string{"POST /users 10/1h per ip"},
+		},
+		Tenancy: Tenancy{
+			DefaultTenant: model.DefaultTenantId,
+		},
+		Auth: Auth{
+			Required: true,
+		},
+	}
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FileEnv names the environment variable that points to the YAML file when the --config flag is absent.
+const FileEnv = "CONFIG_FILE"
+
+const fileFlag = "config"
+
+// setting is one leaf of Config together with the names it is read under.
+type setting struct {
+	path   string
+	env    string
+	flag   string
+	secret string
+	value  reflect.Value
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load builds the configuration from the defaults, the YAML file named by --config or CONFIG_FILE, the
+// environment and the flags in args, each source overriding the ones before it. Every problem found on the way
+// and in the result is reported at once, joined into the returned error.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	config := Default()
+	settings := settingsOf(&config)
+
+	flags := flag.NewFlagSet("user-service", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	file := flags.String(fileFlag, "", "")
+	for _, setting := range settings {
+		flags.String(setting.flag, "", "")
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	if *file == "" {
+		*file, _ = lookupEnv(FileEnv)
+	}
+
+	var problems []error
+	if *file != "" {
+		problems, err = loadFile(*file, &config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, setting := range settings {
+		if raw, ok := lookupEnv(setting.env); ok {
+			problems = appendProblem(problems, setting, raw, "environment variable "+setting.env)
+		}
+	}
+
+	flags.Visit(func(set *flag.Flag) {
+		for _, setting := range settings {
+			if setting.flag == set.Name {
+				problems = appendProblem(problems, setting, set.Value.String(), "flag --"+set.Name)
+			}
+		}
+	})
+
+	problems = append(problems, config.validate()...)
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+
+	return &config, nil
+}
+
+func appendProblem(problems []error, setting setting, raw string, source string) []error {
+	err := set(setting.value, raw)
+	if err != nil {
+		return append(problems, fmt.Errorf("%s: %s: %w", setting.path, source, err))
+	}
+
+	return problems
+}
+
+// Usage describes every flag along with its environment variable and default.
+func Usage(w io.Writer) {
+	defaults := Default()
+
+	fmt.Fprintf(w, "Usage: user-service [flags]\n       user-service config print [flags]\n\nFlags:\n")
+	fmt.Fprintf(w, "  --%s\n\tthe YAML configuration file (env %s)\n", fileFlag, FileEnv)
+	for _, setting := range settingsOf(&defaults) {
+		fmt.Fprintf(w, "  --%s\n\t%s (env %s, default %q)\n", setting.flag, setting.path, setting.env, format(setting.value))
+	}
+}
+
+// loadFile reads the YAML file into config. Values of the wrong type and unknown keys are returned as problems
+// so they are reported along with the rest; a file that cannot be read or parsed at all fails the load.
+func loadFile(name string, config *Config) ([]error, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(config)
+
+	var typeError *yaml.TypeError
+	if errors.As(err, &typeError) {
+		problems := make([]error, len(typeError.Errors))
+		for i, message := range typeError.Errors {
+			problems[i] = fmt.Errorf("%s: %s", name, message)
+		}
+
+		return problems, nil
+	} else if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil, nil
+}
+
+// settingsOf lists the leaves of config in declaration order, addressable so that sources can set them.
+func settingsOf(config *Config) []setting {
+	return appendSettings(nil, reflect.ValueOf(config).Elem(), "")
+}
+
+func appendSettings(settings []setting, value reflect.Value, prefix string) []setting {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		path := prefix + field.Tag.Get("yaml")
+
+		if field.Type.Kind() == reflect.Struct {
+			settings = appendSettings(settings, value.Field(i), path+".")
+			continue
+		}
+
+		settings = append(settings, setting{
+			path:   path,
+			env:    field.Tag.Get("env"),
+			flag:   flagName(path),
+			secret: field.Tag.Get("secret"),
+			value:  value.Field(i),
+		})
+	}
+
+	return settings
+}
+
+// flagName turns a path such as database.userCollection into database-user-collection.
+func flagName(path string) string {
+	var name strings.Builder
+	for _, r := range path {
+		switch {
+		case r == '.':
+			name.WriteRune('-')
+		case unicode.IsUpper(r):
+			name.WriteRune('-')
+			name.WriteRune(unicode.ToLower(r))
+		default:
+			name.WriteRune(r)
+		}
+	}
+
+	return name.String()
+}
+
+// set parses raw into value. Lists are comma separated.
+func set(value reflect.Value, raw string) error {
+	if value.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 30s or 5m", raw)
+		}
+
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int:
+		number, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", raw)
+		}
+		value.SetInt(int64(number))
+	case reflect.Float64:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		value.SetFloat(number)
+	case reflect.Bool:
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", raw)
+		}
+		value.SetBool(flag)
+	case reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("settings of type %s are not supported", value.Type())
+	}
+
+	return nil
+}
+
+// format renders a value the way set reads it.
+func format(value reflect.Value) string {
+	if value.Type() == durationType {
+		return time.Duration(value.Int()).String()
+	}
+	if value.Kind() == reflect.Slice {
+		return strings.Join(value.Interface().([]string), ",")
+	}
+
+	return fmt.Sprint(value.Interface())
+}
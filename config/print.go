@@ -0,0 +1,57 @@
+package config
+
+import (
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/url"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+// Print writes the configuration as YAML that Load accepts again, with secrets redacted. Settings tagged
+// secret:"url" only have the passwords in their URL redacted, so the host stays visible.
+func Print(w io.Writer, config *Config) error {
+	redactedConfig := *config
+	for _, setting := range settingsOf(&redactedConfig) {
+		if setting.secret != "" && setting.value.String() != "" {
+			setting.value.SetString(redact(setting.value.String(), setting.secret))
+		}
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+
+	err := encoder.Encode(redactedConfig)
+	if err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+func redact(value string, secret string) string {
+	if secret != "url" {
+		return redacted
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return redacted
+	}
+
+	if _, hasPassword := parsed.User.Password(); hasPassword {
+		parsed.User = url.UserPassword(parsed.User.Username(), redacted)
+	}
+
+	// Connection strings can carry passwords as options too, such as tlsCertificateKeyFilePassword.
+	query := parsed.Query()
+	for key := range query {
+		if strings.Contains(strings.ToLower(key), "password") {
+			query.Set(key, redacted)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String()
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The keys a rate limit policy can count requests by. Policies per IP address run before authentication, so that
+// requests with invalid keys are counted too. Policies per user count the requests to the user of a
+// /users/:id route, as callers authenticate with API keys rather than as users, and policies per API key count
+// the requests made with a key. Requests without the user or API key that a policy is keyed by are counted by
+// their IP address instead.
+const (
+	RateLimitKeyIP     = "ip"
+	RateLimitKeyUser   = "user"
+	RateLimitKeyApiKey = "apiKey"
+)
+
+// RateLimitKeys lists the accepted keys of a rate limit policy.
+var RateLimitKeys = []string{RateLimitKeyIP, RateLimitKeyUser, RateLimitKeyApiKey}
+
+// AnyRoute is the route of the policies that apply to every route without policies of its own.
+const AnyRoute = "*"
+
+// RateLimitPolicy is one parsed entry of RateLimit.Policies. Every client gets a bucket of Requests tokens that
+// refills evenly over Period, so bursts of up to Requests are allowed.
+type RateLimitPolicy struct {
+	// Route is a method and route template such as POST /users, or AnyRoute. Custom methods are named with their
+	// verb, as in POST /users:batchGet.
+	Route    string
+	Requests int
+	Period   time.Duration
+	Key      string
+}
+
+// ParseRateLimitPolicy reads a policy written as "<route> <requests>/<period> per <key>".
+func ParseRateLimitPolicy(policy string) (RateLimitPolicy, error) {
+	fields := strings.Fields(policy)
+	if len(fields) < 4 || fields[len(fields)-2] != "per" {
+		return RateLimitPolicy{}, fmt.Errorf("%q is not a policy such as POST /users 10/1h per ip", policy)
+	}
+
+	route := strings.Join(fields[:len(fields)-3], " ")
+	if !isRoute(route) {
+		return RateLimitPolicy{}, fmt.Errorf("%q: %q is not * or a method and route such as POST /users", policy, route)
+	}
+
+	requests, period, found := strings.Cut(fields[len(fields)-3], "/")
+	parsed := RateLimitPolicy{Route: route, Key: fields[len(fields)-1]}
+
+	var err error
+	parsed.Requests, err = strconv.Atoi(requests)
+	if !found || err != nil || parsed.Requests < 1 {
+		return RateLimitPolicy{}, fmt.Errorf("%q: %q is not a rate such as 10/1h", policy, fields[len(fields)-3])
+	}
+	parsed.Period, err = time.ParseDuration(period)
+	if err != nil || parsed.Period <= 0 {
+		return RateLimitPolicy{}, fmt.Errorf("%q: %q is not a positive duration such as 1h", policy, period)
+	}
+
+	if !contains(RateLimitKeys, parsed.Key) {
+		return RateLimitPolicy{}, fmt.Errorf("%q: %q is not one of %s", policy, parsed.Key, strings.Join(RateLimitKeys, ", "))
+	}
+
+	return parsed, nil
+}
+
+// ParsedPolicies parses every policy, which validation has already checked.
+func (r RateLimit) ParsedPolicies() []RateLimitPolicy {
+	policies := make([]RateLimitPolicy, 0, len(r.Policies))
+	for _, policy := range r.Policies {
+		parsed, err := ParseRateLimitPolicy(policy)
+		if err == nil {
+			policies = append(policies, parsed)
+		}
+	}
+
+	return policies
+}
+
+// String writes the policy the way ParseRateLimitPolicy reads it.
+func (p RateLimitPolicy) String() string {
+	return fmt.Sprintf("%s %d/%s per %s", p.Route, p.Requests, p.Period, p.Key)
+}
+
+func isRoute(route string) bool {
+	if route == AnyRoute {
+		return true
+	}
+
+	method, path, found := strings.Cut(route, " ")
+	if !found || method == "" || strings.ToUpper(method) != method || !strings.HasPrefix(path, "/") {
+		return false
+	}
+
+	return !strings.ContainsAny(path, " \t")
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+	"user-service/model"
+)
+
+// minBootstrapKeyLength keeps the bootstrap key about as hard to guess as the keys the service issues.
+const minBootstrapKeyLength = 32
+
+// validate returns every problem with the configuration rather than stopping at the first, so a broken
+// deployment can be fixed in one go.
+func (c *Config) validate() []error {
+	var problems []error
+	problem := func(path string, format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf(path+": "+format, args...))
+	}
+
+	if !isListenAddress(c.Server.Address) {
+		problem("server.address", "%q is not an address such as :8080 or 127.0.0.1:8080", c.Server.Address)
+	}
+	if c.Server.ReadHeaderTimeout <= 0 {
+		problem("server.readHeaderTimeout", "must be positive")
+	}
+	for _, timeout := range []struct {
+		path  string
+		value time.Duration
+	}{
+		{"server.readTimeout", c.Server.ReadTimeout},
+		{"server.writeTimeout", c.Server.WriteTimeout},
+		{"server.idleTimeout", c.Server.IdleTimeout},
+	} {
+		if timeout.value < 0 {
+			problem(timeout.path, "must not be negative, 0 turns it off")
+		}
+	}
+	if c.Server.ShutdownDelay < 0 {
+		problem("server.shutdownDelay", "must not be negative, 0 turns it off")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		problem("server.shutdownTimeout", "must be positive")
+	}
+
+	if c.Features.GRPC && !isListenAddress(c.GRPC.Address) {
+		problem("grpc.address", "%q is not an address such as :9090 or 127.0.0.1:9090", c.GRPC.Address)
+	}
+	if c.Features.GRPC && c.GRPC.Address == c.Server.Address {
+		problem("grpc.address", "must differ from server.address")
+	}
+
+	if !strings.HasPrefix(c.Database.URI, "mongodb://") && !strings.HasPrefix(c.Database.URI, "mongodb+srv://") {
+		problem("database.uri", "must start with mongodb:// or mongodb+srv://")
+	}
+	if c.Database.Name == "" || strings.ContainsAny(c.Database.Name, `/\. "$`) {
+		problem("database.name", "%q is not a valid database name", c.Database.Name)
+	}
+	if c.Database.UserCollection == "" || strings.Contains(c.Database.UserCollection, "$") || strings.HasPrefix(c.Database.UserCollection, "system.") {
+		problem("database.userCollection", "%q is not a valid collection name", c.Database.UserCollection)
+	}
+	if c.Database.ConnectTimeout <= 0 {
+		problem("database.connectTimeout", "must be positive")
+	}
+	if c.Database.ConnectAttempts < 1 {
+		problem("database.connectAttempts", "must be at least 1")
+	}
+	if c.Database.ConnectBackoff <= 0 {
+		problem("database.connectBackoff", "must be positive")
+	}
+	if c.Database.OperationTimeout <= 0 {
+		problem("database.operationTimeout", "must be positive")
+	}
+
+	if c.Security.BcryptCost < bcrypt.MinCost || c.Security.BcryptCost > bcrypt.MaxCost {
+		problem("security.bcryptCost", "must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			if c.CORS.AllowCredentials {
+				problem("cors.allowedOrigins", "* cannot be combined with cors.allowCredentials")
+			}
+		} else if !isOrigin(origin) {
+			problem("cors.allowedOrigins", "%q is not an origin such as https://example.com", origin)
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		problem("cors.maxAge", "must not be negative")
+	}
+
+	if !contains(LogLevels, c.Log.Level) {
+		problem("log.level", "%q is not one of %s", c.Log.Level, strings.Join(LogLevels, ", "))
+	}
+
+	if c.Storage.BlobDirectory == "" {
+		problem("storage.blobDirectory", "must not be empty")
+	}
+
+	if c.Health.Timeout <= 0 {
+		problem("health.timeout", "must be positive")
+	}
+
+	if !contains(TracingExporters, c.Tracing.Exporter) {
+		problem("tracing.exporter", "%q is not one of %s", c.Tracing.Exporter, strings.Join(TracingExporters, ", "))
+	}
+	if c.Tracing.Endpoint != "" && !isHTTPURL(c.Tracing.Endpoint) {
+		problem("tracing.endpoint", "%q is not an http or https URL", c.Tracing.Endpoint)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problem("tracing.sampleRatio", "must be between 0 and 1")
+	}
+
+	for _, proxy := range c.Server.TrustedProxies {
+		if !isIPOrCIDR(proxy) {
+			problem("server.trustedProxies", "%q is not an IP address or CIDR range", proxy)
+		}
+	}
+
+	if !contains(RateLimitStores, c.RateLimit.Store) {
+		problem("rateLimit.store", "%q is not one of %s", c.RateLimit.Store, strings.Join(RateLimitStores, ", "))
+	}
+	for _, policy := range c.RateLimit.Policies {
+		_, err := ParseRateLimitPolicy(policy)
+		if err != nil {
+			problem("rateLimit.policies", "%v", err)
+		}
+	}
+
+	if c.Auth.BootstrapKey != "" && len(c.Auth.BootstrapKey) < minBootstrapKeyLength {
+		problem("auth.bootstrapKey", "must be at least %d characters long", minBootstrapKeyLength)
+	}
+
+	if c.Tenancy.DefaultTenant != "" && !model.IsTenantId(c.Tenancy.DefaultTenant) {
+		problem("tenancy.defaultTenant", "%q is not a tenant id of lowercase letters, digits and hyphens", c.Tenancy.DefaultTenant)
+	}
+	if c.Tenancy.BaseDomain != "" && !isDomain(c.Tenancy.BaseDomain) {
+		problem("tenancy.baseDomain", "%q is not a lowercase domain such as users.example.com", c.Tenancy.BaseDomain)
+	}
+
+	return problems
+}
+
+func isIPOrCIDR(value string) bool {
+	if net.ParseIP(value) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(value)
+
+	return err == nil
+}
+
+// isDomain accepts lowercase domains, whose labels follow the same rules as tenant ids.
+func isDomain(value string) bool {
+	for _, label := range strings.Split(value, ".") {
+		if !model.IsTenantId(label) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isListenAddress(address string) bool {
+	_, port, err := net.SplitHostPort(address)
+	return err == nil && port != ""
+}
+
+// isOrigin accepts the scheme, host and optional port that browsers send in the Origin header, and nothing more.
+func isOrigin(origin string) bool {
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "" && parsed.User == nil &&
+		parsed.Path == "" && parsed.RawQuery == "" && parsed.Fragment == ""
+}
+
+func isHTTPURL(value string) bool {
+	parsed, err := url.Parse(value)
+
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	errs "user-service/error"
+	"user-service/i18n"
+	"user-service/model"
+	"user-service/service"
+)
+
+// apiKeyScheme is the authentication scheme of the Authorization header, as in Authorization: ApiKey usk_....
+const apiKeyScheme = "ApiKey"
+
+// scopesKey is the key of the gin context under which Authenticate stores the scopes of the request.
+const scopesKey = "scopes"
+
+type ApiKeyController struct {
+	problemResponder
+	apiKeyService   service.ApiKeyServiceInterface
+	validator       *validator.Validate
+	anonymousScopes []string
+}
+
+// NewApiKeyController grants requests without an API key the anonymousScopes.
+func NewApiKeyController(apiKeyService service.ApiKeyServiceInterface, validator *validator.Validate, catalog *i18n.Catalog, logger *slog.Logger, anonymousScopes []string) *ApiKeyController {
+	return &ApiKeyController{
+		problemResponder: newProblemResponder(catalog, logger),
+		apiKeyService:    apiKeyService,
+		validator:        validator,
+		anonymousScopes:  anonymousScopes,
+	}
+}
+
+func (c *ApiKeyController) Create(ctx *gin.Context) {
+	var createViewModel model.CreateApiKeyViewModel
+
+	err := ctx.ShouldBindJSON(&createViewModel)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	err = c.validator.Struct(createViewModel)
+	if err != nil {
+		c.configureValidationErrorResponse(ctx, err)
+		return
+	}
+
+	domainModel, err := c.apiKeyService.Create(requestContext(ctx), model.CreateApiKeyDomainModel{
+		TenantId:  createViewModel.TenantId,
+		Name:      createViewModel.Name,
+		Scopes:    createViewModel.Scopes,
+		ExpiresAt: createViewModel.ExpiresAt,
+	})
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.IndentedJSON(http.StatusCreated, copyApiKeyDomainModelToViewModel(domainModel))
+}
+
+func (c *ApiKeyController) GetAll(ctx *gin.Context) {
+	domainModels, err := c.apiKeyService.GetAll(requestContext(ctx))
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	viewModels := make([]model.ApiKeyViewModel, len(domainModels))
+	for i, domainModel := range domainModels {
+		viewModels[i] = copyApiKeyDomainModelToViewModel(domainModel)
+	}
+
+	ctx.IndentedJSON(http.StatusOK, viewModels)
+}
+
+func (c *ApiKeyController) Revoke(ctx *gin.Context) {
+	err := c.apiKeyService.Revoke(requestContext(ctx), ctx.Param("id"))
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Rotate issues the replacement of a key. The body is optional and only sets how long the old key keeps working.
+func (c *ApiKeyController) Rotate(ctx *gin.Context) {
+	var rotateViewModel model.RotateApiKeyViewModel
+
+	err := ctx.ShouldBindJSON(&rotateViewModel)
+	if err != nil && !errors.Is(err, io.EOF) {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	err = c.validator.Struct(rotateViewModel)
+	if err != nil {
+		c.configureValidationErrorResponse(ctx, err)
+		return
+	}
+
+	overlap := service.DefaultRotationOverlap
+	if rotateViewModel.OverlapSeconds != nil {
+		overlap = time.Duration(*rotateViewModel.OverlapSeconds) * time.Second
+	}
+
+	domainModel, err := c.apiKeyService.Rotate(requestContext(ctx), ctx.Param("id"), overlap)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.IndentedJSON(http.StatusCreated, copyApiKeyDomainModelToViewModel(domainModel))
+}
+
+// Authenticate is a middleware that identifies requests by the key in their Authorization header and attributes
+// their writes to it. Requests without the header stay anonymous; a header with an invalid key or another
+// scheme is rejected with 401, so clients never go on anonymously by mistake.
+func (c *ApiKeyController) Authenticate(ctx *gin.Context) {
+	header := ctx.GetHeader("Authorization")
+	if header == "" {
+		return
+	}
+
+	scheme, key, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, apiKeyScheme) {
+		c.rejectUnauthenticated(ctx, errs.UnauthorizedError)
+		return
+	}
+
+	principal, err := c.apiKeyService.Authenticate(requestContext(ctx), strings.TrimSpace(key))
+	if err != nil {
+		c.rejectUnauthenticated(ctx, err)
+		return
+	}
+
+	ctx.Set(apiKeyIdKey, principal.ApiKeyId)
+	ctx.Set(scopesKey, principal.Scopes)
+	ctx.Request = ctx.Request.WithContext(service.WithPrincipal(ctx.Request.Context(), principal))
+}
+
+// Require returns a middleware that lets requests through whose key has scope, and anonymous requests if they
+// are granted scope. It has to run after Authenticate.
+func (c *ApiKeyController) Require(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		scopes, authenticated := ctx.Get(scopesKey)
+		if !authenticated {
+			if !slices.Contains(c.anonymousScopes, scope) {
+				c.rejectUnauthenticated(ctx, errs.UnauthorizedError)
+			}
+			return
+		}
+
+		if !slices.Contains(scopes.([]string), scope) {
+			c.configureErrorResponse(ctx, errs.ForbiddenError)
+			ctx.Abort()
+		}
+	}
+}
+
+func (c *ApiKeyController) rejectUnauthenticated(ctx *gin.Context, err error) {
+	if errors.Is(err, errs.UnauthorizedError) {
+		ctx.Header("WWW-Authenticate", apiKeyScheme)
+	}
+	c.configureErrorResponse(ctx, err)
+	ctx.Abort()
+}
+
+func copyApiKeyDomainModelToViewModel(domainModel *model.ApiKeyDomainModel) model.ApiKeyViewModel {
+	return model.ApiKeyViewModel{
+		Id:          domainModel.Id,
+		TenantId:    domainModel.TenantId,
+		Name:        domainModel.Name,
+		Prefix:      domainModel.Prefix,
+		Key:         domainModel.Key,
+		Scopes:      domainModel.Scopes,
+		CreatedAt:   domainModel.CreatedAt,
+		CreatedBy:   domainModel.CreatedBy,
+		ExpiresAt:   domainModel.ExpiresAt,
+		RevokedAt:   domainModel.RevokedAt,
+		LastUsedAt:  domainModel.LastUsedAt,
+		RotatedFrom: domainModel.RotatedFrom,
+	}
+}
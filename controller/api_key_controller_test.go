@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	errs "user-service/error"
+	"user-service/logging"
+	"user-service/model"
+	repositoryMock "user-service/repository/mock"
+	"user-service/service"
+	serviceMock "user-service/service/mock"
+)
+
+// authenticatedAs lets every key through as principal and leaves the rest to the embedded service.
+type authenticatedAs struct {
+	service.ApiKeyServiceInterface
+	principal *model.Principal
+}
+
+func (a authenticatedAs) Authenticate(context.Context, string) (*model.Principal, error) {
+	return a.principal, nil
+}
+
+func newApiKeyTestRouter(apiKeyService service.ApiKeyServiceInterface, anonymousScopes ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	classUnderTest := NewApiKeyController(apiKeyService, testValidator, testCatalog, logging.Discard(), anonymousScopes)
+	router.Use(classUnderTest.Authenticate)
+	router.POST("/apikeys", classUnderTest.Require(model.ScopeApiKeysManage), classUnderTest.Create)
+	router.POST("/apikeys/:id/rotate", classUnderTest.Require(model.ScopeApiKeysManage), classUnderTest.Rotate)
+	router.GET("/users", classUnderTest.Require(model.ScopeUsersRead), func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.GetString(apiKeyIdKey))
+	})
+
+	return router
+}
+
+func Test_ApiKeyCreate_Should_Return_201_With_The_Key(t *testing.T) {
+	apiKeyServiceMock := new(serviceMock.ApiKeyServiceInterface)
+	apiKeyServiceMock.On("Authenticate", mock.Anything, "usk_0123456789ab_secret").Return(&model.Principal{ApiKeyId: "admin", Scopes: []string{model.ScopeApiKeysManage}}, nil).Once()
+	apiKeyServiceMock.On("Create", mock.Anything, model.CreateApiKeyDomainModel{Name: "nightly export", Scopes: []string{model.ScopeUsersRead}}).
+		Return(&model.ApiKeyDomainModel{Id: "1", Name: "nightly export", Prefix: "usk_abcdefabcdef", Key: "usk_abcdefabcdef_secret", Scopes: []string{model.ScopeUsersRead}}, nil).Once()
+
+	request := httptest.NewRequest(http.MethodPost, "/apikeys", strings.NewReader(`{"name":"nightly export","scopes":["users:read"]}`))
+	request.Header.Set("Authorization", "ApiKey usk_0123456789ab_secret")
+	responseRecorder := httptest.NewRecorder()
+	newApiKeyTestRouter(apiKeyServiceMock).ServeHTTP(responseRecorder, request)
+
+	var viewModel model.ApiKeyViewModel
+	json.NewDecoder(responseRecorder.Body).Decode(&viewModel)
+
+	assert.Equal(t, http.StatusCreated, responseRecorder.Code)
+	assert.Equal(t, "no-store", responseRecorder.Header().Get("Cache-Control"))
+	assert.Equal(t, "usk_abcdefabcdef_secret", viewModel.Key)
+	apiKeyServiceMock.AssertExpectations(t)
+}
+
+func Test_ApiKeyCreate_Should_Return_400_When_A_Scope_Is_Unknown(t *testing.T) {
+	apiKeyServiceMock := new(serviceMock.ApiKeyServiceInterface)
+	apiKeyServiceMock.On("Authenticate", mock.Anything, mock.Anything).Return(&model.Principal{ApiKeyId: "admin", Scopes: []string{model.ScopeApiKeysManage}}, nil).Once()
+
+	request := httptest.NewRequest(http.MethodPost, "/apikeys", strings.NewReader(`{"name":"nightly export","scopes":["users:everything"]}`))
+	request.Header.Set("Authorization", "ApiKey usk_0123456789ab_secret")
+	responseRecorder := httptest.NewRecorder()
+	newApiKeyTestRouter(apiKeyServiceMock).ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+	apiKeyServiceMock.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func Test_ApiKeyCreate_Should_Return_403_When_A_Scope_Is_Not_Held_By_The_Calling_Key(t *testing.T) {
+	apiKeyRepositoryMock := new(repositoryMock.ApiKeyRepositoryInterface)
+	apiKeyService := authenticatedAs{
+		ApiKeyServiceInterface: service.NewApiKeyService(apiKeyRepositoryMock, new(serviceMock.TenantServiceInterface), "", logging.Discard()),
+		principal:              &model.Principal{ApiKeyId: "admin", Scopes: []string{model.ScopeApiKeysManage}},
+	}
+
+	for _, scope := range []string{model.ScopeUsersWrite, model.ScopeProfileSchemaWrite, model.ScopeTenantsManage} {
+		request := httptest.NewRequest(http.MethodPost, "/apikeys", strings.NewReader(`{"name":"escalation","scopes":["`+scope+`"]}`))
+		request.Header.Set("Authorization", "ApiKey usk_0123456789ab_secret")
+		responseRecorder := httptest.NewRecorder()
+		newApiKeyTestRouter(apiKeyService).ServeHTTP(responseRecorder, request)
+
+		assert.Equal(t, http.StatusForbidden, responseRecorder.Code, scope)
+	}
+	apiKeyRepositoryMock.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func Test_ApiKeyRotate_Should_Default_The_Overlap_When_The_Body_Is_Empty(t *testing.T) {
+	apiKeyServiceMock := new(serviceMock.ApiKeyServiceInterface)
+	apiKeyServiceMock.On("Authenticate", mock.Anything, mock.Anything).Return(&model.Principal{ApiKeyId: "admin", Scopes: []string{model.ScopeApiKeysManage}}, nil).Twice()
+	apiKeyServiceMock.On("Rotate", mock.Anything, "1", 24*time.Hour).Return(&model.ApiKeyDomainModel{Id: "2", Key: "usk_abcdefabcdef_secret"}, nil).Once()
+	apiKeyServiceMock.On("Rotate", mock.Anything, "1", time.Minute).Return(&model.ApiKeyDomainModel{Id: "3", Key: "usk_fedcbafedcba_secret"}, nil).Once()
+
+	for _, body := range []string{"", `{"overlapSeconds":60}`} {
+		request := httptest.NewRequest(http.MethodPost, "/apikeys/1/rotate", strings.NewReader(body))
+		request.Header.Set("Authorization", "ApiKey usk_0123456789ab_secret")
+		responseRecorder := httptest.NewRecorder()
+		newApiKeyTestRouter(apiKeyServiceMock).ServeHTTP(responseRecorder, request)
+
+		assert.Equal(t, http.StatusCreated, responseRecorder.Code)
+	}
+	apiKeyServiceMock.AssertExpectations(t)
+}
+
+func Test_Authenticate_Should_Return_401_When_The_Key_Or_Scheme_Is_Invalid(t *testing.T) {
+	apiKeyServiceMock := new(serviceMock.ApiKeyServiceInterface)
+	apiKeyServiceMock.On("Authenticate", mock.Anything, "usk_0123456789ab_wrong").Return(nil, errs.UnauthorizedError).Once()
+
+	for _, header := range []string{"ApiKey usk_0123456789ab_wrong", "Bearer token"} {
+		request := httptest.NewRequest(http.MethodGet, "/users", nil)
+		request.Header.Set("Authorization", header)
+		responseRecorder := httptest.NewRecorder()
+		newApiKeyTestRouter(apiKeyServiceMock, model.ScopeUsersRead).ServeHTTP(responseRecorder, request)
+
+		assert.Equal(t, http.StatusUnauthorized, responseRecorder.Code, header)
+		assert.Equal(t, "ApiKey", responseRecorder.Header().Get("WWW-Authenticate"))
+	}
+}
+
+func Test_Require_Should_Return_403_When_The_Key_Lacks_The_Scope(t *testing.T) {
+	apiKeyServiceMock := new(serviceMock.ApiKeyServiceInterface)
+	apiKeyServiceMock.On("Authenticate", mock.Anything, mock.Anything).Return(&model.Principal{ApiKeyId: "reader", Scopes: []string{model.ScopeUsersRead}}, nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/apikeys", strings.NewReader(`{}`))
+	request.Header.Set("Authorization", "ApiKey usk_0123456789ab_secret")
+	responseRecorder := httptest.NewRecorder()
+	newApiKeyTestRouter(apiKeyServiceMock).ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusForbidden, responseRecorder.Code)
+
+	request = httptest.NewRequest(http.MethodGet, "/users", nil)
+	request.Header.Set("Authorization", "ApiKey usk_0123456789ab_secret")
+	responseRecorder = httptest.NewRecorder()
+	newApiKeyTestRouter(apiKeyServiceMock).ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, "reader", responseRecorder.Body.String())
+}
+
+func Test_Require_Should_Only_Let_Anonymous_Requests_Through_With_The_Anonymous_Scopes(t *testing.T) {
+	apiKeyServiceMock := new(serviceMock.ApiKeyServiceInterface)
+	router := newApiKeyTestRouter(apiKeyServiceMock, model.ScopeUsersRead)
+
+	responseRecorder := httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+
+	responseRecorder = httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/apikeys", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusUnauthorized, responseRecorder.Code)
+	apiKeyServiceMock.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything)
+}
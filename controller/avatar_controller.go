@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"user-service/avatar"
+	errs "user-service/error"
+	"user-service/i18n"
+	"user-service/service"
+)
+
+// multipartOverhead allows for the boundaries and part headers around the image in an upload.
+const multipartOverhead = 64 << 10
+
+// avatarFormField names the part of a multipart upload that holds the image.
+const avatarFormField = "avatar"
+
+// avatarCacheControl lets clients keep avatars but makes them revalidate with the ETag, so a new upload shows at
+// once. Avatars are private, so shared caches keep no copy past the deletion of a user.
+const avatarCacheControl = "private, no-cache"
+
+type AvatarController struct {
+	problemResponder
+	avatarService service.AvatarServiceInterface
+}
+
+func NewAvatarController(avatarService service.AvatarServiceInterface, catalog *i18n.Catalog, logger *slog.Logger) *AvatarController {
+	return &AvatarController{
+		problemResponder: newProblemResponder(catalog, logger),
+		avatarService:    avatarService,
+	}
+}
+
+// Upload replaces the avatar of a user with the PNG or JPEG image in the avatar part of a multipart/form-data body.
+func (c *AvatarController) Upload(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, avatar.MaxFileSize+multipartOverhead)
+
+	fileHeader, err := ctx.FormFile(avatarFormField)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			c.configureErrorResponse(ctx, errs.PayloadTooLargeError)
+			return
+		}
+
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	if fileHeader.Size > avatar.MaxFileSize {
+		c.configureErrorResponse(ctx, errs.PayloadTooLargeError)
+		return
+	}
+
+	// The declared type is only checked to fail early; the service sniffs the type from the content.
+	if declared := fileHeader.Header.Get("Content-Type"); declared != "" {
+		mediaType, _, _ := mime.ParseMediaType(declared)
+		if mediaType != "image/png" && mediaType != "image/jpeg" {
+			c.configureErrorResponse(ctx, errs.UnsupportedMediaTypeError)
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.logger.ErrorContext(requestContext(ctx), "opening the uploaded avatar failed", "error", err)
+		c.configureErrorResponse(ctx, errs.ServerError)
+		return
+	}
+	defer file.Close()
+
+	err = c.avatarService.Upload(requestContext(ctx), ctx.Param("id"), file)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Get serves the avatar of a user as a square thumbnail. The size query parameter picks one of avatar.Sizes and
+// defaults to avatar.DefaultSize.
+func (c *AvatarController) Get(ctx *gin.Context) {
+	size, err := strconv.Atoi(ctx.DefaultQuery("size", strconv.Itoa(avatar.DefaultSize)))
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	blob, err := c.avatarService.Get(requestContext(ctx), ctx.Param("id"), size)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+	defer blob.Content.Close()
+
+	etag := fmt.Sprintf(`"%x-%x"`, blob.ModTime.UnixNano(), blob.Size)
+
+	ctx.Header("ETag", etag)
+	ctx.Header("Last-Modified", blob.ModTime.UTC().Format(http.TimeFormat))
+	ctx.Header("Cache-Control", avatarCacheControl)
+
+	if isNotModified(ctx, etag, blob.ModTime) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	ctx.Header("Content-Type", blob.ContentType)
+	ctx.Header("Content-Length", strconv.FormatInt(blob.Size, 10))
+	ctx.Status(http.StatusOK)
+
+	_, err = io.Copy(ctx.Writer, blob.Content)
+	if err != nil {
+		c.logger.WarnContext(requestContext(ctx), "sending the avatar was cut off", "error", err)
+	}
+}
+
+// isNotModified evaluates If-None-Match, or If-Modified-Since when that is absent. If-None-Match uses weak
+// comparison, so a W/ prefix is ignored.
+func isNotModified(ctx *gin.Context, etag string, modTime time.Time) bool {
+	if header := ctx.GetHeader("If-None-Match"); header != "" {
+		for _, tag := range strings.Split(header, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	since, err := http.ParseTime(ctx.GetHeader("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !modTime.Truncate(time.Second).After(since)
+}
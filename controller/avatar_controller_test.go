@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+	"user-service/avatar"
+	errs "user-service/error"
+	"user-service/logging"
+	"user-service/model"
+	"user-service/repository"
+	serviceMock "user-service/service/mock"
+)
+
+func newAvatarTestRouter(avatarServiceMock *serviceMock.AvatarServiceInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	classUnderTest := NewAvatarController(avatarServiceMock, testCatalog, logging.Discard())
+	router.GET("/users/:id/avatar", classUnderTest.Get)
+	router.PUT("/users/:id/avatar", classUnderTest.Upload)
+
+	return router
+}
+
+func newAvatarUploadRequest(contentType string, content []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="avatar"; filename="avatar"`)
+	header.Set("Content-Type", contentType)
+	part, _ := writer.CreatePart(header)
+	part.Write(content)
+	writer.Close()
+
+	request := httptest.NewRequest(http.MethodPut, "/users/1/avatar", &body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return request
+}
+
+func Test_AvatarUpload_Should_Return_204_When_Nothing_Fails(t *testing.T) {
+	avatarServiceMock := new(serviceMock.AvatarServiceInterface)
+	avatarServiceMock.On("Upload", mock.Anything, "1", mock.Anything).Return(nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	newAvatarTestRouter(avatarServiceMock).ServeHTTP(responseRecorder, newAvatarUploadRequest("image/png", []byte("png")))
+
+	assert.Equal(t, http.StatusNoContent, responseRecorder.Code)
+	avatarServiceMock.AssertExpectations(t)
+}
+
+func Test_AvatarUpload_Should_Return_415_When_Declared_Type_Is_Not_PNG_Or_JPEG(t *testing.T) {
+	avatarServiceMock := new(serviceMock.AvatarServiceInterface)
+
+	responseRecorder := httptest.NewRecorder()
+	newAvatarTestRouter(avatarServiceMock).ServeHTTP(responseRecorder, newAvatarUploadRequest("image/gif", []byte("gif")))
+
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Body).Decode(&problem)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, responseRecorder.Code)
+	assert.Equal(t, "/problems/unsupported-media-type", problem.Type)
+	avatarServiceMock.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_AvatarUpload_Should_Return_413_When_Image_Is_Too_Large(t *testing.T) {
+	avatarServiceMock := new(serviceMock.AvatarServiceInterface)
+
+	responseRecorder := httptest.NewRecorder()
+	newAvatarTestRouter(avatarServiceMock).ServeHTTP(responseRecorder, newAvatarUploadRequest("image/png", make([]byte, avatar.MaxFileSize+1)))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, responseRecorder.Code)
+	avatarServiceMock.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_AvatarUpload_Should_Return_400_When_Body_Is_Not_Multipart(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPut, "/users/1/avatar", strings.NewReader("png"))
+	request.Header.Set("Content-Type", "image/png")
+
+	responseRecorder := httptest.NewRecorder()
+	newAvatarTestRouter(new(serviceMock.AvatarServiceInterface)).ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+}
+
+func Test_AvatarGet_Should_Serve_The_Default_Size_With_Caching_Headers(t *testing.T) {
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	avatarServiceMock := new(serviceMock.AvatarServiceInterface)
+	avatarServiceMock.On("Get", mock.Anything, "1", avatar.DefaultSize).Return(&repository.Blob{
+		Content:     io.NopCloser(strings.NewReader("thumbnail")),
+		ContentType: "image/png",
+		Size:        9,
+		ModTime:     modTime,
+	}, nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	newAvatarTestRouter(avatarServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, "/users/1/avatar", nil))
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, "thumbnail", responseRecorder.Body.String())
+	assert.Equal(t, "image/png", responseRecorder.Header().Get("Content-Type"))
+	assert.Equal(t, "Wed, 01 May 2024 12:00:00 GMT", responseRecorder.Header().Get("Last-Modified"))
+	assert.Equal(t, avatarCacheControl, responseRecorder.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, responseRecorder.Header().Get("ETag"))
+	avatarServiceMock.AssertExpectations(t)
+}
+
+func Test_AvatarGet_Should_Return_304_When_The_Client_Has_The_Current_Version(t *testing.T) {
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	newBlob := func() *repository.Blob {
+		return &repository.Blob{Content: io.NopCloser(strings.NewReader("thumbnail")), ContentType: "image/png", Size: 9, ModTime: modTime}
+	}
+
+	avatarServiceMock := new(serviceMock.AvatarServiceInterface)
+	avatarServiceMock.On("Get", mock.Anything, "1", 64).Return(newBlob(), nil).Once()
+	avatarServiceMock.On("Get", mock.Anything, "1", 64).Return(newBlob(), nil).Once()
+	avatarServiceMock.On("Get", mock.Anything, "1", 64).Return(newBlob(), nil).Once()
+	router := newAvatarTestRouter(avatarServiceMock)
+
+	responseRecorder := httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, "/users/1/avatar?size=64", nil))
+	etag := responseRecorder.Header().Get("ETag")
+
+	request := httptest.NewRequest(http.MethodGet, "/users/1/avatar?size=64", nil)
+	request.Header.Set("If-None-Match", `"other", W/`+etag)
+	responseRecorder = httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusNotModified, responseRecorder.Code)
+	assert.Empty(t, responseRecorder.Body.String())
+
+	request = httptest.NewRequest(http.MethodGet, "/users/1/avatar?size=64", nil)
+	request.Header.Set("If-Modified-Since", "Wed, 01 May 2024 12:00:00 GMT")
+	responseRecorder = httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusNotModified, responseRecorder.Code)
+	avatarServiceMock.AssertExpectations(t)
+}
+
+func Test_AvatarGet_Should_Return_404_When_User_Has_No_Avatar(t *testing.T) {
+	avatarServiceMock := new(serviceMock.AvatarServiceInterface)
+	avatarServiceMock.On("Get", mock.Anything, "1", 32).Return(nil, errs.AvatarNotFoundError).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	newAvatarTestRouter(avatarServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, "/users/1/avatar?size=32", nil))
+
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Body).Decode(&problem)
+
+	assert.Equal(t, http.StatusNotFound, responseRecorder.Code)
+	assert.Equal(t, "/problems/avatar-not-found", problem.Type)
+}
+
+func Test_AvatarGet_Should_Return_400_When_Size_Is_Not_A_Number(t *testing.T) {
+	avatarServiceMock := new(serviceMock.AvatarServiceInterface)
+
+	responseRecorder := httptest.NewRecorder()
+	newAvatarTestRouter(avatarServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, "/users/1/avatar?size=large", nil))
+
+	assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+	avatarServiceMock.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything)
+}
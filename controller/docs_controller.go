@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+	"net/http"
+	"user-service/openapi"
+)
+
+// swaggerInitializer replaces the initializer shipped with the Swagger UI assets, which points at the
+// petstore example, with one that loads this service's document.
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "/openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+type DocsController struct {
+	document *openapi.Document
+}
+
+func NewDocsController(document *openapi.Document) *DocsController {
+	return &DocsController{
+		document: document,
+	}
+}
+
+func (c *DocsController) GetDocument(ctx *gin.Context) {
+	ctx.IndentedJSON(http.StatusOK, c.document)
+}
+
+func (c *DocsController) GetUI(ctx *gin.Context) {
+	filepath := ctx.Param("filepath")
+
+	if filepath == "/swagger-initializer.js" {
+		ctx.Data(http.StatusOK, "application/javascript", []byte(swaggerInitializer))
+		return
+	}
+
+	ctx.FileFromFS(filepath, http.FS(swaggerFiles.FS))
+}
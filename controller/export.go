@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+	"user-service/model"
+)
+
+// exportColumns lists the columns an export can contain, in their default order. Passwords are never exported.
+var exportColumns = []string{"id", "name", "email", "createdAt", "updatedAt", "createdBy", "updatedBy", "profile"}
+
+// exportColumnValues returns the value of every column. The profile is an object, which CSV exports hold as
+// JSON, the way the importer reads it back.
+var exportColumnValues = map[string]func(*model.UserDomainModel) interface{}{
+	"id":        func(user *model.UserDomainModel) interface{} { return user.Id },
+	"name":      func(user *model.UserDomainModel) interface{} { return user.Name },
+	"email":     func(user *model.UserDomainModel) interface{} { return user.Email },
+	"createdAt": func(user *model.UserDomainModel) interface{} { return user.CreatedAt.Format(time.RFC3339Nano) },
+	"updatedAt": func(user *model.UserDomainModel) interface{} { return user.UpdatedAt.Format(time.RFC3339Nano) },
+	"createdBy": func(user *model.UserDomainModel) interface{} { return user.CreatedBy },
+	"updatedBy": func(user *model.UserDomainModel) interface{} { return user.UpdatedBy },
+	"profile":   func(user *model.UserDomainModel) interface{} { return user.Profile },
+}
+
+// exportFormats maps the format query parameter to the content type and file extension of the export.
+var exportFormats = map[string]struct {
+	contentType string
+	extension   string
+	newWriter   func(io.Writer, []string) userExportWriter
+}{
+	"csv":    {"text/csv; charset=utf-8", "csv", newCSVExportWriter},
+	"ndjson": {"application/x-ndjson", "ndjson", newNDJSONExportWriter},
+	"json":   {"application/json; charset=utf-8", "json", newJSONExportWriter},
+}
+
+// userExportWriter encodes users one at a time. Close completes the document and must only be called when every
+// user was written, so a failed export is left visibly truncated.
+type userExportWriter interface {
+	Write(*model.UserDomainModel) error
+	Flush() error
+	Close() error
+}
+
+type csvExportWriter struct {
+	writer  *csv.Writer
+	columns []string
+	started bool
+}
+
+func newCSVExportWriter(w io.Writer, columns []string) userExportWriter {
+	return &csvExportWriter{writer: csv.NewWriter(w), columns: columns}
+}
+
+func (w *csvExportWriter) Write(user *model.UserDomainModel) error {
+	if !w.started {
+		w.started = true
+		w.writer.Write(w.columns)
+	}
+
+	record := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		value, err := csvValue(exportColumnValues[column](user))
+		if err != nil {
+			return err
+		}
+		record[i] = value
+	}
+
+	return w.writer.Write(record)
+}
+
+// csvValue writes strings as they are and objects as JSON. An empty profile is an empty field.
+func csvValue(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case map[string]interface{}:
+		if len(value) == 0 {
+			return "", nil
+		}
+	}
+
+	encoded, err := json.Marshal(value)
+	return string(encoded), err
+}
+
+func (w *csvExportWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func (w *csvExportWriter) Close() error {
+	if !w.started {
+		w.started = true
+		w.writer.Write(w.columns)
+	}
+
+	return w.Flush()
+}
+
+// objectExportWriter writes users as JSON objects with the keys in column order, either one per line or as the
+// elements of an array.
+type objectExportWriter struct {
+	writer    *bufio.Writer
+	columns   []string
+	array     bool
+	separator string
+}
+
+func newNDJSONExportWriter(w io.Writer, columns []string) userExportWriter {
+	return &objectExportWriter{writer: bufio.NewWriter(w), columns: columns}
+}
+
+func newJSONExportWriter(w io.Writer, columns []string) userExportWriter {
+	return &objectExportWriter{writer: bufio.NewWriter(w), columns: columns, array: true, separator: "["}
+}
+
+func (w *objectExportWriter) Write(user *model.UserDomainModel) error {
+	if w.array {
+		w.writer.WriteString(w.separator)
+		w.separator = ","
+	}
+
+	w.writer.WriteByte('{')
+	for i, column := range w.columns {
+		if i > 0 {
+			w.writer.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(column)
+		value, err := json.Marshal(exportColumnValues[column](user))
+		if err != nil {
+			return err
+		}
+
+		w.writer.Write(key)
+		w.writer.WriteByte(':')
+		w.writer.Write(value)
+	}
+	w.writer.WriteByte('}')
+
+	if !w.array {
+		w.writer.WriteByte('\n')
+	}
+
+	return nil
+}
+
+func (w *objectExportWriter) Flush() error {
+	return w.writer.Flush()
+}
+
+func (w *objectExportWriter) Close() error {
+	if w.array {
+		if w.separator == "[" {
+			w.writer.WriteString("[")
+		}
+		w.writer.WriteString("]\n")
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+	"strconv"
+	errs "user-service/error"
+	"user-service/i18n"
+	"user-service/model"
+	"user-service/service"
+)
+
+type GroupController struct {
+	problemResponder
+	groupService service.GroupServiceInterface
+	validator    *validator.Validate
+}
+
+func NewGroupController(groupService service.GroupServiceInterface, validator *validator.Validate, catalog *i18n.Catalog, logger *slog.Logger) *GroupController {
+	return &GroupController{
+		problemResponder: newProblemResponder(catalog, logger),
+		groupService:     groupService,
+		validator:        validator,
+	}
+}
+
+func (c *GroupController) Create(ctx *gin.Context) {
+	var createViewModel model.CreateGroupViewModel
+
+	err := ctx.ShouldBindJSON(&createViewModel)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	err = c.validator.Struct(createViewModel)
+	if err != nil {
+		c.configureValidationErrorResponse(ctx, err)
+		return
+	}
+
+	domainModel, err := c.groupService.Create(requestContext(ctx), model.CreateGroupDomainModel{
+		Name:        createViewModel.Name,
+		Description: createViewModel.Description,
+	})
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header("Location", "/groups/"+domainModel.Id)
+	ctx.IndentedJSON(http.StatusCreated, copyGroupDomainModelToViewModel(domainModel))
+}
+
+func (c *GroupController) GetAll(ctx *gin.Context) {
+	limit, err := parseLimit(ctx)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	domainModels, next, err := c.groupService.GetAll(requestContext(ctx), ctx.Query("after"), limit)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	setNextLink(ctx, limit, next)
+	ctx.IndentedJSON(http.StatusOK, copyGroupDomainModelsToViewModels(domainModels))
+}
+
+func (c *GroupController) GetById(ctx *gin.Context) {
+	domainModel, err := c.groupService.GetById(requestContext(ctx), ctx.Param("id"))
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.IndentedJSON(http.StatusOK, copyGroupDomainModelToViewModel(domainModel))
+}
+
+func (c *GroupController) UpdateById(ctx *gin.Context) {
+	var updateViewModel model.UpdateGroupViewModel
+
+	err := ctx.ShouldBindJSON(&updateViewModel)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	err = c.validator.Struct(updateViewModel)
+	if err != nil {
+		c.configureValidationErrorResponse(ctx, err)
+		return
+	}
+
+	domainModel, err := c.groupService.UpdateById(requestContext(ctx), ctx.Param("id"), model.UpdateGroupDomainModel{
+		Name:        updateViewModel.Name,
+		Description: updateViewModel.Description,
+	})
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.IndentedJSON(http.StatusOK, copyGroupDomainModelToViewModel(domainModel))
+}
+
+func (c *GroupController) DeleteById(ctx *gin.Context) {
+	err := c.groupService.DeleteById(requestContext(ctx), ctx.Param("id"))
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// GetMembers lists the users and groups that are direct members of the group.
+func (c *GroupController) GetMembers(ctx *gin.Context) {
+	limit, err := parseLimit(ctx)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	domainModels, next, err := c.groupService.GetMembers(requestContext(ctx), ctx.Param("id"), ctx.Query("after"), limit)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	viewModels := make([]model.GroupMemberViewModel, len(domainModels))
+	for i, domainModel := range domainModels {
+		viewModels[i] = model.GroupMemberViewModel{
+			Type:    domainModel.Type,
+			Id:      domainModel.Id,
+			AddedAt: domainModel.AddedAt,
+			AddedBy: domainModel.AddedBy,
+		}
+	}
+
+	setNextLink(ctx, limit, next)
+	ctx.IndentedJSON(http.StatusOK, viewModels)
+}
+
+// AddMembers adds every given user and group and reports each one separately, so unknown ids and groups that
+// would close a cycle do not fail the batch.
+func (c *GroupController) AddMembers(ctx *gin.Context) {
+	members, ok := c.bindMembers(ctx)
+	if !ok {
+		return
+	}
+
+	results, err := c.groupService.AddMembers(requestContext(ctx), ctx.Param("id"), members)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.IndentedJSON(http.StatusOK, c.copyBatchResultsToViewModel(ctx, results))
+}
+
+// RemoveMembers removes every given user and group that is a member of the group.
+func (c *GroupController) RemoveMembers(ctx *gin.Context) {
+	members, ok := c.bindMembers(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.groupService.RemoveMembers(requestContext(ctx), ctx.Param("id"), members)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (c *GroupController) bindMembers(ctx *gin.Context) (model.GroupMembersDomainModel, bool) {
+	var membersViewModel model.GroupMembersViewModel
+
+	err := ctx.ShouldBindJSON(&membersViewModel)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return model.GroupMembersDomainModel{}, false
+	}
+
+	err = c.validator.Struct(membersViewModel)
+	if err != nil {
+		c.configureValidationErrorResponse(ctx, err)
+		return model.GroupMembersDomainModel{}, false
+	}
+
+	return model.GroupMembersDomainModel{UserIds: membersViewModel.Users, GroupIds: membersViewModel.Groups}, true
+}
+
+// GetGroupsOfUser lists the groups the user in the path is a direct member of.
+func (c *GroupController) GetGroupsOfUser(ctx *gin.Context) {
+	limit, err := parseLimit(ctx)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	domainModels, next, err := c.groupService.GetGroupsOfUser(requestContext(ctx), ctx.Param("id"), ctx.Query("after"), limit)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	setNextLink(ctx, limit, next)
+	ctx.IndentedJSON(http.StatusOK, copyGroupDomainModelsToViewModels(domainModels))
+}
+
+// parseLimit reads the optional limit query of a paged list, which GetAll of the users bounds the same way.
+func parseLimit(ctx *gin.Context) (int, error) {
+	limit := ctx.Query("limit")
+	if limit == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.Atoi(limit)
+	if err != nil || parsed < 1 || parsed > MaxPageSize {
+		return 0, errs.BadRequestError
+	}
+
+	return parsed, nil
+}
+
+// setNextLink points the Link header at the page after the current one, keeping the other query parameters.
+func setNextLink(ctx *gin.Context, limit int, next string) {
+	if next == "" {
+		return
+	}
+
+	query := ctx.Request.URL.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("after", next)
+	ctx.Header("Link", `<`+ctx.Request.URL.Path+`?`+query.Encode()+`>; rel="next"`)
+}
+
+func copyGroupDomainModelsToViewModels(domainModels []*model.GroupDomainModel) []model.GroupViewModel {
+	viewModels := make([]model.GroupViewModel, len(domainModels))
+	for i, domainModel := range domainModels {
+		viewModels[i] = copyGroupDomainModelToViewModel(domainModel)
+	}
+
+	return viewModels
+}
+
+func copyGroupDomainModelToViewModel(domainModel *model.GroupDomainModel) model.GroupViewModel {
+	return model.GroupViewModel{
+		Id:          domainModel.Id,
+		Name:        domainModel.Name,
+		Description: domainModel.Description,
+		CreatedAt:   domainModel.CreatedAt,
+		UpdatedAt:   domainModel.UpdatedAt,
+		CreatedBy:   domainModel.CreatedBy,
+		UpdatedBy:   domainModel.UpdatedBy,
+	}
+}
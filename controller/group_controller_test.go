@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	errs "user-service/error"
+	"user-service/logging"
+	"user-service/model"
+	serviceMock "user-service/service/mock"
+)
+
+func newGroupTestRouter(groupServiceMock *serviceMock.GroupServiceInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	classUnderTest := NewGroupController(groupServiceMock, testValidator, testCatalog, logging.Discard())
+	router.POST("/groups", classUnderTest.Create)
+	router.GET("/groups/:id/members", classUnderTest.GetMembers)
+	router.POST("/groups/:id/members:batchAdd", classUnderTest.AddMembers)
+	router.GET("/users/:id/groups", classUnderTest.GetGroupsOfUser)
+
+	return router
+}
+
+func Test_GroupCreate_Should_Return_201_With_The_Location_Of_The_Group(t *testing.T) {
+	groupServiceMock := new(serviceMock.GroupServiceInterface)
+	groupServiceMock.On("Create", mock.Anything, model.CreateGroupDomainModel{Name: "Engineering"}).
+		Return(&model.GroupDomainModel{Id: "1", Name: "Engineering"}, nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	newGroupTestRouter(groupServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/groups", strings.NewReader(`{"name":"Engineering"}`)))
+
+	assert.Equal(t, http.StatusCreated, responseRecorder.Code)
+	assert.Equal(t, "/groups/1", responseRecorder.Header().Get("Location"))
+	groupServiceMock.AssertExpectations(t)
+}
+
+func Test_GroupGetMembers_Should_Link_The_Next_Page(t *testing.T) {
+	groupServiceMock := new(serviceMock.GroupServiceInterface)
+	groupServiceMock.On("GetMembers", mock.Anything, "1", "", 1).
+		Return([]*model.GroupMemberDomainModel{{Type: model.MemberTypeUser, Id: "2"}}, "3", nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	newGroupTestRouter(groupServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, "/groups/1/members?limit=1", nil))
+
+	var viewModels []model.GroupMemberViewModel
+	json.NewDecoder(responseRecorder.Body).Decode(&viewModels)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, `</groups/1/members?after=3&limit=1>; rel="next"`, responseRecorder.Header().Get("Link"))
+	assert.Equal(t, []model.GroupMemberViewModel{{Type: model.MemberTypeUser, Id: "2"}}, viewModels)
+}
+
+func Test_GroupGetGroupsOfUser_Should_Return_400_When_Limit_Is_Out_Of_Range(t *testing.T) {
+	groupServiceMock := new(serviceMock.GroupServiceInterface)
+
+	responseRecorder := httptest.NewRecorder()
+	newGroupTestRouter(groupServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, "/users/1/groups?limit=101", nil))
+
+	assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+	groupServiceMock.AssertNotCalled(t, "GetGroupsOfUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_GroupAddMembers_Should_Report_Cycles_As_409(t *testing.T) {
+	groupServiceMock := new(serviceMock.GroupServiceInterface)
+	groupServiceMock.On("AddMembers", mock.Anything, "1", model.GroupMembersDomainModel{UserIds: []string{"2"}, GroupIds: []string{"1"}}).
+		Return([]*model.BatchResultDomainModel{{Id: "2"}, {Id: "1", Err: errs.GroupCycleError}}, nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	newGroupTestRouter(groupServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/groups/1/members:batchAdd", strings.NewReader(`{"users":["2"],"groups":["1"]}`)))
+
+	var viewModel model.BatchResultsViewModel
+	json.NewDecoder(responseRecorder.Body).Decode(&viewModel)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, http.StatusOK, viewModel.Results[0].Status)
+	assert.Equal(t, http.StatusConflict, viewModel.Results[1].Status)
+	assert.Equal(t, "/problems/group-cycle", viewModel.Results[1].Error.Type)
+}
+
+func Test_GroupAddMembers_Should_Return_400_When_No_Member_Is_Given(t *testing.T) {
+	groupServiceMock := new(serviceMock.GroupServiceInterface)
+
+	responseRecorder := httptest.NewRecorder()
+	newGroupTestRouter(groupServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/groups/1/members:batchAdd", strings.NewReader(`{}`)))
+
+	assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+	assert.Contains(t, responseRecorder.Body.String(), "/problems/validation-error")
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"user-service/model"
+	"user-service/service"
+)
+
+const healthContentType = "application/health+json"
+
+type HealthController struct {
+	healthService service.HealthServiceInterface
+}
+
+func NewHealthController(healthService service.HealthServiceInterface) *HealthController {
+	return &HealthController{
+		healthService: healthService,
+	}
+}
+
+// Live reports that the process serves requests at all. It checks no dependencies, so an outage of the database
+// does not get the service restarted.
+func (c *HealthController) Live(ctx *gin.Context) {
+	respondHealth(ctx, model.HealthViewModel{Status: model.HealthPass})
+}
+
+func (c *HealthController) Ready(ctx *gin.Context) {
+	respondHealth(ctx, c.healthService.Ready(requestContext(ctx)))
+}
+
+func (c *HealthController) Health(ctx *gin.Context) {
+	respondHealth(ctx, c.healthService.Health(requestContext(ctx)))
+}
+
+// respondHealth answers with 503 when the health fails and with 200 otherwise, including when it warns.
+func respondHealth(ctx *gin.Context, health model.HealthViewModel) {
+	status := http.StatusOK
+	if health.Status == model.HealthFail {
+		status = http.StatusServiceUnavailable
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Content-Type", healthContentType)
+	ctx.JSON(status, health)
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/model"
+	serviceMock "user-service/service/mock"
+)
+
+func newHealthTestRouter(healthServiceMock *serviceMock.HealthServiceInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	classUnderTest := NewHealthController(healthServiceMock)
+	router.GET("/healthz", classUnderTest.Live)
+	router.GET("/readyz", classUnderTest.Ready)
+	router.GET("/health", classUnderTest.Health)
+
+	return router
+}
+
+func Test_Live_Should_Pass_Without_Checking_Dependencies(t *testing.T) {
+	healthServiceMock := new(serviceMock.HealthServiceInterface)
+
+	responseRecorder := httptest.NewRecorder()
+	newHealthTestRouter(healthServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, "application/health+json", responseRecorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"status":"pass"}`, responseRecorder.Body.String())
+	healthServiceMock.AssertNotCalled(t, "Ready", mock.Anything)
+}
+
+func Test_Ready_Should_Return_503_When_Failing(t *testing.T) {
+	healthServiceMock := new(serviceMock.HealthServiceInterface)
+	healthServiceMock.On("Ready", mock.Anything).Return(model.HealthViewModel{Status: model.HealthFail, Output: "the service is shutting down"}).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	newHealthTestRouter(healthServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var health model.HealthViewModel
+	json.NewDecoder(responseRecorder.Body).Decode(&health)
+
+	assert.Equal(t, http.StatusServiceUnavailable, responseRecorder.Code)
+	assert.Equal(t, "no-store", responseRecorder.Header().Get("Cache-Control"))
+	assert.Equal(t, "the service is shutting down", health.Output)
+	healthServiceMock.AssertExpectations(t)
+}
+
+func Test_Health_Should_Return_200_When_Warning(t *testing.T) {
+	healthServiceMock := new(serviceMock.HealthServiceInterface)
+	healthServiceMock.On("Health", mock.Anything).Return(model.HealthViewModel{
+		Status: model.HealthWarn,
+		Checks: map[string][]model.HealthCheckViewModel{
+			"blobStore:responseTime": {{ComponentType: "datastore", ObservedValue: 1.5, ObservedUnit: "ms", Status: model.HealthFail}},
+		},
+	}).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	newHealthTestRouter(healthServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	var health model.HealthViewModel
+	json.NewDecoder(responseRecorder.Body).Decode(&health)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, model.HealthWarn, health.Status)
+	assert.Equal(t, 1.5, health.Checks["blobStore:responseTime"][0].ObservedValue)
+	healthServiceMock.AssertExpectations(t)
+}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	errs "user-service/error"
+	"user-service/i18n"
+	"user-service/importer"
+	"user-service/model"
+)
+
+// MaxImportSize is the largest request body Create accepts, in bytes.
+const MaxImportSize = 32 << 20
+
+type ImportController struct {
+	problemResponder
+	importer *importer.Importer
+}
+
+func NewImportController(importer *importer.Importer, catalog *i18n.Catalog, logger *slog.Logger) *ImportController {
+	return &ImportController{
+		problemResponder: newProblemResponder(catalog, logger),
+		importer:         importer,
+	}
+}
+
+func (c *ImportController) Create(ctx *gin.Context) {
+	options, ok := parseImportOptions(ctx)
+	if !ok {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, MaxImportSize)
+
+	job, err := c.importer.Start(requestContext(ctx), body, options)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			c.configureErrorResponse(ctx, errs.PayloadTooLargeError)
+			return
+		}
+
+		c.logger.ErrorContext(requestContext(ctx), "starting the import failed", "error", err)
+		c.configureErrorResponse(ctx, errs.ServerError)
+		return
+	}
+
+	ctx.Header("Location", "/users/import/"+job.Id)
+	ctx.IndentedJSON(http.StatusAccepted, copyJobToViewModel(job))
+}
+
+func (c *ImportController) GetById(ctx *gin.Context) {
+	job, err := c.importer.Get(requestContext(ctx), ctx.Param("jobId"))
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.IndentedJSON(http.StatusOK, copyJobToViewModel(job))
+}
+
+func (c *ImportController) GetErrorReport(ctx *gin.Context) {
+	report, err := c.importer.OpenReport(requestContext(ctx), ctx.Param("jobId"))
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+	defer report.Close()
+
+	ctx.Header("Content-Disposition", `attachment; filename="import-`+ctx.Param("jobId")+`-errors.csv"`)
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Status(http.StatusOK)
+
+	_, err = io.Copy(ctx.Writer, report)
+	if err != nil {
+		c.logger.WarnContext(requestContext(ctx), "sending the error report was cut off", "error", err)
+	}
+}
+
+// parseImportOptions reads the format from the query or else the Content-Type, and the dryRun and onError flags.
+func parseImportOptions(ctx *gin.Context) (importer.Options, bool) {
+	options := importer.Options{
+		Format:  importer.Format(ctx.Query("format")),
+		OnError: importer.OnErrorPolicy(ctx.DefaultQuery("onError", string(importer.OnErrorSkip))),
+	}
+
+	if options.Format == "" {
+		mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+		switch mediaType {
+		case "text/csv":
+			options.Format = importer.FormatCSV
+		case "application/x-ndjson", "application/ndjson":
+			options.Format = importer.FormatNDJSON
+		}
+	}
+	if options.Format != importer.FormatCSV && options.Format != importer.FormatNDJSON {
+		return options, false
+	}
+	if options.OnError != importer.OnErrorSkip && options.OnError != importer.OnErrorAbort {
+		return options, false
+	}
+
+	if dryRun := ctx.Query("dryRun"); dryRun != "" {
+		var err error
+		options.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			return options, false
+		}
+	}
+
+	return options, true
+}
+
+func copyJobToViewModel(job *importer.Job) *model.ImportJobViewModel {
+	return &model.ImportJobViewModel{
+		Id:          job.Id,
+		Status:      string(job.Status),
+		Format:      string(job.Options.Format),
+		DryRun:      job.Options.DryRun,
+		OnError:     string(job.Options.OnError),
+		Processed:   job.Processed,
+		Succeeded:   job.Succeeded,
+		Failed:      job.Failed,
+		CreatedAt:   job.CreatedAt,
+		FinishedAt:  job.FinishedAt,
+		ErrorReport: "/users/import/" + job.Id + "/errors",
+	}
+}
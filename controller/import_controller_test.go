@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"user-service/importer"
+	"user-service/logging"
+	"user-service/model"
+	serviceMock "user-service/service/mock"
+)
+
+func newImportTestRouter(userImporter *importer.Importer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	classUnderTest := NewImportController(userImporter, testCatalog, logging.Discard())
+	router.POST("/users/import", classUnderTest.Create)
+	router.GET("/users/import/:jobId", classUnderTest.GetById)
+	router.GET("/users/import/:jobId/errors", classUnderTest.GetErrorReport)
+
+	return router
+}
+
+func Test_ImportCreate_Should_Return_202_And_Job_When_Body_Is_NDJSON(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("ValidateCreate", mock.Anything, mock.Anything).Return(nil).Once()
+
+	userImporter := importer.NewImporter(userServiceMock, testValidator, testCatalog, t.TempDir(), logging.Discard())
+	router := newImportTestRouter(userImporter)
+
+	request := httptest.NewRequest(http.MethodPost, "/users/import?dryRun=true", strings.NewReader(`{"name":"A","email":"a@site.com","password":"123456"}`))
+	request.Header.Set("Content-Type", "application/x-ndjson")
+	responseRecorder := httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, request)
+
+	var job model.ImportJobViewModel
+	json.NewDecoder(responseRecorder.Body).Decode(&job)
+
+	assert.Equal(t, http.StatusAccepted, responseRecorder.Code)
+	assert.Equal(t, "/users/import/"+job.Id, responseRecorder.Header().Get("Location"))
+	assert.Equal(t, "ndjson", job.Format)
+	assert.True(t, job.DryRun)
+	assert.Equal(t, "skip", job.OnError)
+
+	userImporter.Wait()
+
+	responseRecorder = httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, "/users/import/"+job.Id, nil))
+	json.NewDecoder(responseRecorder.Body).Decode(&job)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, "completed", job.Status)
+	assert.Equal(t, 1, job.Succeeded)
+
+	responseRecorder = httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, job.ErrorReport, nil))
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", responseRecorder.Header().Get("Content-Type"))
+	assert.Equal(t, "row,email,field,rule,message\n", responseRecorder.Body.String())
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_ImportCreate_Should_Return_400_When_Format_Is_Unknown(t *testing.T) {
+	router := newImportTestRouter(importer.NewImporter(new(serviceMock.UserServiceInterface), testValidator, testCatalog, t.TempDir(), logging.Discard()))
+
+	request := httptest.NewRequest(http.MethodPost, "/users/import", strings.NewReader("name,email,password\n"))
+	request.Header.Set("Content-Type", "application/json")
+	responseRecorder := httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+	assert.Equal(t, problemContentType, responseRecorder.Header().Get("Content-Type"))
+}
+
+func Test_ImportGetById_Should_Return_404_When_Job_Does_Not_Exist(t *testing.T) {
+	router := newImportTestRouter(importer.NewImporter(new(serviceMock.UserServiceInterface), testValidator, testCatalog, t.TempDir(), logging.Discard()))
+
+	responseRecorder := httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, "/users/import/missing", nil))
+
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Body).Decode(&problem)
+
+	assert.Equal(t, http.StatusNotFound, responseRecorder.Code)
+	assert.Equal(t, "/problems/import-job-not-found", problem.Type)
+}
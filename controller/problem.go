@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	errs "user-service/error"
+	"user-service/i18n"
+	"user-service/model"
+)
+
+const problemContentType = "application/problem+json"
+
+type problemDefinition struct {
+	err    error
+	status int
+	slug   string
+	title  string
+}
+
+// problemDefinitions maps every error package sentinel to the problem type it is reported as.
+var problemDefinitions = []problemDefinition{
+	{errs.BadRequestError, http.StatusBadRequest, "bad-request", "Bad Request"},
+	{errs.NotFoundError, http.StatusNotFound, "user-not-found", "User Not Found"},
+	{errs.EmailAlreadyInUseError, http.StatusConflict, "email-already-in-use", "Email Already In Use"},
+	{errs.PreconditionFailedError, http.StatusPreconditionFailed, "precondition-failed", "Precondition Failed"},
+	{errs.RevisionNotFoundError, http.StatusNotFound, "revision-not-found", "Revision Not Found"},
+	{errs.ImportJobNotFoundError, http.StatusNotFound, "import-job-not-found", "Import Job Not Found"},
+	{errs.PayloadTooLargeError, http.StatusRequestEntityTooLarge, "payload-too-large", "Payload Too Large"},
+	{errs.ValidationError, http.StatusBadRequest, "validation-error", "Validation Failed"},
+	{errs.ProfileSchemaNotFoundError, http.StatusNotFound, "profile-schema-not-found", "Profile Schema Not Found"},
+	{errs.InvalidProfileSchemaError, http.StatusBadRequest, "invalid-profile-schema", "Invalid Profile Schema"},
+	{errs.AvatarNotFoundError, http.StatusNotFound, "avatar-not-found", "Avatar Not Found"},
+	{errs.UnsupportedMediaTypeError, http.StatusUnsupportedMediaType, "unsupported-media-type", "Unsupported Media Type"},
+	{errs.InvalidAvatarError, http.StatusBadRequest, "invalid-avatar", "Invalid Avatar"},
+	{errs.UnauthorizedError, http.StatusUnauthorized, "unauthorized", "Unauthorized"},
+	{errs.ForbiddenError, http.StatusForbidden, "forbidden", "Forbidden"},
+	{errs.ApiKeyNotFoundError, http.StatusNotFound, "api-key-not-found", "API Key Not Found"},
+	{errs.TenantRequiredError, http.StatusBadRequest, "tenant-required", "Tenant Required"},
+	{errs.TenantNotFoundError, http.StatusNotFound, "tenant-not-found", "Tenant Not Found"},
+	{errs.TenantAlreadyExistsError, http.StatusConflict, "tenant-already-exists", "Tenant Already Exists"},
+	{errs.GroupNotFoundError, http.StatusNotFound, "group-not-found", "Group Not Found"},
+	{errs.GroupCycleError, http.StatusConflict, "group-cycle", "Group Cycle"},
+	{errs.TooManyRequestsError, http.StatusTooManyRequests, "too-many-requests", "Too Many Requests"},
+	{errs.ServerError, http.StatusInternalServerError, "server-error", "Server Error"},
+}
+
+// problemResponder renders errors as localized problem details and holds the logger of the controller. Controllers
+// embed it.
+type problemResponder struct {
+	catalog *i18n.Catalog
+	logger  *slog.Logger
+}
+
+func newProblemResponder(catalog *i18n.Catalog, logger *slog.Logger) problemResponder {
+	return problemResponder{catalog: catalog, logger: logger}
+}
+
+func (c *problemResponder) configureErrorResponse(ctx *gin.Context, err error) {
+	c.writeProblem(ctx, c.problemFor(ctx, err))
+}
+
+// problemFor describes err as a problem, listing the offending fields when err carries them.
+func (c *problemResponder) problemFor(ctx *gin.Context, err error) model.ProblemViewModel {
+	problem := c.newProblem(ctx, problemDefinitionFor(err))
+
+	var fieldErrors *errs.FieldErrors
+	if errors.As(err, &fieldErrors) {
+		lang := c.language(ctx)
+		for _, field := range fieldErrors.Fields {
+			problem.Errors = append(problem.Errors, model.FieldErrorViewModel{
+				Field:   field.Field,
+				Rule:    field.Rule,
+				Message: c.catalog.FieldMessage(lang, field),
+			})
+		}
+	}
+
+	return problem
+}
+
+func (c *problemResponder) configureValidationErrorResponse(ctx *gin.Context, err error) {
+	problem := c.newProblem(ctx, problemDefinitionFor(errs.ValidationError))
+	lang := c.language(ctx)
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for _, fieldError := range validationErrors {
+			problem.Errors = append(problem.Errors, model.FieldErrorViewModel{
+				Field:   fieldError.Field(),
+				Rule:    fieldError.Tag(),
+				Message: c.catalog.ValidationMessage(lang, fieldError),
+			})
+		}
+	}
+
+	c.writeProblem(ctx, problem)
+}
+
+// problemDefinitionFor finds the definition of the sentinel err wraps. Anything that is not a known sentinel is
+// reported as a server error without leaking its details.
+func problemDefinitionFor(err error) problemDefinition {
+	for _, definition := range problemDefinitions {
+		if errors.Is(err, definition.err) {
+			return definition
+		}
+	}
+
+	return problemDefinitions[len(problemDefinitions)-1]
+}
+
+func (c *problemResponder) newProblem(ctx *gin.Context, definition problemDefinition) model.ProblemViewModel {
+	problem := model.ProblemViewModel{
+		Type:   "/problems/" + definition.slug,
+		Title:  definition.title,
+		Status: definition.status,
+		Detail: c.catalog.ErrorMessage(c.language(ctx), definition.err),
+	}
+
+	if ctx.Request != nil && ctx.Request.URL != nil {
+		problem.Instance = ctx.Request.URL.RequestURI()
+	}
+
+	return problem
+}
+
+func (c *problemResponder) writeProblem(ctx *gin.Context, problem model.ProblemViewModel) {
+	ctx.Header("Content-Type", problemContentType)
+	ctx.Header("Content-Language", c.language(ctx))
+	ctx.IndentedJSON(problem.Status, problem)
+}
+
+func (c *problemResponder) language(ctx *gin.Context) string {
+	if ctx.Request == nil {
+		return i18n.DefaultLanguage
+	}
+
+	return c.catalog.Language(ctx.Request.Header.Get("Accept-Language"))
+}
+
+// JsonFieldName makes a validator report fields by their JSON names instead of their Go names when registered with
+// RegisterTagNameFunc, which has to happen before the catalog is made for the validator.
+func JsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	errs "user-service/error"
+	"user-service/i18n"
+	"user-service/logging"
+	"user-service/model"
+)
+
+// testValidator and testCatalog are set up the way main sets them up.
+var testValidator, testCatalog = newTestValidation()
+
+func newTestValidation() (*validator.Validate, *i18n.Catalog) {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(JsonFieldName)
+
+	catalog, err := i18n.NewCatalog(validate)
+	if err != nil {
+		panic(err)
+	}
+
+	return validate, catalog
+}
+
+func Test_ProblemFor_Should_Translate_The_Field_Errors_To_The_Language_Of_The_Request(t *testing.T) {
+	responder := newProblemResponder(testCatalog, logging.Discard())
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/tenants", nil)
+	ctx.Request.Header.Set("Accept-Language", "de")
+
+	problem := responder.problemFor(ctx, &errs.FieldErrors{Err: errs.ValidationError, Fields: []errs.FieldError{{Field: "expiresAt", Rule: "future"}}})
+
+	assert.Equal(t, []model.FieldErrorViewModel{{Field: "expiresAt", Rule: "future", Message: "expiresAt muss in der Zukunft liegen"}}, problem.Errors)
+}
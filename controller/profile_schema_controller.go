@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+	errs "user-service/error"
+	"user-service/i18n"
+	"user-service/model"
+	"user-service/service"
+)
+
+type ProfileSchemaController struct {
+	problemResponder
+	profileSchemaService service.ProfileSchemaServiceInterface
+	validator            *validator.Validate
+}
+
+func NewProfileSchemaController(profileSchemaService service.ProfileSchemaServiceInterface, validator *validator.Validate, catalog *i18n.Catalog, logger *slog.Logger) *ProfileSchemaController {
+	return &ProfileSchemaController{
+		problemResponder:     newProblemResponder(catalog, logger),
+		profileSchemaService: profileSchemaService,
+		validator:            validator,
+	}
+}
+
+func (c *ProfileSchemaController) Get(ctx *gin.Context) {
+	domainModel, err := c.profileSchemaService.GetCurrent(requestContext(ctx))
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", formatETag(domainModel.Version))
+	ctx.IndentedJSON(http.StatusOK, copyProfileSchemaDomainModelToViewModel(domainModel))
+}
+
+// Publish makes the given schema the next version. If-Match guards against overwriting a version published
+// concurrently.
+func (c *ProfileSchemaController) Publish(ctx *gin.Context) {
+	var publishViewModel model.PublishProfileSchemaViewModel
+
+	err := ctx.ShouldBindJSON(&publishViewModel)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	err = c.validator.Struct(publishViewModel)
+	if err != nil {
+		c.configureValidationErrorResponse(ctx, err)
+		return
+	}
+
+	precondition := parseIfMatch(ctx.GetHeader("If-Match"))
+
+	domainModel, err := c.profileSchemaService.Publish(requestContext(ctx), publishViewModel.Schema, precondition)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", formatETag(domainModel.Version))
+	ctx.IndentedJSON(http.StatusOK, copyProfileSchemaDomainModelToViewModel(domainModel))
+}
+
+func copyProfileSchemaDomainModelToViewModel(domainModel *model.ProfileSchemaDomainModel) model.ProfileSchemaViewModel {
+	indexed := domainModel.Indexed
+	if indexed == nil {
+		indexed = []string{}
+	}
+
+	return model.ProfileSchemaViewModel{
+		Version:   domainModel.Version,
+		Schema:    domainModel.Document,
+		Indexed:   indexed,
+		CreatedAt: domainModel.CreatedAt,
+		CreatedBy: domainModel.CreatedBy,
+	}
+}
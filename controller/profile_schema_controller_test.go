@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	errs "user-service/error"
+	"user-service/logging"
+	"user-service/model"
+	serviceMock "user-service/service/mock"
+)
+
+func newProfileSchemaTestRouter(profileSchemaServiceMock *serviceMock.ProfileSchemaServiceInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	classUnderTest := NewProfileSchemaController(profileSchemaServiceMock, testValidator, testCatalog, logging.Discard())
+	router.GET("/profile-schema", classUnderTest.Get)
+	router.PUT("/profile-schema", classUnderTest.Publish)
+
+	return router
+}
+
+func Test_ProfileSchemaGet_Should_Return_404_When_No_Schema_Was_Published(t *testing.T) {
+	profileSchemaServiceMock := new(serviceMock.ProfileSchemaServiceInterface)
+	profileSchemaServiceMock.On("GetCurrent", mock.Anything).Return(nil, errs.ProfileSchemaNotFoundError).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	newProfileSchemaTestRouter(profileSchemaServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, "/profile-schema", nil))
+
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Body).Decode(&problem)
+
+	assert.Equal(t, http.StatusNotFound, responseRecorder.Code)
+	assert.Equal(t, "/problems/profile-schema-not-found", problem.Type)
+	profileSchemaServiceMock.AssertExpectations(t)
+}
+
+func Test_ProfileSchemaPublish_Should_Return_200_And_ETag_When_Nothing_Fails(t *testing.T) {
+	document := json.RawMessage(`{"type":"object"}`)
+
+	profileSchemaServiceMock := new(serviceMock.ProfileSchemaServiceInterface)
+	profileSchemaServiceMock.On("Publish", mock.Anything, document, &model.Precondition{Versions: []int64{1}}).Return(&model.ProfileSchemaDomainModel{Version: 2, Document: document}, nil).Once()
+
+	request := httptest.NewRequest(http.MethodPut, "/profile-schema", strings.NewReader(`{"schema":{"type":"object"}}`))
+	request.Header.Set("If-Match", `"1"`)
+	responseRecorder := httptest.NewRecorder()
+	newProfileSchemaTestRouter(profileSchemaServiceMock).ServeHTTP(responseRecorder, request)
+
+	var schema model.ProfileSchemaViewModel
+	json.NewDecoder(responseRecorder.Body).Decode(&schema)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, `"2"`, responseRecorder.Header().Get("ETag"))
+	assert.Equal(t, int64(2), schema.Version)
+	assert.Equal(t, []string{}, schema.Indexed)
+	profileSchemaServiceMock.AssertExpectations(t)
+}
+
+func Test_ProfileSchemaPublish_Should_Return_400_With_The_Reason_When_Schema_Is_Invalid(t *testing.T) {
+	invalid := &errs.FieldErrors{Err: errs.InvalidProfileSchemaError, Fields: []errs.FieldError{{Field: "schema", Rule: "schema", Param: `the schema must have "type": "object"`}}}
+
+	profileSchemaServiceMock := new(serviceMock.ProfileSchemaServiceInterface)
+	profileSchemaServiceMock.On("Publish", mock.Anything, mock.Anything, (*model.Precondition)(nil)).Return(nil, invalid).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	newProfileSchemaTestRouter(profileSchemaServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPut, "/profile-schema", strings.NewReader(`{"schema":{"type":"string"}}`)))
+
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Body).Decode(&problem)
+
+	assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+	assert.Equal(t, "/problems/invalid-profile-schema", problem.Type)
+	assert.Equal(t, []model.FieldErrorViewModel{{Field: "schema", Rule: "schema", Message: `schema is not a valid profile schema: the schema must have "type": "object"`}}, problem.Errors)
+}
+
+func Test_ProfileSchemaPublish_Should_Return_400_When_Schema_Is_Missing(t *testing.T) {
+	profileSchemaServiceMock := new(serviceMock.ProfileSchemaServiceInterface)
+
+	responseRecorder := httptest.NewRecorder()
+	newProfileSchemaTestRouter(profileSchemaServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPut, "/profile-schema", strings.NewReader(`{}`)))
+
+	assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+	profileSchemaServiceMock.AssertExpectations(t)
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"user-service/config"
+	"user-service/i18n"
+	"user-service/model"
+	"user-service/service"
+)
+
+// apiKeyIdKey is the key of the gin context under which Authenticate stores the API key of a request.
+const apiKeyIdKey = "apiKeyId"
+
+type RateLimitController struct {
+	problemResponder
+	rateLimitService service.RateLimitServiceInterface
+}
+
+func NewRateLimitController(rateLimitService service.RateLimitServiceInterface, catalog *i18n.Catalog, logger *slog.Logger) *RateLimitController {
+	return &RateLimitController{
+		problemResponder: newProblemResponder(catalog, logger),
+		rateLimitService: rateLimitService,
+	}
+}
+
+// LimitByIP is a middleware that applies the rate limit policies keyed by IP address. It has to run before
+// authentication, so that requests with invalid keys are counted as well.
+func (c *RateLimitController) LimitByIP(ctx *gin.Context) {
+	c.take(ctx, []string{config.RateLimitKeyIP}, model.RateLimitClient{IP: ctx.ClientIP()})
+}
+
+// Limit is a middleware that applies the rate limit policies keyed by user or API key. It has to run after
+// authentication.
+func (c *RateLimitController) Limit(ctx *gin.Context) {
+	client := model.RateLimitClient{
+		IP:       ctx.ClientIP(),
+		ApiKeyId: ctx.GetString(apiKeyIdKey),
+	}
+	if strings.HasPrefix(ctx.FullPath(), "/users/:id") && primitive.IsValidObjectID(ctx.Param("id")) {
+		client.UserId = ctx.Param("id")
+	}
+
+	c.take(ctx, []string{config.RateLimitKeyUser, config.RateLimitKeyApiKey}, client)
+}
+
+// take rejects requests beyond the policies keyed by keys with 429 and describes the limit in the RateLimit
+// headers, unless an earlier limiter already described a tighter one. Since the policies are per route, the
+// middlewares must be used on the engine or the routes rather than before routing.
+func (c *RateLimitController) take(ctx *gin.Context, keys []string, client model.RateLimitClient) {
+	limit, err := c.rateLimitService.Take(requestContext(ctx), rateLimitRoute(ctx), keys, client)
+	if limit != nil && (err != nil || !isTighterLimitDescribed(ctx, limit)) {
+		ctx.Header("RateLimit-Limit", strconv.Itoa(limit.Limit))
+		ctx.Header("RateLimit-Remaining", strconv.Itoa(limit.Remaining))
+		ctx.Header("RateLimit-Reset", seconds(limit.Reset))
+		ctx.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%s", limit.Limit, seconds(limit.Period)))
+	}
+
+	if err != nil {
+		if limit != nil {
+			ctx.Header("Retry-After", seconds(limit.RetryAfter))
+		}
+		c.configureErrorResponse(ctx, err)
+		ctx.Abort()
+		return
+	}
+
+	ctx.Next()
+}
+
+// rateLimitRoute names the route of a request the way policies do. The custom methods of a resource share one
+// gin route ending in :verb, which is replaced with the verb so that policies can tell the verbs apart.
+func rateLimitRoute(ctx *gin.Context) string {
+	route := ctx.FullPath()
+	if resource, found := strings.CutSuffix(route, ":verb"); found {
+		route = resource + ":" + strings.TrimPrefix(ctx.Param("verb"), ":")
+	}
+
+	return ctx.Request.Method + " " + route
+}
+
+func isTighterLimitDescribed(ctx *gin.Context, limit *model.RateLimitViewModel) bool {
+	remaining, err := strconv.Atoi(ctx.Writer.Header().Get("RateLimit-Remaining"))
+	return err == nil && remaining < limit.Remaining
+}
+
+// seconds rounds up, so that clients waiting that long do find a token.
+func seconds(duration time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(duration.Seconds())))
+}
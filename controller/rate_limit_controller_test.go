@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"user-service/config"
+	errs "user-service/error"
+	"user-service/logging"
+	"user-service/model"
+	serviceMock "user-service/service/mock"
+)
+
+func newRateLimitTestRouter(rateLimitServiceMock *serviceMock.RateLimitServiceInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	classUnderTest := NewRateLimitController(rateLimitServiceMock, testCatalog, logging.Discard())
+	router.Use(classUnderTest.Limit)
+	router.POST("/users", func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+
+	return router
+}
+
+func Test_Limit_Should_Describe_The_Limit_When_Letting_The_Request_Through(t *testing.T) {
+	rateLimitServiceMock := new(serviceMock.RateLimitServiceInterface)
+	rateLimitServiceMock.On("Take", mock.Anything, "POST /users", []string{config.RateLimitKeyUser, config.RateLimitKeyApiKey}, model.RateLimitClient{IP: "203.0.113.7"}).
+		Return(&model.RateLimitViewModel{Limit: 10, Remaining: 9, Period: time.Hour, Reset: 359*time.Second + time.Millisecond}, nil).Once()
+
+	request := httptest.NewRequest(http.MethodPost, "/users", nil)
+	request.RemoteAddr = "203.0.113.7:54321"
+	responseRecorder := httptest.NewRecorder()
+	newRateLimitTestRouter(rateLimitServiceMock).ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusCreated, responseRecorder.Code)
+	assert.Equal(t, "10", responseRecorder.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "9", responseRecorder.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "360", responseRecorder.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "10;w=3600", responseRecorder.Header().Get("RateLimit-Policy"))
+	assert.Empty(t, responseRecorder.Header().Get("Retry-After"))
+	rateLimitServiceMock.AssertExpectations(t)
+}
+
+func Test_Limit_Should_Return_429_With_Retry_After_When_The_Limit_Is_Exceeded(t *testing.T) {
+	rateLimitServiceMock := new(serviceMock.RateLimitServiceInterface)
+	rateLimitServiceMock.On("Take", mock.Anything, "POST /users", mock.Anything, mock.Anything).
+		Return(&model.RateLimitViewModel{Limit: 10, Period: time.Hour, Reset: time.Hour, RetryAfter: 6 * time.Minute}, errs.TooManyRequestsError).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	newRateLimitTestRouter(rateLimitServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/users", nil))
+
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Body).Decode(&problem)
+
+	assert.Equal(t, http.StatusTooManyRequests, responseRecorder.Code)
+	assert.Equal(t, "360", responseRecorder.Header().Get("Retry-After"))
+	assert.Equal(t, "0", responseRecorder.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "/problems/too-many-requests", problem.Type)
+}
+
+func Test_Limit_Should_Omit_The_Headers_When_No_Policy_Applies(t *testing.T) {
+	rateLimitServiceMock := new(serviceMock.RateLimitServiceInterface)
+	rateLimitServiceMock.On("Take", mock.Anything, "POST /users", mock.Anything, mock.Anything).Return(nil, nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	newRateLimitTestRouter(rateLimitServiceMock).ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/users", nil))
+
+	assert.Equal(t, http.StatusCreated, responseRecorder.Code)
+	assert.Empty(t, responseRecorder.Header().Get("RateLimit-Limit"))
+}
+
+func Test_LimitByIP_Should_Count_Requests_Before_Authentication_Rejects_Them(t *testing.T) {
+	rateLimitServiceMock := new(serviceMock.RateLimitServiceInterface)
+	rateLimitServiceMock.On("Take", mock.Anything, "POST /users", []string{config.RateLimitKeyIP}, model.RateLimitClient{IP: "203.0.113.7"}).
+		Return(&model.RateLimitViewModel{Limit: 10, Period: time.Hour, Reset: time.Hour, RetryAfter: time.Minute}, errs.TooManyRequestsError).Once()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewRateLimitController(rateLimitServiceMock, testCatalog, logging.Discard()).LimitByIP)
+	router.Use(func(ctx *gin.Context) {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+	})
+	router.POST("/users", func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/users", nil)
+	request.RemoteAddr = "203.0.113.7:54321"
+	request.Header.Set("Authorization", "ApiKey usk_guessed")
+	responseRecorder := httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusTooManyRequests, responseRecorder.Code)
+	rateLimitServiceMock.AssertExpectations(t)
+}
+
+func Test_Limit_Should_Name_Custom_Methods_By_Their_Verb_And_Count_Requests_By_Their_User(t *testing.T) {
+	id := "5f1e6a3b9d3e2a0001a1b2c3"
+	keys := []string{config.RateLimitKeyUser, config.RateLimitKeyApiKey}
+
+	rateLimitServiceMock := new(serviceMock.RateLimitServiceInterface)
+	rateLimitServiceMock.On("Take", mock.Anything, "POST /users:batchGet", keys, model.RateLimitClient{IP: "192.0.2.1"}).Return(nil, nil).Once()
+	rateLimitServiceMock.On("Take", mock.Anything, "PUT /users/:id/avatar", keys, model.RateLimitClient{IP: "192.0.2.1", UserId: id}).Return(nil, nil).Once()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewRateLimitController(rateLimitServiceMock, testCatalog, logging.Discard()).Limit)
+	router.POST("/users:verb", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	router.PUT("/users/:id/avatar", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users:batchGet", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/users/"+id+"/avatar", nil))
+
+	rateLimitServiceMock.AssertExpectations(t)
+}
+
+func Test_Limit_Should_Keep_The_Tighter_Limit_Described_Before_Authentication(t *testing.T) {
+	rateLimitServiceMock := new(serviceMock.RateLimitServiceInterface)
+	rateLimitServiceMock.On("Take", mock.Anything, "POST /users", []string{config.RateLimitKeyIP}, mock.Anything).
+		Return(&model.RateLimitViewModel{Limit: 10, Remaining: 2, Period: time.Hour}, nil).Once()
+	rateLimitServiceMock.On("Take", mock.Anything, "POST /users", []string{config.RateLimitKeyUser, config.RateLimitKeyApiKey}, mock.Anything).
+		Return(&model.RateLimitViewModel{Limit: 100, Remaining: 99, Period: time.Hour}, nil).Once()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	classUnderTest := NewRateLimitController(rateLimitServiceMock, testCatalog, logging.Discard())
+	router.Use(classUnderTest.LimitByIP, classUnderTest.Limit)
+	router.POST("/users", func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+
+	responseRecorder := httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/users", nil))
+
+	assert.Equal(t, "10", responseRecorder.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "2", responseRecorder.Header().Get("RateLimit-Remaining"))
+}
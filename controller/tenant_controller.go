@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	errs "user-service/error"
+	"user-service/i18n"
+	"user-service/model"
+	"user-service/service"
+)
+
+// TenantHeader names the tenant of a request made without a subdomain or a key bound to a tenant.
+const TenantHeader = "X-Tenant-ID"
+
+type TenantController struct {
+	problemResponder
+	tenantService service.TenantServiceInterface
+	validator     *validator.Validate
+	defaultTenant string
+	baseDomain    string
+}
+
+// NewTenantController serves the tenants of tenantService. Requests to a host below baseDomain name their tenant
+// with the first label of the host; requests that name none are served for defaultTenant, or rejected when it is
+// empty.
+func NewTenantController(tenantService service.TenantServiceInterface, validator *validator.Validate, catalog *i18n.Catalog, logger *slog.Logger, defaultTenant string, baseDomain string) *TenantController {
+	return &TenantController{
+		problemResponder: newProblemResponder(catalog, logger),
+		tenantService:    tenantService,
+		validator:        validator,
+		defaultTenant:    defaultTenant,
+		baseDomain:       strings.ToLower(baseDomain),
+	}
+}
+
+func (c *TenantController) Create(ctx *gin.Context) {
+	var createViewModel model.CreateTenantViewModel
+
+	err := ctx.ShouldBindJSON(&createViewModel)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	err = c.validator.Struct(createViewModel)
+	if err != nil {
+		c.configureValidationErrorResponse(ctx, err)
+		return
+	}
+
+	domainModel, err := c.tenantService.Create(requestContext(ctx), model.CreateTenantDomainModel{
+		Id:   createViewModel.Id,
+		Name: createViewModel.Name,
+	})
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header("Location", "/tenants/"+domainModel.Id)
+	ctx.IndentedJSON(http.StatusCreated, copyTenantDomainModelToViewModel(domainModel))
+}
+
+func (c *TenantController) GetAll(ctx *gin.Context) {
+	domainModels, err := c.tenantService.GetAll(requestContext(ctx))
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	viewModels := make([]model.TenantViewModel, len(domainModels))
+	for i, domainModel := range domainModels {
+		viewModels[i] = copyTenantDomainModelToViewModel(domainModel)
+	}
+
+	ctx.IndentedJSON(http.StatusOK, viewModels)
+}
+
+func (c *TenantController) GetById(ctx *gin.Context) {
+	domainModel, err := c.tenantService.GetById(requestContext(ctx), ctx.Param("id"))
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.IndentedJSON(http.StatusOK, copyTenantDomainModelToViewModel(domainModel))
+}
+
+func (c *TenantController) UpdateById(ctx *gin.Context) {
+	var updateViewModel model.UpdateTenantViewModel
+
+	err := ctx.ShouldBindJSON(&updateViewModel)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	err = c.validator.Struct(updateViewModel)
+	if err != nil {
+		c.configureValidationErrorResponse(ctx, err)
+		return
+	}
+
+	domainModel, err := c.tenantService.UpdateById(requestContext(ctx), ctx.Param("id"), model.UpdateTenantDomainModel{
+		Name:     updateViewModel.Name,
+		Disabled: updateViewModel.Disabled,
+	})
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.IndentedJSON(http.StatusOK, copyTenantDomainModelToViewModel(domainModel))
+}
+
+// Resolve is a middleware that confines the request to its tenant. A key bound to a tenant always acts for that
+// tenant, and naming another one is rejected with 403. Other requests name their tenant with the X-Tenant-ID
+// header or the subdomain, in that order, and fall back to the default tenant. Anonymous requests are kept to the
+// default tenant, and naming another one is rejected with 401. It has to run after Authenticate.
+func (c *TenantController) Resolve(ctx *gin.Context) {
+	requested := ctx.GetHeader(TenantHeader)
+	if requested == "" {
+		requested = tenantOfHost(ctx.Request.Host, c.baseDomain)
+	}
+
+	tenantId := requested
+	principal := service.PrincipalFrom(requestContext(ctx))
+	if principal == nil && requested != "" && requested != c.defaultTenant {
+		ctx.Header("WWW-Authenticate", apiKeyScheme)
+		c.configureErrorResponse(ctx, errs.UnauthorizedError)
+		ctx.Abort()
+		return
+	}
+	if principal != nil && principal.TenantId != "" {
+		if requested != "" && requested != principal.TenantId {
+			c.configureErrorResponse(ctx, errs.ForbiddenError)
+			ctx.Abort()
+			return
+		}
+		tenantId = principal.TenantId
+	}
+	if tenantId == "" {
+		tenantId = c.defaultTenant
+	}
+	if tenantId == "" {
+		c.configureErrorResponse(ctx, errs.TenantRequiredError)
+		ctx.Abort()
+		return
+	}
+
+	err := c.tenantService.Resolve(requestContext(ctx), tenantId)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		ctx.Abort()
+		return
+	}
+
+	ctx.Request = ctx.Request.WithContext(service.WithTenant(ctx.Request.Context(), tenantId))
+}
+
+// tenantOfHost returns the label in front of baseDomain in host, as acme in acme.users.example.com. Hosts that are
+// not directly below baseDomain name no tenant.
+func tenantOfHost(host string, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+
+	label, found := strings.CutSuffix(strings.ToLower(host), "."+baseDomain)
+	if !found || strings.Contains(label, ".") {
+		return ""
+	}
+
+	return label
+}
+
+func copyTenantDomainModelToViewModel(domainModel *model.TenantDomainModel) model.TenantViewModel {
+	return model.TenantViewModel{
+		Id:        domainModel.Id,
+		Name:      domainModel.Name,
+		Disabled:  domainModel.Disabled,
+		CreatedAt: domainModel.CreatedAt,
+		UpdatedAt: domainModel.UpdatedAt,
+		CreatedBy: domainModel.CreatedBy,
+		UpdatedBy: domainModel.UpdatedBy,
+	}
+}
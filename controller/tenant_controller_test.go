@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	errs "user-service/error"
+	"user-service/logging"
+	"user-service/model"
+	"user-service/repository"
+	serviceMock "user-service/service/mock"
+)
+
+func newTenantTestRouter(tenantServiceMock *serviceMock.TenantServiceInterface, principal *model.Principal, defaultTenant string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	apiKeyServiceMock := new(serviceMock.ApiKeyServiceInterface)
+	apiKeyServiceMock.On("Authenticate", mock.Anything, mock.Anything).Return(principal, nil)
+	apiKeyController := NewApiKeyController(apiKeyServiceMock, testValidator, testCatalog, logging.Discard(), nil)
+
+	classUnderTest := NewTenantController(tenantServiceMock, testValidator, testCatalog, logging.Discard(), defaultTenant, "users.example.com")
+	router.Use(apiKeyController.Authenticate)
+	router.POST("/tenants", classUnderTest.Create)
+	router.GET("/users", classUnderTest.Resolve, func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, repository.TenantFrom(ctx.Request.Context()))
+	})
+
+	return router
+}
+
+func serveTenantRequest(router *gin.Engine, host string, tenantHeader string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, "/users", nil)
+	request.Host = host
+	request.Header.Set("Authorization", "ApiKey usk_0123456789ab_secret")
+	if tenantHeader != "" {
+		request.Header.Set(TenantHeader, tenantHeader)
+	}
+	responseRecorder := httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, request)
+
+	return responseRecorder
+}
+
+func Test_TenantResolve_Should_Take_The_Tenant_From_Header_Subdomain_Or_Default(t *testing.T) {
+	tenantServiceMock := new(serviceMock.TenantServiceInterface)
+	tenantServiceMock.On("Resolve", mock.Anything, mock.Anything).Return(nil)
+	router := newTenantTestRouter(tenantServiceMock, &model.Principal{ApiKeyId: "admin"}, "default")
+
+	for _, testCase := range []struct{ host, header, expected string }{
+		{"acme.users.example.com", "", "acme"},
+		{"acme.users.example.com:8080", "globex", "globex"},
+		{"a.b.users.example.com", "", "default"},
+		{"localhost", "", "default"},
+	} {
+		responseRecorder := serveTenantRequest(router, testCase.host, testCase.header)
+
+		assert.Equal(t, http.StatusOK, responseRecorder.Code, testCase.host)
+		assert.Equal(t, testCase.expected, responseRecorder.Body.String(), testCase.host)
+	}
+}
+
+func Test_TenantResolve_Should_Confine_Keys_Bound_To_A_Tenant(t *testing.T) {
+	tenantServiceMock := new(serviceMock.TenantServiceInterface)
+	tenantServiceMock.On("Resolve", mock.Anything, "acme").Return(nil)
+	router := newTenantTestRouter(tenantServiceMock, &model.Principal{ApiKeyId: "1", TenantId: "acme"}, "default")
+
+	responseRecorder := serveTenantRequest(router, "localhost", "")
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, "acme", responseRecorder.Body.String())
+
+	responseRecorder = serveTenantRequest(router, "globex.users.example.com", "")
+	assert.Equal(t, http.StatusForbidden, responseRecorder.Code)
+	tenantServiceMock.AssertNumberOfCalls(t, "Resolve", 1)
+}
+
+func Test_TenantResolve_Should_Keep_Anonymous_Requests_To_The_Default_Tenant(t *testing.T) {
+	tenantServiceMock := new(serviceMock.TenantServiceInterface)
+	tenantServiceMock.On("Resolve", mock.Anything, "default").Return(nil)
+	router := newTenantTestRouter(tenantServiceMock, nil, "default")
+
+	for _, testCase := range []struct {
+		host, header string
+		expected     int
+	}{
+		{"localhost", "", http.StatusOK},
+		{"localhost", "default", http.StatusOK},
+		{"default.users.example.com", "", http.StatusOK},
+		{"localhost", "acme", http.StatusUnauthorized},
+		{"acme.users.example.com", "", http.StatusUnauthorized},
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/users", nil)
+		request.Host = testCase.host
+		if testCase.header != "" {
+			request.Header.Set(TenantHeader, testCase.header)
+		}
+		responseRecorder := httptest.NewRecorder()
+		router.ServeHTTP(responseRecorder, request)
+
+		assert.Equal(t, testCase.expected, responseRecorder.Code, testCase.host+" "+testCase.header)
+	}
+	tenantServiceMock.AssertNotCalled(t, "Resolve", mock.Anything, "acme")
+}
+
+func Test_TenantResolve_Should_Return_400_When_No_Tenant_Is_Named_And_There_Is_No_Default(t *testing.T) {
+	tenantServiceMock := new(serviceMock.TenantServiceInterface)
+	router := newTenantTestRouter(tenantServiceMock, &model.Principal{ApiKeyId: "admin"}, "")
+
+	responseRecorder := serveTenantRequest(router, "localhost", "")
+
+	assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+	assert.Contains(t, responseRecorder.Body.String(), "/problems/tenant-required")
+	tenantServiceMock.AssertNotCalled(t, "Resolve", mock.Anything, mock.Anything)
+}
+
+func Test_TenantResolve_Should_Return_404_When_The_Tenant_Does_Not_Exist(t *testing.T) {
+	tenantServiceMock := new(serviceMock.TenantServiceInterface)
+	tenantServiceMock.On("Resolve", mock.Anything, "globex").Return(errs.TenantNotFoundError)
+	router := newTenantTestRouter(tenantServiceMock, &model.Principal{ApiKeyId: "admin"}, "default")
+
+	responseRecorder := serveTenantRequest(router, "localhost", "globex")
+
+	assert.Equal(t, http.StatusNotFound, responseRecorder.Code)
+}
+
+func Test_TenantCreate_Should_Return_201_With_The_Location_Of_The_Tenant(t *testing.T) {
+	tenantServiceMock := new(serviceMock.TenantServiceInterface)
+	tenantServiceMock.On("Create", mock.Anything, model.CreateTenantDomainModel{Id: "acme", Name: "Acme"}).
+		Return(&model.TenantDomainModel{Id: "acme", Name: "Acme"}, nil).Once()
+
+	request := httptest.NewRequest(http.MethodPost, "/tenants", strings.NewReader(`{"id":"acme","name":"Acme"}`))
+	request.Header.Set("Authorization", "ApiKey usk_0123456789ab_secret")
+	responseRecorder := httptest.NewRecorder()
+	newTenantTestRouter(tenantServiceMock, &model.Principal{ApiKeyId: "admin"}, "default").ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusCreated, responseRecorder.Code)
+	assert.Equal(t, "/tenants/acme", responseRecorder.Header().Get("Location"))
+	tenantServiceMock.AssertExpectations(t)
+}
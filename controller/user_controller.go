@@ -1,79 +1,201 @@
 package controller
 
 import (
-	"errors"
+	"context"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 	errs "user-service/error"
+	"user-service/i18n"
 	"user-service/model"
 	"user-service/service"
 )
 
+const (
+	// MaxPageSize is the largest limit GetAll accepts.
+	MaxPageSize = 100
+	// exportFlushInterval is how many users Export encodes before flushing them to the client.
+	exportFlushInterval = 100
+)
+
 type UserController struct {
+	problemResponder
 	userService service.UserServiceInterface
 	validator   *validator.Validate
 }
 
-func NewUserController(userService service.UserServiceInterface, validator *validator.Validate) *UserController {
+func NewUserController(userService service.UserServiceInterface, validator *validator.Validate, catalog *i18n.Catalog, logger *slog.Logger) *UserController {
 	return &UserController{
-		userService: userService,
-		validator:   validator,
+		problemResponder: newProblemResponder(catalog, logger),
+		userService:      userService,
+		validator:        validator,
 	}
 }
 
 func (c *UserController) GetById(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	domainModel, err := c.userService.GetById(ctx, id)
+	domainModel, err := c.userService.GetById(requestContext(ctx), id)
 	if err != nil {
-		configureErrorResponse(ctx, err)
+		c.configureErrorResponse(ctx, err)
 		return
 	}
 
+	ctx.Header("ETag", formatETag(domainModel.Version))
 	ctx.IndentedJSON(http.StatusOK, copyDomainModelToViewModel(domainModel))
 }
 
 func (c *UserController) Create(ctx *gin.Context) {
 	var createViewModel model.CreateUserViewModel
 
-	err := ctx.BindJSON(&createViewModel)
+	err := ctx.ShouldBindJSON(&createViewModel)
 	if err != nil {
-		ctx.IndentedJSON(http.StatusBadRequest, map[string]string{"error": "Bad request"})
+		c.configureErrorResponse(ctx, errs.BadRequestError)
 		return
 	}
 
 	err = c.validator.Struct(createViewModel)
 	if err != nil {
-		ctx.IndentedJSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		c.configureValidationErrorResponse(ctx, err)
 		return
 	}
 
-	domainModel, err := c.userService.Create(ctx, copyCreateViewModelToCreateDomainModel(&createViewModel))
+	domainModel, err := c.userService.Create(requestContext(ctx), copyCreateViewModelToCreateDomainModel(&createViewModel))
 	if err != nil {
-		configureErrorResponse(ctx, err)
+		c.configureErrorResponse(ctx, err)
 		return
 	}
 
+	ctx.Header("ETag", formatETag(domainModel.Version))
 	ctx.IndentedJSON(http.StatusOK, copyDomainModelToViewModel(domainModel))
 }
 
 func (c *UserController) GetAll(ctx *gin.Context) {
-	domainModels, err := c.userService.GetAll(ctx)
+	filter, err := parseUserFilter(ctx)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	sort, err := parseUserSort(ctx.Query("sort"))
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	page := model.PageDomainModel{
+		After:  ctx.Query("after"),
+		Filter: filter,
+		Sort:   sort,
+	}
+
+	if limit := ctx.Query("limit"); limit != "" {
+		page.Limit, err = strconv.Atoi(limit)
+		if err != nil || page.Limit < 1 || page.Limit > MaxPageSize {
+			c.configureErrorResponse(ctx, errs.BadRequestError)
+			return
+		}
+	}
+
+	domainModels, next, err := c.userService.GetAll(requestContext(ctx), page)
 	if err != nil {
-		configureErrorResponse(ctx, err)
+		c.configureErrorResponse(ctx, err)
 		return
 	}
 
+	if next != "" {
+		query := ctx.Request.URL.Query()
+		query.Set("limit", strconv.Itoa(page.Limit))
+		query.Set("after", next)
+		ctx.Header("Link", `</users?`+query.Encode()+`>; rel="next"`)
+	}
+
 	ctx.IndentedJSON(http.StatusOK, copyDomainModelsToViewModels(domainModels))
 }
 
+// Export streams every user matching the list filters as csv, ndjson or json, flushing as it goes so the
+// response is sent chunked and memory stays bounded however many users there are.
+func (c *UserController) Export(ctx *gin.Context) {
+	format, ok := exportFormats[ctx.DefaultQuery("format", "json")]
+	if !ok {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	filter, err := parseUserFilter(ctx)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	columns := exportColumns
+	if selected := ctx.Query("columns"); selected != "" {
+		columns = strings.Split(selected, ",")
+		for _, column := range columns {
+			if _, ok := exportColumnValues[column]; !ok {
+				c.configureErrorResponse(ctx, errs.BadRequestError)
+				return
+			}
+		}
+	}
+
+	writer := format.newWriter(ctx.Writer, columns)
+	written := 0
+	startResponse := func() {
+		ctx.Header("Content-Type", format.contentType)
+		ctx.Header("Content-Disposition", `attachment; filename="users.`+format.extension+`"`)
+		ctx.Status(http.StatusOK)
+	}
+
+	err = c.userService.Export(requestContext(ctx), filter, func(domainModel *model.UserDomainModel) error {
+		if written == 0 {
+			startResponse()
+		}
+
+		err := writer.Write(domainModel)
+		if err != nil {
+			return err
+		}
+
+		written++
+		if written%exportFlushInterval == 0 {
+			err = writer.Flush()
+			ctx.Writer.Flush()
+		}
+
+		return err
+	})
+	if err != nil && written == 0 {
+		c.configureErrorResponse(ctx, err)
+		return
+	} else if err != nil {
+		// The status line is already sent, so the best signal left is a document that ends abruptly.
+		c.logger.ErrorContext(requestContext(ctx), "the export was cut off", "error", err)
+		writer.Flush()
+		return
+	}
+
+	if written == 0 {
+		startResponse()
+	}
+
+	err = writer.Close()
+	if err != nil {
+		c.logger.ErrorContext(requestContext(ctx), "finishing the export failed", "error", err)
+	}
+}
+
 func (c *UserController) DeleteById(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	err := c.userService.DeleteById(ctx, id)
+	err := c.userService.DeleteById(requestContext(ctx), id)
 	if err != nil {
-		configureErrorResponse(ctx, err)
+		c.configureErrorResponse(ctx, err)
 		return
 	}
 
@@ -85,48 +207,354 @@ func (c *UserController) UpdateById(ctx *gin.Context) {
 
 	var updateViewModel model.UpdateUserViewModel
 
-	err := ctx.BindJSON(&updateViewModel)
+	err := ctx.ShouldBindJSON(&updateViewModel)
 	if err != nil {
-		ctx.IndentedJSON(http.StatusBadRequest, map[string]string{"error": "Bad request"})
+		c.configureErrorResponse(ctx, errs.BadRequestError)
 		return
 	}
 
 	err = c.validator.Struct(updateViewModel)
 	if err != nil {
-		ctx.IndentedJSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		c.configureValidationErrorResponse(ctx, err)
 		return
 	}
 
-	domainModel, err := c.userService.UpdateById(ctx, id, copyUpdateViewModelToUpdateDomainModel(&updateViewModel))
+	domainModel, err := c.userService.UpdateById(requestContext(ctx), id, copyUpdateViewModelToUpdateDomainModel(&updateViewModel))
 	if err != nil {
-		configureErrorResponse(ctx, err)
+		c.configureErrorResponse(ctx, err)
 		return
 	}
 
+	ctx.Header("ETag", formatETag(domainModel.Version))
 	ctx.IndentedJSON(http.StatusOK, copyDomainModelToViewModel(domainModel))
 }
 
-func configureErrorResponse(ctx *gin.Context, err error) {
-	if errors.Is(err, errs.BadRequestError) {
-		ctx.IndentedJSON(http.StatusBadRequest, map[string]string{"error": errs.BadRequestError.Error()})
+func (c *UserController) ReplaceById(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var replaceViewModel model.ReplaceUserViewModel
+
+	err := ctx.ShouldBindJSON(&replaceViewModel)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
 		return
-	} else if errors.Is(err, errs.NotFoundError) {
-		ctx.IndentedJSON(http.StatusNotFound, map[string]string{"error": errs.NotFoundError.Error()})
+	}
+
+	err = c.validator.Struct(replaceViewModel)
+	if err != nil {
+		c.configureValidationErrorResponse(ctx, err)
 		return
-	} else if errors.Is(err, errs.EmailAlreadyInUseError) {
-		ctx.IndentedJSON(http.StatusConflict, map[string]string{"error": errs.EmailAlreadyInUseError.Error()})
+	}
+
+	precondition := parseIfMatch(ctx.GetHeader("If-Match"))
+
+	domainModel, created, err := c.userService.ReplaceById(requestContext(ctx), id, copyReplaceViewModelToReplaceDomainModel(&replaceViewModel), precondition)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
 		return
-	} else if errors.Is(err, errs.ServerError) {
-		ctx.IndentedJSON(http.StatusInternalServerError, map[string]string{"error": errs.ServerError.Error()})
+	}
+
+	ctx.Header("ETag", formatETag(domainModel.Version))
+	if created {
+		ctx.Header("Location", "/users/"+domainModel.Id)
+		ctx.IndentedJSON(http.StatusCreated, copyDomainModelToViewModel(domainModel))
+		return
+	}
+
+	ctx.IndentedJSON(http.StatusOK, copyDomainModelToViewModel(domainModel))
+}
+
+// BatchGet returns the users with the given ids along with the ids that do not belong to a user.
+func (c *UserController) BatchGet(ctx *gin.Context) {
+	var batchViewModel model.BatchIdsViewModel
+
+	err := ctx.ShouldBindJSON(&batchViewModel)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	err = c.validator.Struct(batchViewModel)
+	if err != nil {
+		c.configureValidationErrorResponse(ctx, err)
 		return
 	}
+
+	domainModels, err := c.userService.GetByIds(requestContext(ctx), batchViewModel.Ids)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	found := map[string]bool{}
+	for _, domainModel := range domainModels {
+		found[domainModel.Id] = true
+	}
+
+	response := model.BatchGetUsersViewModel{Users: copyDomainModelsToViewModels(domainModels), Missing: []string{}}
+	if response.Users == nil {
+		response.Users = []model.UserViewModel{}
+	}
+	for _, id := range batchViewModel.Ids {
+		if !found[id] {
+			response.Missing = append(response.Missing, id)
+		}
+	}
+
+	ctx.IndentedJSON(http.StatusOK, response)
+}
+
+// BatchUpdate applies every update and reports each one separately, so some can fail while the rest succeed.
+func (c *UserController) BatchUpdate(ctx *gin.Context) {
+	var batchViewModel model.BatchUpdateUsersViewModel
+
+	err := ctx.ShouldBindJSON(&batchViewModel)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	err = c.validator.Struct(batchViewModel)
+	if err != nil {
+		c.configureValidationErrorResponse(ctx, err)
+		return
+	}
+
+	updates := make([]model.BatchUpdateUserDomainModel, len(batchViewModel.Updates))
+	for i, update := range batchViewModel.Updates {
+		updates[i] = model.BatchUpdateUserDomainModel{
+			Id: update.Id,
+			Update: model.UpdateUserDomainModel{
+				Name:     update.Name,
+				Email:    update.Email,
+				Password: update.Password,
+				Profile:  update.Profile,
+			},
+		}
+	}
+
+	results, err := c.userService.UpdateByIds(requestContext(ctx), updates)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.IndentedJSON(http.StatusOK, c.copyBatchResultsToViewModel(ctx, results))
+}
+
+// BatchDelete deletes every given user and reports each id separately, so unknown ids do not fail the batch.
+func (c *UserController) BatchDelete(ctx *gin.Context) {
+	var batchViewModel model.BatchIdsViewModel
+
+	err := ctx.ShouldBindJSON(&batchViewModel)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	err = c.validator.Struct(batchViewModel)
+	if err != nil {
+		c.configureValidationErrorResponse(ctx, err)
+		return
+	}
+
+	results, err := c.userService.DeleteByIds(requestContext(ctx), batchViewModel.Ids)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.IndentedJSON(http.StatusOK, c.copyBatchResultsToViewModel(ctx, results))
+}
+
+func (c *problemResponder) copyBatchResultsToViewModel(ctx *gin.Context, results []*model.BatchResultDomainModel) model.BatchResultsViewModel {
+	viewModel := model.BatchResultsViewModel{Results: make([]model.BatchResultViewModel, len(results))}
+
+	for i, result := range results {
+		viewModel.Results[i] = model.BatchResultViewModel{Id: result.Id, Status: http.StatusOK}
+
+		if result.Err != nil {
+			problem := c.problemFor(ctx, result.Err)
+			viewModel.Results[i].Status = problem.Status
+			viewModel.Results[i].Error = &problem
+		} else if result.User != nil {
+			user := copyDomainModelToViewModel(result.User)
+			viewModel.Results[i].User = &user
+		}
+	}
+
+	return viewModel
+}
+
+func (c *UserController) GetRevisions(ctx *gin.Context) {
+	domainModels, err := c.userService.GetRevisions(requestContext(ctx), ctx.Param("id"))
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	viewModels := make([]model.UserRevisionViewModel, len(domainModels))
+	for i, domainModel := range domainModels {
+		viewModels[i] = copyRevisionDomainModelToViewModel(domainModel)
+	}
+
+	ctx.IndentedJSON(http.StatusOK, viewModels)
+}
+
+func (c *UserController) GetRevision(ctx *gin.Context) {
+	revision, err := strconv.ParseInt(ctx.Param("rev"), 10, 64)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	domainModel, err := c.userService.GetRevision(requestContext(ctx), ctx.Param("id"), revision)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.IndentedJSON(http.StatusOK, copyRevisionDomainModelToViewModel(domainModel))
+}
+
+// DiffRevisions compares the revision in the path with the one in the from query, which defaults to the
+// revision before it.
+func (c *UserController) DiffRevisions(ctx *gin.Context) {
+	to, err := strconv.ParseInt(ctx.Param("rev"), 10, 64)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	from := to - 1
+	if query := ctx.Query("from"); query != "" {
+		from, err = strconv.ParseInt(query, 10, 64)
+		if err != nil {
+			c.configureErrorResponse(ctx, errs.BadRequestError)
+			return
+		}
+	}
+
+	diff, err := c.userService.DiffRevisions(requestContext(ctx), ctx.Param("id"), from, to)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	viewModel := model.RevisionDiffViewModel{From: diff.From, To: diff.To, Changes: make([]model.FieldChangeViewModel, len(diff.Changes))}
+	for i, change := range diff.Changes {
+		viewModel.Changes[i] = model.FieldChangeViewModel{Field: change.Field, From: change.From, To: change.To}
+	}
+
+	ctx.IndentedJSON(http.StatusOK, viewModel)
+}
+
+// RevertToRevision validates the revision like a PATCH body before restoring it, since the rules may have
+// tightened since it was written.
+func (c *UserController) RevertToRevision(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	revision, err := strconv.ParseInt(ctx.Param("rev"), 10, 64)
+	if err != nil {
+		c.configureErrorResponse(ctx, errs.BadRequestError)
+		return
+	}
+
+	revisionModel, err := c.userService.GetRevision(requestContext(ctx), id, revision)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	err = c.validator.Struct(model.UpdateUserViewModel{Name: &revisionModel.Name, Email: &revisionModel.Email})
+	if err != nil {
+		c.configureValidationErrorResponse(ctx, err)
+		return
+	}
+
+	domainModel, err := c.userService.RevertToRevision(requestContext(ctx), id, revision)
+	if err != nil {
+		c.configureErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", formatETag(domainModel.Version))
+	ctx.IndentedJSON(http.StatusOK, copyDomainModelToViewModel(domainModel))
+}
+
+func copyRevisionDomainModelToViewModel(domainModel *model.UserRevisionDomainModel) model.UserRevisionViewModel {
+	return model.UserRevisionViewModel{
+		Revision:  domainModel.Revision,
+		Name:      domainModel.Name,
+		Email:     domainModel.Email,
+		Profile:   domainModel.Profile,
+		CreatedAt: domainModel.CreatedAt,
+	}
+}
+
+// parseUserFilter reads the list filters shared by GetAll and Export. Timestamps are RFC 3339 and profile
+// attributes are given as profile[name]=value.
+func parseUserFilter(ctx *gin.Context) (model.UserFilterDomainModel, error) {
+	filter := model.UserFilterDomainModel{
+		Name:      ctx.Query("name"),
+		Email:     ctx.Query("email"),
+		CreatedBy: ctx.Query("createdBy"),
+		UpdatedBy: ctx.Query("updatedBy"),
+	}
+
+	for attribute, value := range ctx.QueryMap("profile") {
+		if filter.Profile == nil {
+			filter.Profile = map[string]interface{}{}
+		}
+		filter.Profile[attribute] = value
+	}
+
+	timeFilters := map[string]*time.Time{
+		"createdAfter":  &filter.CreatedAfter,
+		"createdBefore": &filter.CreatedBefore,
+		"updatedAfter":  &filter.UpdatedAfter,
+		"updatedBefore": &filter.UpdatedBefore,
+	}
+	for name, value := range timeFilters {
+		if query := ctx.Query(name); query != "" {
+			var err error
+			*value, err = time.Parse(time.RFC3339, query)
+			if err != nil {
+				return filter, err
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+// parseUserSort reads a sort field, prefixed with - for descending order. An empty value sorts by id.
+func parseUserSort(value string) (model.UserSort, error) {
+	sort := model.UserSort{Field: strings.TrimPrefix(value, "-"), Descending: strings.HasPrefix(value, "-")}
+
+	switch sort.Field {
+	case model.SortById, model.SortByCreatedAt, model.SortByUpdatedAt:
+	case "":
+		if sort.Descending {
+			return sort, fmt.Errorf("the sort field is missing")
+		}
+	default:
+		return sort, fmt.Errorf("unknown sort field %q", sort.Field)
+	}
+
+	return sort, nil
 }
 
 func copyDomainModelToViewModel(domainModel *model.UserDomainModel) model.UserViewModel {
 	return model.UserViewModel{
-		Id:    domainModel.Id,
-		Name:  domainModel.Name,
-		Email: domainModel.Email,
+		Id:        domainModel.Id,
+		Name:      domainModel.Name,
+		Email:     domainModel.Email,
+		CreatedAt: domainModel.CreatedAt,
+		UpdatedAt: domainModel.UpdatedAt,
+		CreatedBy: domainModel.CreatedBy,
+		UpdatedBy: domainModel.UpdatedBy,
+		Profile:   domainModel.Profile,
 	}
 }
 
@@ -135,6 +563,7 @@ func copyCreateViewModelToCreateDomainModel(viewModel *model.CreateUserViewModel
 		Name:     viewModel.Name,
 		Email:    viewModel.Email,
 		Password: viewModel.Password,
+		Profile:  viewModel.Profile,
 	}
 }
 
@@ -143,19 +572,67 @@ func copyUpdateViewModelToUpdateDomainModel(viewModel *model.UpdateUserViewModel
 		Name:     viewModel.Name,
 		Email:    viewModel.Email,
 		Password: viewModel.Password,
+		Profile:  viewModel.Profile,
+	}
+}
+
+func copyReplaceViewModelToReplaceDomainModel(viewModel *model.ReplaceUserViewModel) model.ReplaceUserDomainModel {
+	return model.ReplaceUserDomainModel{
+		Name:     viewModel.Name,
+		Email:    viewModel.Email,
+		Password: viewModel.Password,
+		Profile:  viewModel.Profile,
 	}
 }
 
 func copyDomainModelsToViewModels(domainModels []*model.UserDomainModel) (viewModels []model.UserViewModel) {
 	for i := 0; i < len(domainModels); i++ {
-		var viewModel model.UserViewModel
+		viewModels = append(viewModels, copyDomainModelToViewModel(domainModels[i]))
+	}
 
-		viewModel.Id = domainModels[i].Id
-		viewModel.Name = domainModels[i].Name
-		viewModel.Email = domainModels[i].Email
+	return
+}
 
-		viewModels = append(viewModels, viewModel)
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch turns an If-Match header into a precondition, returning nil when the header is absent.
+// If-Match uses strong comparison, so weak and malformed entity tags never match.
+func parseIfMatch(header string) *model.Precondition {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return nil
 	}
 
-	return
+	if header == "*" {
+		return &model.Precondition{AnyVersion: true}
+	}
+
+	precondition := &model.Precondition{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+
+		version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		precondition.Versions = append(precondition.Versions, version)
+	}
+
+	return precondition
+}
+
+// requestContext returns the context of the underlying request, which is cancelled when the client goes away.
+// *gin.Context itself never reports cancellation, so it must not be handed to the service layer.
+func requestContext(ctx *gin.Context) context.Context {
+	if ctx.Request == nil {
+		return context.Background()
+	}
+
+	return ctx.Request.Context()
 }
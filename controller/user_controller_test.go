@@ -2,17 +2,21 @@ package controller
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 	errs "user-service/error"
+	"user-service/logging"
 	"user-service/model"
 	serviceMock "user-service/service/mock"
 )
@@ -44,7 +48,7 @@ func Test_Create_Should_Return_200_And_User_When_Nothing_Fails(t *testing.T) {
 	requestBody, _ := json.Marshal(createViewModel)
 	ctx.Request = &http.Request{Body: io.NopCloser(bytes.NewBuffer(requestBody))}
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.Create(ctx)
 
 	var user model.UserViewModel
@@ -73,13 +77,45 @@ func Test_Create_Should_Return_400_And_BadRequestError_When_Email_Is_Invalid(t *
 	requestBody, _ := json.Marshal(createViewModel)
 	ctx.Request = &http.Request{Body: io.NopCloser(bytes.NewBuffer(requestBody))}
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.Create(ctx)
 
-	var err map[string]string
-	json.NewDecoder(responseRecorder.Result().Body).Decode(&err)
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&problem)
 
 	assert.Equal(t, ctx.Writer.Status(), 400)
+	assert.Equal(t, responseRecorder.Header().Get("Content-Type"), "application/problem+json")
+	assert.Equal(t, problem.Type, "/problems/validation-error")
+	assert.Equal(t, len(problem.Errors), 1)
+	assert.Equal(t, problem.Errors[0].Field, "email")
+	assert.Equal(t, problem.Errors[0].Rule, "email")
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Create_Should_Return_Localized_Problem_When_Accept_Language_Is_Sent(t *testing.T) {
+	var createViewModel = model.CreateUserViewModel{
+		Name:  "something",
+		Email: "something@site.com",
+	}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Create", mock.Anything, mock.Anything).Maybe().Times(0)
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	requestBody, _ := json.Marshal(createViewModel)
+	ctx.Request = &http.Request{Header: http.Header{"Accept-Language": []string{"tr-TR,tr;q=0.9,en;q=0.8"}}, Body: io.NopCloser(bytes.NewBuffer(requestBody))}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.Create(ctx)
+
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&problem)
+
+	assert.Equal(t, ctx.Writer.Status(), 400)
+	assert.Equal(t, responseRecorder.Header().Get("Content-Language"), "tr")
+	assert.Equal(t, problem.Detail, "istek gövdesi doğrulamadan geçemedi")
+	assert.Equal(t, problem.Errors[0].Message, "password zorunlu bir alandır")
 	userServiceMock.AssertExpectations(t)
 }
 
@@ -106,14 +142,14 @@ func Test_Create_Should_Return_409_And_EmailAlreadyInUseError_When_Email_Already
 	requestBody, _ := json.Marshal(createViewModel)
 	ctx.Request = &http.Request{Body: io.NopCloser(bytes.NewBuffer(requestBody))}
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.Create(ctx)
 
-	var err map[string]string
-	json.NewDecoder(responseRecorder.Result().Body).Decode(&err)
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&problem)
 
 	assert.Equal(t, ctx.Writer.Status(), 409)
-	assert.Equal(t, err["error"], errs.EmailAlreadyInUseError.Error())
+	assert.Equal(t, problem.Detail, errs.EmailAlreadyInUseError.Error())
 	userServiceMock.AssertExpectations(t)
 }
 
@@ -131,7 +167,7 @@ func Test_GetById_Should_Return_200_And_User_When_Nothing_Fails(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(responseRecorder)
 	ctx.AddParam("id", id.Hex())
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.GetById(ctx)
 
 	var user model.UserViewModel
@@ -143,6 +179,26 @@ func Test_GetById_Should_Return_200_And_User_When_Nothing_Fails(t *testing.T) {
 
 }
 
+func Test_GetById_Should_Pass_Request_Context_To_Service(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	requestContext, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetById", requestContext, id.Hex()).Return(nil, context.Canceled).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.AddParam("id", id.Hex())
+	ctx.Request = (&http.Request{URL: &url.URL{Path: "/users/" + id.Hex()}}).WithContext(requestContext)
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.GetById(ctx)
+
+	userServiceMock.AssertExpectations(t)
+}
+
 func Test_GetById_Should_Return_400_When_Id_Is_Invalid(t *testing.T) {
 	var id = "an invalid id"
 
@@ -153,14 +209,14 @@ func Test_GetById_Should_Return_400_When_Id_Is_Invalid(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(responseRecorder)
 	ctx.AddParam("id", id)
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.GetById(ctx)
 
-	var err map[string]string
-	json.NewDecoder(responseRecorder.Result().Body).Decode(&err)
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&problem)
 
 	assert.Equal(t, ctx.Writer.Status(), 400)
-	assert.Equal(t, err["error"], errs.BadRequestError.Error())
+	assert.Equal(t, problem.Detail, errs.BadRequestError.Error())
 	userServiceMock.AssertExpectations(t)
 
 }
@@ -175,14 +231,14 @@ func Test_GetById_Should_Return_404_When_User_Is_Not_Found(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(responseRecorder)
 	ctx.AddParam("id", id.Hex())
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.GetById(ctx)
 
-	var err map[string]string
-	json.NewDecoder(responseRecorder.Result().Body).Decode(&err)
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&problem)
 
 	assert.Equal(t, ctx.Writer.Status(), 404)
-	assert.Equal(t, err["error"], errs.NotFoundError.Error())
+	assert.Equal(t, problem.Detail, errs.NotFoundError.Error())
 	userServiceMock.AssertExpectations(t)
 
 }
@@ -197,18 +253,42 @@ func Test_GetById_Should_Return_500_When_Fetch_Operation_Fails(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(responseRecorder)
 	ctx.AddParam("id", id.Hex())
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.GetById(ctx)
 
-	var err map[string]string
-	json.NewDecoder(responseRecorder.Result().Body).Decode(&err)
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&problem)
 
 	assert.Equal(t, ctx.Writer.Status(), 500)
-	assert.Equal(t, err["error"], errs.ServerError.Error())
+	assert.Equal(t, problem.Detail, errs.ServerError.Error())
 	userServiceMock.AssertExpectations(t)
 
 }
 
+func Test_GetById_Should_Return_500_Problem_When_Error_Is_Unknown(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetById", mock.Anything, id.Hex()).Return(nil, errors.New("unexpected")).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.AddParam("id", id.Hex())
+	ctx.Request = &http.Request{URL: &url.URL{Path: "/users/" + id.Hex()}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.GetById(ctx)
+
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&problem)
+
+	assert.Equal(t, ctx.Writer.Status(), 500)
+	assert.Equal(t, problem.Status, 500)
+	assert.Equal(t, problem.Detail, errs.ServerError.Error())
+	assert.Equal(t, problem.Instance, "/users/"+id.Hex())
+	userServiceMock.AssertExpectations(t)
+}
+
 func Test_GetAll_Should_Return_200_And_Users_When_Nothing_Fails(t *testing.T) {
 	var firstUser = model.UserDomainModel{
 		Id: primitive.NewObjectID().Hex(),
@@ -219,12 +299,12 @@ func Test_GetAll_Should_Return_200_And_Users_When_Nothing_Fails(t *testing.T) {
 	}
 
 	userServiceMock := new(serviceMock.UserServiceInterface)
-	userServiceMock.On("GetAll", mock.Anything).Return([]*model.UserDomainModel{&firstUser, &secondUser}, nil).Once()
+	userServiceMock.On("GetAll", mock.Anything, model.PageDomainModel{}).Return([]*model.UserDomainModel{&firstUser, &secondUser}, "", nil).Once()
 
 	responseRecorder := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(responseRecorder)
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.GetAll(ctx)
 
 	var users []*model.UserViewModel
@@ -236,6 +316,127 @@ func Test_GetAll_Should_Return_200_And_Users_When_Nothing_Fails(t *testing.T) {
 	userServiceMock.AssertExpectations(t)
 }
 
+func Test_GetAll_Should_Return_Next_Link_When_Another_Page_Exists(t *testing.T) {
+	var user = model.UserDomainModel{
+		Id: primitive.NewObjectID().Hex(),
+	}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetAll", mock.Anything, model.PageDomainModel{Limit: 1}).Return([]*model.UserDomainModel{&user}, user.Id, nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Request = &http.Request{URL: &url.URL{Path: "/users", RawQuery: "limit=1"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.GetAll(ctx)
+
+	assert.Equal(t, ctx.Writer.Status(), 200)
+	assert.Equal(t, responseRecorder.Header().Get("Link"), `</users?after=`+user.Id+`&limit=1>; rel="next"`)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_GetAll_Should_Pass_Filters_To_Service_And_Keep_Them_In_Next_Link(t *testing.T) {
+	var user = model.UserDomainModel{
+		Id: primitive.NewObjectID().Hex(),
+	}
+
+	var page = model.PageDomainModel{
+		Limit:  1,
+		Filter: model.UserFilterDomainModel{Name: "bat", Email: "batuhan@site.com"},
+	}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetAll", mock.Anything, page).Return([]*model.UserDomainModel{&user}, user.Id, nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Request = &http.Request{URL: &url.URL{Path: "/users", RawQuery: "limit=1&name=bat&email=batuhan%40site.com"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.GetAll(ctx)
+
+	assert.Equal(t, ctx.Writer.Status(), 200)
+	assert.Equal(t, responseRecorder.Header().Get("Link"), `</users?after=`+user.Id+`&email=batuhan%40site.com&limit=1&name=bat>; rel="next"`)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_GetAll_Should_Pass_Sort_And_Metadata_Filters_To_Service(t *testing.T) {
+	var page = model.PageDomainModel{
+		Sort: model.UserSort{Field: model.SortByCreatedAt, Descending: true},
+		Filter: model.UserFilterDomainModel{
+			CreatedBy:    "import:1",
+			CreatedAfter: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetAll", mock.Anything, page).Return([]*model.UserDomainModel{}, "", nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Request = &http.Request{URL: &url.URL{Path: "/users", RawQuery: "sort=-createdAt&createdBy=import:1&createdAfter=2024-01-02T03:04:05Z"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.GetAll(ctx)
+
+	assert.Equal(t, ctx.Writer.Status(), 200)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_GetAll_Should_Return_400_When_Sort_Or_Timestamp_Is_Invalid(t *testing.T) {
+	for _, query := range []string{"sort=password", "sort=-", "createdAfter=yesterday", "updatedBefore=2024-01-02"} {
+		userServiceMock := new(serviceMock.UserServiceInterface)
+
+		responseRecorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(responseRecorder)
+		ctx.Request = &http.Request{URL: &url.URL{Path: "/users", RawQuery: query}}
+
+		classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+		classUnderTest.GetAll(ctx)
+
+		assert.Equal(t, 400, ctx.Writer.Status(), query)
+		userServiceMock.AssertExpectations(t)
+	}
+}
+
+func Test_GetAll_Should_Pass_Profile_Filters_And_Report_Rejected_Ones(t *testing.T) {
+	var page = model.PageDomainModel{Filter: model.UserFilterDomainModel{Profile: map[string]interface{}{"department": "sales", "age": "30"}}}
+	var rejected = &errs.FieldErrors{Err: errs.BadRequestError, Fields: []errs.FieldError{{Field: "profile[age]", Rule: "x-indexed", Param: "age"}}}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetAll", mock.Anything, page).Return(nil, "", rejected).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Request = &http.Request{URL: &url.URL{Path: "/users", RawQuery: "profile%5Bdepartment%5D=sales&profile[age]=30"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.GetAll(ctx)
+
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&problem)
+
+	assert.Equal(t, 400, ctx.Writer.Status())
+	assert.Equal(t, "profile[age]", problem.Errors[0].Field)
+	assert.Equal(t, "age is not an indexed profile attribute", problem.Errors[0].Message)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_GetAll_Should_Return_400_When_Limit_Is_Out_Of_Range(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Request = &http.Request{URL: &url.URL{Path: "/users", RawQuery: "limit=1000"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.GetAll(ctx)
+
+	assert.Equal(t, ctx.Writer.Status(), 400)
+	userServiceMock.AssertExpectations(t)
+}
+
 func Test_DeleteById_Should_Return_200_When_Nothing_Fails(t *testing.T) {
 	var id = primitive.NewObjectID()
 
@@ -246,7 +447,7 @@ func Test_DeleteById_Should_Return_200_When_Nothing_Fails(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(responseRecorder)
 	ctx.AddParam("id", id.Hex())
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.DeleteById(ctx)
 
 	assert.Equal(t, ctx.Writer.Status(), 200)
@@ -263,14 +464,14 @@ func Test_DeleteById_Should_Return_400_When_Id_Is_Invalid(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(responseRecorder)
 	ctx.AddParam("id", id)
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.DeleteById(ctx)
 
-	var err map[string]string
-	json.NewDecoder(responseRecorder.Result().Body).Decode(&err)
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&problem)
 
 	assert.Equal(t, ctx.Writer.Status(), 400)
-	assert.Equal(t, err["error"], errs.BadRequestError.Error())
+	assert.Equal(t, problem.Detail, errs.BadRequestError.Error())
 	userServiceMock.AssertExpectations(t)
 }
 
@@ -284,14 +485,14 @@ func Test_DeleteById_Should_Return_404_When_User_Is_Not_Found(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(responseRecorder)
 	ctx.AddParam("id", id.Hex())
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.DeleteById(ctx)
 
-	var err map[string]string
-	json.NewDecoder(responseRecorder.Result().Body).Decode(&err)
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&problem)
 
 	assert.Equal(t, ctx.Writer.Status(), 404)
-	assert.Equal(t, err["error"], errs.NotFoundError.Error())
+	assert.Equal(t, problem.Detail, errs.NotFoundError.Error())
 	userServiceMock.AssertExpectations(t)
 }
 
@@ -305,14 +506,14 @@ func Test_DeleteById_Should_Return_500_When_Delete_Operation_Fails(t *testing.T)
 	ctx, _ := gin.CreateTestContext(responseRecorder)
 	ctx.AddParam("id", id.Hex())
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.DeleteById(ctx)
 
-	var err map[string]string
-	json.NewDecoder(responseRecorder.Result().Body).Decode(&err)
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&problem)
 
 	assert.Equal(t, ctx.Writer.Status(), 500)
-	assert.Equal(t, err["error"], errs.ServerError.Error())
+	assert.Equal(t, problem.Detail, errs.ServerError.Error())
 	userServiceMock.AssertExpectations(t)
 }
 
@@ -341,7 +542,7 @@ func Test_UpdateById_Should_Return_200_And_User_When_Nothing_Fails(t *testing.T)
 	requestBody, _ := json.Marshal(updateViewModel)
 	ctx.Request = &http.Request{Body: io.NopCloser(bytes.NewBuffer(requestBody))}
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.UpdateById(ctx)
 
 	var user model.UserViewModel
@@ -353,6 +554,29 @@ func Test_UpdateById_Should_Return_200_And_User_When_Nothing_Fails(t *testing.T)
 
 }
 
+func Test_UpdateById_Should_Return_200_When_Email_Is_Omitted(t *testing.T) {
+	var id = primitive.NewObjectID()
+	var name = "Batuhan"
+
+	var updateDomainModel = model.UpdateUserDomainModel{
+		Name: &name,
+	}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("UpdateById", mock.Anything, id.Hex(), updateDomainModel).Return(&model.UserDomainModel{Name: name}, nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.AddParam("id", id.Hex())
+	ctx.Request = &http.Request{Body: io.NopCloser(bytes.NewBufferString(`{"name":"Batuhan"}`))}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.UpdateById(ctx)
+
+	assert.Equal(t, 200, ctx.Writer.Status())
+	userServiceMock.AssertExpectations(t)
+}
+
 func Test_UpdateById_Should_Return_400_And_BadRequestError_When_Email_Is_Invalid(t *testing.T) {
 	var id = primitive.NewObjectID()
 	var email = "not an email"
@@ -370,11 +594,11 @@ func Test_UpdateById_Should_Return_400_And_BadRequestError_When_Email_Is_Invalid
 	requestBody, _ := json.Marshal(updateViewModel)
 	ctx.Request = &http.Request{Body: io.NopCloser(bytes.NewBuffer(requestBody))}
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.UpdateById(ctx)
 
-	var err map[string]string
-	json.NewDecoder(responseRecorder.Result().Body).Decode(&err)
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&problem)
 
 	assert.Equal(t, ctx.Writer.Status(), 400)
 	userServiceMock.AssertExpectations(t)
@@ -401,13 +625,418 @@ func Test_UpdateById_Should_Return_409_And_EmailAlreadyInUseError_When_Email_Alr
 	requestBody, _ := json.Marshal(updateViewModel)
 	ctx.Request = &http.Request{Body: io.NopCloser(bytes.NewBuffer(requestBody))}
 
-	classUnderTest := NewUserController(userServiceMock, validator.New())
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
 	classUnderTest.UpdateById(ctx)
 
-	var err map[string]string
-	json.NewDecoder(responseRecorder.Result().Body).Decode(&err)
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&problem)
 
 	assert.Equal(t, ctx.Writer.Status(), 409)
-	assert.Equal(t, err["error"], errs.EmailAlreadyInUseError.Error())
+	assert.Equal(t, problem.Detail, errs.EmailAlreadyInUseError.Error())
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_ReplaceById_Should_Return_201_And_Location_When_User_Is_Created(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	var replaceViewModel = model.ReplaceUserViewModel{
+		Name:     "Batuhan",
+		Email:    "batuhan@site.com",
+		Password: "123456",
+	}
+
+	var replaceDomainModel = model.ReplaceUserDomainModel{
+		Name:     replaceViewModel.Name,
+		Email:    replaceViewModel.Email,
+		Password: replaceViewModel.Password,
+	}
+
+	var domainModel = model.UserDomainModel{
+		Id:      id.Hex(),
+		Name:    replaceDomainModel.Name,
+		Email:   replaceDomainModel.Email,
+		Version: 1,
+	}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("ReplaceById", mock.Anything, id.Hex(), replaceDomainModel, (*model.Precondition)(nil)).Return(&domainModel, true, nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.AddParam("id", id.Hex())
+	requestBody, _ := json.Marshal(replaceViewModel)
+	ctx.Request = &http.Request{Body: io.NopCloser(bytes.NewBuffer(requestBody))}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.ReplaceById(ctx)
+
+	var user model.UserViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&user)
+
+	assert.Equal(t, ctx.Writer.Status(), 201)
+	assert.Equal(t, responseRecorder.Header().Get("Location"), "/users/"+id.Hex())
+	assert.Equal(t, responseRecorder.Header().Get("ETag"), `"1"`)
+	assert.Equal(t, user.Email, domainModel.Email)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_ReplaceById_Should_Return_400_When_Payload_Is_Incomplete(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	var replaceViewModel = model.ReplaceUserViewModel{
+		Email: "batuhan@site.com",
+	}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("ReplaceById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe().Times(0)
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.AddParam("id", id.Hex())
+	requestBody, _ := json.Marshal(replaceViewModel)
+	ctx.Request = &http.Request{Body: io.NopCloser(bytes.NewBuffer(requestBody))}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.ReplaceById(ctx)
+
+	assert.Equal(t, ctx.Writer.Status(), 400)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_ReplaceById_Should_Return_412_When_If_Match_Does_Not_Match(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	var replaceViewModel = model.ReplaceUserViewModel{
+		Name:     "Batuhan",
+		Email:    "batuhan@site.com",
+		Password: "123456",
+	}
+
+	var precondition = &model.Precondition{Versions: []int64{3}}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("ReplaceById", mock.Anything, id.Hex(), mock.Anything, precondition).Return(nil, false, errs.PreconditionFailedError).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.AddParam("id", id.Hex())
+	requestBody, _ := json.Marshal(replaceViewModel)
+	ctx.Request = &http.Request{Header: http.Header{"If-Match": []string{`"3"`}}, Body: io.NopCloser(bytes.NewBuffer(requestBody))}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.ReplaceById(ctx)
+
+	var problem model.ProblemViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&problem)
+
+	assert.Equal(t, ctx.Writer.Status(), 412)
+	assert.Equal(t, problem.Detail, errs.PreconditionFailedError.Error())
+	userServiceMock.AssertExpectations(t)
+}
+
+// exportUsers makes a mocked Export hand the given users to the controller's callback.
+func exportUsers(users []*model.UserDomainModel) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		fn := args.Get(2).(func(*model.UserDomainModel) error)
+		for _, user := range users {
+			fn(user)
+		}
+	}
+}
+
+func Test_Export_Should_Stream_Selected_Columns_As_CSV_When_Format_Is_CSV(t *testing.T) {
+	var users = []*model.UserDomainModel{
+		{Id: "1", Name: "Batuhan", Email: "batuhan@site.com"},
+		{Id: "2", Name: "Name, with comma", Email: "other@site.com"},
+	}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Export", mock.Anything, model.UserFilterDomainModel{Name: "a"}, mock.Anything).Run(exportUsers(users)).Return(nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Request = &http.Request{URL: &url.URL{Path: "/users/export", RawQuery: "format=csv&columns=email,name&name=a"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.Export(ctx)
+
+	assert.Equal(t, 200, responseRecorder.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", responseRecorder.Header().Get("Content-Type"))
+	assert.Equal(t, "email,name\nbatuhan@site.com,Batuhan\nother@site.com,\"Name, with comma\"\n", responseRecorder.Body.String())
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Export_Should_Write_The_Profile_As_JSON_When_Format_Is_CSV(t *testing.T) {
+	var users = []*model.UserDomainModel{
+		{Id: "1", Email: "batuhan@site.com", Profile: map[string]interface{}{"team": "red"}},
+		{Id: "2", Email: "other@site.com"},
+	}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Export", mock.Anything, model.UserFilterDomainModel{}, mock.Anything).Run(exportUsers(users)).Return(nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Request = &http.Request{URL: &url.URL{Path: "/users/export", RawQuery: "format=csv&columns=email,profile"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.Export(ctx)
+
+	assert.Equal(t, 200, responseRecorder.Code)
+	assert.Equal(t, "email,profile\nbatuhan@site.com,\"{\"\"team\"\":\"\"red\"\"}\"\nother@site.com,\n", responseRecorder.Body.String())
+}
+
+func Test_Export_Should_Stream_JSON_Array_When_Format_Is_Omitted(t *testing.T) {
+	var users = []*model.UserDomainModel{
+		{Id: "1", Name: "Batuhan", Email: "batuhan@site.com"},
+		{Id: "2", Name: "Other", Email: "other@site.com"},
+	}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Export", mock.Anything, model.UserFilterDomainModel{}, mock.Anything).Run(exportUsers(users)).Return(nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Request = &http.Request{URL: &url.URL{Path: "/users/export"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.Export(ctx)
+
+	var exported []*model.UserViewModel
+	err := json.NewDecoder(responseRecorder.Body).Decode(&exported)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 200, responseRecorder.Code)
+	assert.Equal(t, "2", exported[1].Id)
+	assert.Equal(t, "other@site.com", exported[1].Email)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Export_Should_Return_Empty_Document_When_No_User_Matches(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Export", mock.Anything, model.UserFilterDomainModel{}, mock.Anything).Return(nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Request = &http.Request{URL: &url.URL{Path: "/users/export", RawQuery: "format=json"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.Export(ctx)
+
+	assert.Equal(t, 200, responseRecorder.Code)
+	assert.Equal(t, "[]\n", responseRecorder.Body.String())
+}
+
+func Test_Export_Should_Return_400_When_Column_Is_Unknown(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Request = &http.Request{URL: &url.URL{Path: "/users/export", RawQuery: "columns=id,password"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.Export(ctx)
+
+	assert.Equal(t, 400, responseRecorder.Code)
+	userServiceMock.AssertNotCalled(t, "Export", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_Export_Should_Leave_JSON_Unterminated_When_Export_Fails_Midway(t *testing.T) {
+	var users = []*model.UserDomainModel{{Id: "1", Name: "Batuhan", Email: "batuhan@site.com"}}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Export", mock.Anything, model.UserFilterDomainModel{}, mock.Anything).Run(exportUsers(users)).Return(errs.ServerError).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Request = &http.Request{URL: &url.URL{Path: "/users/export", RawQuery: "format=json"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.Export(ctx)
+
+	var exported []*model.UserViewModel
+	err := json.Unmarshal(responseRecorder.Body.Bytes(), &exported)
+
+	assert.Equal(t, 200, responseRecorder.Code)
+	assert.NotNil(t, err)
+}
+
+func Test_Export_Should_Return_500_When_Export_Fails_Before_Any_User(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Export", mock.Anything, model.UserFilterDomainModel{}, mock.Anything).Return(errs.ServerError).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Request = &http.Request{URL: &url.URL{Path: "/users/export", RawQuery: "format=csv"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.Export(ctx)
+
+	assert.Equal(t, 500, responseRecorder.Code)
+	assert.Equal(t, problemContentType, responseRecorder.Header().Get("Content-Type"))
+}
+
+func Test_BatchGet_Should_Return_200_With_Found_Users_And_Missing_Ids(t *testing.T) {
+	var user = model.UserDomainModel{Id: primitive.NewObjectID().Hex(), Name: "Batuhan"}
+	var missingId = primitive.NewObjectID().Hex()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetByIds", mock.Anything, []string{user.Id, missingId}).Return([]*model.UserDomainModel{&user}, nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	requestBody, _ := json.Marshal(model.BatchIdsViewModel{Ids: []string{user.Id, missingId}})
+	ctx.Request = &http.Request{Body: io.NopCloser(bytes.NewBuffer(requestBody))}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.BatchGet(ctx)
+
+	var response model.BatchGetUsersViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&response)
+
+	assert.Equal(t, 200, responseRecorder.Code)
+	assert.Equal(t, []model.UserViewModel{{Id: user.Id, Name: user.Name}}, response.Users)
+	assert.Equal(t, []string{missingId}, response.Missing)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_BatchGet_Should_Return_400_When_More_Than_100_Ids_Are_Given(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	requestBody, _ := json.Marshal(model.BatchIdsViewModel{Ids: make([]string, 101)})
+	ctx.Request = &http.Request{Body: io.NopCloser(bytes.NewBuffer(requestBody))}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.BatchGet(ctx)
+
+	assert.Equal(t, 400, responseRecorder.Code)
+	userServiceMock.AssertNotCalled(t, "GetByIds", mock.Anything, mock.Anything)
+}
+
+func Test_BatchDelete_Should_Return_200_With_A_Status_And_Problem_Per_Id(t *testing.T) {
+	var deletedId = primitive.NewObjectID().Hex()
+	var missingId = primitive.NewObjectID().Hex()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("DeleteByIds", mock.Anything, []string{deletedId, missingId}).Return([]*model.BatchResultDomainModel{
+		{Id: deletedId},
+		{Id: missingId, Err: errs.NotFoundError},
+	}, nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	requestBody, _ := json.Marshal(model.BatchIdsViewModel{Ids: []string{deletedId, missingId}})
+	ctx.Request = &http.Request{Body: io.NopCloser(bytes.NewBuffer(requestBody))}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.BatchDelete(ctx)
+
+	var response model.BatchResultsViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&response)
+
+	assert.Equal(t, 200, responseRecorder.Code)
+	assert.Equal(t, 200, response.Results[0].Status)
+	assert.Nil(t, response.Results[0].Error)
+	assert.Equal(t, 404, response.Results[1].Status)
+	assert.Equal(t, "/problems/user-not-found", response.Results[1].Error.Type)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_BatchUpdate_Should_Return_200_With_Updated_Users_And_Problems(t *testing.T) {
+	var name = "Batuhan"
+	var user = model.UserDomainModel{Id: primitive.NewObjectID().Hex(), Name: name}
+	var takenEmail = "taken@site.com"
+	var otherId = primitive.NewObjectID().Hex()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("UpdateByIds", mock.Anything, []model.BatchUpdateUserDomainModel{
+		{Id: user.Id, Update: model.UpdateUserDomainModel{Name: &name}},
+		{Id: otherId, Update: model.UpdateUserDomainModel{Email: &takenEmail}},
+	}).Return([]*model.BatchResultDomainModel{
+		{Id: user.Id, User: &user},
+		{Id: otherId, Err: errs.EmailAlreadyInUseError},
+	}, nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	requestBody, _ := json.Marshal(model.BatchUpdateUsersViewModel{Updates: []model.BatchUpdateUserViewModel{
+		{Id: user.Id, Name: &name},
+		{Id: otherId, Email: &takenEmail},
+	}})
+	ctx.Request = &http.Request{Body: io.NopCloser(bytes.NewBuffer(requestBody))}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.BatchUpdate(ctx)
+
+	var response model.BatchResultsViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&response)
+
+	assert.Equal(t, 200, responseRecorder.Code)
+	assert.Equal(t, name, response.Results[0].User.Name)
+	assert.Equal(t, 409, response.Results[1].Status)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_DiffRevisions_Should_Compare_With_Previous_Revision_When_From_Is_Omitted(t *testing.T) {
+	var id = primitive.NewObjectID().Hex()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("DiffRevisions", mock.Anything, id, int64(2), int64(3)).Return(&model.RevisionDiffDomainModel{
+		From:    2,
+		To:      3,
+		Changes: []model.FieldChangeDomainModel{{Field: "name", From: "Old", To: "New"}},
+	}, nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Params = gin.Params{{Key: "id", Value: id}, {Key: "rev", Value: "3"}}
+	ctx.Request = &http.Request{URL: &url.URL{Path: "/users/" + id + "/revisions/3/diff"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.DiffRevisions(ctx)
+
+	var diff model.RevisionDiffViewModel
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&diff)
+
+	assert.Equal(t, 200, responseRecorder.Code)
+	assert.Equal(t, []model.FieldChangeViewModel{{Field: "name", From: "Old", To: "New"}}, diff.Changes)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_RevertToRevision_Should_Return_400_When_Revision_Fails_Validation(t *testing.T) {
+	var id = primitive.NewObjectID().Hex()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetRevision", mock.Anything, id, int64(1)).Return(&model.UserRevisionDomainModel{Revision: 1, Name: "Old", Email: "not an email"}, nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Params = gin.Params{{Key: "id", Value: id}, {Key: "rev", Value: "1"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.RevertToRevision(ctx)
+
+	assert.Equal(t, 400, responseRecorder.Code)
+	userServiceMock.AssertNotCalled(t, "RevertToRevision", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_RevertToRevision_Should_Return_200_And_ETag_When_Nothing_Fails(t *testing.T) {
+	var id = primitive.NewObjectID().Hex()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetRevision", mock.Anything, id, int64(1)).Return(&model.UserRevisionDomainModel{Revision: 1, Name: "Old", Email: "old@site.com"}, nil).Once()
+	userServiceMock.On("RevertToRevision", mock.Anything, id, int64(1)).Return(&model.UserDomainModel{Id: id, Name: "Old", Email: "old@site.com", Version: 5}, nil).Once()
+
+	responseRecorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(responseRecorder)
+	ctx.Params = gin.Params{{Key: "id", Value: id}, {Key: "rev", Value: "1"}}
+
+	classUnderTest := NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	classUnderTest.RevertToRevision(ctx)
+
+	assert.Equal(t, 200, responseRecorder.Code)
+	assert.Equal(t, `"5"`, responseRecorder.Header().Get("ETag"))
 	userServiceMock.AssertExpectations(t)
 }
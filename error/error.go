@@ -1,6 +1,9 @@
 package error
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var EmailAlreadyInUseError = errors.New("a user with that email already exists")
 
@@ -9,3 +12,73 @@ var BadRequestError = errors.New("bad request")
 var NotFoundError = errors.New("user with that id does not exist")
 
 var ServerError = errors.New("server error")
+
+var PreconditionFailedError = errors.New("user does not match the given precondition")
+
+var ValidationError = errors.New("the request body failed validation")
+
+var ImportJobNotFoundError = errors.New("import job with that id does not exist")
+
+var PayloadTooLargeError = errors.New("the request body is too large")
+
+var RevisionNotFoundError = errors.New("revision of that user does not exist")
+
+var ProfileSchemaNotFoundError = errors.New("no profile schema has been published")
+
+var InvalidProfileSchemaError = errors.New("the profile schema is invalid")
+
+var AvatarNotFoundError = errors.New("user with that id has no avatar")
+
+var UnsupportedMediaTypeError = errors.New("avatars must be PNG or JPEG images")
+
+var InvalidAvatarError = errors.New("the avatar is not a valid image or exceeds the maximum dimensions")
+
+var TooManyRequestsError = errors.New("too many requests, try again later")
+
+var UnauthorizedError = errors.New("the request lacks a valid API key")
+
+var ForbiddenError = errors.New("the API key is not allowed to make this request")
+
+var ApiKeyNotFoundError = errors.New("API key with that id does not exist or is no longer valid")
+
+var TenantRequiredError = errors.New("the request names no tenant")
+
+var TenantNotFoundError = errors.New("tenant with that id does not exist")
+
+var TenantAlreadyExistsError = errors.New("a tenant with that id already exists")
+
+var GroupNotFoundError = errors.New("group with that id does not exist")
+
+var GroupCycleError = errors.New("the group would end up containing itself")
+
+// FieldError is a problem with one field of a request that is found past the validator package, such as a
+// profile attribute that breaks the profile schema. The i18n catalog describes it by the message of its Key, or of
+// its Rule when Key is empty, filling in Param.
+type FieldError struct {
+	Field string
+	Rule  string
+	Key   string
+	Param string
+}
+
+// FieldErrors reports the fields that caused Err, which is one of the sentinels above.
+type FieldErrors struct {
+	Err    error
+	Fields []FieldError
+}
+
+func (e *FieldErrors) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + " failed on the '" + field.Rule + "' rule"
+		if field.Param != "" {
+			messages[i] += ": " + field.Param
+		}
+	}
+
+	return e.Err.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e *FieldErrors) Unwrap() error {
+	return e.Err
+}
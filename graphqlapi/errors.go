@@ -0,0 +1,73 @@
+package graphqlapi
+
+import (
+	"errors"
+	"github.com/go-playground/validator/v10"
+	errs "user-service/error"
+)
+
+type errorDefinition struct {
+	err  error
+	code string
+}
+
+// errorDefinitions maps every error package sentinel to the code reported in the extensions of a GraphQL error.
+var errorDefinitions = []errorDefinition{
+	{errs.BadRequestError, "BAD_REQUEST"},
+	{errs.ValidationError, "VALIDATION_FAILED"},
+	{errs.NotFoundError, "NOT_FOUND"},
+	{errs.EmailAlreadyInUseError, "EMAIL_ALREADY_IN_USE"},
+	{errs.PreconditionFailedError, "PRECONDITION_FAILED"},
+	{errs.ServerError, "SERVER_ERROR"},
+}
+
+type resolverError struct {
+	err    error
+	code   string
+	fields []map[string]string
+}
+
+func (e *resolverError) Error() string {
+	return e.err.Error()
+}
+
+func (e *resolverError) Unwrap() error {
+	return e.err
+}
+
+// Extensions is picked up by graphql-go and rendered as the extensions of the error.
+func (e *resolverError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.code}
+	if len(e.fields) > 0 {
+		extensions["errors"] = e.fields
+	}
+
+	return extensions
+}
+
+func toResolverError(err error) error {
+	for _, definition := range errorDefinitions {
+		if errors.Is(err, definition.err) {
+			return &resolverError{err: definition.err, code: definition.code}
+		}
+	}
+
+	// Anything that is not a known sentinel is reported as a server error without leaking its details.
+	return &resolverError{err: errs.ServerError, code: "SERVER_ERROR"}
+}
+
+func toValidationResolverError(err error) error {
+	resolverError := &resolverError{err: errs.ValidationError, code: "VALIDATION_FAILED"}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for _, fieldError := range validationErrors {
+			resolverError.fields = append(resolverError.fields, map[string]string{
+				"field": fieldError.Field(),
+				"rule":  fieldError.Tag(),
+			})
+		}
+	}
+
+	return resolverError
+}
@@ -0,0 +1,40 @@
+package graphqlapi
+
+import (
+	_ "embed"
+	"github.com/go-playground/validator/v10"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"net/http"
+	"user-service/service"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// maxQueryDepth keeps clients from sending arbitrarily nested queries.
+const maxQueryDepth = 10
+
+type Handler struct {
+	userService service.UserServiceInterface
+	relay       *relay.Handler
+}
+
+// NewHandler serves GraphQL queries over HTTP, resolving them through the given UserServiceInterface.
+func NewHandler(userService service.UserServiceInterface, validator *validator.Validate) *Handler {
+	resolver := &Resolver{
+		userService: userService,
+		validator:   validator,
+	}
+
+	return &Handler{
+		userService: userService,
+		relay:       &relay.Handler{Schema: graphql.MustParseSchema(schema, resolver, graphql.MaxDepth(maxQueryDepth))},
+	}
+}
+
+func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	ctx := withUserLoader(request.Context(), newUserLoader(h.userService))
+
+	h.relay.ServeHTTP(writer, request.WithContext(ctx))
+}
@@ -0,0 +1,147 @@
+package graphqlapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	errs "user-service/error"
+	"user-service/model"
+	serviceMock "user-service/service/mock"
+)
+
+type graphqlResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func query(userServiceMock *serviceMock.UserServiceInterface, query string, variables map[string]interface{}) graphqlResponse {
+	requestBody, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+
+	responseRecorder := httptest.NewRecorder()
+	classUnderTest := NewHandler(userServiceMock, validator.New())
+	classUnderTest.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBuffer(requestBody)))
+
+	var response graphqlResponse
+	json.NewDecoder(responseRecorder.Result().Body).Decode(&response)
+
+	return response
+}
+
+func Test_User_Should_Batch_Lookups_Into_One_GetByIds_Call(t *testing.T) {
+	var firstId = primitive.NewObjectID().Hex()
+	var secondId = primitive.NewObjectID().Hex()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetByIds", mock.Anything, mock.MatchedBy(func(ids []string) bool {
+		sorted := append([]string{}, ids...)
+		sort.Strings(sorted)
+		expected := []string{firstId, secondId}
+		sort.Strings(expected)
+		return assert.ObjectsAreEqual(expected, sorted)
+	})).Return([]*model.UserDomainModel{{Id: firstId, Name: "First"}, {Id: secondId, Name: "Second"}}, nil).Once()
+
+	response := query(userServiceMock, `query($first: ID!, $second: ID!) {
+		first: user(id: $first) { name }
+		second: user(id: $second) { name }
+	}`, map[string]interface{}{"first": firstId, "second": secondId})
+
+	assert.Empty(t, response.Errors)
+	assert.Equal(t, "First", response.Data["first"].(map[string]interface{})["name"])
+	assert.Equal(t, "Second", response.Data["second"].(map[string]interface{})["name"])
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_User_Should_Return_Null_When_User_Does_Not_Exist(t *testing.T) {
+	var id = primitive.NewObjectID().Hex()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetByIds", mock.Anything, []string{id}).Return(nil, nil).Once()
+
+	response := query(userServiceMock, `query($id: ID!) { user(id: $id) { id } }`, map[string]interface{}{"id": id})
+
+	assert.Empty(t, response.Errors)
+	assert.Nil(t, response.Data["user"])
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Users_Should_Return_Connection_With_Filter_And_Page_Info(t *testing.T) {
+	var users = []*model.UserDomainModel{{Id: primitive.NewObjectID().Hex()}, {Id: primitive.NewObjectID().Hex()}}
+
+	var page = model.PageDomainModel{
+		Limit:  2,
+		After:  "cursor",
+		Filter: model.UserFilterDomainModel{Name: "bat"},
+	}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetAll", mock.Anything, page).Return(users, users[1].Id, nil).Once()
+
+	response := query(userServiceMock, `{
+		users(filter: {name: "bat"}, first: 2, after: "cursor") {
+			edges { cursor node { id } }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`, nil)
+
+	connection := response.Data["users"].(map[string]interface{})
+	edges := connection["edges"].([]interface{})
+	pageInfo := connection["pageInfo"].(map[string]interface{})
+
+	assert.Empty(t, response.Errors)
+	assert.Equal(t, 2, len(edges))
+	assert.Equal(t, users[0].Id, edges[0].(map[string]interface{})["cursor"])
+	assert.Equal(t, true, pageInfo["hasNextPage"])
+	assert.Equal(t, users[1].Id, pageInfo["endCursor"])
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_CreateUser_Should_Return_Validation_Error_With_Code_When_Email_Is_Invalid(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+
+	response := query(userServiceMock, `mutation {
+		createUser(input: {name: "Batuhan", email: "not an email", password: "123456"}) { id }
+	}`, nil)
+
+	assert.Equal(t, 1, len(response.Errors))
+	assert.Equal(t, "VALIDATION_FAILED", response.Errors[0].Extensions["code"])
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_UpdateUser_Should_Return_Error_Code_When_Email_Is_In_Use(t *testing.T) {
+	var id = primitive.NewObjectID().Hex()
+	var email = "taken@site.com"
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("UpdateById", mock.Anything, id, model.UpdateUserDomainModel{Email: &email}).Return(nil, errs.EmailAlreadyInUseError).Once()
+
+	response := query(userServiceMock, `mutation($id: ID!) {
+		updateUser(id: $id, input: {email: "taken@site.com"}) { id }
+	}`, map[string]interface{}{"id": id})
+
+	assert.Equal(t, 1, len(response.Errors))
+	assert.Equal(t, "EMAIL_ALREADY_IN_USE", response.Errors[0].Extensions["code"])
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_DeleteUser_Should_Return_True_When_Nothing_Fails(t *testing.T) {
+	var id = primitive.NewObjectID().Hex()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("DeleteById", mock.Anything, id).Return(nil).Once()
+
+	response := query(userServiceMock, `mutation($id: ID!) { deleteUser(id: $id) }`, map[string]interface{}{"id": id})
+
+	assert.Empty(t, response.Errors)
+	assert.Equal(t, true, response.Data["deleteUser"])
+	userServiceMock.AssertExpectations(t)
+}
@@ -0,0 +1,58 @@
+package graphqlapi
+
+import (
+	"context"
+	"github.com/graph-gophers/dataloader/v7"
+	"time"
+	errs "user-service/error"
+	"user-service/model"
+	"user-service/service"
+)
+
+// loaderBatchWait is how long the loader collects ids before fetching them in one GetByIds call.
+const loaderBatchWait = 2 * time.Millisecond
+
+type loaderKey struct{}
+
+type userLoader = dataloader.Loader[string, *model.UserDomainModel]
+
+// newUserLoader batches every user lookup of a request into a single GetByIds call. Loaders cache their results,
+// so a new one must be created per request.
+func newUserLoader(userService service.UserServiceInterface) *userLoader {
+	batch := func(ctx context.Context, ids []string) []*dataloader.Result[*model.UserDomainModel] {
+		results := make([]*dataloader.Result[*model.UserDomainModel], len(ids))
+
+		domainModels, err := userService.GetByIds(ctx, ids)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[*model.UserDomainModel]{Error: err}
+			}
+			return results
+		}
+
+		found := map[string]*model.UserDomainModel{}
+		for _, domainModel := range domainModels {
+			found[domainModel.Id] = domainModel
+		}
+
+		for i, id := range ids {
+			if domainModel, ok := found[id]; ok {
+				results[i] = &dataloader.Result[*model.UserDomainModel]{Data: domainModel}
+			} else {
+				results[i] = &dataloader.Result[*model.UserDomainModel]{Error: errs.NotFoundError}
+			}
+		}
+
+		return results
+	}
+
+	return dataloader.NewBatchedLoader(batch, dataloader.WithWait[string, *model.UserDomainModel](loaderBatchWait))
+}
+
+func withUserLoader(ctx context.Context, loader *userLoader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, loader)
+}
+
+func userLoaderFrom(ctx context.Context) *userLoader {
+	return ctx.Value(loaderKey{}).(*userLoader)
+}
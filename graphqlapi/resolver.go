@@ -0,0 +1,222 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/graph-gophers/graphql-go"
+	errs "user-service/error"
+	"user-service/model"
+	"user-service/service"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type Resolver struct {
+	userService service.UserServiceInterface
+	validator   *validator.Validate
+}
+
+type userArgs struct {
+	Id graphql.ID
+}
+
+type usersArgs struct {
+	Filter *struct {
+		Name  *string
+		Email *string
+	}
+	First *int32
+	After *string
+}
+
+type createUserArgs struct {
+	Input struct {
+		Name     string
+		Email    string
+		Password string
+	}
+}
+
+type updateUserArgs struct {
+	Id    graphql.ID
+	Input struct {
+		Name     *string
+		Email    *string
+		Password *string
+	}
+}
+
+func (r *Resolver) User(ctx context.Context, args userArgs) (*userResolver, error) {
+	domainModel, err := userLoaderFrom(ctx).Load(ctx, string(args.Id))()
+	if errors.Is(err, errs.NotFoundError) {
+		return nil, nil
+	} else if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	return &userResolver{domainModel}, nil
+}
+
+func (r *Resolver) Users(ctx context.Context, args usersArgs) (*userConnectionResolver, error) {
+	page := model.PageDomainModel{Limit: defaultPageSize}
+	if args.First != nil {
+		page.Limit = int(*args.First)
+	}
+	if page.Limit < 1 || page.Limit > maxPageSize {
+		return nil, toResolverError(errs.BadRequestError)
+	}
+	if args.After != nil {
+		page.After = *args.After
+	}
+	if args.Filter != nil && args.Filter.Name != nil {
+		page.Filter.Name = *args.Filter.Name
+	}
+	if args.Filter != nil && args.Filter.Email != nil {
+		page.Filter.Email = *args.Filter.Email
+	}
+
+	domainModels, next, err := r.userService.GetAll(ctx, page)
+	if errors.Is(err, errs.NotFoundError) {
+		return &userConnectionResolver{}, nil
+	} else if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	// Later user(id) lookups in the same request are served from the listed users.
+	loader := userLoaderFrom(ctx)
+	for _, domainModel := range domainModels {
+		loader.Prime(ctx, domainModel.Id, domainModel)
+	}
+
+	return &userConnectionResolver{domainModels: domainModels, hasNextPage: next != ""}, nil
+}
+
+func (r *Resolver) CreateUser(ctx context.Context, args createUserArgs) (*userResolver, error) {
+	createViewModel := model.CreateUserViewModel{
+		Name:     args.Input.Name,
+		Email:    args.Input.Email,
+		Password: args.Input.Password,
+	}
+
+	err := r.validator.Struct(createViewModel)
+	if err != nil {
+		return nil, toValidationResolverError(err)
+	}
+
+	domainModel, err := r.userService.Create(ctx, model.CreateUserDomainModel{
+		Name:     createViewModel.Name,
+		Email:    createViewModel.Email,
+		Password: createViewModel.Password,
+	})
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	return &userResolver{domainModel}, nil
+}
+
+func (r *Resolver) UpdateUser(ctx context.Context, args updateUserArgs) (*userResolver, error) {
+	updateViewModel := model.UpdateUserViewModel{
+		Name:     args.Input.Name,
+		Email:    args.Input.Email,
+		Password: args.Input.Password,
+	}
+
+	err := r.validator.Struct(updateViewModel)
+	if err != nil {
+		return nil, toValidationResolverError(err)
+	}
+
+	domainModel, err := r.userService.UpdateById(ctx, string(args.Id), model.UpdateUserDomainModel{
+		Name:     updateViewModel.Name,
+		Email:    updateViewModel.Email,
+		Password: updateViewModel.Password,
+	})
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	userLoaderFrom(ctx).Clear(ctx, domainModel.Id)
+
+	return &userResolver{domainModel}, nil
+}
+
+func (r *Resolver) DeleteUser(ctx context.Context, args userArgs) (bool, error) {
+	err := r.userService.DeleteById(ctx, string(args.Id))
+	if err != nil {
+		return false, toResolverError(err)
+	}
+
+	userLoaderFrom(ctx).Clear(ctx, string(args.Id))
+
+	return true, nil
+}
+
+type userResolver struct {
+	domainModel *model.UserDomainModel
+}
+
+func (r *userResolver) Id() graphql.ID {
+	return graphql.ID(r.domainModel.Id)
+}
+
+func (r *userResolver) Name() string {
+	return r.domainModel.Name
+}
+
+func (r *userResolver) Email() string {
+	return r.domainModel.Email
+}
+
+type userConnectionResolver struct {
+	domainModels []*model.UserDomainModel
+	hasNextPage  bool
+}
+
+func (r *userConnectionResolver) Edges() []*userEdgeResolver {
+	edges := make([]*userEdgeResolver, len(r.domainModels))
+	for i, domainModel := range r.domainModels {
+		edges[i] = &userEdgeResolver{domainModel}
+	}
+
+	return edges
+}
+
+func (r *userConnectionResolver) PageInfo() *pageInfoResolver {
+	pageInfo := &pageInfoResolver{hasNextPage: r.hasNextPage}
+	if len(r.domainModels) > 0 {
+		pageInfo.endCursor = &r.domainModels[len(r.domainModels)-1].Id
+	}
+
+	return pageInfo
+}
+
+// userEdgeResolver uses the user id as cursor, which is what the service pages by.
+type userEdgeResolver struct {
+	domainModel *model.UserDomainModel
+}
+
+func (r *userEdgeResolver) Cursor() string {
+	return r.domainModel.Id
+}
+
+func (r *userEdgeResolver) Node() *userResolver {
+	return &userResolver{r.domainModel}
+}
+
+type pageInfoResolver struct {
+	hasNextPage bool
+	endCursor   *string
+}
+
+func (r *pageInfoResolver) HasNextPage() bool {
+	return r.hasNextPage
+}
+
+func (r *pageInfoResolver) EndCursor() *string {
+	return r.endCursor
+}
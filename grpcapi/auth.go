@@ -0,0 +1,86 @@
+package grpcapi
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"slices"
+	"strings"
+	errs "user-service/error"
+	"user-service/model"
+	"user-service/proto/userpb"
+	"user-service/service"
+)
+
+// authorizationMetadataKey carries the API key of an RPC the way the Authorization header does for the REST API,
+// as in authorization: ApiKey usk_....
+const authorizationMetadataKey = "authorization"
+
+const apiKeyScheme = "ApiKey"
+
+// methodScopes is the scope every RPC requires, matching that of the REST route doing the same.
+var methodScopes = map[string]string{
+	userpb.UserService_CreateUser_FullMethodName: model.ScopeUsersWrite,
+	userpb.UserService_GetUser_FullMethodName:    model.ScopeUsersRead,
+	userpb.UserService_ListUsers_FullMethodName:  model.ScopeUsersRead,
+	userpb.UserService_UpdateUser_FullMethodName: model.ScopeUsersWrite,
+	userpb.UserService_DeleteUser_FullMethodName: model.ScopeUsersWrite,
+}
+
+// AuthInterceptor identifies every RPC by the key in its authorization metadata and lets it through if the key
+// has the scope of the method. RPCs without a key are let through if anonymousScopes grant that scope. It has to
+// run before TenantInterceptor, which confines keys bound to a tenant to it.
+func AuthInterceptor(apiKeyService service.ApiKeyServiceInterface, anonymousScopes []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, apiKeyService, anonymousScopes, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, request)
+	}
+}
+
+// AuthStreamInterceptor is the AuthInterceptor of streaming RPCs.
+func AuthStreamInterceptor(apiKeyService service.ApiKeyServiceInterface, anonymousScopes []string) grpc.StreamServerInterceptor {
+	return func(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), apiKeyService, anonymousScopes, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(server, &contextServerStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, apiKeyService service.ApiKeyServiceInterface, anonymousScopes []string, fullMethod string) (context.Context, error) {
+	// Methods without a scope are ones this package does not know of, which nobody is let through to.
+	scope, ok := methodScopes[fullMethod]
+	if !ok {
+		return nil, toStatusError(errs.ForbiddenError)
+	}
+
+	values := metadata.ValueFromIncomingContext(ctx, authorizationMetadataKey)
+	if len(values) == 0 || values[0] == "" {
+		if !slices.Contains(anonymousScopes, scope) {
+			return nil, toStatusError(errs.UnauthorizedError)
+		}
+		return ctx, nil
+	}
+
+	scheme, key, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, apiKeyScheme) {
+		return nil, toStatusError(errs.UnauthorizedError)
+	}
+
+	principal, err := apiKeyService.Authenticate(ctx, strings.TrimSpace(key))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	if !slices.Contains(principal.Scopes, scope) {
+		return nil, toStatusError(errs.ForbiddenError)
+	}
+
+	return service.WithPrincipal(ctx, principal), nil
+}
@@ -0,0 +1,98 @@
+package grpcapi
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+	errs "user-service/error"
+	"user-service/model"
+	"user-service/proto/userpb"
+	"user-service/repository"
+	serviceMock "user-service/service/mock"
+)
+
+func newAuthTestClient(t *testing.T, userServiceMock *serviceMock.UserServiceInterface, apiKeyServiceMock *serviceMock.ApiKeyServiceInterface, anonymousScopes []string) userpb.UserServiceClient {
+	tenantServiceMock := new(serviceMock.TenantServiceInterface)
+	tenantServiceMock.On("Resolve", mock.Anything, mock.Anything).Return(nil)
+
+	return newTestClient(t, userServiceMock,
+		grpc.ChainUnaryInterceptor(AuthInterceptor(apiKeyServiceMock, anonymousScopes), TenantInterceptor(tenantServiceMock, "default")),
+		grpc.ChainStreamInterceptor(AuthStreamInterceptor(apiKeyServiceMock, anonymousScopes), TenantStreamInterceptor(tenantServiceMock, "default")),
+	)
+}
+
+func withApiKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationMetadataKey, "ApiKey "+key)
+}
+
+func Test_Auth_Should_Return_Unauthenticated_When_Anonymous_Requests_Are_Not_Allowed(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	client := newAuthTestClient(t, userServiceMock, new(serviceMock.ApiKeyServiceInterface), nil)
+
+	_, err := client.GetUser(context.Background(), &userpb.GetUserRequest{Id: "1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	stream, err := client.ListUsers(context.Background(), &userpb.ListUsersRequest{})
+	assert.Nil(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Auth_Should_Let_Anonymous_Requests_Through_With_The_Anonymous_Scopes(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetById", mock.Anything, "1").Return(&model.UserDomainModel{Id: "1"}, nil)
+	client := newAuthTestClient(t, userServiceMock, new(serviceMock.ApiKeyServiceInterface), []string{model.ScopeUsersRead})
+
+	_, err := client.GetUser(context.Background(), &userpb.GetUserRequest{Id: "1"})
+	assert.Nil(t, err)
+
+	_, err = client.DeleteUser(context.Background(), &userpb.DeleteUserRequest{Id: "1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func Test_Auth_Should_Return_Unauthenticated_When_Key_Is_Invalid(t *testing.T) {
+	apiKeyServiceMock := new(serviceMock.ApiKeyServiceInterface)
+	apiKeyServiceMock.On("Authenticate", mock.Anything, "usk_invalid").Return(nil, errs.UnauthorizedError)
+	client := newAuthTestClient(t, new(serviceMock.UserServiceInterface), apiKeyServiceMock, []string{model.ScopeUsersRead})
+
+	_, err := client.GetUser(withApiKey("usk_invalid"), &userpb.GetUserRequest{Id: "1"})
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func Test_Auth_Should_Return_PermissionDenied_When_Key_Lacks_The_Scope(t *testing.T) {
+	apiKeyServiceMock := new(serviceMock.ApiKeyServiceInterface)
+	apiKeyServiceMock.On("Authenticate", mock.Anything, "usk_reader").Return(&model.Principal{ApiKeyId: "key", Scopes: []string{model.ScopeUsersRead}}, nil)
+	client := newAuthTestClient(t, new(serviceMock.UserServiceInterface), apiKeyServiceMock, nil)
+
+	_, err := client.DeleteUser(withApiKey("usk_reader"), &userpb.DeleteUserRequest{Id: "1"})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func Test_Auth_Should_Confine_Keys_Bound_To_A_Tenant_To_It(t *testing.T) {
+	apiKeyServiceMock := new(serviceMock.ApiKeyServiceInterface)
+	apiKeyServiceMock.On("Authenticate", mock.Anything, "usk_acme").Return(&model.Principal{ApiKeyId: "key", TenantId: "acme", Scopes: []string{model.ScopeUsersRead}}, nil)
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetById", mock.MatchedBy(func(ctx context.Context) bool {
+		return repository.TenantFrom(ctx) == "acme"
+	}), "1").Return(&model.UserDomainModel{Id: "1"}, nil).Once()
+	client := newAuthTestClient(t, userServiceMock, apiKeyServiceMock, nil)
+
+	_, err := client.GetUser(withApiKey("usk_acme"), &userpb.GetUserRequest{Id: "1"})
+	assert.Nil(t, err)
+
+	ctx := metadata.AppendToOutgoingContext(withApiKey("usk_acme"), tenantMetadataKey, "globex")
+	_, err = client.GetUser(ctx, &userpb.GetUserRequest{Id: "1"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	userServiceMock.AssertExpectations(t)
+}
@@ -0,0 +1,76 @@
+package grpcapi
+
+import (
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	errs "user-service/error"
+)
+
+type statusDefinition struct {
+	err  error
+	code codes.Code
+}
+
+// statusDefinitions maps every error package sentinel to the gRPC status code it is reported as.
+var statusDefinitions = []statusDefinition{
+	{errs.BadRequestError, codes.InvalidArgument},
+	{errs.ValidationError, codes.InvalidArgument},
+	{errs.NotFoundError, codes.NotFound},
+	{errs.EmailAlreadyInUseError, codes.AlreadyExists},
+	{errs.PreconditionFailedError, codes.FailedPrecondition},
+	{errs.RevisionNotFoundError, codes.NotFound},
+	{errs.ImportJobNotFoundError, codes.NotFound},
+	{errs.PayloadTooLargeError, codes.ResourceExhausted},
+	{errs.ProfileSchemaNotFoundError, codes.NotFound},
+	{errs.InvalidProfileSchemaError, codes.InvalidArgument},
+	{errs.AvatarNotFoundError, codes.NotFound},
+	{errs.UnsupportedMediaTypeError, codes.InvalidArgument},
+	{errs.InvalidAvatarError, codes.InvalidArgument},
+	{errs.UnauthorizedError, codes.Unauthenticated},
+	{errs.ForbiddenError, codes.PermissionDenied},
+	{errs.ApiKeyNotFoundError, codes.NotFound},
+	{errs.TenantRequiredError, codes.InvalidArgument},
+	{errs.TenantNotFoundError, codes.NotFound},
+	{errs.TenantAlreadyExistsError, codes.AlreadyExists},
+	{errs.GroupNotFoundError, codes.NotFound},
+	{errs.GroupCycleError, codes.FailedPrecondition},
+	{errs.TooManyRequestsError, codes.ResourceExhausted},
+	{errs.ServerError, codes.Internal},
+}
+
+func toStatusError(err error) error {
+	for _, definition := range statusDefinitions {
+		if errors.Is(err, definition.err) {
+			return status.Error(definition.code, definition.err.Error())
+		}
+	}
+
+	// Anything that is not a known sentinel is reported as a server error without leaking its details.
+	return status.Error(codes.Internal, errs.ServerError.Error())
+}
+
+// toValidationStatusError reports validator errors as InvalidArgument with a BadRequest detail listing the
+// offending fields.
+func toValidationStatusError(err error) error {
+	badRequest := &errdetails.BadRequest{}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for _, fieldError := range validationErrors {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       fieldError.Field(),
+				Description: fieldError.Tag(),
+			})
+		}
+	}
+
+	st, detailErr := status.New(codes.InvalidArgument, errs.ValidationError.Error()).WithDetails(badRequest)
+	if detailErr != nil {
+		return status.Error(codes.InvalidArgument, errs.ValidationError.Error())
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,82 @@
+package grpcapi
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	errs "user-service/error"
+	"user-service/service"
+)
+
+// tenantMetadataKey is the metadata counterpart of the X-Tenant-ID header of the REST API.
+const tenantMetadataKey = "x-tenant-id"
+
+// TenantInterceptor confines every RPC to its tenant like the Resolve middleware of the REST API. A key bound to a
+// tenant always acts for that tenant, and naming another one is rejected. Other RPCs name their tenant with the
+// x-tenant-id metadata, falling back to defaultTenant, which anonymous RPCs cannot leave. RPCs naming no tenant
+// when defaultTenant is empty, or an unknown or disabled one, fail before reaching the server. It has to run after
+// AuthInterceptor.
+func TenantInterceptor(tenantService service.TenantServiceInterface, defaultTenant string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := resolveTenant(ctx, tenantService, defaultTenant)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, request)
+	}
+}
+
+// TenantStreamInterceptor is the TenantInterceptor of streaming RPCs.
+func TenantStreamInterceptor(tenantService service.TenantServiceInterface, defaultTenant string) grpc.StreamServerInterceptor {
+	return func(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := resolveTenant(stream.Context(), tenantService, defaultTenant)
+		if err != nil {
+			return err
+		}
+
+		return handler(server, &contextServerStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func resolveTenant(ctx context.Context, tenantService service.TenantServiceInterface, defaultTenant string) (context.Context, error) {
+	var requested string
+	if values := metadata.ValueFromIncomingContext(ctx, tenantMetadataKey); len(values) > 0 {
+		requested = values[0]
+	}
+
+	tenantId := requested
+	principal := service.PrincipalFrom(ctx)
+	if principal == nil && requested != "" && requested != defaultTenant {
+		return nil, toStatusError(errs.UnauthorizedError)
+	}
+	if principal != nil && principal.TenantId != "" {
+		if requested != "" && requested != principal.TenantId {
+			return nil, toStatusError(errs.ForbiddenError)
+		}
+		tenantId = principal.TenantId
+	}
+	if tenantId == "" {
+		tenantId = defaultTenant
+	}
+	if tenantId == "" {
+		return nil, toStatusError(errs.TenantRequiredError)
+	}
+
+	err := tenantService.Resolve(ctx, tenantId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return service.WithTenant(ctx, tenantId), nil
+}
+
+// contextServerStream hands the handler of a streaming RPC a context the interceptors added to.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,95 @@
+package grpcapi
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io"
+	"testing"
+	errs "user-service/error"
+	"user-service/model"
+	"user-service/proto/userpb"
+	"user-service/repository"
+	serviceMock "user-service/service/mock"
+)
+
+func newTenantTestClient(t *testing.T, userServiceMock *serviceMock.UserServiceInterface, tenantServiceMock *serviceMock.TenantServiceInterface) userpb.UserServiceClient {
+	return newTestClient(t, userServiceMock,
+		grpc.UnaryInterceptor(TenantInterceptor(tenantServiceMock, "default")),
+		grpc.StreamInterceptor(TenantStreamInterceptor(tenantServiceMock, "default")),
+	)
+}
+
+func Test_ListUsers_Should_Stream_The_Users_Of_The_Tenant_In_The_Metadata(t *testing.T) {
+	user := &model.UserDomainModel{Id: primitive.NewObjectID().Hex()}
+
+	apiKeyServiceMock := new(serviceMock.ApiKeyServiceInterface)
+	apiKeyServiceMock.On("Authenticate", mock.Anything, "usk_admin").Return(&model.Principal{ApiKeyId: "admin", Scopes: []string{model.ScopeUsersRead}}, nil)
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetAll", mock.MatchedBy(func(ctx context.Context) bool {
+		return repository.TenantFrom(ctx) == "acme"
+	}), mock.Anything).Return([]*model.UserDomainModel{user}, "", nil).Once()
+
+	ctx := metadata.AppendToOutgoingContext(withApiKey("usk_admin"), tenantMetadataKey, "acme")
+	stream, err := newAuthTestClient(t, userServiceMock, apiKeyServiceMock, nil).ListUsers(ctx, &userpb.ListUsersRequest{})
+	assert.Nil(t, err)
+
+	var ids []string
+	for {
+		message, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		ids = append(ids, message.Id)
+	}
+
+	assert.Equal(t, []string{user.Id}, ids)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_ListUsers_Should_Return_NotFound_When_Tenant_Is_Unknown(t *testing.T) {
+	tenantServiceMock := new(serviceMock.TenantServiceInterface)
+	tenantServiceMock.On("Resolve", mock.Anything, "default").Return(errs.TenantNotFoundError)
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+
+	stream, err := newTenantTestClient(t, userServiceMock, tenantServiceMock).ListUsers(context.Background(), &userpb.ListUsersRequest{})
+	assert.Nil(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	userServiceMock.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything)
+}
+
+func Test_GetUser_Should_Run_In_The_Default_Tenant_When_Metadata_Names_None(t *testing.T) {
+	tenantServiceMock := new(serviceMock.TenantServiceInterface)
+	tenantServiceMock.On("Resolve", mock.Anything, "default").Return(nil)
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetById", mock.MatchedBy(func(ctx context.Context) bool {
+		return repository.TenantFrom(ctx) == "default"
+	}), "1").Return(&model.UserDomainModel{Id: "1"}, nil)
+
+	user, err := newTenantTestClient(t, userServiceMock, tenantServiceMock).GetUser(context.Background(), &userpb.GetUserRequest{Id: "1"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1", user.Id)
+}
+
+func Test_GetUser_Should_Return_Unauthenticated_When_Anonymous_Request_Names_Another_Tenant_Than_The_Default(t *testing.T) {
+	tenantServiceMock := new(serviceMock.TenantServiceInterface)
+	userServiceMock := new(serviceMock.UserServiceInterface)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), tenantMetadataKey, "acme")
+	_, err := newTenantTestClient(t, userServiceMock, tenantServiceMock).GetUser(ctx, &userpb.GetUserRequest{Id: "1"})
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	tenantServiceMock.AssertNotCalled(t, "Resolve", mock.Anything, mock.Anything)
+}
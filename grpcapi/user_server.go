@@ -0,0 +1,170 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"user-service/controller"
+	errs "user-service/error"
+	"user-service/model"
+	"user-service/proto/userpb"
+	"user-service/service"
+)
+
+const defaultListPageSize = 100
+
+// UserServer serves userpb.UserService on top of the same UserServiceInterface the REST controllers use.
+type UserServer struct {
+	userpb.UnimplementedUserServiceServer
+	userService service.UserServiceInterface
+	validator   *validator.Validate
+}
+
+func NewUserServer(userService service.UserServiceInterface, validator *validator.Validate) *UserServer {
+	return &UserServer{
+		userService: userService,
+		validator:   validator,
+	}
+}
+
+func (s *UserServer) CreateUser(ctx context.Context, request *userpb.CreateUserRequest) (*userpb.User, error) {
+	createViewModel := model.CreateUserViewModel{
+		Name:     request.GetName(),
+		Email:    request.GetEmail(),
+		Password: request.GetPassword(),
+	}
+
+	err := s.validator.Struct(createViewModel)
+	if err != nil {
+		return nil, toValidationStatusError(err)
+	}
+
+	domainModel, err := s.userService.Create(ctx, model.CreateUserDomainModel{
+		Name:     createViewModel.Name,
+		Email:    createViewModel.Email,
+		Password: createViewModel.Password,
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return copyDomainModelToMessage(domainModel), nil
+}
+
+func (s *UserServer) GetUser(ctx context.Context, request *userpb.GetUserRequest) (*userpb.User, error) {
+	domainModel, err := s.userService.GetById(ctx, request.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return copyDomainModelToMessage(domainModel), nil
+}
+
+func (s *UserServer) ListUsers(request *userpb.ListUsersRequest, stream userpb.UserService_ListUsersServer) error {
+	page := model.PageDomainModel{Limit: int(request.GetPageSize())}
+	if page.Limit <= 0 {
+		page.Limit = defaultListPageSize
+	} else if page.Limit > controller.MaxPageSize {
+		return status.Errorf(codes.InvalidArgument, "page_size must not exceed %d", controller.MaxPageSize)
+	}
+
+	for {
+		domainModels, next, err := s.userService.GetAll(stream.Context(), page)
+		if errors.Is(err, errs.NotFoundError) {
+			return nil
+		} else if err != nil {
+			return toStatusError(err)
+		}
+
+		for _, domainModel := range domainModels {
+			err = stream.Send(copyDomainModelToMessage(domainModel))
+			if err != nil {
+				return err
+			}
+		}
+
+		if next == "" {
+			return nil
+		}
+
+		page.After = next
+	}
+}
+
+func (s *UserServer) UpdateUser(ctx context.Context, request *userpb.UpdateUserRequest) (*userpb.User, error) {
+	updateViewModel, err := copyUpdateMessageToUpdateViewModel(request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.validator.Struct(updateViewModel)
+	if err != nil {
+		return nil, toValidationStatusError(err)
+	}
+
+	domainModel, err := s.userService.UpdateById(ctx, request.GetId(), model.UpdateUserDomainModel{
+		Name:     updateViewModel.Name,
+		Email:    updateViewModel.Email,
+		Password: updateViewModel.Password,
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return copyDomainModelToMessage(domainModel), nil
+}
+
+func (s *UserServer) DeleteUser(ctx context.Context, request *userpb.DeleteUserRequest) (*emptypb.Empty, error) {
+	err := s.userService.DeleteById(ctx, request.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// copyUpdateMessageToUpdateViewModel picks the fields named in the update mask. Without a mask every non-empty
+// field is updated, as proto3 cannot tell an empty string from an absent one.
+func copyUpdateMessageToUpdateViewModel(request *userpb.UpdateUserRequest) (model.UpdateUserViewModel, error) {
+	var viewModel model.UpdateUserViewModel
+	user := request.GetUser()
+
+	paths := request.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		for path, value := range map[string]string{"name": user.GetName(), "email": user.GetEmail(), "password": user.GetPassword()} {
+			if value != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	for _, path := range paths {
+		switch path {
+		case "name":
+			name := user.GetName()
+			viewModel.Name = &name
+		case "email":
+			email := user.GetEmail()
+			viewModel.Email = &email
+		case "password":
+			password := user.GetPassword()
+			viewModel.Password = &password
+		default:
+			return viewModel, status.Errorf(codes.InvalidArgument, "unknown update mask path %q", path)
+		}
+	}
+
+	return viewModel, nil
+}
+
+func copyDomainModelToMessage(domainModel *model.UserDomainModel) *userpb.User {
+	return &userpb.User{
+		Id:      domainModel.Id,
+		Name:    domainModel.Name,
+		Email:   domainModel.Email,
+		Version: domainModel.Version,
+	}
+}
@@ -0,0 +1,198 @@
+package grpcapi
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"io"
+	"net"
+	"testing"
+	errs "user-service/error"
+	"user-service/model"
+	"user-service/proto/userpb"
+	serviceMock "user-service/service/mock"
+)
+
+func newTestClient(t *testing.T, userServiceMock *serviceMock.UserServiceInterface, options ...grpc.ServerOption) userpb.UserServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(options...)
+	userpb.RegisterUserServiceServer(server, NewUserServer(userServiceMock, validator.New()))
+	go server.Serve(listener)
+
+	connection, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, err)
+
+	t.Cleanup(func() {
+		connection.Close()
+		server.Stop()
+	})
+
+	return userpb.NewUserServiceClient(connection)
+}
+
+func Test_CreateUser_Should_Return_User_When_Nothing_Fails(t *testing.T) {
+	var request = &userpb.CreateUserRequest{Name: "Batuhan", Email: "batuhan@site.com", Password: "123456"}
+	var domainModel = model.UserDomainModel{Id: primitive.NewObjectID().Hex(), Name: request.Name, Email: request.Email, Version: 1}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Create", mock.Anything, model.CreateUserDomainModel{
+		Name:     request.Name,
+		Email:    request.Email,
+		Password: request.Password,
+	}).Return(&domainModel, nil).Once()
+
+	user, err := newTestClient(t, userServiceMock).CreateUser(context.Background(), request)
+
+	assert.Nil(t, err)
+	assert.Equal(t, domainModel.Id, user.Id)
+	assert.Equal(t, int64(1), user.Version)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_CreateUser_Should_Return_InvalidArgument_With_Field_Violations_When_Email_Is_Invalid(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+
+	user, err := newTestClient(t, userServiceMock).CreateUser(context.Background(), &userpb.CreateUserRequest{
+		Name:     "Batuhan",
+		Email:    "not an email",
+		Password: "123456",
+	})
+
+	st := status.Convert(err)
+	assert.Nil(t, user)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "Email", st.Details()[0].(*errdetails.BadRequest).FieldViolations[0].Field)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_CreateUser_Should_Return_AlreadyExists_When_Email_Is_In_Use(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Create", mock.Anything, mock.Anything).Return(nil, errs.EmailAlreadyInUseError).Once()
+
+	_, err := newTestClient(t, userServiceMock).CreateUser(context.Background(), &userpb.CreateUserRequest{
+		Name:     "Batuhan",
+		Email:    "taken@site.com",
+		Password: "123456",
+	})
+
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_GetUser_Should_Map_Service_Errors_To_Status_Codes(t *testing.T) {
+	var cases = map[error]codes.Code{
+		errs.BadRequestError:         codes.InvalidArgument,
+		errs.NotFoundError:           codes.NotFound,
+		errs.PreconditionFailedError: codes.FailedPrecondition,
+		errs.RevisionNotFoundError:   codes.NotFound,
+		errs.PayloadTooLargeError:    codes.ResourceExhausted,
+		errs.GroupCycleError:         codes.FailedPrecondition,
+		errs.ServerError:             codes.Internal,
+		io.ErrUnexpectedEOF:          codes.Internal,
+	}
+
+	for serviceErr, code := range cases {
+		userServiceMock := new(serviceMock.UserServiceInterface)
+		userServiceMock.On("GetById", mock.Anything, "id").Return(nil, serviceErr).Once()
+
+		_, err := newTestClient(t, userServiceMock).GetUser(context.Background(), &userpb.GetUserRequest{Id: "id"})
+
+		assert.Equal(t, code, status.Code(err), serviceErr.Error())
+		userServiceMock.AssertExpectations(t)
+	}
+}
+
+func Test_ListUsers_Should_Stream_Every_Page(t *testing.T) {
+	var users = []*model.UserDomainModel{
+		{Id: primitive.NewObjectID().Hex()},
+		{Id: primitive.NewObjectID().Hex()},
+		{Id: primitive.NewObjectID().Hex()},
+	}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetAll", mock.Anything, model.PageDomainModel{Limit: 2}).Return(users[:2], users[1].Id, nil).Once()
+	userServiceMock.On("GetAll", mock.Anything, model.PageDomainModel{After: users[1].Id, Limit: 2}).Return(users[2:], "", nil).Once()
+
+	stream, err := newTestClient(t, userServiceMock).ListUsers(context.Background(), &userpb.ListUsersRequest{PageSize: 2})
+	assert.Nil(t, err)
+
+	var ids []string
+	for {
+		user, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		ids = append(ids, user.Id)
+	}
+
+	assert.Equal(t, []string{users[0].Id, users[1].Id, users[2].Id}, ids)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_ListUsers_Should_Return_InvalidArgument_When_The_Page_Size_Exceeds_The_Maximum(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+
+	stream, err := newTestClient(t, userServiceMock).ListUsers(context.Background(), &userpb.ListUsersRequest{PageSize: 101})
+	assert.Nil(t, err)
+
+	_, err = stream.Recv()
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	userServiceMock.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything)
+}
+
+func Test_UpdateUser_Should_Only_Update_Fields_In_Mask(t *testing.T) {
+	var id = primitive.NewObjectID().Hex()
+	var name = "New Name"
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("UpdateById", mock.Anything, id, model.UpdateUserDomainModel{Name: &name}).Return(&model.UserDomainModel{Id: id, Name: name}, nil).Once()
+
+	user, err := newTestClient(t, userServiceMock).UpdateUser(context.Background(), &userpb.UpdateUserRequest{
+		Id:         id,
+		User:       &userpb.UserUpdate{Name: name, Email: "ignored@site.com"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, name, user.Name)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_UpdateUser_Should_Return_InvalidArgument_When_Mask_Has_Unknown_Path(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+
+	_, err := newTestClient(t, userServiceMock).UpdateUser(context.Background(), &userpb.UpdateUserRequest{
+		Id:         primitive.NewObjectID().Hex(),
+		User:       &userpb.UserUpdate{},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"id"}},
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_DeleteUser_Should_Return_NotFound_When_User_Does_Not_Exist(t *testing.T) {
+	var id = primitive.NewObjectID().Hex()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("DeleteById", mock.Anything, id).Return(errs.NotFoundError).Once()
+
+	_, err := newTestClient(t, userServiceMock).DeleteUser(context.Background(), &userpb.DeleteUserRequest{Id: id})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	userServiceMock.AssertExpectations(t)
+}
@@ -0,0 +1,152 @@
+package i18n
+
+import (
+	"fmt"
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/tr"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/language"
+	"reflect"
+	"strings"
+	"unicode"
+	errs "user-service/error"
+)
+
+const DefaultLanguage = "en"
+
+// supportedLanguages lists the languages with a message catalog. The first one is the fallback.
+var supportedLanguages = []language.Tag{language.English, language.German, language.Turkish}
+
+var matcher = language.NewMatcher(supportedLanguages)
+
+type Catalog struct {
+	universalTranslator *ut.UniversalTranslator
+}
+
+// NewCatalog registers the validation rule translations of every supported language on the given validator.
+func NewCatalog(validate *validator.Validate) (*Catalog, error) {
+	fallback := en.New()
+	universalTranslator := ut.New(fallback, fallback, de.New(), tr.New())
+
+	for _, translator := range []locales.Translator{fallback, de.New(), tr.New()} {
+		trans, _ := universalTranslator.GetTranslator(translator.Locale())
+
+		for key, message := range validationMessages[translator.Locale()] {
+			rule, _, _ := strings.Cut(key, ".")
+			err := validate.RegisterTranslation(rule, trans, registerMessage(key, message), translateFieldError)
+			if err != nil {
+				return nil, fmt.Errorf("registering %s translation for %q: %w", translator.Locale(), rule, err)
+			}
+		}
+	}
+
+	return &Catalog{universalTranslator: universalTranslator}, nil
+}
+
+// Language picks the best supported language for an Accept-Language header, falling back to DefaultLanguage.
+func (c *Catalog) Language(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLanguage
+	}
+
+	_, index, _ := matcher.Match(tags...)
+	base, _ := supportedLanguages[index].Base()
+
+	return base.String()
+}
+
+// ErrorMessage translates an error package sentinel, falling back to English and then to the error itself.
+func (c *Catalog) ErrorMessage(lang string, err error) string {
+	if message, ok := errorMessages[lang][err]; ok {
+		return message
+	}
+	if message, ok := errorMessages[DefaultLanguage][err]; ok {
+		return message
+	}
+
+	return err.Error()
+}
+
+func (c *Catalog) ValidationMessage(lang string, fieldError validator.FieldError) string {
+	trans, _ := c.universalTranslator.GetTranslator(lang)
+	if _, ok := validationMessages[lang][fieldError.Tag()]; !ok {
+		trans, _ = c.universalTranslator.GetTranslator(DefaultLanguage)
+	}
+	if _, ok := validationMessages[DefaultLanguage][fieldError.Tag()]; !ok {
+		return fmt.Sprintf("%s failed on the '%s' rule", fieldError.Field(), fieldError.Tag())
+	}
+
+	return fieldError.Translate(trans)
+}
+
+// FieldMessage translates an errs.FieldError, falling back to English and then to a description of the rule.
+func (c *Catalog) FieldMessage(lang string, fieldError errs.FieldError) string {
+	key := fieldError.Key
+	if key == "" {
+		key = fieldError.Rule
+	}
+
+	message, ok := fieldMessages[lang][key]
+	if !ok {
+		message, ok = fieldMessages[DefaultLanguage][key]
+	}
+	if !ok {
+		return fmt.Sprintf("%s failed on the '%s' rule", fieldError.Field, fieldError.Rule)
+	}
+
+	return strings.NewReplacer("{0}", fieldError.Field, "{1}", fieldError.Param).Replace(message)
+}
+
+func registerMessage(key string, message string) validator.RegisterTranslationsFunc {
+	return func(trans ut.Translator) error {
+		return trans.Add(key, message, true)
+	}
+}
+
+func translateFieldError(trans ut.Translator, fieldError validator.FieldError) string {
+	message, err := trans.T(messageKey(fieldError), fieldError.Field(), messageParam(fieldError))
+	if err != nil {
+		return fieldError.Error()
+	}
+
+	return message
+}
+
+// messageKey picks the message of the rule for the kind of the field, such as characters for strings and items
+// for slices, falling back to that of the rule.
+func messageKey(fieldError validator.FieldError) string {
+	var key string
+	switch fieldError.Kind() {
+	case reflect.String:
+		key = fieldError.Tag() + ".string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		key = fieldError.Tag() + ".items"
+	}
+
+	if _, ok := validationMessages[DefaultLanguage][key]; ok {
+		return key
+	}
+
+	return fieldError.Tag()
+}
+
+// messageParam makes the parameter of the rule readable. The fields named by required_without are the Go
+// names of fields, whose JSON names are the same in camel case.
+func messageParam(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "oneof":
+		return strings.Join(strings.Fields(fieldError.Param()), ", ")
+	case "required_without":
+		param := []rune(fieldError.Param())
+		if len(param) > 0 {
+			param[0] = unicode.ToLower(param[0])
+		}
+		return string(param)
+	default:
+		return fieldError.Param()
+	}
+}
@@ -0,0 +1,159 @@
+package i18n
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	errs "user-service/error"
+)
+
+func Test_Language_Should_Return_Best_Supported_Language_When_Header_Lists_Several(t *testing.T) {
+	classUnderTest, _ := NewCatalog(validator.New())
+
+	assert.Equal(t, "de", classUnderTest.Language("fr-CH, fr;q=0.9, de;q=0.8, en;q=0.7"))
+	assert.Equal(t, "tr", classUnderTest.Language("tr-TR"))
+}
+
+func Test_Language_Should_Fall_Back_To_English_When_Header_Is_Missing_Or_Unsupported(t *testing.T) {
+	classUnderTest, _ := NewCatalog(validator.New())
+
+	assert.Equal(t, DefaultLanguage, classUnderTest.Language(""))
+	assert.Equal(t, DefaultLanguage, classUnderTest.Language("ja"))
+	assert.Equal(t, DefaultLanguage, classUnderTest.Language("not a header;;"))
+}
+
+func Test_Catalogs_Should_Translate_Every_Error_And_Validation_Rule_In_Every_Language(t *testing.T) {
+	var sentinels = []error{
+		errs.EmailAlreadyInUseError,
+		errs.BadRequestError,
+		errs.NotFoundError,
+		errs.ServerError,
+		errs.PreconditionFailedError,
+		errs.ValidationError,
+		errs.ImportJobNotFoundError,
+		errs.PayloadTooLargeError,
+		errs.RevisionNotFoundError,
+		errs.ProfileSchemaNotFoundError,
+		errs.InvalidProfileSchemaError,
+		errs.AvatarNotFoundError,
+		errs.UnsupportedMediaTypeError,
+		errs.InvalidAvatarError,
+		errs.TooManyRequestsError,
+		errs.UnauthorizedError,
+		errs.ForbiddenError,
+		errs.ApiKeyNotFoundError,
+		errs.TenantRequiredError,
+		errs.TenantNotFoundError,
+		errs.TenantAlreadyExistsError,
+		errs.GroupNotFoundError,
+		errs.GroupCycleError,
+	}
+
+	for _, tag := range supportedLanguages {
+		lang := tag.String()
+
+		for _, sentinel := range sentinels {
+			_, ok := errorMessages[lang][sentinel]
+			assert.True(t, ok, "%s has no %s translation", sentinel, lang)
+		}
+
+		for rule := range validationMessages[DefaultLanguage] {
+			_, ok := validationMessages[lang][rule]
+			assert.True(t, ok, "rule %s has no %s translation", rule, lang)
+		}
+
+		for key := range fieldMessages[DefaultLanguage] {
+			_, ok := fieldMessages[lang][key]
+			assert.True(t, ok, "field error %s has no %s translation", key, lang)
+		}
+	}
+}
+
+func Test_ValidationMessage_Should_Translate_Field_Error_When_Language_Is_Supported(t *testing.T) {
+	type request struct {
+		Email string `validate:"required"`
+	}
+
+	validate := validator.New()
+	classUnderTest, _ := NewCatalog(validate)
+
+	err := validate.Struct(request{})
+	fieldError := err.(validator.ValidationErrors)[0]
+
+	assert.Equal(t, "Email ist ein Pflichtfeld", classUnderTest.ValidationMessage("de", fieldError))
+	assert.Equal(t, "Email is required", classUnderTest.ValidationMessage("en", fieldError))
+}
+
+func Test_Catalogs_Should_Translate_Every_Rule_Of_The_View_Models(t *testing.T) {
+	// omitempty and dive only steer the validation of the other rules and never fail a field themselves.
+	var steering = map[string]bool{"omitempty": true, "dive": true}
+
+	packages, err := parser.ParseDir(token.NewFileSet(), "../model", nil, 0)
+	assert.Nil(t, err)
+
+	var rules []string
+	for _, modelPackage := range packages {
+		ast.Inspect(modelPackage, func(node ast.Node) bool {
+			field, ok := node.(*ast.Field)
+			if !ok || field.Tag == nil {
+				return true
+			}
+
+			tag, _ := strconv.Unquote(field.Tag.Value)
+			for _, rule := range strings.Split(reflect.StructTag(tag).Get("validate"), ",") {
+				rule, _, _ = strings.Cut(rule, "=")
+				if rule != "" && !steering[rule] {
+					rules = append(rules, rule)
+				}
+			}
+			return true
+		})
+	}
+
+	assert.NotEmpty(t, rules)
+	for _, rule := range rules {
+		_, ok := validationMessages[DefaultLanguage][rule]
+		assert.True(t, ok, "rule %s of a view model has no message", rule)
+	}
+}
+
+func Test_ValidationMessage_Should_Fill_In_The_Parameter_For_The_Kind_Of_The_Field(t *testing.T) {
+	type request struct {
+		Name   string   `validate:"max=3"`
+		Users  []string `validate:"required_without=Groups,max=1"`
+		Groups []string
+		Status string `validate:"oneof=pass fail"`
+	}
+
+	validate := validator.New()
+	classUnderTest, _ := NewCatalog(validate)
+
+	err := validate.Struct(request{Name: "Batuhan", Users: []string{"1", "2"}, Status: "warn"})
+	fieldErrors := err.(validator.ValidationErrors)
+
+	assert.Equal(t, "Name must be at most 3 characters long", classUnderTest.ValidationMessage("en", fieldErrors[0]))
+	assert.Equal(t, "Users darf höchstens 1 Elemente enthalten", classUnderTest.ValidationMessage("de", fieldErrors[1]))
+	assert.Equal(t, "Status şu değerlerden biri olmalıdır: pass, fail", classUnderTest.ValidationMessage("tr", fieldErrors[2]))
+
+	err = validate.Struct(request{Status: "pass"})
+	fieldError := err.(validator.ValidationErrors)[0]
+
+	assert.Equal(t, "Users is required when groups is missing", classUnderTest.ValidationMessage("en", fieldError))
+}
+
+func Test_FieldMessage_Should_Translate_By_Key_Or_Rule_And_Fill_In_The_Parameter(t *testing.T) {
+	classUnderTest, _ := NewCatalog(validator.New())
+
+	assert.Equal(t, "tenants:manage kann nur Schlüsseln ohne Mandanten gewährt werden",
+		classUnderTest.FieldMessage("de", errs.FieldError{Field: "scopes", Rule: "platform", Param: "tenants:manage"}))
+	assert.Equal(t, "profile.age profil şemasına uymuyor: must be >= 0 but found -1",
+		classUnderTest.FieldMessage("tr", errs.FieldError{Field: "profile.age", Rule: "minimum", Key: "profileSchema", Param: "must be >= 0 but found -1"}))
+	assert.Equal(t, "profile.age failed on the 'unknown' rule",
+		classUnderTest.FieldMessage("de", errs.FieldError{Field: "profile.age", Rule: "unknown"}))
+}
@@ -0,0 +1,161 @@
+package i18n
+
+import errs "user-service/error"
+
+// errorMessages holds the translation of every error package sentinel, keyed by language.
+var errorMessages = map[string]map[error]string{
+	"en": {
+		errs.EmailAlreadyInUseError:     errs.EmailAlreadyInUseError.Error(),
+		errs.BadRequestError:            errs.BadRequestError.Error(),
+		errs.NotFoundError:              errs.NotFoundError.Error(),
+		errs.ServerError:                errs.ServerError.Error(),
+		errs.PreconditionFailedError:    errs.PreconditionFailedError.Error(),
+		errs.ValidationError:            errs.ValidationError.Error(),
+		errs.ImportJobNotFoundError:     errs.ImportJobNotFoundError.Error(),
+		errs.PayloadTooLargeError:       errs.PayloadTooLargeError.Error(),
+		errs.RevisionNotFoundError:      errs.RevisionNotFoundError.Error(),
+		errs.ProfileSchemaNotFoundError: errs.ProfileSchemaNotFoundError.Error(),
+		errs.InvalidProfileSchemaError:  errs.InvalidProfileSchemaError.Error(),
+		errs.AvatarNotFoundError:        errs.AvatarNotFoundError.Error(),
+		errs.UnsupportedMediaTypeError:  errs.UnsupportedMediaTypeError.Error(),
+		errs.InvalidAvatarError:         errs.InvalidAvatarError.Error(),
+		errs.TooManyRequestsError:       errs.TooManyRequestsError.Error(),
+		errs.UnauthorizedError:          errs.UnauthorizedError.Error(),
+		errs.ForbiddenError:             errs.ForbiddenError.Error(),
+		errs.ApiKeyNotFoundError:        errs.ApiKeyNotFoundError.Error(),
+		errs.TenantRequiredError:        errs.TenantRequiredError.Error(),
+		errs.TenantNotFoundError:        errs.TenantNotFoundError.Error(),
+		errs.TenantAlreadyExistsError:   errs.TenantAlreadyExistsError.Error(),
+		errs.GroupNotFoundError:         errs.GroupNotFoundError.Error(),
+		errs.GroupCycleError:            errs.GroupCycleError.Error(),
+	},
+	"de": {
+		errs.EmailAlreadyInUseError:     "ein Benutzer mit dieser E-Mail-Adresse existiert bereits",
+		errs.BadRequestError:            "ungültige Anfrage",
+		errs.NotFoundError:              "ein Benutzer mit dieser ID existiert nicht",
+		errs.ServerError:                "Serverfehler",
+		errs.PreconditionFailedError:    "der Benutzer erfüllt die angegebene Vorbedingung nicht",
+		errs.ValidationError:            "der Anfragetext ist ungültig",
+		errs.ImportJobNotFoundError:     "ein Importauftrag mit dieser ID existiert nicht",
+		errs.PayloadTooLargeError:       "der Anfragetext ist zu groß",
+		errs.RevisionNotFoundError:      "diese Revision des Benutzers existiert nicht",
+		errs.ProfileSchemaNotFoundError: "es wurde noch kein Profilschema veröffentlicht",
+		errs.InvalidProfileSchemaError:  "das Profilschema ist ungültig",
+		errs.AvatarNotFoundError:        "der Benutzer mit dieser ID hat keinen Avatar",
+		errs.UnsupportedMediaTypeError:  "Avatare müssen PNG- oder JPEG-Bilder sein",
+		errs.InvalidAvatarError:         "der Avatar ist kein gültiges Bild oder überschreitet die maximalen Abmessungen",
+		errs.TooManyRequestsError:       "zu viele Anfragen, bitte später erneut versuchen",
+		errs.UnauthorizedError:          "der Anfrage fehlt ein gültiger API-Schlüssel",
+		errs.ForbiddenError:             "der API-Schlüssel darf diese Anfrage nicht stellen",
+		errs.ApiKeyNotFoundError:        "ein API-Schlüssel mit dieser ID existiert nicht oder ist nicht mehr gültig",
+		errs.TenantRequiredError:        "die Anfrage nennt keinen Mandanten",
+		errs.TenantNotFoundError:        "ein Mandant mit dieser ID existiert nicht",
+		errs.TenantAlreadyExistsError:   "ein Mandant mit dieser ID existiert bereits",
+		errs.GroupNotFoundError:         "eine Gruppe mit dieser ID existiert nicht",
+		errs.GroupCycleError:            "die Gruppe würde sich selbst enthalten",
+	},
+	"tr": {
+		errs.EmailAlreadyInUseError:     "bu e-posta adresine sahip bir kullanıcı zaten mevcut",
+		errs.BadRequestError:            "geçersiz istek",
+		errs.NotFoundError:              "bu kimliğe sahip bir kullanıcı bulunamadı",
+		errs.ServerError:                "sunucu hatası",
+		errs.PreconditionFailedError:    "kullanıcı verilen ön koşulu karşılamıyor",
+		errs.ValidationError:            "istek gövdesi doğrulamadan geçemedi",
+		errs.ImportJobNotFoundError:     "bu kimliğe sahip bir içe aktarma işi bulunamadı",
+		errs.PayloadTooLargeError:       "istek gövdesi çok büyük",
+		errs.RevisionNotFoundError:      "kullanıcının bu revizyonu bulunamadı",
+		errs.ProfileSchemaNotFoundError: "henüz bir profil şeması yayımlanmadı",
+		errs.InvalidProfileSchemaError:  "profil şeması geçersiz",
+		errs.AvatarNotFoundError:        "bu kimliğe sahip kullanıcının avatarı yok",
+		errs.UnsupportedMediaTypeError:  "avatarlar PNG veya JPEG görüntüsü olmalıdır",
+		errs.InvalidAvatarError:         "avatar geçerli bir görüntü değil veya izin verilen boyutları aşıyor",
+		errs.TooManyRequestsError:       "çok fazla istek, lütfen daha sonra tekrar deneyin",
+		errs.UnauthorizedError:          "istekte geçerli bir API anahtarı yok",
+		errs.ForbiddenError:             "API anahtarının bu isteği yapma yetkisi yok",
+		errs.ApiKeyNotFoundError:        "bu kimliğe sahip bir API anahtarı mevcut değil veya artık geçerli değil",
+		errs.TenantRequiredError:        "istek bir kiracı belirtmiyor",
+		errs.TenantNotFoundError:        "bu kimliğe sahip bir kiracı mevcut değil",
+		errs.TenantAlreadyExistsError:   "bu kimliğe sahip bir kiracı zaten mevcut",
+		errs.GroupNotFoundError:         "bu kimliğe sahip bir grup mevcut değil",
+		errs.GroupCycleError:            "grup kendisini içermiş olurdu",
+	},
+}
+
+// validationMessages holds the translation of every validation rule used by the view models, keyed by
+// language and then by validator tag. Rules whose message depends on the kind of the field also have a
+// tag.string message for strings and a tag.items one for slices and maps. {0} is replaced with the JSON name of
+// the offending field and {1} with the parameter of the rule, which has to come after {0}.
+var validationMessages = map[string]map[string]string{
+	"en": {
+		"required":         "{0} is required",
+		"email":            "{0} must be a valid email address",
+		"min":              "{0} must be at least {1}",
+		"min.string":       "{0} must be at least {1} characters long",
+		"min.items":        "{0} must contain at least {1} items",
+		"max":              "{0} must be at most {1}",
+		"max.string":       "{0} must be at most {1} characters long",
+		"max.items":        "{0} must contain at most {1} items",
+		"oneof":            "{0} must be one of {1}",
+		"required_without": "{0} is required when {1} is missing",
+	},
+	"de": {
+		"required":         "{0} ist ein Pflichtfeld",
+		"email":            "{0} muss eine gültige E-Mail-Adresse sein",
+		"min":              "{0} muss mindestens {1} sein",
+		"min.string":       "{0} muss mindestens {1} Zeichen lang sein",
+		"min.items":        "{0} muss mindestens {1} Elemente enthalten",
+		"max":              "{0} darf höchstens {1} sein",
+		"max.string":       "{0} darf höchstens {1} Zeichen lang sein",
+		"max.items":        "{0} darf höchstens {1} Elemente enthalten",
+		"oneof":            "{0} muss einer dieser Werte sein: {1}",
+		"required_without": "{0} ist ein Pflichtfeld, wenn {1} fehlt",
+	},
+	"tr": {
+		"required":         "{0} zorunlu bir alandır",
+		"email":            "{0} geçerli bir e-posta adresi olmalıdır",
+		"min":              "{0} en az {1} olmalıdır",
+		"min.string":       "{0} en az {1} karakter uzunluğunda olmalıdır",
+		"min.items":        "{0} en az {1} öğe içermelidir",
+		"max":              "{0} en fazla {1} olabilir",
+		"max.string":       "{0} en fazla {1} karakter uzunluğunda olabilir",
+		"max.items":        "{0} en fazla {1} öğe içerebilir",
+		"oneof":            "{0} şu değerlerden biri olmalıdır: {1}",
+		"required_without": "{0}, {1} verilmediğinde zorunludur",
+	},
+}
+
+// fieldMessages holds the translation of every errs.FieldError, keyed by language and then by the key or rule of
+// the error. {0} is replaced with the offending field and {1} with the parameter of the error. The parameters of
+// profileSchema and schema are the details the JSON Schema library gives, which are only available in English.
+var fieldMessages = map[string]map[string]string{
+	"en": {
+		"future":        "{0} must be in the future",
+		"platform":      "{1} can only be granted to keys without a tenant",
+		"tenantId":      "{0} must be lowercase letters, digits and hyphens that neither start nor end with a hyphen",
+		"key":           "attribute names must not be empty, contain a dot or start with a dollar sign",
+		"x-indexed":     "{1} is not an indexed profile attribute",
+		"type":          "value must be of type {1}",
+		"schema":        "{0} is not a valid profile schema: {1}",
+		"profileSchema": "{0} does not match the profile schema: {1}",
+	},
+	"de": {
+		"future":        "{0} muss in der Zukunft liegen",
+		"platform":      "{1} kann nur Schlüsseln ohne Mandanten gewährt werden",
+		"tenantId":      "{0} darf nur aus Kleinbuchstaben, Ziffern und Bindestrichen bestehen und weder mit einem Bindestrich beginnen noch enden",
+		"key":           "Attributnamen dürfen nicht leer sein, keinen Punkt enthalten und nicht mit einem Dollarzeichen beginnen",
+		"x-indexed":     "{1} ist kein indiziertes Profilattribut",
+		"type":          "der Wert muss vom Typ {1} sein",
+		"schema":        "{0} ist kein gültiges Profilschema: {1}",
+		"profileSchema": "{0} entspricht nicht dem Profilschema: {1}",
+	},
+	"tr": {
+		"future":        "{0} gelecekte bir zaman olmalıdır",
+		"platform":      "{1} yalnızca bir kiracıya ait olmayan anahtarlara verilebilir",
+		"tenantId":      "{0} yalnızca küçük harf, rakam ve kısa çizgiden oluşmalı, kısa çizgiyle başlamamalı ve bitmemelidir",
+		"key":           "öznitelik adları boş olamaz, nokta içeremez ve dolar işaretiyle başlayamaz",
+		"x-indexed":     "{1} dizinlenmiş bir profil özniteliği değildir",
+		"type":          "değer {1} türünde olmalıdır",
+		"schema":        "{0} geçerli bir profil şeması değildir: {1}",
+		"profileSchema": "{0} profil şemasına uymuyor: {1}",
+	},
+}
@@ -0,0 +1,420 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	errs "user-service/error"
+	"user-service/i18n"
+	"user-service/model"
+	"user-service/repository"
+	"user-service/service"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+type OnErrorPolicy string
+
+const (
+	// OnErrorSkip records a failing row in the error report and carries on with the next one.
+	OnErrorSkip OnErrorPolicy = "skip"
+	// OnErrorAbort stops the import at the first failing row. Rows created before it are kept.
+	OnErrorAbort OnErrorPolicy = "abort"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusAborted   Status = "aborted"
+	StatusFailed    Status = "failed"
+)
+
+type Options struct {
+	Format  Format
+	DryRun  bool
+	OnError OnErrorPolicy
+}
+
+// Job is a snapshot of an import. Get returns copies, so it is safe to read without locking.
+type Job struct {
+	Id         string
+	Status     Status
+	Options    Options
+	Processed  int
+	Succeeded  int
+	Failed     int
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+var reportHeader = []string{"row", "email", "field", "rule", "message"}
+
+// JobRetention is how long a finished job and its error report are kept for the caller to fetch.
+const JobRetention = 24 * time.Hour
+
+type Importer struct {
+	userService service.UserServiceInterface
+	validator   *validator.Validate
+	catalog     *i18n.Catalog
+	directory   string
+	logger      *slog.Logger
+	retention   time.Duration
+
+	mutex   sync.Mutex
+	jobs    map[string]*job
+	closed  bool
+	running sync.WaitGroup
+
+	// ctx is the parent of every job's context and is cancelled to interrupt the jobs on shutdown.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// ErrClosed is returned by Start once Shutdown was called.
+var ErrClosed = errors.New("the importer is shutting down")
+
+type job struct {
+	Job
+	report string
+	// tenantId is the tenant the job imports into, which is the only one it can be seen from.
+	tenantId string
+}
+
+// NewImporter spools uploads and error reports to files in directory. An empty directory means os.TempDir. The
+// catalog has to be the one made for validator.
+func NewImporter(userService service.UserServiceInterface, validator *validator.Validate, catalog *i18n.Catalog, directory string, logger *slog.Logger) *Importer {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Importer{
+		userService: userService,
+		validator:   validator,
+		catalog:     catalog,
+		directory:   directory,
+		logger:      logger,
+		retention:   JobRetention,
+		jobs:        map[string]*job{},
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start copies source to disk and processes it in the background. The request body can be closed once it returns.
+// The users are imported into the tenant of ctx, which the job takes nothing else from since it outlives the
+// request.
+func (i *Importer) Start(ctx context.Context, source io.Reader, options Options) (*Job, error) {
+	upload, err := os.CreateTemp(i.directory, "import-*")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.Copy(upload, source)
+	if err == nil {
+		_, err = upload.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		upload.Close()
+		os.Remove(upload.Name())
+		return nil, err
+	}
+
+	current := &job{Job: Job{
+		Id:        primitive.NewObjectID().Hex(),
+		Status:    StatusPending,
+		Options:   options,
+		CreatedAt: time.Now().UTC(),
+	}, tenantId: repository.TenantFrom(ctx)}
+
+	i.mutex.Lock()
+	if i.closed {
+		i.mutex.Unlock()
+		upload.Close()
+		os.Remove(upload.Name())
+		return nil, ErrClosed
+	}
+	i.expire(time.Now())
+	i.jobs[current.Id] = current
+	snapshot := current.Job
+	// Adding under the mutex keeps Shutdown from waiting on a group that is still growing.
+	i.running.Add(1)
+	i.mutex.Unlock()
+
+	go func() {
+		defer i.running.Done()
+		defer os.Remove(upload.Name())
+		defer upload.Close()
+
+		// Users created by an import are attributed to the job, so they can be found with createdBy.
+		ctx := repository.WithActor(repository.WithTenant(i.ctx, current.tenantId), "import:"+current.Id)
+		i.run(ctx, current, upload)
+	}()
+
+	return &snapshot, nil
+}
+
+// Get returns the job with the given id if it imports into the tenant of ctx.
+func (i *Importer) Get(ctx context.Context, id string) (*Job, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.expire(time.Now())
+	current, ok := i.jobs[id]
+	if !ok || current.tenantId != repository.TenantFrom(ctx) {
+		return nil, errs.ImportJobNotFoundError
+	}
+
+	snapshot := current.Job
+	return &snapshot, nil
+}
+
+// OpenReport opens the CSV error report of a job of the tenant of ctx. It is only available once the job has
+// finished.
+func (i *Importer) OpenReport(ctx context.Context, id string) (io.ReadCloser, error) {
+	i.mutex.Lock()
+	i.expire(time.Now())
+	current, ok := i.jobs[id]
+	var report string
+	if ok && current.tenantId == repository.TenantFrom(ctx) {
+		report = current.report
+	}
+	i.mutex.Unlock()
+
+	if !ok || report == "" {
+		return nil, errs.ImportJobNotFoundError
+	}
+
+	return os.Open(report)
+}
+
+// Wait blocks until every started import has finished.
+func (i *Importer) Wait() {
+	i.running.Wait()
+}
+
+// Shutdown stops accepting imports and waits for the running ones to finish. Imports still running when ctx is
+// done are interrupted before their next row and reported as aborted; Shutdown waits for them to stop and then
+// returns the error of ctx. Either way the error reports are removed, as they would outlive the process that
+// serves them.
+func (i *Importer) Shutdown(ctx context.Context) error {
+	i.mutex.Lock()
+	i.closed = true
+	i.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		i.running.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		i.cancel()
+		<-done
+		err = ctx.Err()
+	}
+
+	i.mutex.Lock()
+	for _, current := range i.jobs {
+		i.removeReport(current)
+		current.report = ""
+	}
+	i.mutex.Unlock()
+
+	return err
+}
+
+// expire forgets the jobs that finished longer than the retention ago and removes their reports. It has to be
+// called with the mutex held.
+func (i *Importer) expire(now time.Time) {
+	for id, current := range i.jobs {
+		if current.FinishedAt != nil && now.Sub(*current.FinishedAt) > i.retention {
+			i.removeReport(current)
+			delete(i.jobs, id)
+		}
+	}
+}
+
+// removeReport deletes the report file of a job. Reports already opened can still be read to the end.
+func (i *Importer) removeReport(current *job) {
+	if current.report == "" {
+		return
+	}
+
+	err := os.Remove(current.report)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		i.logger.Error("removing the error report failed", "job_id", current.Id, "error", err)
+	}
+}
+
+func (i *Importer) run(ctx context.Context, current *job, upload io.Reader) {
+	i.update(current, func(job *job) { job.Status = StatusRunning })
+
+	reportFile, err := os.CreateTemp(i.directory, "import-report-*.csv")
+	if err != nil {
+		i.logger.ErrorContext(ctx, "creating the error report failed", "job_id", current.Id, "error", err)
+		i.finish(current, StatusFailed, "")
+		return
+	}
+	defer reportFile.Close()
+
+	report := csv.NewWriter(reportFile)
+	report.Write(reportHeader)
+
+	status := i.process(ctx, current, upload, report)
+
+	report.Flush()
+	if err := report.Error(); err != nil {
+		i.logger.ErrorContext(ctx, "writing the error report failed", "job_id", current.Id, "error", err)
+		status = StatusFailed
+	}
+
+	i.finish(current, status, reportFile.Name())
+}
+
+func (i *Importer) process(ctx context.Context, current *job, upload io.Reader, report *csv.Writer) Status {
+	var rows rowReader
+	if current.Options.Format == FormatCSV {
+		csvRows, err := newCSVRowReader(upload)
+		if err != nil && err != io.EOF {
+			report.Write([]string{"1", "", "", "", fmt.Sprintf("the header row could not be read: %v", err)})
+			return StatusFailed
+		} else if err == io.EOF {
+			return StatusCompleted
+		}
+		rows = csvRows
+	} else {
+		rows = newNDJSONRowReader(upload)
+	}
+
+	// seen catches duplicate emails within the file, which a dry run would otherwise not notice.
+	seen := map[string]bool{}
+
+	for {
+		if ctx.Err() != nil {
+			report.Write([]string{"", "", "", "", "the import was interrupted by a shutdown of the service"})
+			return StatusAborted
+		}
+
+		row, viewModel, err := rows.Next()
+		if err == io.EOF {
+			return StatusCompleted
+		} else if err != nil && !errors.Is(err, errMalformedRow) {
+			i.logger.ErrorContext(ctx, "reading the import failed", "job_id", current.Id, "row", row, "error", err)
+			report.Write([]string{strconv.Itoa(row), "", "", "", "the file could not be read"})
+			return StatusFailed
+		}
+
+		failures := i.importRow(ctx, current, viewModel, err, seen)
+		for _, failure := range failures {
+			report.Write(append([]string{strconv.Itoa(row), viewModel.Email}, failure...))
+		}
+
+		i.update(current, func(job *job) {
+			job.Processed++
+			if len(failures) == 0 {
+				job.Succeeded++
+			} else {
+				job.Failed++
+			}
+		})
+
+		if len(failures) > 0 && current.Options.OnError == OnErrorAbort {
+			return StatusAborted
+		}
+	}
+}
+
+// importRow creates or, on a dry run, only checks one row. It returns one field, rule, message triple per problem.
+func (i *Importer) importRow(ctx context.Context, current *job, viewModel model.CreateUserViewModel, readErr error, seen map[string]bool) [][]string {
+	if readErr != nil {
+		return [][]string{{"", "", "the row could not be parsed"}}
+	}
+
+	err := i.validator.Struct(viewModel)
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		var failures [][]string
+		for _, fieldError := range validationErrors {
+			failures = append(failures, []string{
+				fieldError.Field(),
+				fieldError.Tag(),
+				i.catalog.ValidationMessage(i18n.DefaultLanguage, fieldError),
+			})
+		}
+		return failures
+	} else if err != nil {
+		return [][]string{{"", "", err.Error()}}
+	}
+
+	email := model.NormalizeEmail(viewModel.Email)
+	if seen[email] {
+		return [][]string{{"email", "", i.catalog.ErrorMessage(i18n.DefaultLanguage, errs.EmailAlreadyInUseError)}}
+	}
+
+	domainModel := model.CreateUserDomainModel{
+		Name:     viewModel.Name,
+		Email:    viewModel.Email,
+		Password: viewModel.Password,
+		Profile:  viewModel.Profile,
+	}
+
+	if current.Options.DryRun {
+		err = i.userService.ValidateCreate(ctx, domainModel)
+	} else {
+		_, err = i.userService.Create(ctx, domainModel)
+	}
+	var fieldErrors *errs.FieldErrors
+	if errors.As(err, &fieldErrors) {
+		var failures [][]string
+		for _, field := range fieldErrors.Fields {
+			failures = append(failures, []string{field.Field, field.Rule, i.catalog.FieldMessage(i18n.DefaultLanguage, field)})
+		}
+		return failures
+	} else if err != nil {
+		if errors.Is(err, errs.EmailAlreadyInUseError) {
+			return [][]string{{"email", "", i.catalog.ErrorMessage(i18n.DefaultLanguage, err)}}
+		}
+
+		// Unexpected errors are logged rather than written into a report the caller can download.
+		i.logger.ErrorContext(ctx, "importing a row failed", "job_id", current.Id, "error", err)
+		return [][]string{{"", "", i.catalog.ErrorMessage(i18n.DefaultLanguage, errs.ServerError)}}
+	}
+
+	seen[email] = true
+	return nil
+}
+
+func (i *Importer) update(current *job, change func(*job)) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	change(current)
+}
+
+func (i *Importer) finish(current *job, status Status, report string) {
+	finishedAt := time.Now().UTC()
+
+	i.update(current, func(job *job) {
+		job.Status = status
+		job.FinishedAt = &finishedAt
+		job.report = report
+	})
+}
@@ -0,0 +1,268 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	errs "user-service/error"
+	"user-service/i18n"
+	"user-service/logging"
+	"user-service/model"
+	"user-service/repository"
+	serviceMock "user-service/service/mock"
+)
+
+// testValidator and testCatalog are set up the way main sets them up, except that the field names stay the Go
+// names, which only the controller package maps to JSON names.
+var testValidator, testCatalog = newTestValidation()
+
+func newTestValidation() (*validator.Validate, *i18n.Catalog) {
+	validate := validator.New()
+
+	catalog, err := i18n.NewCatalog(validate)
+	if err != nil {
+		panic(err)
+	}
+
+	return validate, catalog
+}
+
+func runImport(t *testing.T, userServiceMock *serviceMock.UserServiceInterface, body string, options Options) (*Job, [][]string) {
+	classUnderTest := NewImporter(userServiceMock, testValidator, testCatalog, t.TempDir(), logging.Discard())
+
+	started, err := classUnderTest.Start(context.Background(), strings.NewReader(body), options)
+	assert.Nil(t, err)
+	classUnderTest.Wait()
+
+	job, err := classUnderTest.Get(context.Background(), started.Id)
+	assert.Nil(t, err)
+
+	report, err := classUnderTest.OpenReport(context.Background(), started.Id)
+	assert.Nil(t, err)
+	defer report.Close()
+
+	records, err := csv.NewReader(report).ReadAll()
+	assert.Nil(t, err)
+
+	return job, records
+}
+
+func Test_Start_Should_Create_Every_Valid_CSV_Row_And_Report_The_Others_When_OnError_Is_Skip(t *testing.T) {
+	body := "Email,Name,Password\n" +
+		"a@site.com,A,123456\n" +
+		"not an email,B,123456\n" +
+		"c@site.com,C,123456\n"
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Create", mock.Anything, model.CreateUserDomainModel{Name: "A", Email: "a@site.com", Password: "123456"}).Return(&model.UserDomainModel{}, nil).Once()
+	userServiceMock.On("Create", mock.Anything, model.CreateUserDomainModel{Name: "C", Email: "c@site.com", Password: "123456"}).Return(nil, errs.EmailAlreadyInUseError).Once()
+
+	job, records := runImport(t, userServiceMock, body, Options{Format: FormatCSV, OnError: OnErrorSkip})
+
+	assert.Equal(t, StatusCompleted, job.Status)
+	assert.Equal(t, 3, job.Processed)
+	assert.Equal(t, 1, job.Succeeded)
+	assert.Equal(t, 2, job.Failed)
+	assert.NotNil(t, job.FinishedAt)
+	assert.Equal(t, reportHeader, records[0])
+	assert.Equal(t, []string{"2", "not an email", "Email", "email", "Email must be a valid email address"}, records[1])
+	assert.Equal(t, []string{"3", "c@site.com", "email", "", errs.EmailAlreadyInUseError.Error()}, records[2])
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Start_Should_Stop_At_The_First_Failing_Row_When_OnError_Is_Abort(t *testing.T) {
+	body := `{"name":"A","email":"a@site.com","password":"123456"}` + "\n" +
+		"{broken\n" +
+		`{"name":"C","email":"c@site.com","password":"123456"}` + "\n"
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Create", mock.Anything, model.CreateUserDomainModel{Name: "A", Email: "a@site.com", Password: "123456"}).Return(&model.UserDomainModel{}, nil).Once()
+
+	job, records := runImport(t, userServiceMock, body, Options{Format: FormatNDJSON, OnError: OnErrorAbort})
+
+	assert.Equal(t, StatusAborted, job.Status)
+	assert.Equal(t, 2, job.Processed)
+	assert.Equal(t, 1, job.Failed)
+	assert.Equal(t, []string{"2", "", "", "", "the row could not be parsed"}, records[1])
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Start_Should_Only_Validate_Rows_When_DryRun_Is_Set(t *testing.T) {
+	body := `{"name":"A","email":"a@site.com","password":"123456"}` + "\n" +
+		"\n" +
+		`{"name":"A again","email":"A@site.com","password":"123456"}` + "\n"
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("ValidateCreate", mock.Anything, model.CreateUserDomainModel{Name: "A", Email: "a@site.com", Password: "123456"}).Return(nil).Once()
+
+	job, records := runImport(t, userServiceMock, body, Options{Format: FormatNDJSON, DryRun: true, OnError: OnErrorSkip})
+
+	assert.Equal(t, StatusCompleted, job.Status)
+	assert.Equal(t, 2, job.Processed)
+	assert.Equal(t, 1, job.Succeeded)
+	assert.Equal(t, []string{"3", "A@site.com", "email", "", errs.EmailAlreadyInUseError.Error()}, records[1])
+	userServiceMock.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Start_Should_Read_The_Profile_Of_CSV_Rows_As_JSON(t *testing.T) {
+	body := "email,name,password,profile\n" +
+		"a@site.com,A,123456,\"{\"\"team\"\":\"\"red\"\"}\"\n" +
+		"b@site.com,B,123456,not json\n"
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Create", mock.Anything, model.CreateUserDomainModel{
+		Name: "A", Email: "a@site.com", Password: "123456", Profile: map[string]interface{}{"team": "red"},
+	}).Return(&model.UserDomainModel{}, nil).Once()
+
+	job, records := runImport(t, userServiceMock, body, Options{Format: FormatCSV, OnError: OnErrorSkip})
+
+	assert.Equal(t, 1, job.Succeeded)
+	assert.Equal(t, []string{"2", "b@site.com", "", "", "the row could not be parsed"}, records[1])
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Start_Should_Report_Every_Profile_Attribute_That_Breaks_The_Schema(t *testing.T) {
+	body := `{"name":"A","email":"a@site.com","password":"123456","profile":{"age":-1}}` + "\n"
+	profileErrors := &errs.FieldErrors{Err: errs.ValidationError, Fields: []errs.FieldError{
+		{Field: "profile", Rule: "required", Key: "profileSchema", Param: "missing properties: 'department'"},
+		{Field: "profile.age", Rule: "minimum", Key: "profileSchema", Param: "must be >= 0 but found -1"},
+	}}
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Create", mock.Anything, model.CreateUserDomainModel{
+		Name: "A", Email: "a@site.com", Password: "123456", Profile: map[string]interface{}{"age": -1.0},
+	}).Return(nil, profileErrors).Once()
+
+	job, records := runImport(t, userServiceMock, body, Options{Format: FormatNDJSON, OnError: OnErrorSkip})
+
+	assert.Equal(t, 1, job.Failed)
+	assert.Equal(t, []string{"1", "a@site.com", "profile", "required", "profile does not match the profile schema: missing properties: 'department'"}, records[1])
+	assert.Equal(t, []string{"1", "a@site.com", "profile.age", "minimum", "profile.age does not match the profile schema: must be >= 0 but found -1"}, records[2])
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Get_Should_Return_ImportJobNotFoundError_When_Job_Does_Not_Exist(t *testing.T) {
+	classUnderTest := NewImporter(new(serviceMock.UserServiceInterface), testValidator, testCatalog, t.TempDir(), logging.Discard())
+
+	_, err := classUnderTest.Get(context.Background(), "missing")
+	assert.ErrorIs(t, err, errs.ImportJobNotFoundError)
+
+	_, err = classUnderTest.OpenReport(context.Background(), "missing")
+	assert.ErrorIs(t, err, errs.ImportJobNotFoundError)
+}
+
+func Test_Get_Should_Only_Find_Jobs_Of_The_Tenant_They_Import_Into(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Create", mock.MatchedBy(func(ctx context.Context) bool {
+		return repository.TenantFrom(ctx) == "acme"
+	}), mock.Anything).Return(&model.UserDomainModel{}, nil).Once()
+
+	classUnderTest := NewImporter(userServiceMock, testValidator, testCatalog, t.TempDir(), logging.Discard())
+
+	acme := repository.WithTenant(context.Background(), "acme")
+	started, err := classUnderTest.Start(acme, strings.NewReader(`{"name":"A","email":"a@site.com","password":"123456"}`+"\n"), Options{Format: FormatNDJSON, OnError: OnErrorSkip})
+	assert.Nil(t, err)
+	classUnderTest.Wait()
+
+	other := repository.WithTenant(context.Background(), "other")
+	_, err = classUnderTest.Get(other, started.Id)
+	assert.ErrorIs(t, err, errs.ImportJobNotFoundError)
+	_, err = classUnderTest.OpenReport(other, started.Id)
+	assert.ErrorIs(t, err, errs.ImportJobNotFoundError)
+
+	job, err := classUnderTest.Get(acme, started.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, job.Succeeded)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Shutdown_Should_Wait_For_Running_Imports_And_Refuse_New_Ones(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Create", mock.Anything, mock.Anything).WaitUntil(time.After(20*time.Millisecond)).Return(&model.UserDomainModel{}, nil).Once()
+
+	classUnderTest := NewImporter(userServiceMock, testValidator, testCatalog, t.TempDir(), logging.Discard())
+	started, err := classUnderTest.Start(context.Background(), strings.NewReader(`{"name":"A","email":"a@site.com","password":"123456"}`+"\n"), Options{Format: FormatNDJSON, OnError: OnErrorSkip})
+	assert.Nil(t, err)
+
+	err = classUnderTest.Shutdown(context.Background())
+	assert.Nil(t, err)
+
+	job, _ := classUnderTest.Get(context.Background(), started.Id)
+	assert.Equal(t, StatusCompleted, job.Status)
+	assert.Equal(t, 1, job.Succeeded)
+
+	_, err = classUnderTest.Start(context.Background(), strings.NewReader(""), Options{Format: FormatNDJSON, OnError: OnErrorSkip})
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func Test_Shutdown_Should_Interrupt_Imports_Still_Running_At_The_Deadline(t *testing.T) {
+	body := `{"name":"A","email":"a@site.com","password":"123456"}` + "\n" +
+		`{"name":"B","email":"b@site.com","password":"123456"}` + "\n"
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil, errs.ServerError).Once()
+
+	classUnderTest := NewImporter(userServiceMock, testValidator, testCatalog, t.TempDir(), logging.Discard())
+	started, err := classUnderTest.Start(context.Background(), strings.NewReader(body), Options{Format: FormatNDJSON, OnError: OnErrorSkip})
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err = classUnderTest.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	job, _ := classUnderTest.Get(context.Background(), started.Id)
+	assert.Equal(t, StatusAborted, job.Status)
+	assert.Equal(t, 1, job.Processed)
+	userServiceMock.AssertExpectations(t)
+}
+
+func Test_Get_Should_Forget_Jobs_And_Remove_Their_Reports_Once_The_Retention_Is_Over(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Create", mock.Anything, mock.Anything).Return(&model.UserDomainModel{}, nil)
+
+	directory := t.TempDir()
+	classUnderTest := NewImporter(userServiceMock, testValidator, testCatalog, directory, logging.Discard())
+	classUnderTest.retention = time.Millisecond
+
+	started, err := classUnderTest.Start(context.Background(), strings.NewReader(`{"name":"A","email":"a@site.com","password":"123456"}`+"\n"), Options{Format: FormatNDJSON, OnError: OnErrorSkip})
+	assert.Nil(t, err)
+	classUnderTest.Wait()
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = classUnderTest.Get(context.Background(), started.Id)
+	assert.ErrorIs(t, err, errs.ImportJobNotFoundError)
+
+	entries, _ := os.ReadDir(directory)
+	assert.Empty(t, entries)
+}
+
+func Test_Shutdown_Should_Remove_The_Reports(t *testing.T) {
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("Create", mock.Anything, mock.Anything).Return(&model.UserDomainModel{}, nil)
+
+	directory := t.TempDir()
+	classUnderTest := NewImporter(userServiceMock, testValidator, testCatalog, directory, logging.Discard())
+
+	started, err := classUnderTest.Start(context.Background(), strings.NewReader(`{"name":"A","email":"a@site.com","password":"123456"}`+"\n"), Options{Format: FormatNDJSON, OnError: OnErrorSkip})
+	assert.Nil(t, err)
+
+	err = classUnderTest.Shutdown(context.Background())
+	assert.Nil(t, err)
+
+	_, err = classUnderTest.OpenReport(context.Background(), started.Id)
+	assert.ErrorIs(t, err, errs.ImportJobNotFoundError)
+
+	entries, _ := os.ReadDir(directory)
+	assert.Empty(t, entries)
+}
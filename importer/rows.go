@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"user-service/model"
+)
+
+// errMalformedRow marks a row that could not be parsed. Unlike other read errors it only fails that row.
+var errMalformedRow = errors.New("row is malformed")
+
+// maxNDJSONLineSize bounds a single NDJSON line so a file without newlines cannot exhaust memory.
+const maxNDJSONLineSize = 64 * 1024
+
+// rowReader yields the rows of an import one at a time. It returns io.EOF after the last row.
+type rowReader interface {
+	Next() (row int, viewModel model.CreateUserViewModel, err error)
+}
+
+// csvRowReader reads CSV with a header row naming the name, email, password and profile columns in any order.
+// The profile is a JSON object, as in the CSV exports.
+type csvRowReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+func newCSVRowReader(source io.Reader) (*csvRowReader, error) {
+	reader := csv.NewReader(source)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	return &csvRowReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvRowReader) Next() (int, model.CreateUserViewModel, error) {
+	record, err := r.reader.Read()
+	r.row++
+
+	var parseError *csv.ParseError
+	if errors.As(err, &parseError) && parseError.Err != csv.ErrFieldCount {
+		return r.row, model.CreateUserViewModel{}, errMalformedRow
+	} else if err != nil {
+		return r.row, model.CreateUserViewModel{}, err
+	}
+
+	viewModel := model.CreateUserViewModel{
+		Name:     r.column(record, "name"),
+		Email:    r.column(record, "email"),
+		Password: r.column(record, "password"),
+	}
+
+	if userProfile := r.column(record, "profile"); userProfile != "" {
+		err = json.Unmarshal([]byte(userProfile), &viewModel.Profile)
+		if err != nil {
+			return r.row, viewModel, errMalformedRow
+		}
+	}
+
+	return r.row, viewModel, nil
+}
+
+func (r *csvRowReader) column(record []string, name string) string {
+	i, ok := r.columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+
+	return record[i]
+}
+
+// ndjsonRowReader reads one JSON object per line, skipping blank lines.
+type ndjsonRowReader struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+func newNDJSONRowReader(source io.Reader) *ndjsonRowReader {
+	scanner := bufio.NewScanner(source)
+	scanner.Buffer(make([]byte, 0, 4096), maxNDJSONLineSize)
+
+	return &ndjsonRowReader{scanner: scanner}
+}
+
+func (r *ndjsonRowReader) Next() (int, model.CreateUserViewModel, error) {
+	for r.scanner.Scan() {
+		r.row++
+
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var viewModel model.CreateUserViewModel
+		err := json.Unmarshal(line, &viewModel)
+		if err != nil {
+			return r.row, viewModel, errMalformedRow
+		}
+
+		return r.row, viewModel, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return r.row, model.CreateUserViewModel{}, err
+	}
+
+	return r.row, model.CreateUserViewModel{}, io.EOF
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+type requestIdKey struct{}
+
+// New returns a logger writing JSON lines to w from the given level on, one of config.LogLevels. Every line
+// carries the request id and the trace of its context, and emails and passwords are redacted from it.
+func New(w io.Writer, level string) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       parseLevel(level),
+		ReplaceAttr: redactAttr,
+	})
+
+	return slog.New(contextHandler{handler})
+}
+
+// Discard returns a logger that drops everything, for tests.
+func Discard() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+}
+
+// WithRequestID returns a context whose log lines carry the given request id.
+func WithRequestID(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// RequestID returns the request id of ctx, or an empty string outside of requests.
+func RequestID(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdKey{}).(string)
+
+	return requestId
+}
+
+func parseLevel(level string) slog.Level {
+	var parsed slog.Level
+	err := parsed.UnmarshalText([]byte(strings.ToUpper(level)))
+	if err != nil {
+		return slog.LevelInfo
+	}
+
+	return parsed
+}
+
+// contextHandler adds the request id and the trace of the context to every record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestId := RequestID(ctx); requestId != "" {
+		record.AddAttrs(slog.String("request_id", requestId))
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func decodeLine(t *testing.T, output *bytes.Buffer) map[string]interface{} {
+	var line map[string]interface{}
+	err := json.Unmarshal(output.Bytes(), &line)
+	assert.Nil(t, err, output.String())
+
+	return line
+}
+
+func Test_New_Should_Add_The_Request_Id_Of_The_Context(t *testing.T) {
+	var output bytes.Buffer
+
+	New(&output, "info").InfoContext(WithRequestID(context.Background(), "abc-123"), "deleted the user")
+
+	line := decodeLine(t, &output)
+	assert.Equal(t, "deleted the user", line["msg"])
+	assert.Equal(t, "abc-123", line["request_id"])
+	assert.Equal(t, "INFO", line["level"])
+}
+
+func Test_New_Should_Drop_Records_Below_The_Level(t *testing.T) {
+	var output bytes.Buffer
+
+	New(&output, "warn").Info("ignored")
+
+	assert.Empty(t, output.String())
+}
+
+func Test_New_Should_Redact_Emails_And_Passwords(t *testing.T) {
+	var output bytes.Buffer
+
+	New(&output, "info").Error("creating the user failed for jane@example.com",
+		"email", "jane@example.com",
+		"error", errors.New(`E11000 duplicate key error dup key: { email: "jane@example.com" }`),
+		"payload", map[string]interface{}{"name": "Jane", "password": "hunter2", "contact": "jane@example.com"},
+		"query", "password=hunter2&name=jane",
+	)
+
+	line := decodeLine(t, &output)
+	assert.NotContains(t, output.String(), "jane@example.com")
+	assert.NotContains(t, output.String(), "hunter2")
+	assert.Equal(t, "creating the user failed for REDACTED", line["msg"])
+	assert.Equal(t, "REDACTED", line["email"])
+	assert.Equal(t, `E11000 duplicate key error dup key: { email: "REDACTED" }`, line["error"])
+	assert.Equal(t, map[string]interface{}{"name": "Jane", "password": "REDACTED", "contact": "REDACTED"}, line["payload"])
+	assert.Equal(t, "password=REDACTED&name=jane", line["query"])
+}
+
+func Test_Redact_Should_Keep_Payloads_Valid_JSON(t *testing.T) {
+	assert.Equal(t, `{"password":"REDACTED","name":"a"}`, Redact(`{"password":"se\"cret","name":"a"}`))
+	assert.Equal(t, `{"newPassword": "REDACTED"}`, Redact(`{"newPassword": "x"}`))
+}
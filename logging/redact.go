@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// passwordPattern finds password fields in JSON, query strings and the like, such as "password":"secret" or
+	// password=secret, and captures everything up to the value.
+	passwordPattern = regexp.MustCompile(`(?i)("?\w*password"?\s*[:=]\s*)("(?:[^"\\]|\\.)*"|[^\s,&}"]+)`)
+)
+
+// redactAttr replaces the values of attributes named after emails or passwords, and the emails and password
+// fields in every other string, error and payload that is logged.
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) == 0 && (attr.Key == slog.TimeKey || attr.Key == slog.LevelKey || attr.Key == slog.SourceKey) {
+		return attr
+	}
+
+	key := strings.ToLower(attr.Key)
+	if strings.Contains(key, "password") || strings.Contains(key, "email") {
+		return slog.String(attr.Key, redacted)
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, Redact(value.String()))
+	case slog.KindAny:
+		switch any := value.Any().(type) {
+		case error:
+			return slog.String(attr.Key, Redact(any.Error()))
+		case json.RawMessage:
+			return redactPayload(attr.Key, any)
+		default:
+			// Payloads such as view models are logged as the JSON they would be sent as, minus their secrets.
+			payload, err := json.Marshal(any)
+			if err != nil {
+				return slog.String(attr.Key, redacted)
+			}
+
+			return redactPayload(attr.Key, payload)
+		}
+	}
+
+	return attr
+}
+
+func redactPayload(key string, payload []byte) slog.Attr {
+	text := Redact(string(payload))
+	if !json.Valid([]byte(text)) {
+		// Redacting an unquoted value, such as "password":null, leaves text that is not JSON anymore.
+		return slog.String(key, text)
+	}
+
+	return slog.Any(key, json.RawMessage(text))
+}
+
+// Redact replaces the email addresses and the values of password fields in text.
+func Redact(text string) string {
+	text = passwordPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := passwordPattern.FindStringSubmatch(match)
+		if strings.HasPrefix(parts[2], `"`) {
+			return parts[1] + `"` + redacted + `"`
+		}
+
+		return parts[1] + redacted
+	})
+
+	return emailPattern.ReplaceAllString(text, redacted)
+}
@@ -1,33 +1,323 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc"
 	"log"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"user-service/config"
 	"user-service/controller"
+	"user-service/graphqlapi"
+	"user-service/grpcapi"
+	"user-service/i18n"
+	"user-service/importer"
+	"user-service/logging"
+	"user-service/metrics"
+	"user-service/middleware"
+	"user-service/migration"
+	"user-service/model"
+	"user-service/openapi"
+	"user-service/proto/userpb"
 	"user-service/repository"
+	"user-service/router"
 	"user-service/service"
+	"user-service/tracing"
 )
 
 func main() {
-	router := gin.Default()
+	args := os.Args[1:]
+	printConfig := len(args) >= 2 && args[0] == "config" && args[1] == "print"
+	if printConfig {
+		args = args[2:]
+	}
+
+	cfg, err := config.Load(args, os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		config.Usage(os.Stdout)
+		return
+	} else if err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+
+	if printConfig {
+		err = config.Print(os.Stdout, cfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	err = run(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run serves until SIGINT or SIGTERM and then shuts down gracefully. It returns the first error that keeps the
+// service from starting or serving.
+func run(cfg *config.Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
+	// gin prints its routes and warnings about its setup in debug mode only.
+	if cfg.Log.Level != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	repository.CollectionName = cfg.Database.UserCollection
+	repository.OperationTimeout = cfg.Database.OperationTimeout
+	service.PasswordCost = cfg.Security.BcryptCost
+
+	logger := logging.New(os.Stdout, cfg.Log.Level)
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing, os.Stdout)
+	if err != nil {
+		return err
+	}
+	defer flushSpans(shutdownTracing, cfg.Server.ShutdownTimeout, logger)
+
+	engine := gin.New()
+	err = engine.SetTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		return err
+	}
+	engine.Use(middleware.RequestID(), middleware.Tracing(), middleware.AccessLog(logger), gin.Recovery())
+	if cfg.Features.Metrics {
+		engine.Use(middleware.Metrics())
+	}
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		engine.Use(middleware.CORS(cfg.CORS))
+	}
+
+	// Every API reports fields by their JSON names, and the catalog translates the messages of the validator.
 	validator := validator.New()
-	database := repository.InitDatabase(os.Getenv("MONGO_URI"))
-	userRepository := repository.NewUserRepository(database)
-	userService := service.NewUserService(userRepository)
-	userController := controller.NewUserController(userService, validator)
+	validator.RegisterTagNameFunc(controller.JsonFieldName)
+	catalog, err := i18n.NewCatalog(validator)
+	if err != nil {
+		return err
+	}
+
+	database, err := repository.InitDatabase(ctx, cfg.Database.URI, cfg.Database.Name, cfg.Database.ConnectTimeout, repository.ConnectRetry{
+		Attempts: cfg.Database.ConnectAttempts,
+		Backoff:  cfg.Database.ConnectBackoff,
+	}, logger)
+	if err != nil {
+		return err
+	}
+	defer disconnect(database.Client(), cfg.Server.ShutdownTimeout, logger)
+
+	userRepository := repository.NewInstrumentedUserRepository(repository.NewUserRepository(database, logger))
+	err = migration.Run(ctx, database, logger)
+	if err != nil {
+		return err
+	}
+
+	revisionRepository := repository.NewUserRevisionRepository(database, logger)
+	profileSchemaRepository := repository.NewProfileSchemaRepository(database, logger)
+
+	blobStore, err := repository.NewLocalBlobStore(cfg.Storage.BlobDirectory)
+	if err != nil {
+		return err
+	}
+
+	profileSchemaService := service.NewProfileSchemaService(profileSchemaRepository, userRepository, logger)
+	groupRepository := repository.NewGroupRepository(database, logger)
+	userService := service.NewInstrumentedUserService(service.NewUserService(userRepository, revisionRepository, profileSchemaService, blobStore, groupRepository, logger))
+	avatarService := service.NewAvatarService(userRepository, blobStore, logger)
+	userController := controller.NewUserController(userService, validator, catalog, logger)
+	avatarController := controller.NewAvatarController(avatarService, catalog, logger)
+	userImporter := importer.NewImporter(userService, validator, catalog, cfg.Storage.ImportDirectory, logger)
+	importController := controller.NewImportController(userImporter, catalog, logger)
+	profileSchemaController := controller.NewProfileSchemaController(profileSchemaService, validator, catalog, logger)
+	groupController := controller.NewGroupController(service.NewGroupService(groupRepository, userRepository, logger), validator, catalog, logger)
+	tenantService := service.NewTenantService(repository.NewTenantRepository(database, logger), logger)
+	tenantController := controller.NewTenantController(tenantService, validator, catalog, logger, cfg.Tenancy.DefaultTenant, cfg.Tenancy.BaseDomain)
+	apiKeyService := service.NewApiKeyService(repository.NewApiKeyRepository(database, logger), tenantService, cfg.Auth.BootstrapKey, logger)
+	var anonymousScopes []string
+	if !cfg.Auth.Required {
+		anonymousScopes = []string{model.ScopeUsersRead, model.ScopeUsersWrite}
+	}
+	apiKeyController := controller.NewApiKeyController(apiKeyService, validator, catalog, logger, anonymousScopes)
+	healthService := service.NewHealthService(cfg.Health.Timeout, logger,
+		service.HealthCheck{Name: "mongodb", ComponentType: "datastore", Check: func(ctx context.Context) error {
+			return repository.Ping(ctx, database)
+		}},
+		service.HealthCheck{Name: "migrations", ComponentType: "datastore", Check: func(ctx context.Context) error {
+			return migration.Check(ctx, database)
+		}},
+		// Only avatars need the blob store, so the users can still be served without it.
+		service.HealthCheck{Name: "blobStore", ComponentType: "datastore", Optional: true, Check: blobStore.Check},
+	)
+	healthController := controller.NewHealthController(healthService)
+
+	var graphqlHandler http.Handler
+	if cfg.Features.GraphQL {
+		graphqlHandler = graphqlapi.NewHandler(userService, validator)
+	}
+
+	var docsController *controller.DocsController
+	if cfg.Features.Docs {
+		document := openapi.NewDocument()
+		if !cfg.Features.GraphQL {
+			delete(document.Paths, "/graphql")
+		}
+		if !cfg.Features.Metrics {
+			delete(document.Paths, "/metrics")
+		}
+		docsController = controller.NewDocsController(document)
+	}
+
+	var metricsHandler http.Handler
+	if cfg.Features.Metrics {
+		metricsHandler = metrics.Handler()
+	}
+
+	// Authentication and rate limiting run on every route registered after them, so they have to be in place
+	// before the routes. Rate limits keyed by IP address run before authentication, so that guessing keys is
+	// limited too, and those keyed by user or API key after it.
+	var rateLimitController *controller.RateLimitController
+	if policies := cfg.RateLimit.ParsedPolicies(); len(policies) > 0 {
+		var rateLimitStore repository.RateLimitStore = repository.NewMemoryRateLimitStore()
+		if cfg.RateLimit.Store == "mongodb" {
+			rateLimitStore = repository.NewMongoRateLimitStore(database, logger)
+		}
+		rateLimitService := service.NewRateLimitService(rateLimitStore, policies, logger)
+		rateLimitController = controller.NewRateLimitController(rateLimitService, catalog, logger)
+		engine.Use(rateLimitController.LimitByIP)
+	}
+	engine.Use(apiKeyController.Authenticate)
+	if rateLimitController != nil {
+		engine.Use(rateLimitController.Limit)
+	}
+
+	router.Register(engine, userController, avatarController, importController, profileSchemaController, apiKeyController, tenantController, groupController, healthController, docsController, graphqlHandler, metricsHandler)
+
+	// Both servers report here when they stop serving on their own, which only happens when they fail.
+	serveErrors := make(chan error, 2)
+
+	var grpcServer *grpc.Server
+	if cfg.Features.GRPC {
+		grpcServer = grpc.NewServer(
+			grpc.ChainUnaryInterceptor(
+				tracing.UnaryServerInterceptor(),
+				grpcapi.AuthInterceptor(apiKeyService, anonymousScopes),
+				grpcapi.TenantInterceptor(tenantService, cfg.Tenancy.DefaultTenant),
+			),
+			grpc.ChainStreamInterceptor(
+				tracing.StreamServerInterceptor(),
+				grpcapi.AuthStreamInterceptor(apiKeyService, anonymousScopes),
+				grpcapi.TenantStreamInterceptor(tenantService, cfg.Tenancy.DefaultTenant),
+			),
+		)
+		userpb.RegisterUserServiceServer(grpcServer, grpcapi.NewUserServer(userService, validator))
+
+		listener, err := net.Listen("tcp", cfg.GRPC.Address)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("serving gRPC", "address", listener.Addr().String())
+		go func() {
+			serveErrors <- grpcServer.Serve(listener)
+		}()
+	}
+
+	server := &http.Server{
+		Addr:              cfg.Server.Address,
+		Handler:           engine,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	logger.Info("serving HTTP", "address", cfg.Server.Address)
+	go func() {
+		serveErrors <- server.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serveErrors:
+	case <-ctx.Done():
+		logger.Info("shutting down")
+	}
+
+	// A second signal kills the process right away instead of waiting for the shutdown.
+	stop()
+
+	healthService.ShutDown()
+	if err == nil && cfg.Server.ShutdownDelay > 0 {
+		logger.Info("failing readiness before shutting down", "delay", cfg.Server.ShutdownDelay)
+		time.Sleep(cfg.Server.ShutdownDelay)
+	}
+
+	shutdown(server, grpcServer, userImporter, cfg.Server.ShutdownTimeout, logger)
+
+	return err
+}
+
+// shutdown stops accepting requests and waits up to timeout for the HTTP requests, RPCs and imports in flight.
+// Whatever is still running by then is cut off.
+func shutdown(server *http.Server, grpcServer *grpc.Server, userImporter *importer.Importer, timeout time.Duration, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := server.Shutdown(ctx)
+	if err != nil {
+		logger.Warn("HTTP requests were cut off", "error", err)
+		server.Close()
+	}
+
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			logger.Warn("RPCs were cut off")
+			grpcServer.Stop()
+		}
+	}
+
+	err = userImporter.Shutdown(ctx)
+	if err != nil {
+		logger.Warn("running imports were interrupted", "error", err)
+	}
+}
+
+// flushSpans exports the spans that are still buffered, which includes those of the shutdown itself.
+func flushSpans(shutdownTracing func(context.Context) error, timeout time.Duration, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := shutdownTracing(ctx)
+	if err != nil {
+		logger.Error(err.Error())
+	}
+}
 
-	router.GET("/users", userController.GetAll)
-	router.GET("/users/:id", userController.GetById)
-	router.POST("/users", userController.Create)
-	router.PATCH("/users/:id", userController.UpdateById)
-	router.DELETE("/users/:id", userController.DeleteById)
+// disconnect closes the connections to the database once nothing uses them anymore.
+func disconnect(client *mongo.Client, timeout time.Duration, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	err := router.Run()
+	err := client.Disconnect(ctx)
 	if err != nil {
-		log.Println(err)
-		panic(err)
+		logger.Error(err.Error())
 	}
 }
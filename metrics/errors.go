@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"errors"
+	errs "user-service/error"
+)
+
+// errorLabels names every error package sentinel in the error label of a metric.
+var errorLabels = []struct {
+	err   error
+	label string
+}{
+	{errs.BadRequestError, "bad_request"},
+	{errs.NotFoundError, "not_found"},
+	{errs.EmailAlreadyInUseError, "email_already_in_use"},
+	{errs.PreconditionFailedError, "precondition_failed"},
+	{errs.RevisionNotFoundError, "revision_not_found"},
+	{errs.ImportJobNotFoundError, "import_job_not_found"},
+	{errs.PayloadTooLargeError, "payload_too_large"},
+	{errs.ValidationError, "validation_error"},
+	{errs.ProfileSchemaNotFoundError, "profile_schema_not_found"},
+	{errs.InvalidProfileSchemaError, "invalid_profile_schema"},
+	{errs.AvatarNotFoundError, "avatar_not_found"},
+	{errs.UnsupportedMediaTypeError, "unsupported_media_type"},
+	{errs.InvalidAvatarError, "invalid_avatar"},
+	{errs.TooManyRequestsError, "too_many_requests"},
+	{errs.UnauthorizedError, "unauthorized"},
+	{errs.ForbiddenError, "forbidden"},
+	{errs.ApiKeyNotFoundError, "api_key_not_found"},
+	{errs.TenantRequiredError, "tenant_required"},
+	{errs.TenantNotFoundError, "tenant_not_found"},
+	{errs.TenantAlreadyExistsError, "tenant_already_exists"},
+	{errs.GroupNotFoundError, "group_not_found"},
+	{errs.GroupCycleError, "group_cycle"},
+	{errs.ServerError, "server_error"},
+}
+
+// ErrorLabel returns the label of the sentinel that err is or wraps, and other for errors of any other kind.
+func ErrorLabel(err error) string {
+	for _, errorLabel := range errorLabels {
+		if errors.Is(err, errorLabel.err) {
+			return errorLabel.label
+		}
+	}
+
+	return "other"
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+// Registry holds every metric of the service. It is separate from the default registry so that libraries cannot
+// add metrics behind the service's back.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequestDuration is labelled with the route template, such as /users/:id, rather than the path, so that
+	// ids do not make a series each.
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to serve HTTP requests.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	UserServiceErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "user_service_errors_total",
+		Help: "Errors returned by the user service, by operation and error.",
+	}, []string{"operation", "error"})
+
+	MongoOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mongodb_operation_duration_seconds",
+		Help:    "Time taken by the operations of the user repository.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	MongoOperationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mongodb_operation_errors_total",
+		Help: "Operations of the user repository that failed, not counting users that were not found or did not match a precondition.",
+	}, []string{"operation"})
+
+	// PasswordHashDuration follows the bcrypt cost, which makes hashing deliberately slow.
+	PasswordHashDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "password_hash_duration_seconds",
+		Help:    "Time taken to hash a password with bcrypt.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 10),
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		UserServiceErrors,
+		MongoOperationDuration,
+		MongoOperationErrors,
+		PasswordHashDuration,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"user-service/config"
+)
+
+// CORS answers preflight requests and adds the CORS headers to the responses for allowed origins. Requests from
+// other origins are served without the headers, so browsers withhold the response from the calling page.
+func CORS(cors config.CORS) gin.HandlerFunc {
+	allowed := map[string]bool{}
+	for _, origin := range cors.AllowedOrigins {
+		allowed[origin] = true
+	}
+
+	allowedMethods := strings.Join(cors.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(cors.AllowedHeaders, ", ")
+	exposedHeaders := strings.Join(cors.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cors.MaxAge.Seconds()))
+
+	return func(ctx *gin.Context) {
+		origin := ctx.GetHeader("Origin")
+		if origin == "" {
+			ctx.Next()
+			return
+		}
+
+		ctx.Writer.Header().Add("Vary", "Origin")
+		if !allowed["*"] && !allowed[origin] {
+			ctx.Next()
+			return
+		}
+
+		if allowed["*"] {
+			ctx.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			ctx.Header("Access-Control-Allow-Origin", origin)
+		}
+		if cors.AllowCredentials {
+			ctx.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if ctx.Request.Method == http.MethodOptions && ctx.GetHeader("Access-Control-Request-Method") != "" {
+			ctx.Header("Access-Control-Allow-Methods", allowedMethods)
+			ctx.Header("Access-Control-Allow-Headers", allowedHeaders)
+			ctx.Header("Access-Control-Max-Age", maxAge)
+			ctx.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if exposedHeaders != "" {
+			ctx.Header("Access-Control-Expose-Headers", exposedHeaders)
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"user-service/config"
+)
+
+func newCORSTestRouter(cors config.CORS) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORS(cors))
+	router.GET("/users", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	return router
+}
+
+var testCORS = config.CORS{
+	AllowedOrigins:   []string{"https://app.example.com"},
+	AllowedMethods:   []string{"GET", "PUT"},
+	AllowedHeaders:   []string{"Content-Type", "If-Match"},
+	ExposedHeaders:   []string{"ETag"},
+	AllowCredentials: true,
+	MaxAge:           10 * time.Minute,
+}
+
+func Test_CORS_Should_Answer_Preflight_Requests_From_Allowed_Origins(t *testing.T) {
+	request := httptest.NewRequest(http.MethodOptions, "/users", nil)
+	request.Header.Set("Origin", "https://app.example.com")
+	request.Header.Set("Access-Control-Request-Method", "PUT")
+
+	responseRecorder := httptest.NewRecorder()
+	newCORSTestRouter(testCORS).ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusNoContent, responseRecorder.Code)
+	assert.Equal(t, "https://app.example.com", responseRecorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", responseRecorder.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "GET, PUT", responseRecorder.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, If-Match", responseRecorder.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", responseRecorder.Header().Get("Access-Control-Max-Age"))
+}
+
+func Test_CORS_Should_Expose_Headers_On_Requests_From_Allowed_Origins(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/users", nil)
+	request.Header.Set("Origin", "https://app.example.com")
+
+	responseRecorder := httptest.NewRecorder()
+	newCORSTestRouter(testCORS).ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, "https://app.example.com", responseRecorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "ETag", responseRecorder.Header().Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, "Origin", responseRecorder.Header().Get("Vary"))
+}
+
+func Test_CORS_Should_Leave_Out_The_Headers_For_Other_Origins(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/users", nil)
+	request.Header.Set("Origin", "https://evil.example.com")
+
+	responseRecorder := httptest.NewRecorder()
+	newCORSTestRouter(testCORS).ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Empty(t, responseRecorder.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func Test_CORS_Should_Allow_Every_Origin_With_A_Wildcard(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/users", nil)
+	request.Header.Set("Origin", "https://anywhere.example.com")
+
+	responseRecorder := httptest.NewRecorder()
+	newCORSTestRouter(config.CORS{AllowedOrigins: []string{"*"}}).ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, "*", responseRecorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, responseRecorder.Header().Get("Access-Control-Allow-Credentials"))
+}
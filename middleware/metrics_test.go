@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/metrics"
+)
+
+func Test_Metrics_Should_Label_Requests_With_The_Route_Template(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/users/:id", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	for _, path := range []string{"/users/1", "/users/2", "/unknown/3"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, uint64(2), histogramCount(t, "/users/:id", "200"))
+	assert.Equal(t, uint64(1), histogramCount(t, "unmatched", "404"))
+}
+
+func histogramCount(t *testing.T, route string, status string) uint64 {
+	families, err := metrics.Registry.Gather()
+	assert.Nil(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "http_request_duration_seconds" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			if labels["route"] == route && labels["status"] == status {
+				return metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"regexp"
+	"user-service/logging"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// requestIdPattern bounds the ids accepted from callers, so that they cannot inject arbitrary text into logs.
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+// RequestID takes the request id from the X-Request-ID header, or generates one when it is missing or malformed,
+// and adds it to the response and to the context of the request, from where every log line picks it up.
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestId := ctx.GetHeader(RequestIDHeader)
+		if !requestIdPattern.MatchString(requestId) {
+			requestId = newRequestID()
+		}
+
+		ctx.Header(RequestIDHeader, requestId)
+		ctx.Request = ctx.Request.WithContext(logging.WithRequestID(ctx.Request.Context(), requestId))
+
+		ctx.Next()
+	}
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
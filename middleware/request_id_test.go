@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"user-service/logging"
+)
+
+func newRequestIDTestRouter(output *bytes.Buffer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(), AccessLog(logging.New(output, "info")))
+	router.GET("/users/:id", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	return router
+}
+
+func Test_RequestID_Should_Propagate_The_Id_Of_The_Caller(t *testing.T) {
+	var output bytes.Buffer
+
+	request := httptest.NewRequest(http.MethodGet, "/users/1?email=jane@example.com", nil)
+	request.Header.Set(RequestIDHeader, "abc-123")
+	responseRecorder := httptest.NewRecorder()
+	newRequestIDTestRouter(&output).ServeHTTP(responseRecorder, request)
+
+	var line map[string]interface{}
+	json.Unmarshal(output.Bytes(), &line)
+
+	assert.Equal(t, "abc-123", responseRecorder.Header().Get(RequestIDHeader))
+	assert.Equal(t, "abc-123", line["request_id"])
+	assert.Equal(t, "/users/:id", line["route"])
+	assert.Equal(t, float64(http.StatusOK), line["status"])
+	assert.NotContains(t, output.String(), "jane@example.com")
+}
+
+func Test_RequestID_Should_Replace_Malformed_Ids(t *testing.T) {
+	var output bytes.Buffer
+
+	request := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	request.Header.Set(RequestIDHeader, "forged\nline")
+	responseRecorder := httptest.NewRecorder()
+	newRequestIDTestRouter(&output).ServeHTTP(responseRecorder, request)
+
+	assert.Regexp(t, "^[0-9a-f]{32}$", responseRecorder.Header().Get(RequestIDHeader))
+}
@@ -0,0 +1,184 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log/slog"
+	"strings"
+	"time"
+	"user-service/model"
+	"user-service/repository"
+)
+
+// CollectionName holds one document per applied migration, keyed by its name.
+const CollectionName = "Migration"
+
+// Migration changes the database once. Up must be safe to run again, since instances starting at the same
+// time can both apply a migration before either records it.
+type Migration struct {
+	Name string
+	Up   func(ctx context.Context, database *mongo.Database, logger *slog.Logger) error
+}
+
+// Migrations lists every migration in the order they are applied. New ones go at the end.
+var Migrations = []Migration{
+	{
+		Name: "create-user-revision-indexes",
+		Up: func(ctx context.Context, database *mongo.Database, logger *slog.Logger) error {
+			return repository.NewUserRevisionRepository(database, logger).CreateIndexes(ctx)
+		},
+	},
+	{
+		Name: "create-user-metadata-indexes",
+		Up: func(ctx context.Context, database *mongo.Database, logger *slog.Logger) error {
+			return repository.NewUserRepository(database, logger).CreateIndexes(ctx)
+		},
+	},
+	{
+		Name: "backfill-user-metadata",
+		Up: func(ctx context.Context, database *mongo.Database, logger *slog.Logger) error {
+			count, err := repository.NewUserRepository(database, logger).BackfillMetadata(ctx)
+			logger.InfoContext(ctx, "backfilled the metadata of users", "count", count)
+			return err
+		},
+	},
+	{
+		Name: "create-rate-limit-indexes",
+		Up: func(ctx context.Context, database *mongo.Database, logger *slog.Logger) error {
+			return repository.NewMongoRateLimitStore(database, logger).CreateIndexes(ctx)
+		},
+	},
+	{
+		Name: "create-api-key-indexes",
+		Up: func(ctx context.Context, database *mongo.Database, logger *slog.Logger) error {
+			return repository.NewApiKeyRepository(database, logger).CreateIndexes(ctx)
+		},
+	},
+	{
+		Name: "create-default-tenant",
+		Up: func(ctx context.Context, database *mongo.Database, logger *slog.Logger) error {
+			return repository.NewTenantRepository(database, logger).EnsureDefault(ctx)
+		},
+	},
+	{
+		Name: "backfill-user-tenants",
+		Up: func(ctx context.Context, database *mongo.Database, logger *slog.Logger) error {
+			count, err := repository.NewUserRepository(database, logger).BackfillTenant(ctx, model.DefaultTenantId)
+			logger.InfoContext(ctx, "moved users to the default tenant", "count", count)
+			if err != nil {
+				return err
+			}
+
+			count, err = repository.NewUserRevisionRepository(database, logger).BackfillTenant(ctx, model.DefaultTenantId)
+			logger.InfoContext(ctx, "moved revisions to the default tenant", "count", count)
+			return err
+		},
+	},
+	{
+		// Emails become unique per tenant here, which fails while two users of a tenant share an email.
+		Name: "scope-user-indexes-by-tenant",
+		Up: func(ctx context.Context, database *mongo.Database, logger *slog.Logger) error {
+			return repository.NewUserRepository(database, logger).ScopeIndexesByTenant(ctx)
+		},
+	},
+	{
+		Name: "create-group-indexes",
+		Up: func(ctx context.Context, database *mongo.Database, logger *slog.Logger) error {
+			return repository.NewGroupRepository(database, logger).CreateIndexes(ctx)
+		},
+	},
+	{
+		Name: "delete-orphaned-revisions",
+		Up: func(ctx context.Context, database *mongo.Database, logger *slog.Logger) error {
+			count, err := repository.NewUserRevisionRepository(database, logger).DeleteOrphans(ctx)
+			logger.InfoContext(ctx, "deleted the revisions of deleted users", "count", count)
+			return err
+		},
+	},
+	{
+		Name: "normalize-user-emails",
+		Up: func(ctx context.Context, database *mongo.Database, logger *slog.Logger) error {
+			count, err := repository.NewUserRepository(database, logger).NormalizeEmails(ctx)
+			logger.InfoContext(ctx, "lower cased the emails of users", "count", count)
+			return err
+		},
+	},
+}
+
+type record struct {
+	Name      string    `bson:"_id"`
+	AppliedAt time.Time `bson:"appliedAt"`
+}
+
+// Run applies the pending migrations in order and stops at the first one that fails.
+func Run(ctx context.Context, database *mongo.Database, logger *slog.Logger) error {
+	pending, err := Pending(ctx, database)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range pending {
+		logger.InfoContext(ctx, "applying migration", "migration", migration.Name)
+
+		err := migration.Up(ctx, database, logger)
+		if err != nil {
+			return err
+		}
+
+		_, err = database.Collection(CollectionName).InsertOne(ctx, record{Name: migration.Name, AppliedAt: time.Now().UTC()})
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Pending returns the migrations that have not been applied yet, in order.
+func Pending(ctx context.Context, database *mongo.Database) ([]Migration, error) {
+	cur, err := database.Collection(CollectionName).Find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+
+	var records []record
+	err = cur.All(ctx, &records)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[string]bool{}
+	for _, record := range records {
+		applied[record.Name] = true
+	}
+
+	var pending []Migration
+	for _, migration := range Migrations {
+		if !applied[migration.Name] {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// Check fails while migrations are pending, naming them.
+func Check(ctx context.Context, database *mongo.Database) error {
+	pending, err := Pending(ctx, database)
+	if err != nil {
+		return err
+	}
+
+	if len(pending) > 0 {
+		names := make([]string, len(pending))
+		for i, migration := range pending {
+			names[i] = migration.Name
+		}
+
+		return fmt.Errorf("pending migrations: %s", strings.Join(names, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// The scopes an API key can be granted. Every route of the REST API requires one of them.
+const (
+	ScopeUsersRead          = "users:read"
+	ScopeUsersWrite         = "users:write"
+	ScopeProfileSchemaWrite = "profileSchema:write"
+	ScopeApiKeysManage      = "apiKeys:manage"
+	ScopeTenantsManage      = "tenants:manage"
+)
+
+// Scopes lists every scope in the order they are documented.
+var Scopes = []string{ScopeUsersRead, ScopeUsersWrite, ScopeProfileSchemaWrite, ScopeApiKeysManage, ScopeTenantsManage}
+
+// PlatformScopes reach beyond a single tenant, so they can only be granted to keys that belong to none.
+var PlatformScopes = []string{ScopeProfileSchemaWrite, ScopeTenantsManage}
+
+// ApiKeyEntity stores an API key by the SHA-256 hash of its secret. The prefix is the public part of the key,
+// which finds the entity and tells keys apart in listings. A key with a TenantId only reaches that tenant; one
+// without is a platform key, which may name any tenant.
+type ApiKeyEntity struct {
+	Id         primitive.ObjectID `bson:"_id"`
+	TenantId   string             `bson:"tenantId,omitempty"`
+	Name       string             `bson:"name"`
+	Prefix     string             `bson:"prefix"`
+	Hash       []byte             `bson:"hash"`
+	Scopes     []string           `bson:"scopes"`
+	CreatedAt  time.Time          `bson:"createdAt"`
+	CreatedBy  string             `bson:"createdBy"`
+	ExpiresAt  *time.Time         `bson:"expiresAt,omitempty"`
+	RevokedAt  *time.Time         `bson:"revokedAt,omitempty"`
+	LastUsedAt *time.Time         `bson:"lastUsedAt,omitempty"`
+	// RotatedFrom is the id of the key this one replaced.
+	RotatedFrom *primitive.ObjectID `bson:"rotatedFrom,omitempty"`
+}
+
+type ApiKeyDomainModel struct {
+	Id       string
+	TenantId string
+	Name     string
+	Prefix   string
+	// Key is only known right after the key was created or rotated.
+	Key         string
+	Scopes      []string
+	CreatedAt   time.Time
+	CreatedBy   string
+	ExpiresAt   *time.Time
+	RevokedAt   *time.Time
+	LastUsedAt  *time.Time
+	RotatedFrom string
+}
+
+type CreateApiKeyDomainModel struct {
+	TenantId  string
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+type ApiKeyViewModel struct {
+	Id       string `json:"id" openapi:"readOnly"`
+	TenantId string `json:"tenantId,omitempty"`
+	Name     string `json:"name"`
+	Prefix   string `json:"prefix" openapi:"readOnly"`
+	// Key is only returned when the key is created or rotated, since only its hash is stored.
+	Key         string     `json:"key,omitempty" openapi:"readOnly"`
+	Scopes      []string   `json:"scopes"`
+	CreatedAt   time.Time  `json:"createdAt" openapi:"readOnly"`
+	CreatedBy   string     `json:"createdBy" openapi:"readOnly"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty" openapi:"readOnly"`
+	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty" openapi:"readOnly"`
+	RotatedFrom string     `json:"rotatedFrom,omitempty" openapi:"readOnly"`
+}
+
+type CreateApiKeyViewModel struct {
+	// TenantId defaults to the tenant of the key that creates this one.
+	TenantId  string     `json:"tenantId" validate:"max=63"`
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=users:read users:write profileSchema:write apiKeys:manage tenants:manage"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type RotateApiKeyViewModel struct {
+	// OverlapSeconds is how long the rotated key keeps working next to the new one. It defaults to a day.
+	OverlapSeconds *int `json:"overlapSeconds" validate:"omitempty,min=0,max=2592000"`
+}
+
+// Principal is who an authenticated request was made by. An empty TenantId is a platform key.
+type Principal struct {
+	ApiKeyId string
+	TenantId string
+	Scopes   []string
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// The kinds of members a group can have. Groups nested in a group pass on their members to it, which is why a
+// group can never end up containing itself.
+const (
+	MemberTypeUser  = "user"
+	MemberTypeGroup = "group"
+)
+
+// GroupEntity is a stored group of users and other groups within a tenant. Its members are stored apart from it
+// as GroupMembershipEntity documents, so that large groups can be paged through.
+type GroupEntity struct {
+	Id          primitive.ObjectID `bson:"_id"`
+	TenantId    string             `bson:"tenantId"`
+	Name        string             `bson:"name"`
+	Description string             `bson:"description,omitempty"`
+	CreatedAt   time.Time          `bson:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updatedAt"`
+	CreatedBy   string             `bson:"createdBy"`
+	UpdatedBy   string             `bson:"updatedBy"`
+}
+
+// GroupMembershipEntity makes the user or group MemberId, depending on MemberType, a direct member of GroupId.
+type GroupMembershipEntity struct {
+	Id         primitive.ObjectID `bson:"_id"`
+	TenantId   string             `bson:"tenantId"`
+	GroupId    primitive.ObjectID `bson:"groupId"`
+	MemberType string             `bson:"memberType"`
+	MemberId   primitive.ObjectID `bson:"memberId"`
+	CreatedAt  time.Time          `bson:"createdAt"`
+	CreatedBy  string             `bson:"createdBy"`
+}
+
+type GroupDomainModel struct {
+	Id          string
+	Name        string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CreatedBy   string
+	UpdatedBy   string
+}
+
+type CreateGroupDomainModel struct {
+	Name        string
+	Description string
+}
+
+type UpdateGroupDomainModel struct {
+	Name        *string
+	Description *string
+}
+
+// GroupMembersDomainModel names the users and groups to add to or remove from a group.
+type GroupMembersDomainModel struct {
+	UserIds  []string
+	GroupIds []string
+}
+
+// GroupMemberDomainModel is a direct member of a group along with when and by whom it was added.
+type GroupMemberDomainModel struct {
+	Type    string
+	Id      string
+	AddedAt time.Time
+	AddedBy string
+}
+
+type GroupViewModel struct {
+	Id          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"createdAt" openapi:"readOnly"`
+	UpdatedAt   time.Time `json:"updatedAt" openapi:"readOnly"`
+	CreatedBy   string    `json:"createdBy" openapi:"readOnly"`
+	UpdatedBy   string    `json:"updatedBy" openapi:"readOnly"`
+}
+
+type CreateGroupViewModel struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=1000"`
+}
+
+type UpdateGroupViewModel struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description" validate:"omitempty,max=1000"`
+}
+
+// GroupMembersViewModel names up to 100 users and 100 groups to add to or remove from a group at once.
+type GroupMembersViewModel struct {
+	Users  []string `json:"users" validate:"required_without=Groups,max=100"`
+	Groups []string `json:"groups" validate:"required_without=Users,max=100"`
+}
+
+type GroupMemberViewModel struct {
+	Type    string    `json:"type"`
+	Id      string    `json:"id"`
+	AddedAt time.Time `json:"addedAt"`
+	AddedBy string    `json:"addedBy"`
+}
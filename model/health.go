@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// The statuses of the health check response format for HTTP APIs (draft-inadarei-api-health-check).
+const (
+	HealthPass = "pass"
+	HealthWarn = "warn"
+	HealthFail = "fail"
+)
+
+type HealthViewModel struct {
+	Status string `json:"status" validate:"oneof=pass warn fail"`
+	// Output says why the status is not pass.
+	Output string `json:"output,omitempty"`
+	// Checks is keyed by component and measurement name, such as mongodb:responseTime.
+	Checks map[string][]HealthCheckViewModel `json:"checks,omitempty"`
+}
+
+type HealthCheckViewModel struct {
+	ComponentType string    `json:"componentType"`
+	ObservedValue float64   `json:"observedValue"`
+	ObservedUnit  string    `json:"observedUnit"`
+	Status        string    `json:"status" validate:"oneof=pass warn fail"`
+	Time          time.Time `json:"time"`
+	Output        string    `json:"output,omitempty"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ProfileSchemaEntity is one published version of the profile schema. Versions are never changed or removed, so
+// the highest one is the schema in force.
+type ProfileSchemaEntity struct {
+	Version   int64     `bson:"_id"`
+	Document  string    `bson:"document"`
+	CreatedAt time.Time `bson:"createdAt"`
+	CreatedBy string    `bson:"createdBy"`
+}
+
+type ProfileSchemaDomainModel struct {
+	Version   int64
+	Document  json.RawMessage
+	Indexed   []string
+	CreatedAt time.Time
+	CreatedBy string
+}
+
+type ProfileSchemaViewModel struct {
+	Version   int64           `json:"version" openapi:"readOnly"`
+	Schema    json.RawMessage `json:"schema"`
+	Indexed   []string        `json:"indexed" openapi:"readOnly"`
+	CreatedAt time.Time       `json:"createdAt" openapi:"readOnly"`
+	CreatedBy string          `json:"createdBy" openapi:"readOnly"`
+}
+
+type PublishProfileSchemaViewModel struct {
+	Schema json.RawMessage `json:"schema" validate:"required"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// RateLimitClient identifies who made a request. UserId is the user a request to a /users/:id route is about and
+// ApiKeyId the key it was made with; either is empty when there is none.
+type RateLimitClient struct {
+	IP       string
+	UserId   string
+	ApiKeyId string
+}
+
+// RateLimitViewModel describes the bucket of the policy that is closest to rejecting the requests of a client.
+type RateLimitViewModel struct {
+	Limit     int
+	Remaining int
+	// Period is the window the limit refills over.
+	Period time.Duration
+	// Reset is how long the bucket takes to fill up again.
+	Reset time.Duration
+	// RetryAfter is how long a rejected client has to wait for the next token.
+	RetryAfter time.Duration
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// UserRevisionEntity is a snapshot of a user right after a write. Revision is the version the user reached
+// with that write. Passwords are never snapshotted. TenantId is set by the repository.
+type UserRevisionEntity struct {
+	Id        primitive.ObjectID     `bson:"_id"`
+	TenantId  string                 `bson:"tenantId"`
+	UserId    primitive.ObjectID     `bson:"userId"`
+	Revision  int64                  `bson:"revision"`
+	Name      string                 `bson:"name"`
+	Email     string                 `bson:"email"`
+	Profile   map[string]interface{} `bson:"profile,omitempty"`
+	CreatedAt time.Time              `bson:"createdAt"`
+}
+
+type UserRevisionDomainModel struct {
+	UserId    string
+	Revision  int64
+	Name      string
+	Email     string
+	Profile   map[string]interface{}
+	CreatedAt time.Time
+}
+
+type UserRevisionViewModel struct {
+	Revision  int64                  `json:"revision"`
+	Name      string                 `json:"name"`
+	Email     string                 `json:"email"`
+	Profile   map[string]interface{} `json:"profile,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+// RevisionDiffDomainModel lists the fields that differ between two revisions of a user.
+type RevisionDiffDomainModel struct {
+	From    int64
+	To      int64
+	Changes []FieldChangeDomainModel
+}
+
+// FieldChangeDomainModel is one changed field. Profile attributes are reported as profile.<name> with their
+// values encoded as JSON, and an empty From or To when the attribute was added or removed.
+type FieldChangeDomainModel struct {
+	Field string
+	From  string
+	To    string
+}
+
+type RevisionDiffViewModel struct {
+	From    int64                  `json:"from"`
+	To      int64                  `json:"to"`
+	Changes []FieldChangeViewModel `json:"changes"`
+}
+
+type FieldChangeViewModel struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
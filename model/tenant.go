@@ -0,0 +1,71 @@
+package model
+
+import (
+	"regexp"
+	"time"
+)
+
+// DefaultTenantId is the tenant that the users stored before tenancy was introduced were moved to.
+const DefaultTenantId = "default"
+
+// tenantIdPattern keeps tenant ids usable as DNS labels, so that they can be resolved from subdomains.
+var tenantIdPattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// IsTenantId tells whether id is a valid tenant id: up to 63 lowercase letters, digits and hyphens that neither
+// start nor end with a hyphen.
+func IsTenantId(id string) bool {
+	return tenantIdPattern.MatchString(id)
+}
+
+// TenantEntity is an organization whose users are kept apart from those of every other one. Disabled tenants
+// keep their users but cannot be accessed.
+type TenantEntity struct {
+	Id        string    `bson:"_id"`
+	Name      string    `bson:"name"`
+	Disabled  bool      `bson:"disabled"`
+	CreatedAt time.Time `bson:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt"`
+	CreatedBy string    `bson:"createdBy"`
+	UpdatedBy string    `bson:"updatedBy"`
+}
+
+type TenantDomainModel struct {
+	Id        string
+	Name      string
+	Disabled  bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy string
+	UpdatedBy string
+}
+
+type CreateTenantDomainModel struct {
+	Id   string
+	Name string
+}
+
+type UpdateTenantDomainModel struct {
+	Name     *string
+	Disabled *bool
+}
+
+type TenantViewModel struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Disabled  bool      `json:"disabled"`
+	CreatedAt time.Time `json:"createdAt" openapi:"readOnly"`
+	UpdatedAt time.Time `json:"updatedAt" openapi:"readOnly"`
+	CreatedBy string    `json:"createdBy" openapi:"readOnly"`
+	UpdatedBy string    `json:"updatedBy" openapi:"readOnly"`
+}
+
+type CreateTenantViewModel struct {
+	// Id is also the subdomain the tenant is served on, so it is checked against IsTenantId as well.
+	Id   string `json:"id" validate:"required,max=63"`
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type UpdateTenantViewModel struct {
+	Name     *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Disabled *bool   `json:"disabled"`
+}
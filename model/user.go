@@ -1,46 +1,229 @@
 package model
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+	"time"
+)
 
+// NormalizeEmail lower cases email. Every write path, the uniqueness check and the email filter pass emails
+// through it, so that addresses differing only in case belong to the same user.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(email)
+}
+
+// UserEntity is a stored user. The TenantId and the CreatedAt, UpdatedAt, CreatedBy and UpdatedBy metadata are
+// set by the repository on every write; whatever callers put there is ignored.
 type UserEntity struct {
-	Id       primitive.ObjectID `bson:"_id"`
-	Name     string             `bson:"name"`
-	Email    string             `bson:"email"`
-	Password string             `bson:"password"`
+	Id        primitive.ObjectID     `bson:"_id"`
+	TenantId  string                 `bson:"tenantId"`
+	Name      string                 `bson:"name"`
+	Email     string                 `bson:"email"`
+	Password  string                 `bson:"password"`
+	Version   int64                  `bson:"version"`
+	CreatedAt time.Time              `bson:"createdAt"`
+	UpdatedAt time.Time              `bson:"updatedAt"`
+	CreatedBy string                 `bson:"createdBy"`
+	UpdatedBy string                 `bson:"updatedBy"`
+	Profile   map[string]interface{} `bson:"profile,omitempty"`
 }
 
 type UserDomainModel struct {
-	Id    string
-	Name  string
-	Email string
+	Id        string
+	Name      string
+	Email     string
+	Version   int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy string
+	UpdatedBy string
+	Profile   map[string]interface{}
 }
 
 type UserViewModel struct {
-	Id    string `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Id        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Email     string                 `json:"email"`
+	CreatedAt time.Time              `json:"createdAt" openapi:"readOnly"`
+	UpdatedAt time.Time              `json:"updatedAt" openapi:"readOnly"`
+	CreatedBy string                 `json:"createdBy" openapi:"readOnly"`
+	UpdatedBy string                 `json:"updatedBy" openapi:"readOnly"`
+	Profile   map[string]interface{} `json:"profile,omitempty"`
+}
+
+// PageDomainModel selects a page of users in the given sort order, starting after the cursor After returned
+// with the previous page. A zero Limit selects every remaining user.
+type PageDomainModel struct {
+	After  string
+	Limit  int
+	Filter UserFilterDomainModel
+	Sort   UserSort
+}
+
+// UserFilterDomainModel narrows a user list. Name matches case-insensitively anywhere in the name, Email,
+// CreatedBy and UpdatedBy match exactly, Email after NormalizeEmail, After bounds are inclusive and Before bounds
+// exclusive. Zero fields do not filter. Profile matches indexed profile attributes exactly; the controller passes
+// the raw query strings and the service converts them to the attribute types before they reach the repository.
+type UserFilterDomainModel struct {
+	Name          string
+	Email         string
+	CreatedBy     string
+	UpdatedBy     string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+	Profile       map[string]interface{}
+}
+
+const (
+	SortById        = "id"
+	SortByCreatedAt = "createdAt"
+	SortByUpdatedAt = "updatedAt"
+)
+
+// UserSort orders a user list by one of the Sort constants, ties broken by id. The zero value sorts by id.
+type UserSort struct {
+	Field      string
+	Descending bool
+}
+
+// UserCursor is the position of the last user of a page. Time holds that user's value of the sort field when
+// sorting by a timestamp.
+type UserCursor struct {
+	Id   primitive.ObjectID
+	Time time.Time
 }
 
 type CreateUserViewModel struct {
-	Name     string `json:"name" validate:"required"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Name     string                 `json:"name" validate:"required"`
+	Email    string                 `json:"email" validate:"required,email"`
+	Password string                 `json:"password" validate:"required"`
+	Profile  map[string]interface{} `json:"profile"`
 }
 
 type CreateUserDomainModel struct {
 	Name     string
 	Email    string
 	Password string
+	Profile  map[string]interface{}
 }
 
+// UpdateUserViewModel is a partial update. Profile is merged into the stored profile, with null removing an
+// attribute.
 type UpdateUserViewModel struct {
-	Name     *string `json:"name"`
-	Email    *string `json:"email" validate:"email"`
-	Password *string `json:"password"`
+	Name     *string                `json:"name"`
+	Email    *string                `json:"email" validate:"omitempty,email"`
+	Password *string                `json:"password"`
+	Profile  map[string]interface{} `json:"profile"`
 }
 
 type UpdateUserDomainModel struct {
 	Name     *string
 	Email    *string
 	Password *string
+	Profile  map[string]interface{}
+}
+
+type ReplaceUserViewModel struct {
+	Name     string                 `json:"name" validate:"required"`
+	Email    string                 `json:"email" validate:"required,email"`
+	Password string                 `json:"password" validate:"required"`
+	Profile  map[string]interface{} `json:"profile"`
+}
+
+type ReplaceUserDomainModel struct {
+	Name     string
+	Email    string
+	Password string
+	Profile  map[string]interface{}
+}
+
+// Precondition carries the versions a client expects a user to be at, as parsed from an If-Match header.
+// AnyVersion is set for "If-Match: *", which only requires the user to exist.
+type Precondition struct {
+	AnyVersion bool
+	Versions   []int64
+}
+
+// ProblemViewModel is an RFC 7807 problem details document.
+type ProblemViewModel struct {
+	Type     string                `json:"type"`
+	Title    string                `json:"title"`
+	Status   int                   `json:"status"`
+	Detail   string                `json:"detail"`
+	Instance string                `json:"instance,omitempty"`
+	Errors   []FieldErrorViewModel `json:"errors,omitempty"`
+}
+
+type FieldErrorViewModel struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type BatchIdsViewModel struct {
+	Ids []string `json:"ids" validate:"required,min=1,max=100"`
+}
+
+type BatchGetUsersViewModel struct {
+	Users   []UserViewModel `json:"users"`
+	Missing []string        `json:"missing"`
+}
+
+type BatchUpdateUsersViewModel struct {
+	Updates []BatchUpdateUserViewModel `json:"updates" validate:"required,min=1,max=100,dive"`
+}
+
+type BatchUpdateUserViewModel struct {
+	Id       string                 `json:"id" validate:"required"`
+	Name     *string                `json:"name"`
+	Email    *string                `json:"email" validate:"omitempty,email"`
+	Password *string                `json:"password"`
+	Profile  map[string]interface{} `json:"profile"`
+}
+
+type BatchUpdateUserDomainModel struct {
+	Id     string
+	Update UpdateUserDomainModel
+}
+
+// UserUpdateEntity is one update of a bulk write.
+type UserUpdateEntity struct {
+	Id     primitive.ObjectID
+	Update UpdateUserDomainModel
+}
+
+// BatchResultDomainModel is the outcome of one item of a batch. User is only set when Err is nil and the
+// operation returns the user.
+type BatchResultDomainModel struct {
+	Id   string
+	User *UserDomainModel
+	Err  error
+}
+
+type BatchResultsViewModel struct {
+	Results []BatchResultViewModel `json:"results"`
+}
+
+// BatchResultViewModel reports one item of a batch with the status it would have had as a single request.
+type BatchResultViewModel struct {
+	Id     string            `json:"id"`
+	Status int               `json:"status"`
+	User   *UserViewModel    `json:"user,omitempty"`
+	Error  *ProblemViewModel `json:"error,omitempty"`
+}
+
+type ImportJobViewModel struct {
+	Id          string     `json:"id"`
+	Status      string     `json:"status"`
+	Format      string     `json:"format"`
+	DryRun      bool       `json:"dryRun"`
+	OnError     string     `json:"onError"`
+	Processed   int        `json:"processed"`
+	Succeeded   int        `json:"succeeded"`
+	Failed      int        `json:"failed"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+	ErrorReport string     `json:"errorReport"`
 }
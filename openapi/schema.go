@@ -0,0 +1,132 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaFor derives a JSON schema from a view model, turning its validation tags into schema constraints.
+func schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if t == rawMessageType {
+		return &Schema{Type: "object"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" || field.PkgPath != "" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			property := schemaFor(field.Type)
+			property.ReadOnly = field.Tag.Get("openapi") == "readOnly"
+			if applyValidationRules(property, field.Tag.Get("validate")) {
+				schema.Required = append(schema.Required, name)
+			}
+
+			schema.Properties[name] = property
+		}
+
+		return schema
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		return &Schema{Type: "string"}
+	}
+}
+
+// applyValidationRules copies the validator rules that have a JSON schema equivalent onto the schema and
+// reports whether the field is required.
+func applyValidationRules(schema *Schema, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, param = rule[:i], rule[i+1:]
+		}
+
+		switch name {
+		case "dive":
+			// The rules after dive apply to the items of a slice.
+			if _, itemRules, found := strings.Cut(tag, "dive,"); found && schema.Items != nil {
+				applyValidationRules(schema.Items, itemRules)
+			}
+			return required
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "len":
+			setBound(schema, param, true, true)
+		case "min", "gte":
+			setBound(schema, param, true, false)
+		case "max", "lte":
+			setBound(schema, param, false, true)
+		}
+	}
+
+	return required
+}
+
+func setBound(schema *Schema, param string, lower bool, upper bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	if schema.Type == "string" || schema.Type == "array" {
+		length := int(value)
+		if lower {
+			schema.MinLength = &length
+		}
+		if upper {
+			schema.MaxLength = &length
+		}
+		return
+	}
+
+	if lower {
+		schema.Minimum = &value
+	}
+	if upper {
+		schema.Maximum = &value
+	}
+}
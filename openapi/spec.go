@@ -0,0 +1,984 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"user-service/avatar"
+	"user-service/model"
+	"user-service/profile"
+)
+
+const (
+	jsonContentType    = "application/json"
+	problemContentType = "application/problem+json"
+	healthContentType  = "application/health+json"
+)
+
+const apiKeySecurityScheme = "apiKey"
+
+// NewDocument describes every route the service registers. Schemas are generated from the view models in the
+// model package so the document cannot drift from the payloads the controllers bind and render.
+func NewDocument() *Document {
+	document := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: "User API", Version: "1.0.0"},
+		Paths:   map[string]*PathItem{},
+		Components: Components{Schemas: map[string]*Schema{
+			"User":                 schemaFor(reflect.TypeOf(model.UserViewModel{})),
+			"CreateUser":           schemaFor(reflect.TypeOf(model.CreateUserViewModel{})),
+			"UpdateUser":           schemaFor(reflect.TypeOf(model.UpdateUserViewModel{})),
+			"ReplaceUser":          schemaFor(reflect.TypeOf(model.ReplaceUserViewModel{})),
+			"BatchIds":             schemaFor(reflect.TypeOf(model.BatchIdsViewModel{})),
+			"BatchGetUsers":        schemaFor(reflect.TypeOf(model.BatchGetUsersViewModel{})),
+			"BatchUpdateUsers":     schemaFor(reflect.TypeOf(model.BatchUpdateUsersViewModel{})),
+			"BatchResults":         schemaFor(reflect.TypeOf(model.BatchResultsViewModel{})),
+			"UserRevision":         schemaFor(reflect.TypeOf(model.UserRevisionViewModel{})),
+			"RevisionDiff":         schemaFor(reflect.TypeOf(model.RevisionDiffViewModel{})),
+			"ImportJob":            schemaFor(reflect.TypeOf(model.ImportJobViewModel{})),
+			"ProfileSchema":        schemaFor(reflect.TypeOf(model.ProfileSchemaViewModel{})),
+			"PublishProfileSchema": schemaFor(reflect.TypeOf(model.PublishProfileSchemaViewModel{})),
+			"Problem":              schemaFor(reflect.TypeOf(model.ProblemViewModel{})),
+			"Health":               schemaFor(reflect.TypeOf(model.HealthViewModel{})),
+			"ApiKey":               schemaFor(reflect.TypeOf(model.ApiKeyViewModel{})),
+			"CreateApiKey":         schemaFor(reflect.TypeOf(model.CreateApiKeyViewModel{})),
+			"RotateApiKey":         schemaFor(reflect.TypeOf(model.RotateApiKeyViewModel{})),
+			"Tenant":               schemaFor(reflect.TypeOf(model.TenantViewModel{})),
+			"CreateTenant":         schemaFor(reflect.TypeOf(model.CreateTenantViewModel{})),
+			"UpdateTenant":         schemaFor(reflect.TypeOf(model.UpdateTenantViewModel{})),
+			"Group":                schemaFor(reflect.TypeOf(model.GroupViewModel{})),
+			"CreateGroup":          schemaFor(reflect.TypeOf(model.CreateGroupViewModel{})),
+			"UpdateGroup":          schemaFor(reflect.TypeOf(model.UpdateGroupViewModel{})),
+			"GroupMembers":         schemaFor(reflect.TypeOf(model.GroupMembersViewModel{})),
+			"GroupMember":          schemaFor(reflect.TypeOf(model.GroupMemberViewModel{})),
+		},
+			SecuritySchemes: map[string]*SecurityScheme{
+				apiKeySecurityScheme: {
+					Type: "apiKey",
+					In:   "header",
+					Name: "Authorization",
+					Description: "An API key sent as ApiKey <key>. Every route of the users and groups requires users:read or users:write, " +
+						"and publishing the profile schema requires profileSchema:write. Requests without a key are anonymous " +
+						"and may only read and write users, and only if the service does not require authentication, which it does by default. " +
+						"A key that belongs to a tenant only reaches that tenant and can only be granted the scopes of one tenant",
+				},
+			},
+		},
+	}
+
+	document.add(http.MethodGet, "/users", &Operation{
+		OperationId: "listUsers",
+		Summary:     "List users, ordered by id unless sorted otherwise",
+		Parameters: append([]Parameter{acceptLanguageParameter(), {
+			Name:        "limit",
+			In:          "query",
+			Description: "The maximum number of users to return. Every user is returned when omitted",
+			Schema:      &Schema{Type: "integer", Minimum: float(1), Maximum: float(100)},
+		}, {
+			Name:        "after",
+			In:          "query",
+			Description: "Only return users after this cursor, as given by the next link. It only fits the sort it came from",
+			Schema:      &Schema{Type: "string"},
+		}, {
+			Name:        "sort",
+			In:          "query",
+			Description: "The field to order by, descending when prefixed with -. Ties are ordered by id. Defaults to id",
+			Schema:      &Schema{Type: "string", Enum: []string{"id", "-id", "createdAt", "-createdAt", "updatedAt", "-updatedAt"}},
+		}}, userFilterParameters()...),
+		Responses: responses(
+			withHeader(
+				jsonResponse(http.StatusOK, "The users", &Schema{Type: "array", Items: ref("User")}),
+				"Link", `The next page as <url>; rel="next", absent on the last page`,
+			),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodGet, "/users/export", &Operation{
+		OperationId: "exportUsers",
+		Summary:     "Stream every user matching the filters as a file, ordered by id",
+		Parameters: append([]Parameter{acceptLanguageParameter(), {
+			Name:        "format",
+			In:          "query",
+			Description: "The file format. Defaults to json",
+			Schema:      &Schema{Type: "string", Enum: []string{"csv", "ndjson", "json"}},
+		}, {
+			Name: "columns",
+			In:   "query",
+			Description: "A comma separated list of the columns to export, in order. Defaults to " +
+				"id,name,email,createdAt,updatedAt,createdBy,updatedBy,profile. CSV exports hold the profile as JSON",
+			Schema: &Schema{Type: "string"},
+		}}, userFilterParameters()...),
+		Responses: responses(
+			&statusResponse{http.StatusOK, &Response{
+				Description: "The users. The body ends abruptly if the export fails midway",
+				Content: map[string]*MediaType{
+					"text/csv":             {Schema: &Schema{Type: "string"}},
+					"application/x-ndjson": {Schema: &Schema{Type: "string"}},
+					jsonContentType:        {Schema: &Schema{Type: "array", Items: &Schema{Type: "object"}}},
+				},
+			}},
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPost, "/users", &Operation{
+		OperationId: "createUser",
+		Summary:     "Create a user",
+		Parameters:  []Parameter{acceptLanguageParameter()},
+		RequestBody: jsonRequestBody("CreateUser"),
+		Responses: responses(
+			withETag(jsonResponse(http.StatusOK, "The created user", ref("User"))),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusConflict),
+			withHeader(problemResponse(http.StatusTooManyRequests), "Retry-After", "The seconds to wait before signing up again"),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodGet, "/users/:id", &Operation{
+		OperationId: "getUser",
+		Summary:     "Get a user by id",
+		Parameters:  []Parameter{idParameter(), acceptLanguageParameter()},
+		Responses: responses(
+			withETag(jsonResponse(http.StatusOK, "The user", ref("User"))),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPatch, "/users/:id", &Operation{
+		OperationId: "updateUser",
+		Summary:     "Update the given fields of a user",
+		Parameters:  []Parameter{idParameter(), acceptLanguageParameter()},
+		RequestBody: jsonRequestBody("UpdateUser"),
+		Responses: responses(
+			withETag(jsonResponse(http.StatusOK, "The updated user", ref("User"))),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusConflict),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPut, "/users/:id", &Operation{
+		OperationId: "replaceUser",
+		Summary:     "Replace a user, creating it when the id does not exist. Ids that cannot be created are not found",
+		Parameters: []Parameter{idParameter(), acceptLanguageParameter(), {
+			Name:        "If-Match",
+			In:          "header",
+			Description: "Only replace the user if it is at one of the given entity tags",
+			Schema:      &Schema{Type: "string"},
+		}},
+		RequestBody: jsonRequestBody("ReplaceUser"),
+		Responses: responses(
+			withETag(jsonResponse(http.StatusOK, "The replaced user", ref("User"))),
+			withLocation(withETag(jsonResponse(http.StatusCreated, "The created user", ref("User")))),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusConflict),
+			problemResponse(http.StatusPreconditionFailed),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodDelete, "/users/:id", &Operation{
+		OperationId: "deleteUser",
+		Summary:     "Delete a user by id",
+		Parameters:  []Parameter{idParameter(), acceptLanguageParameter()},
+		Responses: responses(
+			&statusResponse{http.StatusOK, &Response{Description: "The user was deleted"}},
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodGet, "/users/:id/revisions", &Operation{
+		OperationId: "listUserRevisions",
+		Summary:     "List the recorded revisions of a user, oldest first",
+		Parameters:  []Parameter{idParameter(), acceptLanguageParameter()},
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The revisions", &Schema{Type: "array", Items: ref("UserRevision")}),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodGet, "/users/:id/revisions/:rev", &Operation{
+		OperationId: "getUserRevision",
+		Summary:     "Get a revision of a user",
+		Parameters:  []Parameter{idParameter(), revisionParameter(), acceptLanguageParameter()},
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The revision", ref("UserRevision")),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodGet, "/users/:id/revisions/:rev/diff", &Operation{
+		OperationId: "diffUserRevisions",
+		Summary:     "List the fields that changed between two revisions of a user",
+		Parameters: []Parameter{idParameter(), revisionParameter(), acceptLanguageParameter(), {
+			Name:        "from",
+			In:          "query",
+			Description: "The revision to compare against. Defaults to the revision before rev",
+			Schema:      &Schema{Type: "integer"},
+		}},
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The changed fields", ref("RevisionDiff")),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPost, "/users/:id/revisions/:rev/revert", &Operation{
+		OperationId: "revertUserToRevision",
+		Summary:     "Restore the fields of a revision, recording the result as a new revision",
+		Parameters:  []Parameter{idParameter(), revisionParameter(), acceptLanguageParameter()},
+		Responses: responses(
+			withETag(jsonResponse(http.StatusOK, "The reverted user", ref("User"))),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusConflict),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodGet, "/users/:id/avatar", &Operation{
+		OperationId: "getUserAvatar",
+		Summary:     "Get the avatar of a user as a square thumbnail",
+		Parameters: []Parameter{idParameter(), acceptLanguageParameter(), {
+			Name:        "size",
+			In:          "query",
+			Description: "The edge length in pixels, one of " + avatarSizes() + ". Defaults to " + strconv.Itoa(avatar.DefaultSize),
+			Schema:      &Schema{Type: "integer"},
+		}, {
+			Name:        "If-None-Match",
+			In:          "header",
+			Description: "Answer with 304 if the avatar still has one of the given entity tags",
+			Schema:      &Schema{Type: "string"},
+		}},
+		Responses: responses(
+			withHeader(withHeader(&statusResponse{http.StatusOK, &Response{
+				Description: "The thumbnail in the type the avatar was uploaded as",
+				Content: map[string]*MediaType{
+					"image/png":  {Schema: &Schema{Type: "string", Format: "binary"}},
+					"image/jpeg": {Schema: &Schema{Type: "string", Format: "binary"}},
+				},
+			}}, "ETag", "The version of the thumbnail, usable in If-None-Match"), "Last-Modified", "When the avatar was uploaded"),
+			&statusResponse{http.StatusNotModified, &Response{Description: "The avatar has not changed"}},
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPut, "/users/:id/avatar", &Operation{
+		OperationId: "uploadUserAvatar",
+		Summary:     "Replace the avatar of a user with a PNG or JPEG image of up to " + strconv.Itoa(avatar.MaxFileSize>>20) + " MiB and " + strconv.Itoa(avatar.MaxDimension) + " pixels a side",
+		Parameters:  []Parameter{idParameter(), acceptLanguageParameter()},
+		RequestBody: &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{"multipart/form-data": {Schema: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"avatar": {Type: "string", Format: "binary"}},
+				Required:   []string{"avatar"},
+			}}},
+		},
+		Responses: responses(
+			&statusResponse{http.StatusNoContent, &Response{Description: "The avatar was replaced"}},
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusRequestEntityTooLarge),
+			problemResponse(http.StatusUnsupportedMediaType),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPost, "/users:batchGet", &Operation{
+		OperationId: "batchGetUsers",
+		Summary:     "Get up to 100 users by id in one request",
+		Parameters:  []Parameter{acceptLanguageParameter()},
+		RequestBody: jsonRequestBody("BatchIds"),
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The users that were found and the ids that were not", ref("BatchGetUsers")),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPatch, "/users:batchUpdate", &Operation{
+		OperationId: "batchUpdateUsers",
+		Summary:     "Update the given fields of up to 100 users, reporting each update separately. Emails are stored in lower case",
+		Parameters:  []Parameter{acceptLanguageParameter()},
+		RequestBody: jsonRequestBody("BatchUpdateUsers"),
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The status and updated user or problem of every update, in order", ref("BatchResults")),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPost, "/users:batchDelete", &Operation{
+		OperationId: "batchDeleteUsers",
+		Summary:     "Delete up to 100 users by id, reporting each id separately",
+		Parameters:  []Parameter{acceptLanguageParameter()},
+		RequestBody: jsonRequestBody("BatchIds"),
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The status or problem of every id, in order", ref("BatchResults")),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPost, "/users/import", &Operation{
+		OperationId: "importUsers",
+		Summary:     "Start an asynchronous import of users from a CSV or NDJSON body",
+		Parameters: []Parameter{acceptLanguageParameter(), {
+			Name:        "format",
+			In:          "query",
+			Description: "The format of the body. Taken from the Content-Type when omitted",
+			Schema:      &Schema{Type: "string", Enum: []string{"csv", "ndjson"}},
+		}, {
+			Name:        "dryRun",
+			In:          "query",
+			Description: "Only validate the rows without creating any user",
+			Schema:      &Schema{Type: "boolean"},
+		}, {
+			Name:        "onError",
+			In:          "query",
+			Description: "Whether to skip failing rows or abort the import at the first one. Defaults to skip",
+			Schema:      &Schema{Type: "string", Enum: []string{"skip", "abort"}},
+		}},
+		RequestBody: &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{
+				"text/csv":             {Schema: &Schema{Type: "string"}},
+				"application/x-ndjson": {Schema: &Schema{Type: "string"}},
+			},
+		},
+		Responses: responses(
+			withHeader(
+				jsonResponse(http.StatusAccepted, "The started import job", ref("ImportJob")),
+				"Location", "The URL of the import job",
+			),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusRequestEntityTooLarge),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodGet, "/users/import/:jobId", &Operation{
+		OperationId: "getImportJob",
+		Summary:     "Get the progress of an import job. Finished jobs are kept for a day",
+		Parameters:  []Parameter{jobIdParameter(), acceptLanguageParameter()},
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The import job", ref("ImportJob")),
+			problemResponse(http.StatusNotFound),
+		),
+	})
+	document.add(http.MethodGet, "/users/import/:jobId/errors", &Operation{
+		OperationId: "getImportErrorReport",
+		Summary:     "Download the rows a finished import job rejected. The report is kept as long as the job",
+		Parameters:  []Parameter{jobIdParameter(), acceptLanguageParameter()},
+		Responses: responses(
+			&statusResponse{http.StatusOK, &Response{
+				Description: "A CSV report with the row, email, field, rule and message of every problem",
+				Content:     map[string]*MediaType{"text/csv": {Schema: &Schema{Type: "string"}}},
+			}},
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodGet, "/profile-schema", &Operation{
+		OperationId: "getProfileSchema",
+		Summary:     "Get the JSON Schema that user profiles are validated against",
+		Parameters:  []Parameter{acceptLanguageParameter()},
+		Responses: responses(
+			withETag(jsonResponse(http.StatusOK, "The schema in force", ref("ProfileSchema"))),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPut, "/profile-schema", &Operation{
+		OperationId: "publishProfileSchema",
+		Summary: "Publish the next version of the profile schema. Top-level properties with \"" + profile.IndexedKeyword +
+			"\": true are indexed and can be filtered on. Stored profiles are checked against it the next time they change",
+		Parameters: []Parameter{acceptLanguageParameter(), {
+			Name:        "If-Match",
+			In:          "header",
+			Description: "Only publish if the schema in force is at one of the given entity tags",
+			Schema:      &Schema{Type: "string"},
+		}},
+		RequestBody: jsonRequestBody("PublishProfileSchema"),
+		Responses: responses(
+			withETag(jsonResponse(http.StatusOK, "The published schema", ref("ProfileSchema"))),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusPreconditionFailed),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPost, "/apikeys", &Operation{
+		OperationId: "createApiKey",
+		Summary:     "Create an API key. Keys that belong to a tenant can only create keys of that tenant, and keys can only grant the scopes they hold themselves. The key is only returned in this response",
+		Parameters:  []Parameter{acceptLanguageParameter()},
+		RequestBody: jsonRequestBody("CreateApiKey"),
+		Responses: responses(
+			jsonResponse(http.StatusCreated, "The created API key", ref("ApiKey")),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusUnauthorized),
+			problemResponse(http.StatusForbidden),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+		Security: requiresScope(model.ScopeApiKeysManage),
+	})
+	document.add(http.MethodGet, "/apikeys", &Operation{
+		OperationId: "listApiKeys",
+		Summary:     "List the API keys of the tenant of the calling key, or every API key for keys without a tenant, including the revoked and expired ones, newest first",
+		Parameters:  []Parameter{acceptLanguageParameter()},
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The API keys without their keys", &Schema{Type: "array", Items: ref("ApiKey")}),
+			problemResponse(http.StatusUnauthorized),
+			problemResponse(http.StatusForbidden),
+			problemResponse(http.StatusInternalServerError),
+		),
+		Security: requiresScope(model.ScopeApiKeysManage),
+	})
+	document.add(http.MethodDelete, "/apikeys/:id", &Operation{
+		OperationId: "revokeApiKey",
+		Summary:     "Revoke an API key, which stops it from working at once",
+		Parameters:  []Parameter{acceptLanguageParameter(), apiKeyIdParameter()},
+		Responses: responses(
+			&statusResponse{http.StatusNoContent, &Response{Description: "The API key is revoked"}},
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusUnauthorized),
+			problemResponse(http.StatusForbidden),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+		Security: requiresScope(model.ScopeApiKeysManage),
+	})
+	document.add(http.MethodPost, "/apikeys/:id/rotate", &Operation{
+		OperationId: "rotateApiKey",
+		Summary:     "Replace an API key with a new one of the same name, scopes and expiry, which requires holding every scope of the key. The old key keeps working for the overlap",
+		Parameters:  []Parameter{acceptLanguageParameter(), apiKeyIdParameter()},
+		RequestBody: &RequestBody{
+			Content: map[string]*MediaType{jsonContentType: {Schema: ref("RotateApiKey")}},
+		},
+		Responses: responses(
+			jsonResponse(http.StatusCreated, "The new API key", ref("ApiKey")),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusUnauthorized),
+			problemResponse(http.StatusForbidden),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+		Security: requiresScope(model.ScopeApiKeysManage),
+	})
+	document.add(http.MethodGet, "/users/:id/groups", &Operation{
+		OperationId: "listUserGroups",
+		Summary:     "List the groups a user is a direct member of, in the order the user was added to them",
+		Parameters:  append([]Parameter{idParameter(), acceptLanguageParameter()}, pageParameters("groups")...),
+		Responses: responses(
+			withHeader(
+				jsonResponse(http.StatusOK, "The groups", &Schema{Type: "array", Items: ref("Group")}),
+				"Link", `The next page as <url>; rel="next", absent on the last page`,
+			),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPost, "/groups", &Operation{
+		OperationId: "createGroup",
+		Summary:     "Create a group of users and other groups",
+		Parameters:  []Parameter{acceptLanguageParameter()},
+		RequestBody: jsonRequestBody("CreateGroup"),
+		Responses: responses(
+			withHeader(jsonResponse(http.StatusCreated, "The created group", ref("Group")), "Location", "The URL of the created group"),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodGet, "/groups", &Operation{
+		OperationId: "listGroups",
+		Summary:     "List groups, ordered by id",
+		Parameters:  append([]Parameter{acceptLanguageParameter()}, pageParameters("groups")...),
+		Responses: responses(
+			withHeader(
+				jsonResponse(http.StatusOK, "The groups", &Schema{Type: "array", Items: ref("Group")}),
+				"Link", `The next page as <url>; rel="next", absent on the last page`,
+			),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodGet, "/groups/:id", &Operation{
+		OperationId: "getGroup",
+		Summary:     "Get a group by id",
+		Parameters:  []Parameter{groupIdParameter(), acceptLanguageParameter()},
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The group", ref("Group")),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPatch, "/groups/:id", &Operation{
+		OperationId: "updateGroup",
+		Summary:     "Update the given fields of a group",
+		Parameters:  []Parameter{groupIdParameter(), acceptLanguageParameter()},
+		RequestBody: jsonRequestBody("UpdateGroup"),
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The updated group", ref("Group")),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodDelete, "/groups/:id", &Operation{
+		OperationId: "deleteGroup",
+		Summary:     "Delete a group by id, taking it out of the groups it belonged to. Its members are kept",
+		Parameters:  []Parameter{groupIdParameter(), acceptLanguageParameter()},
+		Responses: responses(
+			&statusResponse{http.StatusOK, &Response{Description: "The group was deleted"}},
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodGet, "/groups/:id/members", &Operation{
+		OperationId: "listGroupMembers",
+		Summary:     "List the users and groups that are direct members of a group, in the order they were added",
+		Parameters:  append([]Parameter{groupIdParameter(), acceptLanguageParameter()}, pageParameters("members")...),
+		Responses: responses(
+			withHeader(
+				jsonResponse(http.StatusOK, "The members", &Schema{Type: "array", Items: ref("GroupMember")}),
+				"Link", `The next page as <url>; rel="next", absent on the last page`,
+			),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPost, "/groups/:id/members:batchAdd", &Operation{
+		OperationId: "addGroupMembers",
+		Summary: "Add up to 100 users and 100 groups to a group, reporting each id separately, users first. " +
+			"Groups that already contain the group, directly or through other groups, are rejected with 409",
+		Parameters:  []Parameter{groupIdParameter(), acceptLanguageParameter()},
+		RequestBody: jsonRequestBody("GroupMembers"),
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The status or problem of every id, in order", ref("BatchResults")),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPost, "/groups/:id/members:batchRemove", &Operation{
+		OperationId: "removeGroupMembers",
+		Summary:     "Remove up to 100 users and 100 groups from a group. Ids that are no members are ignored",
+		Parameters:  []Parameter{groupIdParameter(), acceptLanguageParameter()},
+		RequestBody: jsonRequestBody("GroupMembers"),
+		Responses: responses(
+			&statusResponse{http.StatusOK, &Response{Description: "The members were removed"}},
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+	})
+	document.add(http.MethodPost, "/tenants", &Operation{
+		OperationId: "createTenant",
+		Summary:     "Create a tenant, whose users are kept apart from those of every other tenant",
+		Parameters:  []Parameter{acceptLanguageParameter()},
+		RequestBody: jsonRequestBody("CreateTenant"),
+		Responses: responses(
+			withHeader(jsonResponse(http.StatusCreated, "The created tenant", ref("Tenant")), "Location", "The URL of the created tenant"),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusUnauthorized),
+			problemResponse(http.StatusForbidden),
+			problemResponse(http.StatusConflict),
+			problemResponse(http.StatusInternalServerError),
+		),
+		Security: requiresScope(model.ScopeTenantsManage),
+	})
+	document.add(http.MethodGet, "/tenants", &Operation{
+		OperationId: "listTenants",
+		Summary:     "List every tenant including the disabled ones, ordered by id",
+		Parameters:  []Parameter{acceptLanguageParameter()},
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The tenants", &Schema{Type: "array", Items: ref("Tenant")}),
+			problemResponse(http.StatusUnauthorized),
+			problemResponse(http.StatusForbidden),
+			problemResponse(http.StatusInternalServerError),
+		),
+		Security: requiresScope(model.ScopeTenantsManage),
+	})
+	document.add(http.MethodGet, "/tenants/:id", &Operation{
+		OperationId: "getTenant",
+		Summary:     "Get a tenant by id",
+		Parameters:  []Parameter{acceptLanguageParameter(), tenantIdParameter()},
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The tenant", ref("Tenant")),
+			problemResponse(http.StatusUnauthorized),
+			problemResponse(http.StatusForbidden),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+		Security: requiresScope(model.ScopeTenantsManage),
+	})
+	document.add(http.MethodPatch, "/tenants/:id", &Operation{
+		OperationId: "updateTenant",
+		Summary:     "Rename a tenant, or disable it, which keeps its users but rejects every request for it",
+		Parameters:  []Parameter{acceptLanguageParameter(), tenantIdParameter()},
+		RequestBody: jsonRequestBody("UpdateTenant"),
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The updated tenant", ref("Tenant")),
+			problemResponse(http.StatusBadRequest),
+			problemResponse(http.StatusUnauthorized),
+			problemResponse(http.StatusForbidden),
+			problemResponse(http.StatusNotFound),
+			problemResponse(http.StatusInternalServerError),
+		),
+		Security: requiresScope(model.ScopeTenantsManage),
+	})
+	document.add(http.MethodGet, "/healthz", &Operation{
+		OperationId: "getLiveness",
+		Summary:     "Check that the process is alive, without checking its dependencies",
+		Responses: responses(
+			healthResponse(http.StatusOK, "The process is alive"),
+		),
+	})
+	document.add(http.MethodGet, "/readyz", &Operation{
+		OperationId: "getReadiness",
+		Summary:     "Check that the service can take traffic: the database answers, its migrations are applied and the service is not shutting down",
+		Responses: responses(
+			healthResponse(http.StatusOK, "The service is ready, possibly with optional dependencies failing"),
+			healthResponse(http.StatusServiceUnavailable, "The service is not ready, the output names why"),
+		),
+	})
+	document.add(http.MethodGet, "/health", &Operation{
+		OperationId: "getHealth",
+		Summary:     "Report the status and response time of every dependency",
+		Responses: responses(
+			healthResponse(http.StatusOK, "Every required dependency passes"),
+			healthResponse(http.StatusServiceUnavailable, "A required dependency fails or the service is shutting down"),
+		),
+	})
+	document.add(http.MethodPost, "/graphql", &Operation{
+		OperationId: "queryGraphQL",
+		Summary:     "Run a GraphQL query or mutation against the user schema",
+		RequestBody: &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{jsonContentType: {Schema: &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"query":         {Type: "string"},
+					"operationName": {Type: "string"},
+					"variables":     {Type: "object"},
+				},
+				Required: []string{"query"},
+			}}},
+		},
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The GraphQL response, including any errors", &Schema{Type: "object"}),
+		),
+	})
+	document.add(http.MethodGet, "/metrics", &Operation{
+		OperationId: "getMetrics",
+		Summary:     "Metrics of the HTTP API, the user service, the database calls and the Go runtime",
+		Responses: responses(
+			&statusResponse{http.StatusOK, &Response{
+				Description: "The metrics in the Prometheus text format",
+				Content:     map[string]*MediaType{"text/plain": {Schema: &Schema{Type: "string"}}},
+			}},
+		),
+	})
+	document.add(http.MethodGet, "/openapi.json", &Operation{
+		OperationId: "getOpenAPIDocument",
+		Summary:     "This document",
+		Responses: responses(
+			jsonResponse(http.StatusOK, "The OpenAPI document", &Schema{Type: "object"}),
+		),
+	})
+	document.add(http.MethodGet, "/docs/*filepath", &Operation{
+		OperationId: "getDocs",
+		Summary:     "Swagger UI for this document",
+		Parameters: []Parameter{{
+			Name:     "filepath",
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		}},
+		Responses: responses(
+			&statusResponse{http.StatusOK, &Response{Description: "A Swagger UI asset"}},
+		),
+	})
+
+	// Every route of the users and groups is confined to one tenant, so they all take the header that names it.
+	for path, pathItem := range document.Paths {
+		if path == "/graphql" || strings.HasPrefix(path, "/users") || strings.HasPrefix(path, "/groups") {
+			for _, operation := range *pathItem {
+				operation.Parameters = append(operation.Parameters, tenantParameter())
+			}
+		}
+	}
+
+	return document
+}
+
+// HasOperation reports whether the document describes the given method on a gin route path.
+func (d *Document) HasOperation(method string, ginPath string) bool {
+	pathItem, ok := d.Paths[ToOpenAPIPath(ginPath)]
+	if !ok {
+		return false
+	}
+
+	_, ok = (*pathItem)[strings.ToLower(method)]
+
+	return ok
+}
+
+func (d *Document) add(method string, ginPath string, operation *Operation) {
+	path := ToOpenAPIPath(ginPath)
+
+	pathItem, ok := d.Paths[path]
+	if !ok {
+		pathItem = &PathItem{}
+		d.Paths[path] = pathItem
+	}
+
+	(*pathItem)[strings.ToLower(method)] = operation
+}
+
+// ToOpenAPIPath rewrites gin's :param and *param segments into OpenAPI {param} templates.
+func ToOpenAPIPath(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+type statusResponse struct {
+	status   int
+	response *Response
+}
+
+func responses(statusResponses ...*statusResponse) map[string]*Response {
+	result := map[string]*Response{}
+	for _, statusResponse := range statusResponses {
+		result[strconv.Itoa(statusResponse.status)] = statusResponse.response
+	}
+
+	return result
+}
+
+func jsonResponse(status int, description string, schema *Schema) *statusResponse {
+	return &statusResponse{status, &Response{
+		Description: description,
+		Content:     map[string]*MediaType{jsonContentType: {Schema: schema}},
+	}}
+}
+
+func healthResponse(status int, description string) *statusResponse {
+	return &statusResponse{status, &Response{
+		Description: description,
+		Content:     map[string]*MediaType{healthContentType: {Schema: ref("Health")}},
+	}}
+}
+
+func problemResponse(status int) *statusResponse {
+	return &statusResponse{status, &Response{
+		Description: http.StatusText(status),
+		Content:     map[string]*MediaType{problemContentType: {Schema: ref("Problem")}},
+	}}
+}
+
+func withETag(statusResponse *statusResponse) *statusResponse {
+	return withHeader(statusResponse, "ETag", "The version of the user, usable in If-Match")
+}
+
+func withLocation(statusResponse *statusResponse) *statusResponse {
+	return withHeader(statusResponse, "Location", "The URL of the created user")
+}
+
+func withHeader(statusResponse *statusResponse, name string, description string) *statusResponse {
+	if statusResponse.response.Headers == nil {
+		statusResponse.response.Headers = map[string]*Header{}
+	}
+
+	statusResponse.response.Headers[name] = &Header{Description: description, Schema: &Schema{Type: "string"}}
+
+	return statusResponse
+}
+
+func jsonRequestBody(schemaName string) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{jsonContentType: {Schema: ref(schemaName)}},
+	}
+}
+
+func userFilterParameters() []Parameter {
+	return []Parameter{{
+		Name:        "name",
+		In:          "query",
+		Description: "Only return users whose name contains this text, ignoring case",
+		Schema:      &Schema{Type: "string"},
+	}, {
+		Name:        "email",
+		In:          "query",
+		Description: "Only return the user with this email",
+		Schema:      &Schema{Type: "string"},
+	}, {
+		Name:        "createdBy",
+		In:          "query",
+		Description: "Only return users created by this actor",
+		Schema:      &Schema{Type: "string"},
+	}, {
+		Name:        "updatedBy",
+		In:          "query",
+		Description: "Only return users last updated by this actor",
+		Schema:      &Schema{Type: "string"},
+	}, {
+		Name:        "createdAfter",
+		In:          "query",
+		Description: "Only return users created at or after this time",
+		Schema:      &Schema{Type: "string", Format: "date-time"},
+	}, {
+		Name:        "createdBefore",
+		In:          "query",
+		Description: "Only return users created before this time",
+		Schema:      &Schema{Type: "string", Format: "date-time"},
+	}, {
+		Name:        "updatedAfter",
+		In:          "query",
+		Description: "Only return users last updated at or after this time",
+		Schema:      &Schema{Type: "string", Format: "date-time"},
+	}, {
+		Name:        "updatedBefore",
+		In:          "query",
+		Description: "Only return users last updated before this time",
+		Schema:      &Schema{Type: "string", Format: "date-time"},
+	}, {
+		Name:        "profile",
+		In:          "query",
+		Description: "Only return users whose profile attributes equal the given values, as profile[name]=value. Only indexed attributes can be filtered on",
+		Style:       "deepObject",
+		Explode:     true,
+		Schema:      &Schema{Type: "object"},
+	}}
+}
+
+func idParameter() Parameter {
+	return Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: "The hex encoded ObjectID of the user",
+		Required:    true,
+		Schema:      &Schema{Type: "string"},
+	}
+}
+
+func revisionParameter() Parameter {
+	return Parameter{
+		Name:        "rev",
+		In:          "path",
+		Description: "The revision number, which is the version the user reached with that write",
+		Required:    true,
+		Schema:      &Schema{Type: "integer"},
+	}
+}
+
+func jobIdParameter() Parameter {
+	return Parameter{
+		Name:        "jobId",
+		In:          "path",
+		Description: "The id of the import job",
+		Required:    true,
+		Schema:      &Schema{Type: "string"},
+	}
+}
+
+func apiKeyIdParameter() Parameter {
+	return Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: "The id of the API key",
+		Required:    true,
+		Schema:      &Schema{Type: "string"},
+	}
+}
+
+func groupIdParameter() Parameter {
+	return Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: "The hex encoded ObjectID of the group",
+		Required:    true,
+		Schema:      &Schema{Type: "string"},
+	}
+}
+
+// pageParameters are the limit and after parameters of the lists of groups and members, which are paged by id.
+func pageParameters(noun string) []Parameter {
+	return []Parameter{{
+		Name:        "limit",
+		In:          "query",
+		Description: "The maximum number of " + noun + " to return. Every one is returned when omitted",
+		Schema:      &Schema{Type: "integer", Minimum: float(1), Maximum: float(100)},
+	}, {
+		Name:        "after",
+		In:          "query",
+		Description: "Only return " + noun + " after this cursor, as given by the next link",
+		Schema:      &Schema{Type: "string"},
+	}}
+}
+
+func tenantIdParameter() Parameter {
+	return Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: "The id of the tenant",
+		Required:    true,
+		Schema:      &Schema{Type: "string"},
+	}
+}
+
+func tenantParameter() Parameter {
+	return Parameter{
+		Name: "X-Tenant-ID",
+		In:   "header",
+		Description: "The tenant to serve the request for, when the API key belongs to none. Falls back to the subdomain " +
+			"and then the default tenant. Naming another tenant than that of the API key is forbidden, and requests without an " +
+			"API key can only name the default tenant",
+		Schema: &Schema{Type: "string"},
+	}
+}
+
+func requiresScope(scope string) []map[string][]string {
+	return []map[string][]string{{apiKeySecurityScheme: {scope}}}
+}
+
+func acceptLanguageParameter() Parameter {
+	return Parameter{
+		Name:        "Accept-Language",
+		In:          "header",
+		Description: "The preferred language of error messages",
+		Schema:      &Schema{Type: "string"},
+	}
+}
+
+func float(value float64) *float64 {
+	return &value
+}
+
+// avatarSizes lists the sizes avatars are served in for descriptions.
+func avatarSizes() string {
+	sizes := make([]string, len(avatar.Sizes))
+	for i, size := range avatar.Sizes {
+		sizes[i] = strconv.Itoa(size)
+	}
+
+	return strings.Join(sizes, ", ")
+}
+
+func ref(schemaName string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + schemaName}
+}
@@ -0,0 +1,72 @@
+package openapi
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"user-service/model"
+)
+
+func Test_SchemaFor_Should_Turn_Validation_Tags_Into_Constraints(t *testing.T) {
+	type viewModel struct {
+		Name     string  `json:"name" validate:"required,min=2,max=50"`
+		Email    *string `json:"email" validate:"email"`
+		Role     string  `json:"role" validate:"oneof=admin member"`
+		Age      int     `json:"age" validate:"gte=18"`
+		internal string
+	}
+
+	schema := schemaFor(reflect.TypeOf(viewModel{}))
+
+	assert.Equal(t, []string{"name"}, schema.Required)
+	assert.Equal(t, 2, *schema.Properties["name"].MinLength)
+	assert.Equal(t, 50, *schema.Properties["name"].MaxLength)
+	assert.Equal(t, "email", schema.Properties["email"].Format)
+	assert.Equal(t, []string{"admin", "member"}, schema.Properties["role"].Enum)
+	assert.Equal(t, 18.0, *schema.Properties["age"].Minimum)
+	assert.NotContains(t, schema.Properties, "internal")
+}
+
+func Test_NewDocument_Should_Generate_User_Schemas_From_View_Models(t *testing.T) {
+	document := NewDocument()
+
+	createUser := document.Components.Schemas["CreateUser"]
+
+	assert.ElementsMatch(t, []string{"name", "email", "password"}, createUser.Required)
+	assert.Equal(t, "email", createUser.Properties["email"].Format)
+	assert.Empty(t, document.Components.Schemas["UpdateUser"].Required)
+}
+
+func Test_SchemaFor_Should_Mark_Server_Managed_Fields_As_Read_Only(t *testing.T) {
+	schema := schemaFor(reflect.TypeOf(model.UserViewModel{}))
+
+	assert.True(t, schema.Properties["createdAt"].ReadOnly)
+	assert.Equal(t, "date-time", schema.Properties["createdAt"].Format)
+	assert.True(t, schema.Properties["updatedBy"].ReadOnly)
+	assert.False(t, schema.Properties["email"].ReadOnly)
+}
+
+func Test_NewDocument_Should_Marshal_As_OpenAPI_3_1(t *testing.T) {
+	body, err := json.Marshal(NewDocument())
+
+	var document map[string]interface{}
+	json.Unmarshal(body, &document)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "3.1.0", document["openapi"])
+	assert.Contains(t, document["paths"], "/users/{id}")
+}
+
+func Test_ToOpenAPIPath_Should_Rewrite_Gin_Parameters(t *testing.T) {
+	assert.Equal(t, "/users/{id}", ToOpenAPIPath("/users/:id"))
+	assert.Equal(t, "/docs/{filepath}", ToOpenAPIPath("/docs/*filepath"))
+}
+
+func Test_SchemaFor_Should_Apply_The_Rules_After_Dive_To_The_Items(t *testing.T) {
+	scopes := NewDocument().Components.Schemas["CreateApiKey"].Properties["scopes"]
+
+	assert.Equal(t, 1, *scopes.MinLength)
+	assert.Empty(t, scopes.Enum)
+	assert.Equal(t, model.Scopes, scopes.Items.Enum)
+}
@@ -0,0 +1,270 @@
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	errs "user-service/error"
+)
+
+// IndexedKeyword marks a top-level property of a profile schema as filterable. Every indexed property gets a
+// database index, so only properties with a single scalar type can be indexed.
+const IndexedKeyword = "x-indexed"
+
+// schemaURL names the schema inside the compiler. Nothing is loaded from it; an absolute URL keeps the compiler
+// from resolving it against the working directory.
+const schemaURL = "urn:user-service:profile-schema"
+
+var indexableTypes = map[string]bool{"string": true, "integer": true, "number": true, "boolean": true}
+
+// Schema is a compiled profile schema. A nil *Schema stands for no published schema, which accepts any profile
+// and has no indexed attributes.
+type Schema struct {
+	version  int64
+	indexed  map[string]string
+	compiled *jsonschema.Schema
+}
+
+// Compile checks that document is a JSON Schema for a profile object and prepares it for validation. Schemas
+// must be self-contained; references to other documents are refused rather than fetched.
+func Compile(document []byte, version int64) (*Schema, error) {
+	var root map[string]interface{}
+	err := json.Unmarshal(document, &root)
+	if err != nil {
+		return nil, fmt.Errorf("the schema must be a JSON object: %w", err)
+	}
+
+	if root["type"] != "object" {
+		return nil, fmt.Errorf(`the schema must have "type": "object"`)
+	}
+
+	indexed, err := indexedProperties(root)
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.AssertFormat = true
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("%s cannot be loaded, profile schemas must be self-contained", url)
+	}
+
+	err = compiler.AddResource(schemaURL, bytes.NewReader(document))
+	if err != nil {
+		return nil, err
+	}
+
+	compiled, err := compiler.Compile(schemaURL)
+	var schemaError *jsonschema.SchemaError
+	if errors.As(err, &schemaError) {
+		return nil, schemaError.Err
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &Schema{version: version, indexed: indexed, compiled: compiled}, nil
+}
+
+func indexedProperties(root map[string]interface{}) (map[string]string, error) {
+	indexed := map[string]string{}
+
+	properties, _ := root["properties"].(map[string]interface{})
+	for name, property := range properties {
+		property, _ := property.(map[string]interface{})
+		if flag, _ := property[IndexedKeyword].(bool); !flag {
+			continue
+		}
+
+		propertyType, _ := property["type"].(string)
+		if !indexableTypes[propertyType] {
+			return nil, fmt.Errorf("the indexed property %q must have one of the types string, integer, number or boolean", name)
+		}
+		if err := checkKey(name); err != nil {
+			return nil, err
+		}
+
+		indexed[name] = propertyType
+	}
+
+	return indexed, nil
+}
+
+func (s *Schema) Version() int64 {
+	if s == nil {
+		return 0
+	}
+
+	return s.version
+}
+
+// Indexed returns the names of the indexed attributes in alphabetical order.
+func (s *Schema) Indexed() []string {
+	if s == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(s.indexed))
+	for name := range s.indexed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Validate checks a profile against the schema. It fails with errs.ValidationError wrapped in *errs.FieldErrors,
+// naming every offending attribute as profile.<path>. The details the JSON Schema validator gives are only
+// available in English.
+func (s *Schema) Validate(profile map[string]interface{}) error {
+	var fields []errs.FieldError
+	for key := range profile {
+		if checkKey(key) != nil {
+			fields = append(fields, errs.FieldError{Field: "profile." + key, Rule: "key"})
+		}
+	}
+
+	if len(fields) == 0 && s != nil {
+		// Values read back from the database are BSON types, which the validator does not know about.
+		instance, err := normalize(profile)
+		if err != nil {
+			return err
+		}
+
+		err = s.compiled.Validate(instance)
+		validationError, ok := err.(*jsonschema.ValidationError)
+		if err != nil && !ok {
+			return err
+		} else if ok {
+			fields = fieldErrors(validationError)
+		}
+	}
+
+	if len(fields) > 0 {
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+		return &errs.FieldErrors{Err: errs.ValidationError, Fields: fields}
+	}
+
+	return nil
+}
+
+// ParseFilter converts the raw query values of profile filters to the types of their attributes. Only indexed
+// attributes can be filtered on; anything else fails with errs.BadRequestError wrapped in *errs.FieldErrors.
+func (s *Schema) ParseFilter(raw map[string]string) (map[string]interface{}, error) {
+	filter := map[string]interface{}{}
+	var fields []errs.FieldError
+
+	for name, value := range raw {
+		field := "profile[" + name + "]"
+
+		var attributeType string
+		if s != nil {
+			attributeType = s.indexed[name]
+		}
+
+		var err error
+		switch attributeType {
+		case "string":
+			filter[name] = value
+		case "integer":
+			filter[name], err = strconv.ParseInt(value, 10, 64)
+		case "number":
+			filter[name], err = strconv.ParseFloat(value, 64)
+		case "boolean":
+			filter[name], err = strconv.ParseBool(value)
+		default:
+			fields = append(fields, errs.FieldError{Field: field, Rule: IndexedKeyword, Param: name})
+			continue
+		}
+
+		if err != nil {
+			fields = append(fields, errs.FieldError{Field: field, Rule: "type", Param: attributeType})
+		}
+	}
+
+	if len(fields) > 0 {
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+		return nil, &errs.FieldErrors{Err: errs.BadRequestError, Fields: fields}
+	}
+
+	return filter, nil
+}
+
+// checkKey rejects attribute names that the database would read as a path or an operator.
+func checkKey(key string) error {
+	if key == "" || strings.Contains(key, ".") || strings.HasPrefix(key, "$") {
+		return fmt.Errorf("attribute names must not be empty, contain a dot or start with a dollar sign")
+	}
+
+	return nil
+}
+
+func normalize(profile map[string]interface{}) (interface{}, error) {
+	if profile == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	encoded, err := json.Marshal(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	var instance interface{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	err = decoder.Decode(&instance)
+
+	return instance, err
+}
+
+// fieldErrors flattens a validation error to its leaves, which are the failures a client can act on.
+func fieldErrors(validationError *jsonschema.ValidationError) []errs.FieldError {
+	if len(validationError.Causes) == 0 {
+		field := "profile" + strings.ReplaceAll(validationError.InstanceLocation, "/", ".")
+		rule := validationError.KeywordLocation[strings.LastIndex(validationError.KeywordLocation, "/")+1:]
+
+		return []errs.FieldError{{Field: field, Rule: rule, Key: "profileSchema", Param: validationError.Message}}
+	}
+
+	var fields []errs.FieldError
+	for _, cause := range validationError.Causes {
+		fields = append(fields, fieldErrors(cause)...)
+	}
+
+	return fields
+}
+
+// Equal reports whether two attribute values encode to the same JSON, so that numbers read back from the
+// database compare equal to the ones decoded from a request.
+func Equal(a interface{}, b interface{}) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
+
+// Merge applies a patch to a profile the way JSON merge patch treats the top level: attributes set to null are
+// removed and the others replace what was there. The profile itself is left untouched. Merging into a nil
+// profile drops the null attributes of a whole new profile.
+func Merge(profile map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	for key, value := range profile {
+		merged[key] = value
+	}
+
+	for key, value := range patch {
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
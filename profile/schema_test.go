@@ -0,0 +1,115 @@
+package profile
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	errs "user-service/error"
+)
+
+const testSchema = `{
+	"type": "object",
+	"properties": {
+		"department": {"type": "string", "x-indexed": true},
+		"employeeNumber": {"type": "integer", "minimum": 1, "x-indexed": true},
+		"remote": {"type": "boolean", "x-indexed": true},
+		"languages": {"type": "array", "items": {"type": "string"}}
+	},
+	"required": ["department"],
+	"additionalProperties": false
+}`
+
+func Test_Compile_Should_Collect_Indexed_Attributes(t *testing.T) {
+	schema, err := Compile([]byte(testSchema), 3)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), schema.Version())
+	assert.Equal(t, []string{"department", "employeeNumber", "remote"}, schema.Indexed())
+}
+
+func Test_Compile_Should_Reject_Invalid_Schemas(t *testing.T) {
+	documents := []string{
+		`[]`,
+		`{"type": "string"}`,
+		`{"type": "object", "properties": {"tags": {"type": "array", "x-indexed": true}}}`,
+		`{"type": "object", "properties": {"age": {"type": "whole number"}}}`,
+		`{"type": "object", "properties": {"team": {"$ref": "https://example.com/team.json"}}}`,
+	}
+
+	for _, document := range documents {
+		schema, err := Compile([]byte(document), 1)
+
+		assert.Nil(t, schema, document)
+		assert.NotNil(t, err, document)
+	}
+}
+
+func Test_Validate_Should_Report_Every_Offending_Attribute(t *testing.T) {
+	schema, _ := Compile([]byte(testSchema), 1)
+
+	err := schema.Validate(map[string]interface{}{"employeeNumber": 0.0, "languages": []interface{}{"en", 7.0}, "shoeSize": 42.0})
+
+	var fieldErrors *errs.FieldErrors
+	assert.True(t, errors.As(err, &fieldErrors))
+	assert.True(t, errors.Is(err, errs.ValidationError))
+
+	var fields []string
+	for _, field := range fieldErrors.Fields {
+		fields = append(fields, field.Field+" "+field.Rule)
+	}
+	assert.ElementsMatch(t, []string{
+		"profile additionalProperties",
+		"profile required",
+		"profile.employeeNumber minimum",
+		"profile.languages.1 type",
+	}, fields)
+}
+
+func Test_Validate_Should_Accept_Values_Read_Back_From_The_Database(t *testing.T) {
+	schema, _ := Compile([]byte(testSchema), 1)
+
+	err := schema.Validate(map[string]interface{}{"department": "sales", "employeeNumber": int32(7), "languages": primitive.A{"en"}})
+
+	assert.Nil(t, err)
+}
+
+func Test_Validate_Should_Only_Check_Attribute_Names_Without_A_Schema(t *testing.T) {
+	var schema *Schema
+
+	assert.Nil(t, schema.Validate(map[string]interface{}{"anything": []interface{}{1.0}}))
+	assert.True(t, errors.Is(schema.Validate(map[string]interface{}{"a.b": 1.0}), errs.ValidationError))
+	assert.True(t, errors.Is(schema.Validate(map[string]interface{}{"$where": 1.0}), errs.ValidationError))
+}
+
+func Test_ParseFilter_Should_Convert_Values_To_The_Attribute_Types(t *testing.T) {
+	schema, _ := Compile([]byte(testSchema), 1)
+
+	filter, err := schema.ParseFilter(map[string]string{"department": "sales", "employeeNumber": "7", "remote": "true"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"department": "sales", "employeeNumber": int64(7), "remote": true}, filter)
+}
+
+func Test_ParseFilter_Should_Reject_Unindexed_Attributes_And_Mistyped_Values(t *testing.T) {
+	schema, _ := Compile([]byte(testSchema), 1)
+
+	_, err := schema.ParseFilter(map[string]string{"languages": "en", "employeeNumber": "seven"})
+
+	var fieldErrors *errs.FieldErrors
+	assert.True(t, errors.As(err, &fieldErrors))
+	assert.True(t, errors.Is(err, errs.BadRequestError))
+	assert.Equal(t, []errs.FieldError{
+		{Field: "profile[employeeNumber]", Rule: "type", Param: "integer"},
+		{Field: "profile[languages]", Rule: IndexedKeyword, Param: "languages"},
+	}, fieldErrors.Fields)
+}
+
+func Test_Merge_Should_Remove_Null_Attributes_And_Leave_The_Profile_Untouched(t *testing.T) {
+	profile := map[string]interface{}{"department": "sales", "remote": true}
+
+	merged := Merge(profile, map[string]interface{}{"remote": nil, "employeeNumber": 7.0})
+
+	assert.Equal(t, map[string]interface{}{"department": "sales", "employeeNumber": 7.0}, merged)
+	assert.Equal(t, map[string]interface{}{"department": "sales", "remote": true}, profile)
+}
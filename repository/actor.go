@@ -0,0 +1,27 @@
+package repository
+
+import "context"
+
+const (
+	// SystemActor is recorded for writes the service makes on its own, such as migrations.
+	SystemActor = "system"
+	// AnonymousActor is recorded when the context names no actor, which covers the writes of requests made
+	// without an API key.
+	AnonymousActor = "anonymous"
+)
+
+type actorKey struct{}
+
+// WithActor names who the writes made with the returned context are recorded as createdBy and updatedBy.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func actorFrom(ctx context.Context) string {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	if !ok || actor == "" {
+		return AnonymousActor
+	}
+
+	return actor
+}
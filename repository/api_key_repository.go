@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log/slog"
+	"time"
+	errs "user-service/error"
+	"user-service/model"
+)
+
+const ApiKeyCollectionName = "ApiKey"
+
+type ApiKeyRepository struct {
+	apiKeyCollection *mongo.Collection
+	logger           *slog.Logger
+}
+
+func NewApiKeyRepository(database *mongo.Database, logger *slog.Logger) *ApiKeyRepository {
+	return &ApiKeyRepository{
+		apiKeyCollection: database.Collection(ApiKeyCollectionName),
+		logger:           logger,
+	}
+}
+
+type ApiKeyRepositoryInterface interface {
+	Create(context.Context, model.ApiKeyEntity) (*model.ApiKeyEntity, error)
+	GetAll(context.Context, string) ([]*model.ApiKeyEntity, error)
+	GetById(context.Context, primitive.ObjectID) (*model.ApiKeyEntity, error)
+	GetByPrefix(context.Context, string) (*model.ApiKeyEntity, error)
+	Revoke(context.Context, primitive.ObjectID) error
+	ExpireBy(context.Context, primitive.ObjectID, time.Time) error
+	TouchLastUsed(context.Context, primitive.ObjectID, time.Duration) error
+}
+
+// CreateIndexes makes prefixes unique, which also serves the lookups of keys presented by requests.
+func (r *ApiKeyRepository) CreateIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	_, err := r.apiKeyCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "prefix", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return err
+}
+
+// Create stores a new key along with who created it and when. A prefix that is already taken fails with
+// PreconditionFailedError, so the caller can draw another one.
+func (r *ApiKeyRepository) Create(ctx context.Context, apiKey model.ApiKeyEntity) (*model.ApiKeyEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	apiKey.Id = primitive.NewObjectID()
+	apiKey.CreatedAt = now()
+	apiKey.CreatedBy = actorFrom(ctx)
+
+	_, err := r.apiKeyCollection.InsertOne(ctx, apiKey)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, errs.PreconditionFailedError
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "creating the API key failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	return &apiKey, nil
+}
+
+// GetAll returns the keys of the tenant with the given id including the revoked and expired ones, newest first.
+// An empty tenantId returns the keys of every tenant and the platform keys.
+func (r *ApiKeyRepository) GetAll(ctx context.Context, tenantId string) ([]*model.ApiKeyEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	filter := bson.D{}
+	if tenantId != "" {
+		filter = bson.D{{Key: "tenantId", Value: tenantId}}
+	}
+
+	cur, err := r.apiKeyCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "finding the API keys failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	apiKeys := []*model.ApiKeyEntity{}
+	err = cur.All(ctx, &apiKeys)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "reading the API keys failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	return apiKeys, nil
+}
+
+func (r *ApiKeyRepository) GetById(ctx context.Context, id primitive.ObjectID) (*model.ApiKeyEntity, error) {
+	return r.findOne(ctx, bson.D{{Key: "_id", Value: id}})
+}
+
+func (r *ApiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*model.ApiKeyEntity, error) {
+	return r.findOne(ctx, bson.D{{Key: "prefix", Value: prefix}})
+}
+
+func (r *ApiKeyRepository) findOne(ctx context.Context, filter bson.D) (*model.ApiKeyEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	var apiKey model.ApiKeyEntity
+	err := r.apiKeyCollection.FindOne(ctx, filter).Decode(&apiKey)
+	if err == mongo.ErrNoDocuments {
+		return nil, errs.ApiKeyNotFoundError
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "finding the API key failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	return &apiKey, nil
+}
+
+// Revoke makes the key stop working at once. Revoking it again keeps the time it was first revoked at.
+func (r *ApiKeyRepository) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	result, err := r.apiKeyCollection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: id}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.D{
+			{Key: "revokedAt", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$revokedAt", now()}}}},
+		}}}})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "revoking the API key failed", "id", id.Hex(), "error", err)
+		return errs.ServerError
+	}
+	if result.MatchedCount == 0 {
+		return errs.ApiKeyNotFoundError
+	}
+
+	return nil
+}
+
+// ExpireBy makes the key expire at the given time unless it expires earlier anyway.
+func (r *ApiKeyRepository) ExpireBy(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	_, err := r.apiKeyCollection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: id}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.D{
+			{Key: "expiresAt", Value: bson.D{{Key: "$min", Value: bson.A{
+				bson.D{{Key: "$ifNull", Value: bson.A{"$expiresAt", at}}}, at,
+			}}}},
+		}}}})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "expiring the API key failed", "id", id.Hex(), "error", err)
+		return errs.ServerError
+	}
+
+	return nil
+}
+
+// TouchLastUsed records that the key was used now. It only writes when the recorded time is older than
+// precision, so that busy keys do not cost a write per request.
+func (r *ApiKeyRepository) TouchLastUsed(ctx context.Context, id primitive.ObjectID, precision time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	usedAt := now()
+	_, err := r.apiKeyCollection.UpdateOne(ctx,
+		bson.D{
+			{Key: "_id", Value: id},
+			{Key: "$or", Value: bson.A{
+				bson.D{{Key: "lastUsedAt", Value: bson.D{{Key: "$exists", Value: false}}}},
+				bson.D{{Key: "lastUsedAt", Value: bson.D{{Key: "$lt", Value: usedAt.Add(-precision)}}}},
+			}},
+		},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "lastUsedAt", Value: usedAt}}}})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "recording the use of the API key failed", "id", id.Hex(), "error", err)
+		return errs.ServerError
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Blob is stored content opened for reading. The caller must close Content.
+type Blob struct {
+	Content     io.ReadCloser
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
+
+// BlobStore keeps binary content such as avatars under slash-separated keys. Get fails with an error wrapping
+// fs.ErrNotExist for keys that hold nothing.
+type BlobStore interface {
+	Put(ctx context.Context, key string, content io.Reader, contentType string) error
+	Get(ctx context.Context, key string) (*Blob, error)
+	// DeleteAll deletes every blob whose key starts with prefix, which must end in a slash. Deleting a prefix
+	// that holds nothing succeeds.
+	DeleteAll(ctx context.Context, prefix string) error
+}
+
+var errInvalidKey = errors.New("blob keys must be relative slash-separated paths without . or .. elements")
+
+// LocalBlobStore keeps blobs as files below a directory. It does not store content types; Get sniffs them from
+// the content the way http.ServeFile does, which is reliable for the image types the service stores.
+type LocalBlobStore struct {
+	directory string
+}
+
+// NewLocalBlobStore stores blobs below directory, which is created when it does not exist.
+func NewLocalBlobStore(directory string) (*LocalBlobStore, error) {
+	err := os.MkdirAll(directory, 0o750)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalBlobStore{directory: directory}, nil
+}
+
+// Put writes content to a temporary file first and renames it into place, so readers see either the previous
+// blob or the new one in full.
+func (s *LocalBlobStore) Put(ctx context.Context, key string, content io.Reader, contentType string) error {
+	name, err := s.path(key, false)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(name), 0o750)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), name)
+}
+
+func (s *LocalBlobStore) Get(ctx context.Context, key string) (*Blob, error) {
+	name, err := s.path(key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err == nil && info.IsDir() {
+		err = &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	sniffed := make([]byte, 512)
+	n, err := io.ReadFull(file, sniffed)
+	if err == nil || err == io.ErrUnexpectedEOF || err == io.EOF {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &Blob{
+		Content:     file,
+		ContentType: http.DetectContentType(sniffed[:n]),
+		Size:        info.Size(),
+		ModTime:     info.ModTime().UTC(),
+	}, nil
+}
+
+func (s *LocalBlobStore) DeleteAll(ctx context.Context, prefix string) error {
+	name, err := s.path(prefix, true)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(name)
+}
+
+// Check verifies that blobs can be written below the directory, which fails for instance on a volume that was
+// mounted read-only or has run full.
+func (s *LocalBlobStore) Check(ctx context.Context) error {
+	file, err := os.CreateTemp(s.directory, ".check-*")
+	if err != nil {
+		return err
+	}
+
+	file.Close()
+
+	return os.Remove(file.Name())
+}
+
+// path maps a key to a file below the directory. Keys are checked rather than cleaned, so that a key can never
+// name a file outside of it.
+func (s *LocalBlobStore) path(key string, isPrefix bool) (string, error) {
+	if isPrefix {
+		if !strings.HasSuffix(key, "/") {
+			return "", errInvalidKey
+		}
+		key = strings.TrimSuffix(key, "/")
+	}
+
+	if key == "" || !fs.ValidPath(key) || path.Base(key) == "." || strings.Contains(key, `\`) {
+		return "", errInvalidKey
+	}
+
+	return filepath.Join(s.directory, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,400 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log/slog"
+	errs "user-service/error"
+	"user-service/model"
+)
+
+const (
+	GroupCollectionName           = "Group"
+	GroupMembershipCollectionName = "GroupMembership"
+)
+
+type GroupRepository struct {
+	groupCollection      *mongo.Collection
+	membershipCollection *mongo.Collection
+	logger               *slog.Logger
+}
+
+func NewGroupRepository(database *mongo.Database, logger *slog.Logger) *GroupRepository {
+	return &GroupRepository{
+		groupCollection:      database.Collection(GroupCollectionName),
+		membershipCollection: database.Collection(GroupMembershipCollectionName),
+		logger:               logger,
+	}
+}
+
+type GroupRepositoryInterface interface {
+	Create(context.Context, model.GroupEntity) (*model.GroupEntity, error)
+	GetById(context.Context, primitive.ObjectID) (*model.GroupEntity, error)
+	GetAll(context.Context, *primitive.ObjectID, int64) ([]*model.GroupEntity, error)
+	GetByIds(context.Context, []primitive.ObjectID) ([]*model.GroupEntity, error)
+	UpdateById(context.Context, primitive.ObjectID, model.UpdateGroupDomainModel) (*model.GroupEntity, error)
+	DeleteById(context.Context, primitive.ObjectID) error
+	AddMembers(context.Context, primitive.ObjectID, string, []primitive.ObjectID) error
+	RemoveMembers(context.Context, primitive.ObjectID, string, []primitive.ObjectID) error
+	GetMembers(context.Context, primitive.ObjectID, *primitive.ObjectID, int64) ([]*model.GroupMembershipEntity, error)
+	GetMemberships(context.Context, string, primitive.ObjectID, *primitive.ObjectID, int64) ([]*model.GroupMembershipEntity, error)
+	GetParentGroupIds(context.Context, []primitive.ObjectID) ([]primitive.ObjectID, error)
+	DeleteMemberships(context.Context, string, []primitive.ObjectID) error
+}
+
+// CreateIndexes backs the listing of groups and keeps a member from being added to a group twice. The second
+// membership index serves the lookups of the groups of a member, which also find the parents of a group and the
+// memberships to delete along with a user.
+func (r *GroupRepository) CreateIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	_, err := r.groupCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "_id", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.membershipCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "groupId", Value: 1},
+				{Key: "memberType", Value: 1},
+				{Key: "memberId", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "memberType", Value: 1}, {Key: "memberId", Value: 1}}},
+	})
+
+	return err
+}
+
+// Create stores the group in the tenant of ctx and returns it with the metadata the repository set.
+func (r *GroupRepository) Create(ctx context.Context, group model.GroupEntity) (*model.GroupEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	group.TenantId = tenant.Value.(string)
+	group.CreatedAt = now()
+	group.UpdatedAt = group.CreatedAt
+	group.CreatedBy = actorFrom(ctx)
+	group.UpdatedBy = group.CreatedBy
+
+	_, err = r.groupCollection.InsertOne(ctx, group)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "creating the group failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	return &group, nil
+}
+
+func (r *GroupRepository) GetById(ctx context.Context, id primitive.ObjectID) (*model.GroupEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var group model.GroupEntity
+	err = r.groupCollection.FindOne(ctx, bson.D{tenant, {Key: "_id", Value: id}}).Decode(&group)
+	if err == mongo.ErrNoDocuments {
+		return nil, errs.GroupNotFoundError
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "finding the group failed", "id", id.Hex(), "error", err)
+		return nil, errs.ServerError
+	}
+
+	return &group, nil
+}
+
+// GetAll returns the groups ordered by id, starting after the given id, or from the start when it is nil. A zero
+// limit returns every remaining group.
+func (r *GroupRepository) GetAll(ctx context.Context, after *primitive.ObjectID, limit int64) ([]*model.GroupEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{tenant}
+	if after != nil {
+		filter = append(filter, bson.E{Key: "_id", Value: bson.D{{Key: "$gt", Value: *after}}})
+	}
+
+	return r.findGroups(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit))
+}
+
+// GetByIds returns the groups with the given ids ordered by id. Ids that do not belong to a group are left out.
+func (r *GroupRepository) GetByIds(ctx context.Context, ids []primitive.ObjectID) ([]*model.GroupEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{tenant, {Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}}
+
+	return r.findGroups(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+}
+
+func (r *GroupRepository) findGroups(ctx context.Context, filter bson.D, findOptions *options.FindOptions) ([]*model.GroupEntity, error) {
+	cur, err := r.groupCollection.Find(ctx, filter, findOptions)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "finding the groups failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	groups := []*model.GroupEntity{}
+	err = cur.All(ctx, &groups)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "reading the groups failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	return groups, nil
+}
+
+// UpdateById changes the fields of update that are set and returns the updated group.
+func (r *GroupRepository) UpdateById(ctx context.Context, id primitive.ObjectID, update model.UpdateGroupDomainModel) (*model.GroupEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldsToSet := bson.D{
+		{Key: "updatedAt", Value: now()},
+		{Key: "updatedBy", Value: actorFrom(ctx)},
+	}
+	if update.Name != nil {
+		fieldsToSet = append(fieldsToSet, bson.E{Key: "name", Value: *update.Name})
+	}
+	if update.Description != nil {
+		fieldsToSet = append(fieldsToSet, bson.E{Key: "description", Value: *update.Description})
+	}
+	updateOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var group model.GroupEntity
+	err = r.groupCollection.FindOneAndUpdate(ctx, bson.D{tenant, {Key: "_id", Value: id}}, bson.D{{Key: "$set", Value: fieldsToSet}}, updateOptions).Decode(&group)
+	if err == mongo.ErrNoDocuments {
+		return nil, errs.GroupNotFoundError
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "updating the group failed", "id", id.Hex(), "error", err)
+		return nil, errs.ServerError
+	}
+
+	return &group, nil
+}
+
+// DeleteById deletes the group along with its own memberships. Those are deleted first, so that a failure leaves
+// the group in place to be deleted again. The memberships of the group in other groups are left to
+// DeleteMemberships.
+func (r *GroupRepository) DeleteById(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.membershipCollection.DeleteMany(ctx, bson.D{tenant, {Key: "groupId", Value: id}})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "deleting the members of the group failed", "id", id.Hex(), "error", err)
+		return errs.ServerError
+	}
+
+	result, err := r.groupCollection.DeleteOne(ctx, bson.D{tenant, {Key: "_id", Value: id}})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "deleting the group failed", "id", id.Hex(), "error", err)
+		return errs.ServerError
+	}
+	if result.DeletedCount == 0 {
+		return errs.GroupNotFoundError
+	}
+
+	return nil
+}
+
+// AddMembers makes the users or groups with the given ids, depending on memberType, members of the group in a
+// single bulk write. Ids that already are members keep the time they were first added at.
+func (r *GroupRepository) AddMembers(ctx context.Context, groupId primitive.ObjectID, memberType string, memberIds []primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return err
+	}
+
+	addedAt := now()
+	addedBy := actorFrom(ctx)
+	writes := make([]mongo.WriteModel, len(memberIds))
+	for i, memberId := range memberIds {
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.D{tenant, {Key: "groupId", Value: groupId}, {Key: "memberType", Value: memberType}, {Key: "memberId", Value: memberId}}).
+			SetUpdate(bson.D{{Key: "$setOnInsert", Value: bson.D{
+				{Key: "_id", Value: primitive.NewObjectID()},
+				{Key: "createdAt", Value: addedAt},
+				{Key: "createdBy", Value: addedBy},
+			}}}).
+			SetUpsert(true)
+	}
+
+	_, err = r.membershipCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	// Concurrent additions of the same member can race to insert it. The loser hits the unique index, and the
+	// member is in the group either way.
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		r.logger.ErrorContext(ctx, "adding members to the group failed", "id", groupId.Hex(), "error", err)
+		return errs.ServerError
+	}
+
+	return nil
+}
+
+// RemoveMembers removes the users or groups with the given ids, depending on memberType, from the group. Ids that
+// are no members are ignored.
+func (r *GroupRepository) RemoveMembers(ctx context.Context, groupId primitive.ObjectID, memberType string, memberIds []primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{
+		tenant,
+		{Key: "groupId", Value: groupId},
+		{Key: "memberType", Value: memberType},
+		{Key: "memberId", Value: bson.D{{Key: "$in", Value: memberIds}}},
+	}
+
+	_, err = r.membershipCollection.DeleteMany(ctx, filter)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "removing members from the group failed", "id", groupId.Hex(), "error", err)
+		return errs.ServerError
+	}
+
+	return nil
+}
+
+// GetMembers returns the direct members of the group in the order they were added, starting after the membership
+// with the given id, or from the start when it is nil. A zero limit returns every remaining member.
+func (r *GroupRepository) GetMembers(ctx context.Context, groupId primitive.ObjectID, after *primitive.ObjectID, limit int64) ([]*model.GroupMembershipEntity, error) {
+	return r.findMemberships(ctx, bson.D{{Key: "groupId", Value: groupId}}, after, limit)
+}
+
+// GetMemberships returns the memberships of the user or group, depending on memberType, in the groups it directly
+// belongs to, paged like GetMembers.
+func (r *GroupRepository) GetMemberships(ctx context.Context, memberType string, memberId primitive.ObjectID, after *primitive.ObjectID, limit int64) ([]*model.GroupMembershipEntity, error) {
+	return r.findMemberships(ctx, bson.D{{Key: "memberType", Value: memberType}, {Key: "memberId", Value: memberId}}, after, limit)
+}
+
+func (r *GroupRepository) findMemberships(ctx context.Context, conditions bson.D, after *primitive.ObjectID, limit int64) ([]*model.GroupMembershipEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := append(bson.D{tenant}, conditions...)
+	if after != nil {
+		filter = append(filter, bson.E{Key: "_id", Value: bson.D{{Key: "$gt", Value: *after}}})
+	}
+
+	cur, err := r.membershipCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "finding the memberships failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	memberships := []*model.GroupMembershipEntity{}
+	err = cur.All(ctx, &memberships)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "reading the memberships failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	return memberships, nil
+}
+
+// GetParentGroupIds returns the ids of the groups that any of the given groups is a direct member of, each once.
+func (r *GroupRepository) GetParentGroupIds(ctx context.Context, groupIds []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		tenant,
+		{Key: "memberType", Value: model.MemberTypeGroup},
+		{Key: "memberId", Value: bson.D{{Key: "$in", Value: groupIds}}},
+	}
+
+	values, err := r.membershipCollection.Distinct(ctx, "groupId", filter)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "finding the parent groups failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	parentIds := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if parentId, ok := value.(primitive.ObjectID); ok {
+			parentIds = append(parentIds, parentId)
+		}
+	}
+
+	return parentIds, nil
+}
+
+// DeleteMemberships removes the users or groups with the given ids, depending on memberType, from every group
+// they belong to.
+func (r *GroupRepository) DeleteMemberships(ctx context.Context, memberType string, memberIds []primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{
+		tenant,
+		{Key: "memberType", Value: memberType},
+		{Key: "memberId", Value: bson.D{{Key: "$in", Value: memberIds}}},
+	}
+
+	_, err = r.membershipCollection.DeleteMany(ctx, filter)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "deleting the memberships failed", "memberType", memberType, "error", err)
+		return errs.ServerError
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+	"user-service/model"
+)
+
+type ApiKeyRepositoryInterface struct {
+	mock.Mock
+}
+
+func (_m *ApiKeyRepositoryInterface) Create(ctx context.Context, apiKey model.ApiKeyEntity) (*model.ApiKeyEntity, error) {
+	args := _m.Called(ctx, apiKey)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.ApiKeyEntity), args.Error(1)
+}
+
+func (_m *ApiKeyRepositoryInterface) GetAll(ctx context.Context, tenantId string) ([]*model.ApiKeyEntity, error) {
+	args := _m.Called(ctx, tenantId)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.ApiKeyEntity), args.Error(1)
+}
+
+func (_m *ApiKeyRepositoryInterface) GetById(ctx context.Context, id primitive.ObjectID) (*model.ApiKeyEntity, error) {
+	args := _m.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.ApiKeyEntity), args.Error(1)
+}
+
+func (_m *ApiKeyRepositoryInterface) GetByPrefix(ctx context.Context, prefix string) (*model.ApiKeyEntity, error) {
+	args := _m.Called(ctx, prefix)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.ApiKeyEntity), args.Error(1)
+}
+
+func (_m *ApiKeyRepositoryInterface) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	args := _m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (_m *ApiKeyRepositoryInterface) ExpireBy(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	args := _m.Called(ctx, id, at)
+
+	return args.Error(0)
+}
+
+func (_m *ApiKeyRepositoryInterface) TouchLastUsed(ctx context.Context, id primitive.ObjectID, precision time.Duration) error {
+	args := _m.Called(ctx, id, precision)
+
+	return args.Error(0)
+}
@@ -0,0 +1,34 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"user-service/repository"
+)
+
+type BlobStore struct {
+	mock.Mock
+}
+
+func (_m *BlobStore) Put(ctx context.Context, key string, content io.Reader, contentType string) error {
+	args := _m.Called(ctx, key, content, contentType)
+
+	return args.Error(0)
+}
+
+func (_m *BlobStore) Get(ctx context.Context, key string) (*repository.Blob, error) {
+	args := _m.Called(ctx, key)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*repository.Blob), args.Error(1)
+}
+
+func (_m *BlobStore) DeleteAll(ctx context.Context, prefix string) error {
+	args := _m.Called(ctx, prefix)
+
+	return args.Error(0)
+}
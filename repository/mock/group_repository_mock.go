@@ -0,0 +1,116 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"user-service/model"
+)
+
+type GroupRepositoryInterface struct {
+	mock.Mock
+}
+
+func (_m *GroupRepositoryInterface) Create(ctx context.Context, group model.GroupEntity) (*model.GroupEntity, error) {
+	args := _m.Called(ctx, group)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.GroupEntity), args.Error(1)
+}
+
+func (_m *GroupRepositoryInterface) GetById(ctx context.Context, id primitive.ObjectID) (*model.GroupEntity, error) {
+	args := _m.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.GroupEntity), args.Error(1)
+}
+
+func (_m *GroupRepositoryInterface) GetAll(ctx context.Context, after *primitive.ObjectID, limit int64) ([]*model.GroupEntity, error) {
+	args := _m.Called(ctx, after, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.GroupEntity), args.Error(1)
+}
+
+func (_m *GroupRepositoryInterface) GetByIds(ctx context.Context, ids []primitive.ObjectID) ([]*model.GroupEntity, error) {
+	args := _m.Called(ctx, ids)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.GroupEntity), args.Error(1)
+}
+
+func (_m *GroupRepositoryInterface) UpdateById(ctx context.Context, id primitive.ObjectID, update model.UpdateGroupDomainModel) (*model.GroupEntity, error) {
+	args := _m.Called(ctx, id, update)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.GroupEntity), args.Error(1)
+}
+
+func (_m *GroupRepositoryInterface) DeleteById(ctx context.Context, id primitive.ObjectID) error {
+	args := _m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (_m *GroupRepositoryInterface) AddMembers(ctx context.Context, groupId primitive.ObjectID, memberType string, memberIds []primitive.ObjectID) error {
+	args := _m.Called(ctx, groupId, memberType, memberIds)
+
+	return args.Error(0)
+}
+
+func (_m *GroupRepositoryInterface) RemoveMembers(ctx context.Context, groupId primitive.ObjectID, memberType string, memberIds []primitive.ObjectID) error {
+	args := _m.Called(ctx, groupId, memberType, memberIds)
+
+	return args.Error(0)
+}
+
+func (_m *GroupRepositoryInterface) GetMembers(ctx context.Context, groupId primitive.ObjectID, after *primitive.ObjectID, limit int64) ([]*model.GroupMembershipEntity, error) {
+	args := _m.Called(ctx, groupId, after, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.GroupMembershipEntity), args.Error(1)
+}
+
+func (_m *GroupRepositoryInterface) GetMemberships(ctx context.Context, memberType string, memberId primitive.ObjectID, after *primitive.ObjectID, limit int64) ([]*model.GroupMembershipEntity, error) {
+	args := _m.Called(ctx, memberType, memberId, after, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.GroupMembershipEntity), args.Error(1)
+}
+
+func (_m *GroupRepositoryInterface) GetParentGroupIds(ctx context.Context, groupIds []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	args := _m.Called(ctx, groupIds)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]primitive.ObjectID), args.Error(1)
+}
+
+func (_m *GroupRepositoryInterface) DeleteMemberships(ctx context.Context, memberType string, memberIds []primitive.ObjectID) error {
+	args := _m.Called(ctx, memberType, memberIds)
+
+	return args.Error(0)
+}
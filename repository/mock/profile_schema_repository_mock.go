@@ -0,0 +1,31 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"user-service/model"
+)
+
+type ProfileSchemaRepositoryInterface struct {
+	mock.Mock
+}
+
+func (_m *ProfileSchemaRepositoryInterface) GetLatest(ctx context.Context) (*model.ProfileSchemaEntity, error) {
+	args := _m.Called(ctx)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.ProfileSchemaEntity), args.Error(1)
+}
+
+func (_m *ProfileSchemaRepositoryInterface) Create(ctx context.Context, schema model.ProfileSchemaEntity) (*model.ProfileSchemaEntity, error) {
+	args := _m.Called(ctx, schema)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.ProfileSchemaEntity), args.Error(1)
+}
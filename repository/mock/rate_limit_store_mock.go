@@ -0,0 +1,18 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"time"
+	"user-service/repository"
+)
+
+type RateLimitStore struct {
+	mock.Mock
+}
+
+func (_m *RateLimitStore) Take(ctx context.Context, key string, capacity int, period time.Duration) (repository.RateLimitBucket, error) {
+	args := _m.Called(ctx, key, capacity, period)
+
+	return args.Get(0).(repository.RateLimitBucket), args.Error(1)
+}
@@ -0,0 +1,51 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"user-service/model"
+)
+
+type TenantRepositoryInterface struct {
+	mock.Mock
+}
+
+func (_m *TenantRepositoryInterface) Create(ctx context.Context, tenant model.TenantEntity) (*model.TenantEntity, error) {
+	args := _m.Called(ctx, tenant)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.TenantEntity), args.Error(1)
+}
+
+func (_m *TenantRepositoryInterface) GetAll(ctx context.Context) ([]*model.TenantEntity, error) {
+	args := _m.Called(ctx)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.TenantEntity), args.Error(1)
+}
+
+func (_m *TenantRepositoryInterface) GetById(ctx context.Context, id string) (*model.TenantEntity, error) {
+	args := _m.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.TenantEntity), args.Error(1)
+}
+
+func (_m *TenantRepositoryInterface) UpdateById(ctx context.Context, id string, update model.UpdateTenantDomainModel) (*model.TenantEntity, error) {
+	args := _m.Called(ctx, id, update)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.TenantEntity), args.Error(1)
+}
@@ -1,7 +1,7 @@
 package mock
 
 import (
-	"github.com/gin-gonic/gin"
+	"context"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"user-service/model"
@@ -11,7 +11,7 @@ type UserRepositoryInterface struct {
 	mock.Mock
 }
 
-func (_m *UserRepositoryInterface) GetById(ctx *gin.Context, id primitive.ObjectID) (*model.UserEntity, error) {
+func (_m *UserRepositoryInterface) GetById(ctx context.Context, id primitive.ObjectID) (*model.UserEntity, error) {
 	args := _m.Called(ctx, id)
 
 	if args.Get(0) == nil {
@@ -21,31 +21,35 @@ func (_m *UserRepositoryInterface) GetById(ctx *gin.Context, id primitive.Object
 	return args.Get(0).(*model.UserEntity), args.Error(1)
 }
 
-func (_m *UserRepositoryInterface) Create(ctx *gin.Context, user model.UserEntity) error {
+func (_m *UserRepositoryInterface) Create(ctx context.Context, user model.UserEntity) (*model.UserEntity, error) {
 	args := _m.Called(ctx, user)
 
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.UserEntity), args.Error(1)
 }
 
-func (_m *UserRepositoryInterface) CheckIfEmailAlreadyInUse(ctx *gin.Context, email string) (bool, error) {
+func (_m *UserRepositoryInterface) CheckIfEmailAlreadyInUse(ctx context.Context, email string) (bool, error) {
 	args := _m.Called(ctx, email)
 
 	return args.Bool(0), args.Error(1)
 }
 
-func (_m *UserRepositoryInterface) GetAll(ctx *gin.Context) ([]*model.UserEntity, error) {
-	args := _m.Called(ctx)
+func (_m *UserRepositoryInterface) GetAll(ctx context.Context, filter model.UserFilterDomainModel, sort model.UserSort, after *model.UserCursor, limit int64) ([]*model.UserEntity, error) {
+	args := _m.Called(ctx, filter, sort, after, limit)
 
 	return args.Get(0).([]*model.UserEntity), args.Error(1)
 }
 
-func (_m *UserRepositoryInterface) DeleteById(ctx *gin.Context, id primitive.ObjectID) error {
+func (_m *UserRepositoryInterface) DeleteById(ctx context.Context, id primitive.ObjectID) error {
 	args := _m.Called(ctx, id)
 
 	return args.Error(0)
 }
 
-func (_m *UserRepositoryInterface) UpdateById(ctx *gin.Context, id primitive.ObjectID, updateModel model.UpdateUserDomainModel) (*model.UserEntity, error) {
+func (_m *UserRepositoryInterface) UpdateById(ctx context.Context, id primitive.ObjectID, updateModel model.UpdateUserDomainModel) (*model.UserEntity, error) {
 	args := _m.Called(ctx, id, updateModel)
 
 	if args.Get(0) == nil {
@@ -54,3 +58,51 @@ func (_m *UserRepositoryInterface) UpdateById(ctx *gin.Context, id primitive.Obj
 
 	return args.Get(0).(*model.UserEntity), args.Error(1)
 }
+
+func (_m *UserRepositoryInterface) ReplaceById(ctx context.Context, user model.UserEntity, expectedVersion int64) (*model.UserEntity, error) {
+	args := _m.Called(ctx, user, expectedVersion)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.UserEntity), args.Error(1)
+}
+
+func (_m *UserRepositoryInterface) GetByIds(ctx context.Context, ids []primitive.ObjectID) ([]*model.UserEntity, error) {
+	args := _m.Called(ctx, ids)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.UserEntity), args.Error(1)
+}
+
+func (_m *UserRepositoryInterface) Stream(ctx context.Context, filter model.UserFilterDomainModel, fn func(*model.UserEntity) error) error {
+	args := _m.Called(ctx, filter, fn)
+
+	return args.Error(0)
+}
+
+func (_m *UserRepositoryInterface) DeleteByIds(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	args := _m.Called(ctx, ids)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (_m *UserRepositoryInterface) UpdateByIds(ctx context.Context, updates []model.UserUpdateEntity) ([]error, error) {
+	args := _m.Called(ctx, updates)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]error), args.Error(1)
+}
+
+func (_m *UserRepositoryInterface) SyncProfileIndexes(ctx context.Context, attributes []string) error {
+	args := _m.Called(ctx, attributes)
+
+	return args.Error(0)
+}
@@ -0,0 +1,44 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"user-service/model"
+)
+
+type UserRevisionRepositoryInterface struct {
+	mock.Mock
+}
+
+func (_m *UserRevisionRepositoryInterface) Create(ctx context.Context, revisions []model.UserRevisionEntity) error {
+	args := _m.Called(ctx, revisions)
+
+	return args.Error(0)
+}
+
+func (_m *UserRevisionRepositoryInterface) GetAll(ctx context.Context, userId primitive.ObjectID) ([]*model.UserRevisionEntity, error) {
+	args := _m.Called(ctx, userId)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.UserRevisionEntity), args.Error(1)
+}
+
+func (_m *UserRevisionRepositoryInterface) GetByRevision(ctx context.Context, userId primitive.ObjectID, revision int64) (*model.UserRevisionEntity, error) {
+	args := _m.Called(ctx, userId, revision)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.UserRevisionEntity), args.Error(1)
+}
+
+func (_m *UserRevisionRepositoryInterface) DeleteByUserIds(ctx context.Context, userIds []primitive.ObjectID) error {
+	args := _m.Called(ctx, userIds)
+
+	return args.Error(0)
+}
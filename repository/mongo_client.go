@@ -2,30 +2,79 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
-	"log"
+	"log/slog"
+	"time"
+	"user-service/tracing"
 )
 
-const (
-	DatabaseName = "Company"
-)
+// maxConnectBackoff caps the wait between two connection attempts.
+const maxConnectBackoff = 30 * time.Second
+
+// ConnectRetry bounds how InitDatabase waits for a server that is not reachable yet, such as one starting
+// alongside the service. The wait starts at Backoff and doubles after every failed attempt.
+type ConnectRetry struct {
+	Attempts int
+	Backoff  time.Duration
+}
 
-func InitDatabase(databaseUri string) *mongo.Database {
-	client, err := mongo.Connect(context.TODO(), options.Client().ApplyURI(databaseUri))
+// InitDatabase connects to the server at databaseUri and returns the named database. Every attempt must reach
+// the server within connectTimeout. It gives up once the attempts are used up or ctx is done, and disconnects
+// the client again in that case.
+func InitDatabase(ctx context.Context, databaseUri string, databaseName string, connectTimeout time.Duration, retry ConnectRetry, logger *slog.Logger) (*mongo.Database, error) {
+	client, err := mongo.Connect(ctx, options.Client().
+		ApplyURI(databaseUri).
+		SetConnectTimeout(connectTimeout).
+		SetServerSelectionTimeout(connectTimeout).
+		SetMonitor(tracing.CommandMonitor()))
 	if err != nil {
-		log.Println(err)
-		panic(err)
+		return nil, err
 	}
 
-	err = client.Ping(context.TODO(), readpref.Primary())
-	if err != nil {
-		log.Println(err)
-		panic(err)
+	backoff := retry.Backoff
+	for attempt := 1; ; attempt++ {
+		err = ping(ctx, client, connectTimeout)
+		if err == nil {
+			logger.InfoContext(ctx, "connected to the database", "attempts", attempt)
+			return client.Database(databaseName), nil
+		}
+
+		if attempt >= retry.Attempts {
+			break
+		}
+
+		logger.WarnContext(ctx, "the database is not reachable, retrying", "attempt", attempt, "attempts", retry.Attempts, "backoff", backoff, "error", err)
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-time.After(backoff):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		backoff *= 2
+		if backoff > maxConnectBackoff {
+			backoff = maxConnectBackoff
+		}
 	}
 
-	database := client.Database(DatabaseName)
+	client.Disconnect(context.Background())
+	return nil, fmt.Errorf("the database is not reachable: %w", err)
+}
+
+// Ping checks that the primary of the database answers before ctx is done.
+func Ping(ctx context.Context, database *mongo.Database) error {
+	return database.Client().Ping(ctx, readpref.Primary())
+}
+
+func ping(ctx context.Context, client *mongo.Client, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	return database
+	return client.Ping(ctx, readpref.Primary())
 }
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log/slog"
+	errs "user-service/error"
+	"user-service/model"
+)
+
+const ProfileSchemaCollectionName = "ProfileSchema"
+
+type ProfileSchemaRepository struct {
+	profileSchemaCollection *mongo.Collection
+	logger                  *slog.Logger
+}
+
+func NewProfileSchemaRepository(database *mongo.Database, logger *slog.Logger) *ProfileSchemaRepository {
+	return &ProfileSchemaRepository{
+		profileSchemaCollection: database.Collection(ProfileSchemaCollectionName),
+		logger:                  logger,
+	}
+}
+
+type ProfileSchemaRepositoryInterface interface {
+	GetLatest(context.Context) (*model.ProfileSchemaEntity, error)
+	Create(context.Context, model.ProfileSchemaEntity) (*model.ProfileSchemaEntity, error)
+}
+
+func (r *ProfileSchemaRepository) GetLatest(ctx context.Context) (*model.ProfileSchemaEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+
+	var result model.ProfileSchemaEntity
+	err := r.profileSchemaCollection.FindOne(ctx, bson.D{}, findOptions).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return nil, errs.ProfileSchemaNotFoundError
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "finding the latest profile schema failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	return &result, nil
+}
+
+// Create stores a new schema version along with who published it and when. The version doubles as the id, so
+// when two versions are published concurrently only the first succeeds and the other fails with
+// PreconditionFailedError.
+func (r *ProfileSchemaRepository) Create(ctx context.Context, schema model.ProfileSchemaEntity) (*model.ProfileSchemaEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	schema.CreatedAt = now()
+	schema.CreatedBy = actorFrom(ctx)
+
+	_, err := r.profileSchemaCollection.InsertOne(ctx, schema)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, errs.PreconditionFailedError
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "creating the profile schema failed", "version", schema.Version, "error", err)
+		return nil, errs.ServerError
+	}
+
+	return &schema, nil
+}
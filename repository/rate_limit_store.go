@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+	errs "user-service/error"
+)
+
+const RateLimitCollectionName = "RateLimit"
+
+// sweepInterval is how often the MemoryRateLimitStore drops the buckets that have filled up again.
+const sweepInterval = time.Minute
+
+// RateLimitBucket is the state of a token bucket right after a request tried to take a token from it.
+type RateLimitBucket struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left.
+	Remaining int
+	// Reset is how long the bucket takes to fill up again.
+	Reset time.Duration
+	// RetryAfter is how long until the next token, which is zero when the request was allowed.
+	RetryAfter time.Duration
+}
+
+// RateLimitStore keeps token buckets that hold up to capacity tokens and refill at capacity tokens per period.
+// A bucket that does not exist yet is full. Take reports an empty bucket as not allowed rather than as an error.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, capacity int, period time.Duration) (RateLimitBucket, error)
+}
+
+// MemoryRateLimitStore keeps the buckets in the memory of the process, so every replica limits on its own.
+type MemoryRateLimitStore struct {
+	mutex     sync.Mutex
+	buckets   map[string]*memoryBucket
+	nextSweep time.Time
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: map[string]*memoryBucket{},
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, capacity int, period time.Duration) (RateLimitBucket, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: float64(capacity), updatedAt: now}
+		s.buckets[key] = bucket
+	}
+
+	rate := tokensPerSecond(capacity, period)
+	bucket.tokens = math.Min(float64(capacity), bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*rate)
+	bucket.updatedAt = now
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+
+	result := bucketAfter(allowed, bucket.tokens, capacity, period)
+	bucket.fullAt = now.Add(result.Reset)
+
+	return result, nil
+}
+
+// sweep drops the buckets that are full again, since those behave like buckets that do not exist.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	s.nextSweep = now.Add(sweepInterval)
+
+	for key, bucket := range s.buckets {
+		if !now.Before(bucket.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// MongoRateLimitStore keeps the buckets in a collection, so that all replicas share them. Every take is one
+// atomic update that refills the bucket by the time the server measures since the previous take, which keeps
+// the clocks of the replicas out of it. Buckets expire once they would be full again.
+type MongoRateLimitStore struct {
+	rateLimitCollection *mongo.Collection
+	logger              *slog.Logger
+}
+
+type rateLimitDocument struct {
+	Tokens  float64 `bson:"tokens"`
+	Allowed bool    `bson:"allowed"`
+}
+
+func NewMongoRateLimitStore(database *mongo.Database, logger *slog.Logger) *MongoRateLimitStore {
+	return &MongoRateLimitStore{
+		rateLimitCollection: database.Collection(RateLimitCollectionName),
+		logger:              logger,
+	}
+}
+
+// CreateIndexes lets the server delete the buckets that have filled up again.
+func (s *MongoRateLimitStore) CreateIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	_, err := s.rateLimitCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+
+	return err
+}
+
+func (s *MongoRateLimitStore) Take(ctx context.Context, key string, capacity int, period time.Duration) (RateLimitBucket, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tokensPerMillisecond := tokensPerSecond(capacity, period) / 1000
+	elapsed := bson.D{{Key: "$max", Value: bson.A{0, bson.D{{Key: "$subtract", Value: bson.A{
+		"$$NOW", bson.D{{Key: "$ifNull", Value: bson.A{"$updatedAt", "$$NOW"}}},
+	}}}}}}
+	hasToken := bson.D{{Key: "$gte", Value: bson.A{"$tokens", 1}}}
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{
+			{Key: "tokens", Value: bson.D{{Key: "$min", Value: bson.A{capacity, bson.D{{Key: "$add", Value: bson.A{
+				bson.D{{Key: "$ifNull", Value: bson.A{"$tokens", capacity}}},
+				bson.D{{Key: "$multiply", Value: bson.A{elapsed, tokensPerMillisecond}}},
+			}}}}}}},
+			{Key: "updatedAt", Value: "$$NOW"},
+		}}},
+		{{Key: "$set", Value: bson.D{
+			{Key: "allowed", Value: hasToken},
+			{Key: "tokens", Value: bson.D{{Key: "$cond", Value: bson.A{hasToken, bson.D{{Key: "$subtract", Value: bson.A{"$tokens", 1}}}, "$tokens"}}}},
+			{Key: "expiresAt", Value: bson.D{{Key: "$add", Value: bson.A{"$$NOW", period.Milliseconds()}}}},
+		}}},
+	}
+
+	var document rateLimitDocument
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := s.rateLimitCollection.FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: key}}, update, opts).Decode(&document)
+	if mongo.IsDuplicateKeyError(err) {
+		// Another replica created the bucket at the same time, so the update finds it now.
+		err = s.rateLimitCollection.FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: key}}, update, opts).Decode(&document)
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "taking a rate limit token failed", "error", err)
+		return RateLimitBucket{}, errs.ServerError
+	}
+
+	return bucketAfter(document.Allowed, document.Tokens, capacity, period), nil
+}
+
+func tokensPerSecond(capacity int, period time.Duration) float64 {
+	return float64(capacity) / period.Seconds()
+}
+
+func bucketAfter(allowed bool, tokens float64, capacity int, period time.Duration) RateLimitBucket {
+	rate := tokensPerSecond(capacity, period)
+	bucket := RateLimitBucket{
+		Allowed:   allowed,
+		Remaining: int(tokens),
+		Reset:     time.Duration((float64(capacity) - tokens) / rate * float64(time.Second)),
+	}
+	if !allowed {
+		bucket.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+
+	return bucket
+}
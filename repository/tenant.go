@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"log/slog"
+	errs "user-service/error"
+)
+
+type tenantKey struct{}
+
+// WithTenant confines the users read and written with the returned context to the tenant with the given id.
+func WithTenant(ctx context.Context, tenantId string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantId)
+}
+
+// TenantFrom returns the id of the tenant that ctx is confined to, or an empty string when it names none.
+func TenantFrom(ctx context.Context) string {
+	tenantId, _ := ctx.Value(tenantKey{}).(string)
+	return tenantId
+}
+
+// tenantFilter matches the documents of the tenant that ctx is confined to. Every query on the data of a tenant
+// starts with it, so that a guessed id cannot reach into another tenant. A context that names no tenant is a bug
+// and fails rather than matching every tenant.
+func tenantFilter(ctx context.Context, logger *slog.Logger) (bson.E, error) {
+	tenantId := TenantFrom(ctx)
+	if tenantId == "" {
+		logger.ErrorContext(ctx, "the context names no tenant")
+		return bson.E{}, errs.ServerError
+	}
+
+	return bson.E{Key: "tenantId", Value: tenantId}, nil
+}
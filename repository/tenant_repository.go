@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log/slog"
+	errs "user-service/error"
+	"user-service/model"
+)
+
+const TenantCollectionName = "Tenant"
+
+type TenantRepository struct {
+	tenantCollection *mongo.Collection
+	logger           *slog.Logger
+}
+
+func NewTenantRepository(database *mongo.Database, logger *slog.Logger) *TenantRepository {
+	return &TenantRepository{
+		tenantCollection: database.Collection(TenantCollectionName),
+		logger:           logger,
+	}
+}
+
+type TenantRepositoryInterface interface {
+	Create(context.Context, model.TenantEntity) (*model.TenantEntity, error)
+	GetAll(context.Context) ([]*model.TenantEntity, error)
+	GetById(context.Context, string) (*model.TenantEntity, error)
+	UpdateById(context.Context, string, model.UpdateTenantDomainModel) (*model.TenantEntity, error)
+}
+
+// Create stores a new tenant along with who created it and when. An id that is already taken fails with
+// TenantAlreadyExistsError.
+func (r *TenantRepository) Create(ctx context.Context, tenant model.TenantEntity) (*model.TenantEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant.CreatedAt = now()
+	tenant.UpdatedAt = tenant.CreatedAt
+	tenant.CreatedBy = actorFrom(ctx)
+	tenant.UpdatedBy = tenant.CreatedBy
+
+	_, err := r.tenantCollection.InsertOne(ctx, tenant)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, errs.TenantAlreadyExistsError
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "creating the tenant failed", "id", tenant.Id, "error", err)
+		return nil, errs.ServerError
+	}
+
+	return &tenant, nil
+}
+
+// EnsureDefault creates the tenant that the users stored before tenancy belong to, unless it exists already.
+func (r *TenantRepository) EnsureDefault(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	createdAt := now()
+	_, err := r.tenantCollection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: model.DefaultTenantId}},
+		bson.D{{Key: "$setOnInsert", Value: model.TenantEntity{
+			Id:        model.DefaultTenantId,
+			Name:      "Default",
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+			CreatedBy: SystemActor,
+			UpdatedBy: SystemActor,
+		}}},
+		options.Update().SetUpsert(true))
+
+	return err
+}
+
+// GetAll returns every tenant ordered by id.
+func (r *TenantRepository) GetAll(ctx context.Context) ([]*model.TenantEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	cur, err := r.tenantCollection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "finding the tenants failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	tenants := []*model.TenantEntity{}
+	err = cur.All(ctx, &tenants)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "reading the tenants failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	return tenants, nil
+}
+
+func (r *TenantRepository) GetById(ctx context.Context, id string) (*model.TenantEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	var tenant model.TenantEntity
+	err := r.tenantCollection.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&tenant)
+	if err == mongo.ErrNoDocuments {
+		return nil, errs.TenantNotFoundError
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "finding the tenant failed", "id", id, "error", err)
+		return nil, errs.ServerError
+	}
+
+	return &tenant, nil
+}
+
+// UpdateById changes the fields of update that are set and returns the updated tenant.
+func (r *TenantRepository) UpdateById(ctx context.Context, id string, update model.UpdateTenantDomainModel) (*model.TenantEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	fieldsToSet := bson.D{
+		{Key: "updatedAt", Value: now()},
+		{Key: "updatedBy", Value: actorFrom(ctx)},
+	}
+	if update.Name != nil {
+		fieldsToSet = append(fieldsToSet, bson.E{Key: "name", Value: *update.Name})
+	}
+	if update.Disabled != nil {
+		fieldsToSet = append(fieldsToSet, bson.E{Key: "disabled", Value: *update.Disabled})
+	}
+	updateOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var tenant model.TenantEntity
+	err := r.tenantCollection.FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: id}}, bson.D{{Key: "$set", Value: fieldsToSet}}, updateOptions).Decode(&tenant)
+	if err == mongo.ErrNoDocuments {
+		return nil, errs.TenantNotFoundError
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "updating the tenant failed", "id", id, "error", err)
+		return nil, errs.ServerError
+	}
+
+	return &tenant, nil
+}
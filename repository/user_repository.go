@@ -1,87 +1,513 @@
 package repository
 
 import (
-	"github.com/gin-gonic/gin"
+	"context"
+	"errors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"log"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
 	errs "user-service/error"
 	"user-service/model"
 )
 
-const (
+// StreamBatchSize is how many users Stream asks the server for per round trip.
+const StreamBatchSize = 500
+
+// CollectionName and OperationTimeout are configurable and are set at startup, before any repository is created.
+var (
 	CollectionName = "User"
+	// OperationTimeout bounds every database call. A caller deadline that expires earlier still takes precedence.
+	OperationTimeout = 10 * time.Second
 )
 
 type UserRepository struct {
 	userCollection *mongo.Collection
+	logger         *slog.Logger
 }
 
-func NewUserRepository(database *mongo.Database) *UserRepository {
+func NewUserRepository(database *mongo.Database, logger *slog.Logger) *UserRepository {
 	return &UserRepository{
 		userCollection: database.Collection(CollectionName),
+		logger:         logger,
 	}
 }
 
 type UserRepositoryInterface interface {
-	Create(*gin.Context, model.UserEntity) error
-	GetById(*gin.Context, primitive.ObjectID) (*model.UserEntity, error)
-	CheckIfEmailAlreadyInUse(*gin.Context, string) (bool, error)
-	GetAll(*gin.Context) ([]*model.UserEntity, error)
-	DeleteById(*gin.Context, primitive.ObjectID) error
-	UpdateById(*gin.Context, primitive.ObjectID, model.UpdateUserDomainModel) (*model.UserEntity, error)
+	Create(context.Context, model.UserEntity) (*model.UserEntity, error)
+	GetById(context.Context, primitive.ObjectID) (*model.UserEntity, error)
+	CheckIfEmailAlreadyInUse(context.Context, string) (bool, error)
+	GetAll(context.Context, model.UserFilterDomainModel, model.UserSort, *model.UserCursor, int64) ([]*model.UserEntity, error)
+	GetByIds(context.Context, []primitive.ObjectID) ([]*model.UserEntity, error)
+	Stream(context.Context, model.UserFilterDomainModel, func(*model.UserEntity) error) error
+	DeleteById(context.Context, primitive.ObjectID) error
+	DeleteByIds(context.Context, []primitive.ObjectID) (int64, error)
+	UpdateById(context.Context, primitive.ObjectID, model.UpdateUserDomainModel) (*model.UserEntity, error)
+	UpdateByIds(context.Context, []model.UserUpdateEntity) ([]error, error)
+	ReplaceById(context.Context, model.UserEntity, int64) (*model.UserEntity, error)
+	SyncProfileIndexes(context.Context, []string) error
+}
+
+// profileIndexPrefix starts the name of every index on a profile attribute, which tells them apart from the
+// indexes the repository manages itself.
+const profileIndexPrefix = "profile."
+
+// preTenancyIndexes are the names of the indexes CreateIndexes made before every index started with the tenant.
+var preTenancyIndexes = []string{"createdAt_1__id_1", "updatedAt_1__id_1"}
+
+// The codes of the errors MongoDB fails with when dropping an index that or whose collection does not exist.
+const (
+	namespaceNotFoundCode = 26
+	indexNotFoundCode     = 27
+)
+
+// CreateIndexes makes emails unique per tenant and backs the timestamp sort orders of GetAll, which break ties by
+// id. Every index starts with the tenant, since every query is confined to one.
+func (r *UserRepository) CreateIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	_, err := r.userCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tenantId", Value: 1}, {Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "updatedAt", Value: 1}, {Key: "_id", Value: 1}}},
+	})
+
+	return err
 }
 
-func (r *UserRepository) Create(ctx *gin.Context, user model.UserEntity) error {
-	_, err := r.userCollection.InsertOne(ctx, user)
+// ScopeIndexesByTenant replaces the indexes made before tenancy, including those of profile attributes, with
+// ones that start with the tenant. It is safe to run again.
+func (r *UserRepository) ScopeIndexesByTenant(ctx context.Context) error {
+	names, err := r.profileIndexNames(ctx)
 	if err != nil {
-		log.Println(err)
-		return errs.ServerError
+		return err
+	}
+
+	attributes := make([]string, len(names))
+	for i, name := range names {
+		attributes[i] = strings.TrimPrefix(name, profileIndexPrefix)
+	}
+
+	// Index keys cannot be changed, so the profile indexes are dropped and made again under the same names.
+	err = r.SyncProfileIndexes(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range preTenancyIndexes {
+		_, err := r.userCollection.Indexes().DropOne(ctx, name)
+		var commandError mongo.CommandError
+		if errors.As(err, &commandError) && (commandError.Code == indexNotFoundCode || commandError.Code == namespaceNotFoundCode) {
+			continue
+		} else if err != nil {
+			return err
+		}
+	}
+
+	err = r.SyncProfileIndexes(ctx, attributes)
+	if err != nil {
+		return err
+	}
+
+	return r.CreateIndexes(ctx)
+}
+
+// SyncProfileIndexes makes the indexed profile attributes exactly the given ones, creating missing indexes and
+// dropping those of attributes that are no longer indexed.
+func (r *UserRepository) SyncProfileIndexes(ctx context.Context, attributes []string) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	names, err := r.profileIndexNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	wanted := map[string]bool{}
+	for _, attribute := range attributes {
+		wanted[profileIndexPrefix+attribute] = true
+	}
+
+	existing := map[string]bool{}
+	for _, name := range names {
+		existing[name] = true
+		if !wanted[name] {
+			_, err := r.userCollection.Indexes().DropOne(ctx, name)
+			if err != nil {
+				r.logger.ErrorContext(ctx, "dropping a profile index failed", "index", name, "error", err)
+				return errs.ServerError
+			}
+		}
+	}
+
+	var models []mongo.IndexModel
+	for _, attribute := range attributes {
+		name := profileIndexPrefix + attribute
+		if !existing[name] {
+			models = append(models, mongo.IndexModel{
+				Keys:    bson.D{{Key: "tenantId", Value: 1}, {Key: "profile." + attribute, Value: 1}},
+				Options: options.Index().SetName(name),
+			})
+		}
+	}
+
+	if len(models) > 0 {
+		_, err = r.userCollection.Indexes().CreateMany(ctx, models)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "creating profile indexes failed", "error", err)
+			return errs.ServerError
+		}
 	}
 
 	return nil
 }
 
-func (r *UserRepository) GetById(ctx *gin.Context, id primitive.ObjectID) (user *model.UserEntity, err error) {
-	filter := bson.D{{"_id", id}}
+func (r *UserRepository) profileIndexNames(ctx context.Context) ([]string, error) {
+	cur, err := r.userCollection.Indexes().List(ctx)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "listing the indexes of the users failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	var indexes []struct {
+		Name string `bson:"name"`
+	}
+	err = cur.All(ctx, &indexes)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "reading the indexes of the users failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	var names []string
+	for _, index := range indexes {
+		if strings.HasPrefix(index.Name, profileIndexPrefix) {
+			names = append(names, index.Name)
+		}
+	}
+
+	return names, nil
+}
+
+// BackfillMetadata sets the metadata of users stored before the repository managed it. Their creation time is
+// taken from the timestamp in their ObjectID and they are attributed to the system. It returns how many users
+// were changed and is safe to run again.
+func (r *UserRepository) BackfillMetadata(ctx context.Context) (int64, error) {
+	filter := bson.D{{Key: "createdAt", Value: bson.D{{Key: "$exists", Value: false}}}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.D{
+		{Key: "createdAt", Value: bson.D{{Key: "$toDate", Value: "$_id"}}},
+		{Key: "updatedAt", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$updatedAt", bson.D{{Key: "$toDate", Value: "$_id"}}}}}},
+		{Key: "createdBy", Value: SystemActor},
+		{Key: "updatedBy", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$updatedBy", SystemActor}}}},
+	}}}}
+
+	result, err := r.userCollection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// BackfillTenant moves the users stored before tenancy to the tenant with the given id. It returns how many
+// users were moved and is safe to run again.
+func (r *UserRepository) BackfillTenant(ctx context.Context, tenantId string) (int64, error) {
+	filter := bson.D{{Key: "tenantId", Value: bson.D{{Key: "$exists", Value: false}}}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "tenantId", Value: tenantId}}}}
 
-	result := r.userCollection.FindOne(ctx, filter).Decode(&user)
-	if result == mongo.ErrNoDocuments {
+	result, err := r.userCollection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// NormalizeEmails lower cases the emails stored before emails were normalized and returns how many users changed.
+// It fails while two users of a tenant have emails differing only in case, until one of them is changed, and is
+// safe to run again.
+func (r *UserRepository) NormalizeEmails(ctx context.Context) (int64, error) {
+	filter := bson.D{{Key: "email", Value: primitive.Regex{Pattern: "[A-Z]"}}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.D{{Key: "email", Value: bson.D{{Key: "$toLower", Value: "$email"}}}}}}}
+
+	result, err := r.userCollection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// Create stores the user in the tenant of ctx and returns it with the metadata the repository set. An email
+// that is already taken in the tenant fails with EmailAlreadyInUseError. An id that is already taken fails with
+// NotFoundError, as it may belong to a user of another tenant, whose existence must not show.
+func (r *UserRepository) Create(ctx context.Context, user model.UserEntity) (*model.UserEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	user.TenantId = tenant.Value.(string)
+	user.CreatedAt = now()
+	user.UpdatedAt = user.CreatedAt
+	user.CreatedBy = actorFrom(ctx)
+	user.UpdatedBy = user.CreatedBy
+
+	_, err = r.userCollection.InsertOne(ctx, user)
+	if isDuplicateIdError(err) {
+		r.logger.DebugContext(ctx, "the id of the user is taken", "id", user.Id.Hex())
 		return nil, errs.NotFoundError
-	} else if result != nil {
-		log.Println(err)
+	} else if mongo.IsDuplicateKeyError(err) {
+		return nil, errs.EmailAlreadyInUseError
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "creating the user failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepository) GetById(ctx context.Context, id primitive.ObjectID) (user *model.UserEntity, err error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{tenant, {Key: "_id", Value: id}}
+
+	err = r.userCollection.FindOne(ctx, filter).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil, errs.NotFoundError
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "finding the user failed", "id", id.Hex(), "error", err)
 		return nil, errs.ServerError
 	}
 
 	return
 }
 
-func (r *UserRepository) GetAll(ctx *gin.Context) (users []*model.UserEntity, err error) {
-	cur, err := r.userCollection.Find(ctx, bson.D{{}})
+// GetAll returns the users matching the filter in the given order, starting after the cursor, or from the start
+// when it is nil. A zero limit returns every remaining user.
+func (r *UserRepository) GetAll(ctx context.Context, userFilter model.UserFilterDomainModel, sort model.UserSort, after *model.UserCursor, limit int64) (users []*model.UserEntity, err error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := append(bson.D{tenant}, userFilterToBson(userFilter)...)
+	if after != nil {
+		filter = append(filter, cursorFilter(sort, after))
+	}
+
+	findOptions := options.Find().SetSort(sortToBson(sort)).SetLimit(limit)
+
+	cur, err := r.userCollection.Find(ctx, filter, findOptions)
 	if err != nil {
-		log.Println(err)
+		r.logger.ErrorContext(ctx, "listing the users failed", "error", err)
 		return users, errs.ServerError
 	}
 
+	return r.decodeUsers(ctx, cur)
+}
+
+// GetByIds returns the users with the given ids in a single query. Ids that do not belong to a user are left out.
+func (r *UserRepository) GetByIds(ctx context.Context, ids []primitive.ObjectID) ([]*model.UserEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{tenant, {Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}}
+
+	cur, err := r.userCollection.Find(ctx, filter)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "finding the users by id failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	return r.decodeUsers(ctx, cur)
+}
+
+// Stream calls fn with every user matching the filter ordered by id, holding only one cursor batch in memory.
+// Passwords are never read. It is bound by the caller's context rather than OperationTimeout since a full scan
+// can take much longer, and it stops with the first error fn returns.
+func (r *UserRepository) Stream(ctx context.Context, userFilter model.UserFilterDomainModel, fn func(*model.UserEntity) error) error {
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return err
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetProjection(bson.D{{Key: "password", Value: 0}}).
+		SetBatchSize(StreamBatchSize)
+
+	cur, err := r.userCollection.Find(ctx, append(bson.D{tenant}, userFilterToBson(userFilter)...), findOptions)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "streaming the users failed", "error", err)
+		return errs.ServerError
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var user model.UserEntity
+		err := cur.Decode(&user)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "decoding a user failed", "error", err)
+			return errs.ServerError
+		}
+
+		err = fn(&user)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := cur.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "streaming the users failed", "error", err)
+		return errs.ServerError
+	}
+
+	return nil
+}
+
+func (r *UserRepository) decodeUsers(ctx context.Context, cur *mongo.Cursor) (users []*model.UserEntity, err error) {
+	defer cur.Close(ctx)
+
 	for cur.Next(ctx) {
 		var user model.UserEntity
 		err := cur.Decode(&user)
 		if err != nil {
-			log.Println(err)
+			r.logger.ErrorContext(ctx, "decoding a user failed", "error", err)
+			return nil, errs.ServerError
 		}
 
 		users = append(users, &user)
 	}
 
+	// Next also stops when the context is cancelled, which must not pass for a complete result.
+	if err := cur.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "reading the users failed", "error", err)
+		return nil, errs.ServerError
+	}
+
 	return
 }
 
-func (r *UserRepository) DeleteById(ctx *gin.Context, id primitive.ObjectID) error {
-	filter := bson.D{{"_id", id}}
+func userFilterToBson(userFilter model.UserFilterDomainModel) bson.D {
+	filter := bson.D{}
+
+	if userFilter.Name != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(userFilter.Name), Options: "i"}
+		filter = append(filter, bson.E{Key: "name", Value: pattern})
+	}
+	if userFilter.Email != "" {
+		filter = append(filter, bson.E{Key: "email", Value: userFilter.Email})
+	}
+	if userFilter.CreatedBy != "" {
+		filter = append(filter, bson.E{Key: "createdBy", Value: userFilter.CreatedBy})
+	}
+	if userFilter.UpdatedBy != "" {
+		filter = append(filter, bson.E{Key: "updatedBy", Value: userFilter.UpdatedBy})
+	}
+	if createdAt := timeRange(userFilter.CreatedAfter, userFilter.CreatedBefore); createdAt != nil {
+		filter = append(filter, bson.E{Key: "createdAt", Value: createdAt})
+	}
+	if updatedAt := timeRange(userFilter.UpdatedAfter, userFilter.UpdatedBefore); updatedAt != nil {
+		filter = append(filter, bson.E{Key: "updatedAt", Value: updatedAt})
+	}
+	for attribute, value := range userFilter.Profile {
+		filter = append(filter, bson.E{Key: "profile." + attribute, Value: value})
+	}
+
+	return filter
+}
+
+func timeRange(after time.Time, before time.Time) bson.D {
+	var condition bson.D
+
+	if !after.IsZero() {
+		condition = append(condition, bson.E{Key: "$gte", Value: after})
+	}
+	if !before.IsZero() {
+		condition = append(condition, bson.E{Key: "$lt", Value: before})
+	}
+
+	return condition
+}
+
+// sortFields maps the model.Sort constants to the document fields they sort by.
+var sortFields = map[string]string{
+	model.SortByCreatedAt: "createdAt",
+	model.SortByUpdatedAt: "updatedAt",
+}
+
+func sortToBson(sort model.UserSort) bson.D {
+	direction := 1
+	if sort.Descending {
+		direction = -1
+	}
+
+	field, ok := sortFields[sort.Field]
+	if !ok {
+		return bson.D{{Key: "_id", Value: direction}}
+	}
+
+	return bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}
+}
+
+// cursorFilter matches the users that come after the cursor in the sort order. Ties on the sort field are
+// broken by id, which keeps pages stable when many users share a timestamp.
+func cursorFilter(sort model.UserSort, after *model.UserCursor) bson.E {
+	operator := "$gt"
+	if sort.Descending {
+		operator = "$lt"
+	}
+
+	field, ok := sortFields[sort.Field]
+	if !ok {
+		return bson.E{Key: "_id", Value: bson.D{{Key: operator, Value: after.Id}}}
+	}
+
+	return bson.E{Key: "$or", Value: bson.A{
+		bson.D{{Key: field, Value: bson.D{{Key: operator, Value: after.Time}}}},
+		bson.D{{Key: field, Value: after.Time}, {Key: "_id", Value: bson.D{{Key: operator, Value: after.Id}}}},
+	}}
+}
+
+func (r *UserRepository) DeleteById(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{tenant, {Key: "_id", Value: id}}
 
 	result, err := r.userCollection.DeleteOne(ctx, filter)
 	if err != nil {
+		r.logger.ErrorContext(ctx, "deleting the user failed", "id", id.Hex(), "error", err)
 		return errs.ServerError
 	} else if result.DeletedCount == 0 {
 		return errs.NotFoundError
@@ -90,12 +516,42 @@ func (r *UserRepository) DeleteById(ctx *gin.Context, id primitive.ObjectID) err
 	return nil
 }
 
-func (r *UserRepository) CheckIfEmailAlreadyInUse(ctx *gin.Context, email string) (bool, error) {
-	filter := bson.D{{"email", email}}
+// DeleteByIds deletes the users with the given ids in a single query and returns how many were deleted.
+func (r *UserRepository) DeleteByIds(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return 0, err
+	}
+
+	filter := bson.D{tenant, {Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}}
+
+	result, err := r.userCollection.DeleteMany(ctx, filter)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "deleting the users failed", "error", err)
+		return 0, errs.ServerError
+	}
+
+	return result.DeletedCount, nil
+}
+
+// CheckIfEmailAlreadyInUse tells whether a user of the tenant of ctx has the email. Users of other tenants may.
+func (r *UserRepository) CheckIfEmailAlreadyInUse(ctx context.Context, email string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return false, err
+	}
+
+	filter := bson.D{tenant, {Key: "email", Value: email}}
 
 	count, err := r.userCollection.CountDocuments(ctx, filter)
 	if err != nil {
-		log.Println(err)
+		r.logger.ErrorContext(ctx, "checking whether the email is in use failed", "error", err)
 		return false, errs.ServerError
 	}
 
@@ -106,8 +562,74 @@ func (r *UserRepository) CheckIfEmailAlreadyInUse(ctx *gin.Context, email string
 	}
 }
 
-func (r *UserRepository) UpdateById(ctx *gin.Context, id primitive.ObjectID, domainModel model.UpdateUserDomainModel) (*model.UserEntity, error) {
-	var fieldsToUpdate bson.D
+func (r *UserRepository) UpdateById(ctx context.Context, id primitive.ObjectID, domainModel model.UpdateUserDomainModel) (*model.UserEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{tenant, {Key: "_id", Value: id}}
+
+	result, err := r.userCollection.UpdateOne(ctx, filter, updateToBson(ctx, domainModel))
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, errs.EmailAlreadyInUseError
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "updating the user failed", "id", id.Hex(), "error", err)
+		return nil, errs.ServerError
+	} else if result.ModifiedCount == 0 {
+		return nil, errs.NotFoundError
+	}
+
+	return r.GetById(ctx, id)
+}
+
+// UpdateByIds applies every update in one unordered bulk write. The returned slice holds the error of each
+// update by position, nil for the ones that were applied. Updates of ids that do not exist are silently skipped.
+func (r *UserRepository) UpdateByIds(ctx context.Context, updates []model.UserUpdateEntity) ([]error, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	writeModels := make([]mongo.WriteModel, len(updates))
+	for i, update := range updates {
+		writeModels[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.D{tenant, {Key: "_id", Value: update.Id}}).
+			SetUpdate(updateToBson(ctx, update.Update))
+	}
+
+	itemErrors := make([]error, len(updates))
+
+	_, err = r.userCollection.BulkWrite(ctx, writeModels, options.BulkWrite().SetOrdered(false))
+
+	var bulkWriteException mongo.BulkWriteException
+	if errors.As(err, &bulkWriteException) && bulkWriteException.WriteConcernError == nil {
+		for _, writeError := range bulkWriteException.WriteErrors {
+			r.logger.ErrorContext(ctx, "updating a user of a batch failed", "id", updates[writeError.Index].Id.Hex(), "error", writeError)
+			itemErrors[writeError.Index] = errs.ServerError
+			if mongo.IsDuplicateKeyError(writeError) {
+				itemErrors[writeError.Index] = errs.EmailAlreadyInUseError
+			}
+		}
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "updating the users failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	return itemErrors, nil
+}
+
+func updateToBson(ctx context.Context, domainModel model.UpdateUserDomainModel) bson.D {
+	fieldsToUpdate := bson.D{
+		{Key: "updatedAt", Value: now()},
+		{Key: "updatedBy", Value: actorFrom(ctx)},
+	}
 
 	if domainModel.Name != nil {
 		fieldsToUpdate = append(fieldsToUpdate, bson.E{Key: "name", Value: domainModel.Name})
@@ -116,20 +638,102 @@ func (r *UserRepository) UpdateById(ctx *gin.Context, id primitive.ObjectID, dom
 		fieldsToUpdate = append(fieldsToUpdate, bson.E{Key: "email", Value: domainModel.Email})
 	}
 	if domainModel.Password != nil {
-		fieldsToUpdate = append(fieldsToUpdate, bson.E{Key: "password", Value: domainModel.Email})
+		fieldsToUpdate = append(fieldsToUpdate, bson.E{Key: "password", Value: domainModel.Password})
 	}
-	
-	filter := bson.D{{"_id", id}}
 
-	result, err := r.userCollection.UpdateOne(ctx, filter, bson.D{{"$set", fieldsToUpdate}})
+	// The profile is merged attribute by attribute, so a patch leaves the attributes it does not name alone.
+	var fieldsToRemove bson.D
+	for attribute, value := range domainModel.Profile {
+		if value == nil {
+			fieldsToRemove = append(fieldsToRemove, bson.E{Key: "profile." + attribute, Value: ""})
+		} else {
+			fieldsToUpdate = append(fieldsToUpdate, bson.E{Key: "profile." + attribute, Value: value})
+		}
+	}
+
+	update := bson.D{
+		{Key: "$set", Value: fieldsToUpdate},
+		{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}},
+	}
+	if len(fieldsToRemove) > 0 {
+		update = append(update, bson.E{Key: "$unset", Value: fieldsToRemove})
+	}
+
+	return update
+}
+
+// ReplaceById overwrites the stored user only if it is still at expectedVersion, so concurrent writers
+// cannot silently clobber each other. The creation metadata is kept and the replaced user is returned.
+func (r *UserRepository) ReplaceById(ctx context.Context, user model.UserEntity, expectedVersion int64) (*model.UserEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
 	if err != nil {
-		log.Println(err)
+		return nil, err
+	}
+
+	filter := bson.D{tenant, {Key: "_id", Value: user.Id}, versionFilter(expectedVersion)}
+	fieldsToSet := bson.D{
+		{Key: "name", Value: user.Name},
+		{Key: "email", Value: user.Email},
+		{Key: "password", Value: user.Password},
+		{Key: "version", Value: user.Version},
+		{Key: "updatedAt", Value: now()},
+		{Key: "updatedBy", Value: actorFrom(ctx)},
+	}
+
+	update := bson.D{{Key: "$set", Value: fieldsToSet}}
+	if len(user.Profile) > 0 {
+		update[0].Value = append(fieldsToSet, bson.E{Key: "profile", Value: user.Profile})
+	} else {
+		update = append(update, bson.E{Key: "$unset", Value: bson.D{{Key: "profile", Value: ""}}})
+	}
+	updateOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var replaced model.UserEntity
+	err = r.userCollection.FindOneAndUpdate(ctx, filter, update, updateOptions).Decode(&replaced)
+	if err == mongo.ErrNoDocuments {
+		return nil, errs.PreconditionFailedError
+	} else if mongo.IsDuplicateKeyError(err) {
+		return nil, errs.EmailAlreadyInUseError
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "replacing the user failed", "id", user.Id.Hex(), "error", err)
 		return nil, errs.ServerError
-	} else if result.ModifiedCount == 0 {
-		return nil, errs.NotFoundError
 	}
 
-	user, err := r.GetById(ctx, id)
+	return &replaced, nil
+}
+
+// isDuplicateIdError tells a write that failed on the unique index MongoDB keeps on _id apart from those that
+// failed on the indexes CreateIndexes made.
+func isDuplicateIdError(err error) bool {
+	var writeException mongo.WriteException
+	if !errors.As(err, &writeException) {
+		return false
+	}
+
+	for _, writeError := range writeException.WriteErrors {
+		if mongo.IsDuplicateKeyError(writeError) && strings.Contains(writeError.Message, " index: _id_ ") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// now is truncated to milliseconds, the precision BSON dates are stored with, so returned entities match what a
+// later read would see.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// versionFilter matches documents at the given version. Users written before versioning was introduced
+// have no version field and are treated as version 0.
+func versionFilter(version int64) bson.E {
+	if version == 0 {
+		return bson.E{Key: "version", Value: bson.D{{Key: "$in", Value: bson.A{0, nil}}}}
+	}
 
-	return user, nil
+	return bson.E{Key: "version", Value: version}
 }
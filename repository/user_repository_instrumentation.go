@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+	errs "user-service/error"
+	"user-service/metrics"
+	"user-service/model"
+	"user-service/tracing"
+)
+
+// instrumentedUserRepository traces every operation of a user repository and records its latency and failures.
+type instrumentedUserRepository struct {
+	next UserRepositoryInterface
+}
+
+func NewInstrumentedUserRepository(next UserRepositoryInterface) UserRepositoryInterface {
+	return &instrumentedUserRepository{
+		next: next,
+	}
+}
+
+func (r *instrumentedUserRepository) Create(ctx context.Context, entity model.UserEntity) (*model.UserEntity, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserRepository.Create")
+	start := time.Now()
+	user, err := r.next.Create(ctx, entity)
+	observe(span, "Create", start, err)
+	return user, err
+}
+
+func (r *instrumentedUserRepository) GetById(ctx context.Context, id primitive.ObjectID) (*model.UserEntity, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserRepository.GetById")
+	start := time.Now()
+	user, err := r.next.GetById(ctx, id)
+	observe(span, "GetById", start, err)
+	return user, err
+}
+
+func (r *instrumentedUserRepository) CheckIfEmailAlreadyInUse(ctx context.Context, email string) (bool, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserRepository.CheckIfEmailAlreadyInUse")
+	start := time.Now()
+	inUse, err := r.next.CheckIfEmailAlreadyInUse(ctx, email)
+	observe(span, "CheckIfEmailAlreadyInUse", start, err)
+	return inUse, err
+}
+
+func (r *instrumentedUserRepository) GetAll(ctx context.Context, filter model.UserFilterDomainModel, sort model.UserSort, after *model.UserCursor, limit int64) ([]*model.UserEntity, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserRepository.GetAll")
+	start := time.Now()
+	users, err := r.next.GetAll(ctx, filter, sort, after, limit)
+	observe(span, "GetAll", start, err)
+	return users, err
+}
+
+func (r *instrumentedUserRepository) GetByIds(ctx context.Context, ids []primitive.ObjectID) ([]*model.UserEntity, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserRepository.GetByIds")
+	start := time.Now()
+	users, err := r.next.GetByIds(ctx, ids)
+	observe(span, "GetByIds", start, err)
+	return users, err
+}
+
+// Stream is observed as a whole, including the time the callback takes for every user and the errors it returns.
+func (r *instrumentedUserRepository) Stream(ctx context.Context, filter model.UserFilterDomainModel, callback func(*model.UserEntity) error) error {
+	ctx, span := tracing.Tracer.Start(ctx, "UserRepository.Stream")
+	start := time.Now()
+	err := r.next.Stream(ctx, filter, callback)
+	observe(span, "Stream", start, err)
+	return err
+}
+
+func (r *instrumentedUserRepository) DeleteById(ctx context.Context, id primitive.ObjectID) error {
+	ctx, span := tracing.Tracer.Start(ctx, "UserRepository.DeleteById")
+	start := time.Now()
+	err := r.next.DeleteById(ctx, id)
+	observe(span, "DeleteById", start, err)
+	return err
+}
+
+func (r *instrumentedUserRepository) DeleteByIds(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserRepository.DeleteByIds")
+	start := time.Now()
+	deleted, err := r.next.DeleteByIds(ctx, ids)
+	observe(span, "DeleteByIds", start, err)
+	return deleted, err
+}
+
+func (r *instrumentedUserRepository) UpdateById(ctx context.Context, id primitive.ObjectID, updateDomainModel model.UpdateUserDomainModel) (*model.UserEntity, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserRepository.UpdateById")
+	start := time.Now()
+	user, err := r.next.UpdateById(ctx, id, updateDomainModel)
+	observe(span, "UpdateById", start, err)
+	return user, err
+}
+
+func (r *instrumentedUserRepository) UpdateByIds(ctx context.Context, updates []model.UserUpdateEntity) ([]error, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserRepository.UpdateByIds")
+	start := time.Now()
+	results, err := r.next.UpdateByIds(ctx, updates)
+	observe(span, "UpdateByIds", start, err)
+	return results, err
+}
+
+func (r *instrumentedUserRepository) ReplaceById(ctx context.Context, entity model.UserEntity, expectedVersion int64) (*model.UserEntity, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserRepository.ReplaceById")
+	start := time.Now()
+	user, err := r.next.ReplaceById(ctx, entity, expectedVersion)
+	observe(span, "ReplaceById", start, err)
+	return user, err
+}
+
+func (r *instrumentedUserRepository) SyncProfileIndexes(ctx context.Context, attributes []string) error {
+	ctx, span := tracing.Tracer.Start(ctx, "UserRepository.SyncProfileIndexes")
+	start := time.Now()
+	err := r.next.SyncProfileIndexes(ctx, attributes)
+	observe(span, "SyncProfileIndexes", start, err)
+	return err
+}
+
+// observe records the latency of an operation and ends its span. It counts the operation as failed unless it only
+// found no user or a user that did not match a precondition, which are answers of the database rather than
+// failures.
+func observe(span trace.Span, operation string, start time.Time, err error) {
+	metrics.MongoOperationDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+
+	if errors.Is(err, errs.NotFoundError) || errors.Is(err, errs.PreconditionFailedError) {
+		err = nil
+	}
+	if err != nil {
+		metrics.MongoOperationErrors.WithLabelValues(operation).Inc()
+	}
+
+	tracing.End(span, err)
+}
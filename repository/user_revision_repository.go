@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log/slog"
+	errs "user-service/error"
+	"user-service/model"
+)
+
+const RevisionCollectionName = "UserRevision"
+
+type UserRevisionRepository struct {
+	revisionCollection *mongo.Collection
+	logger             *slog.Logger
+}
+
+func NewUserRevisionRepository(database *mongo.Database, logger *slog.Logger) *UserRevisionRepository {
+	return &UserRevisionRepository{
+		revisionCollection: database.Collection(RevisionCollectionName),
+		logger:             logger,
+	}
+}
+
+type UserRevisionRepositoryInterface interface {
+	Create(context.Context, []model.UserRevisionEntity) error
+	GetAll(context.Context, primitive.ObjectID) ([]*model.UserRevisionEntity, error)
+	GetByRevision(context.Context, primitive.ObjectID, int64) (*model.UserRevisionEntity, error)
+	DeleteByUserIds(context.Context, []primitive.ObjectID) error
+}
+
+// CreateIndexes makes revision numbers unique per user, which also serves the lookups of this repository.
+func (r *UserRevisionRepository) CreateIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	_, err := r.revisionCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "revision", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return err
+}
+
+// BackfillTenant moves the revisions recorded before tenancy to the tenant with the given id. It returns how many
+// revisions were moved and is safe to run again.
+func (r *UserRevisionRepository) BackfillTenant(ctx context.Context, tenantId string) (int64, error) {
+	filter := bson.D{{Key: "tenantId", Value: bson.D{{Key: "$exists", Value: false}}}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "tenantId", Value: tenantId}}}}
+
+	result, err := r.revisionCollection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// Create stores the given revisions in the tenant of ctx in one round trip. Revision numbers are unique per user,
+// which the revisions of deleted users would break for a user created again with the same id, so they are
+// deleted with the user.
+func (r *UserRevisionRepository) Create(ctx context.Context, revisions []model.UserRevisionEntity) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return err
+	}
+
+	documents := make([]interface{}, len(revisions))
+	for i, revision := range revisions {
+		revision.TenantId = tenant.Value.(string)
+		documents[i] = revision
+	}
+
+	_, err = r.revisionCollection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "creating revisions failed", "error", err)
+		return errs.ServerError
+	}
+
+	return nil
+}
+
+// GetAll returns every revision of the user, oldest first.
+func (r *UserRevisionRepository) GetAll(ctx context.Context, userId primitive.ObjectID) (revisions []*model.UserRevisionEntity, err error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{tenant, {Key: "userId", Value: userId}}
+	findOptions := options.Find().SetSort(bson.D{{Key: "revision", Value: 1}})
+
+	cur, err := r.revisionCollection.Find(ctx, filter, findOptions)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "listing the revisions failed", "userId", userId.Hex(), "error", err)
+		return nil, errs.ServerError
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var revision model.UserRevisionEntity
+		err := cur.Decode(&revision)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "decoding a revision failed", "userId", userId.Hex(), "error", err)
+			return nil, errs.ServerError
+		}
+
+		revisions = append(revisions, &revision)
+	}
+
+	if err := cur.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "reading the revisions failed", "userId", userId.Hex(), "error", err)
+		return nil, errs.ServerError
+	}
+
+	return
+}
+
+func (r *UserRevisionRepository) GetByRevision(ctx context.Context, userId primitive.ObjectID, revision int64) (*model.UserRevisionEntity, error) {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{tenant, {Key: "userId", Value: userId}, {Key: "revision", Value: revision}}
+
+	var result model.UserRevisionEntity
+	err = r.revisionCollection.FindOne(ctx, filter).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return nil, errs.RevisionNotFoundError
+	} else if err != nil {
+		r.logger.ErrorContext(ctx, "finding the revision failed", "userId", userId.Hex(), "revision", revision, "error", err)
+		return nil, errs.ServerError
+	}
+
+	return &result, nil
+}
+
+// DeleteByUserIds deletes every revision of the users in the tenant of ctx.
+func (r *UserRevisionRepository) DeleteByUserIds(ctx context.Context, userIds []primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, OperationTimeout)
+	defer cancel()
+
+	tenant, err := tenantFilter(ctx, r.logger)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{tenant, {Key: "userId", Value: bson.D{{Key: "$in", Value: userIds}}}}
+
+	_, err = r.revisionCollection.DeleteMany(ctx, filter)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "deleting the revisions failed", "count", len(userIds), "error", err)
+		return errs.ServerError
+	}
+
+	return nil
+}
+
+// DeleteOrphans deletes the revisions of users that no longer exist, which were left behind by deletes before
+// DeleteByUserIds. It returns how many revisions were deleted and is safe to run again.
+func (r *UserRevisionRepository) DeleteOrphans(ctx context.Context) (int64, error) {
+	userIds, err := r.revisionCollection.Distinct(ctx, "userId", bson.D{})
+	if err != nil {
+		return 0, err
+	}
+
+	existing, err := r.revisionCollection.Database().Collection(CollectionName).
+		Distinct(ctx, "_id", bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: userIds}}}})
+	if err != nil {
+		return 0, err
+	}
+
+	filter := bson.D{{Key: "userId", Value: bson.D{{Key: "$nin", Value: existing}}}}
+
+	result, err := r.revisionCollection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
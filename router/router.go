@@ -0,0 +1,129 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"user-service/controller"
+	"user-service/model"
+)
+
+// Register adds every route the service serves to the given engine, each guarded by the scope it requires. The
+// routes that reach users or groups are confined to the tenant of the request. A nil docsController, graphqlHandler or
+// metricsHandler leaves the routes of that feature out.
+func Register(router *gin.Engine, userController *controller.UserController, avatarController *controller.AvatarController, importController *controller.ImportController, profileSchemaController *controller.ProfileSchemaController, apiKeyController *controller.ApiKeyController, tenantController *controller.TenantController, groupController *controller.GroupController, healthController *controller.HealthController, docsController *controller.DocsController, graphqlHandler http.Handler, metricsHandler http.Handler) {
+	router.GET("/healthz", healthController.Live)
+	router.GET("/readyz", healthController.Ready)
+	router.GET("/health", healthController.Health)
+
+	read := apiKeyController.Require(model.ScopeUsersRead)
+	write := apiKeyController.Require(model.ScopeUsersWrite)
+
+	// The group has no prefix of its own; it only resolves the tenant before the routes of the users and groups.
+	tenanted := router.Group("", tenantController.Resolve)
+	tenanted.GET("/users", read, userController.GetAll)
+	tenanted.GET("/users/:id", read, userController.GetById)
+	tenanted.GET("/users/export", read, userController.Export)
+	tenanted.POST("/users/import", write, importController.Create)
+	tenanted.GET("/users/import/:jobId", read, importController.GetById)
+	tenanted.GET("/users/import/:jobId/errors", read, importController.GetErrorReport)
+	tenanted.POST("/users", write, userController.Create)
+	tenanted.PATCH("/users/:id", write, userController.UpdateById)
+	tenanted.PUT("/users/:id", write, userController.ReplaceById)
+	tenanted.DELETE("/users/:id", write, userController.DeleteById)
+	tenanted.GET("/users/:id/revisions", read, userController.GetRevisions)
+	tenanted.GET("/users/:id/revisions/:rev", read, userController.GetRevision)
+	tenanted.GET("/users/:id/revisions/:rev/diff", read, userController.DiffRevisions)
+	tenanted.POST("/users/:id/revisions/:rev/revert", write, userController.RevertToRevision)
+	tenanted.GET("/users/:id/avatar", read, avatarController.Get)
+	tenanted.PUT("/users/:id/avatar", write, avatarController.Upload)
+	tenanted.GET("/users/:id/groups", read, groupController.GetGroupsOfUser)
+
+	// Groups are made of users, so they share their scopes.
+	tenanted.POST("/groups", write, groupController.Create)
+	tenanted.GET("/groups", read, groupController.GetAll)
+	tenanted.GET("/groups/:id", read, groupController.GetById)
+	tenanted.PATCH("/groups/:id", write, groupController.UpdateById)
+	tenanted.DELETE("/groups/:id", write, groupController.DeleteById)
+	tenanted.GET("/groups/:id/members", read, groupController.GetMembers)
+
+	router.GET("/profile-schema", read, profileSchemaController.Get)
+	router.PUT("/profile-schema", apiKeyController.Require(model.ScopeProfileSchemaWrite), profileSchemaController.Publish)
+
+	manage := apiKeyController.Require(model.ScopeApiKeysManage)
+	router.POST("/apikeys", manage, apiKeyController.Create)
+	router.GET("/apikeys", manage, apiKeyController.GetAll)
+	router.DELETE("/apikeys/:id", manage, apiKeyController.Revoke)
+	router.POST("/apikeys/:id/rotate", manage, apiKeyController.Rotate)
+
+	tenants := apiKeyController.Require(model.ScopeTenantsManage)
+	router.POST("/tenants", tenants, tenantController.Create)
+	router.GET("/tenants", tenants, tenantController.GetAll)
+	router.GET("/tenants/:id", tenants, tenantController.GetById)
+	router.PATCH("/tenants/:id", tenants, tenantController.UpdateById)
+
+	for _, route := range customMethodRoutes(userController, groupController) {
+		tenanted.Handle(route.method, route.resource+":verb", route.dispatch(apiKeyController))
+	}
+
+	if graphqlHandler != nil {
+		// Queries and mutations share the route, so it requires the scope of the mutations.
+		tenanted.POST("/graphql", write, gin.WrapH(graphqlHandler))
+	}
+
+	if metricsHandler != nil {
+		router.GET("/metrics", gin.WrapH(metricsHandler))
+	}
+
+	if docsController != nil {
+		router.GET("/openapi.json", docsController.GetDocument)
+		router.GET("/docs/*filepath", docsController.GetUI)
+	}
+}
+
+// customMethodRoute serves custom methods such as POST /users:batchGet. Gin reads the colon as the start of a
+// parameter, so every verb of a resource and HTTP method shares one route that dispatches on the parameter.
+type customMethodRoute struct {
+	method   string
+	resource string
+	verbs    map[string]customMethod
+}
+
+type customMethod struct {
+	scope   string
+	handler gin.HandlerFunc
+}
+
+func customMethodRoutes(userController *controller.UserController, groupController *controller.GroupController) []customMethodRoute {
+	return []customMethodRoute{
+		{http.MethodPost, "/users", map[string]customMethod{
+			"batchGet":    {model.ScopeUsersRead, userController.BatchGet},
+			"batchDelete": {model.ScopeUsersWrite, userController.BatchDelete},
+		}},
+		{http.MethodPatch, "/users", map[string]customMethod{
+			"batchUpdate": {model.ScopeUsersWrite, userController.BatchUpdate},
+		}},
+		{http.MethodPost, "/groups/:id/members", map[string]customMethod{
+			"batchAdd":    {model.ScopeUsersWrite, groupController.AddMembers},
+			"batchRemove": {model.ScopeUsersWrite, groupController.RemoveMembers},
+		}},
+	}
+}
+
+// dispatch checks the scope of the verb before handing the request to it, since the scopes differ by verb.
+func (r customMethodRoute) dispatch(apiKeyController *controller.ApiKeyController) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		verb, ok := r.verbs[strings.TrimPrefix(ctx.Param("verb"), ":")]
+		if !ok {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		apiKeyController.Require(verb.scope)(ctx)
+		if ctx.IsAborted() {
+			return
+		}
+
+		verb.handler(ctx)
+	}
+}
@@ -0,0 +1,185 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"user-service/controller"
+	errs "user-service/error"
+	"user-service/graphqlapi"
+	"user-service/i18n"
+	"user-service/importer"
+	"user-service/logging"
+	"user-service/metrics"
+	"user-service/model"
+	"user-service/openapi"
+	serviceMock "user-service/service/mock"
+)
+
+// testValidator and testCatalog are set up the way main sets them up.
+var testValidator, testCatalog = newTestValidation()
+
+func newTestValidation() (*validator.Validate, *i18n.Catalog) {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(controller.JsonFieldName)
+
+	catalog, err := i18n.NewCatalog(validate)
+	if err != nil {
+		panic(err)
+	}
+
+	return validate, catalog
+}
+
+func newTestRouter(document *openapi.Document, anonymousScopes ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userController := controller.NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard())
+	importController := controller.NewImportController(importer.NewImporter(userServiceMock, testValidator, testCatalog, "", logging.Discard()), testCatalog, logging.Discard())
+	profileSchemaController := controller.NewProfileSchemaController(new(serviceMock.ProfileSchemaServiceInterface), testValidator, testCatalog, logging.Discard())
+	avatarController := controller.NewAvatarController(new(serviceMock.AvatarServiceInterface), testCatalog, logging.Discard())
+	apiKeyController := controller.NewApiKeyController(new(serviceMock.ApiKeyServiceInterface), testValidator, testCatalog, logging.Discard(), anonymousScopes)
+	healthController := controller.NewHealthController(new(serviceMock.HealthServiceInterface))
+	tenantServiceMock := new(serviceMock.TenantServiceInterface)
+	tenantServiceMock.On("Resolve", mock.Anything, model.DefaultTenantId).Return(nil)
+	tenantServiceMock.On("Resolve", mock.Anything, mock.Anything).Return(errs.TenantNotFoundError)
+	tenantController := controller.NewTenantController(tenantServiceMock, testValidator, testCatalog, logging.Discard(), model.DefaultTenantId, "")
+	groupController := controller.NewGroupController(new(serviceMock.GroupServiceInterface), testValidator, testCatalog, logging.Discard())
+	router.Use(apiKeyController.Authenticate)
+	Register(router, userController, avatarController, importController, profileSchemaController, apiKeyController, tenantController, groupController, healthController, controller.NewDocsController(document), graphqlapi.NewHandler(userServiceMock, testValidator), metrics.Handler())
+
+	return router
+}
+
+// registeredRoutes lists the routes of the router with every custom method route expanded into its verbs.
+func registeredRoutes(router *gin.Engine) []gin.RouteInfo {
+	var routes []gin.RouteInfo
+	for _, route := range router.Routes() {
+		if !strings.HasSuffix(route.Path, ":verb") {
+			routes = append(routes, route)
+		}
+	}
+
+	for _, customRoute := range customMethodRoutes(nil, nil) {
+		for verb := range customRoute.verbs {
+			routes = append(routes, gin.RouteInfo{Method: customRoute.method, Path: customRoute.resource + ":" + verb})
+		}
+	}
+
+	return routes
+}
+
+func Test_OpenAPIDocument_Should_Describe_Every_Registered_Route(t *testing.T) {
+	document := openapi.NewDocument()
+	router := newTestRouter(document, model.Scopes...)
+
+	for _, route := range registeredRoutes(router) {
+		assert.True(t, document.HasOperation(route.Method, route.Path), "%s %s is missing from the OpenAPI document", route.Method, route.Path)
+	}
+}
+
+func Test_OpenAPIDocument_Should_Only_Describe_Registered_Routes(t *testing.T) {
+	document := openapi.NewDocument()
+	router := newTestRouter(document, model.Scopes...)
+
+	registered := map[string]bool{}
+	for _, route := range registeredRoutes(router) {
+		registered[route.Method+" "+openapi.ToOpenAPIPath(route.Path)] = true
+	}
+
+	for path, pathItem := range document.Paths {
+		for method := range *pathItem {
+			assert.True(t, registered[strings.ToUpper(method)+" "+path], "%s %s is documented but not registered", method, path)
+		}
+	}
+}
+
+func Test_Docs_Should_Serve_Swagger_UI_And_OpenAPI_Document(t *testing.T) {
+	router := newTestRouter(openapi.NewDocument(), model.Scopes...)
+
+	for _, path := range []string{"/openapi.json", "/docs/", "/docs/swagger-ui-bundle.js", "/docs/swagger-initializer.js"} {
+		responseRecorder := httptest.NewRecorder()
+		router.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		assert.Equal(t, http.StatusOK, responseRecorder.Code, path)
+	}
+}
+
+func Test_Register_Should_Leave_Out_Disabled_Features(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	Register(
+		router,
+		controller.NewUserController(userServiceMock, testValidator, testCatalog, logging.Discard()),
+		controller.NewAvatarController(new(serviceMock.AvatarServiceInterface), testCatalog, logging.Discard()),
+		controller.NewImportController(importer.NewImporter(userServiceMock, testValidator, testCatalog, "", logging.Discard()), testCatalog, logging.Discard()),
+		controller.NewProfileSchemaController(new(serviceMock.ProfileSchemaServiceInterface), testValidator, testCatalog, logging.Discard()),
+		controller.NewApiKeyController(new(serviceMock.ApiKeyServiceInterface), testValidator, testCatalog, logging.Discard(), nil),
+		controller.NewTenantController(new(serviceMock.TenantServiceInterface), testValidator, testCatalog, logging.Discard(), "", ""),
+		controller.NewGroupController(new(serviceMock.GroupServiceInterface), testValidator, testCatalog, logging.Discard()),
+		controller.NewHealthController(new(serviceMock.HealthServiceInterface)),
+		nil,
+		nil,
+		nil,
+	)
+
+	for _, route := range router.Routes() {
+		assert.NotEqual(t, "/graphql", route.Path)
+		assert.NotEqual(t, "/metrics", route.Path)
+		assert.NotEqual(t, "/openapi.json", route.Path)
+	}
+}
+
+func Test_CustomMethods_Should_Dispatch_On_Verb(t *testing.T) {
+	router := newTestRouter(openapi.NewDocument(), model.Scopes...)
+
+	responseRecorder := httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/users:batchGet", strings.NewReader(`{"ids":[]}`)))
+	assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+	assert.Contains(t, responseRecorder.Body.String(), "/problems/validation-error")
+
+	responseRecorder = httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/users:unknown", nil))
+	assert.Equal(t, http.StatusNotFound, responseRecorder.Code)
+
+	responseRecorder = httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+}
+
+func Test_CustomMethods_Should_Require_The_Scope_Of_The_Verb(t *testing.T) {
+	router := newTestRouter(openapi.NewDocument(), model.ScopeUsersRead)
+
+	responseRecorder := httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/users:batchGet", strings.NewReader(`{"ids":[]}`)))
+	assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
+
+	responseRecorder = httptest.NewRecorder()
+	router.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/users:batchDelete", strings.NewReader(`{"ids":[]}`)))
+	assert.Equal(t, http.StatusUnauthorized, responseRecorder.Code)
+}
+
+func Test_User_Routes_Should_Be_Confined_To_The_Tenant_Of_The_Request(t *testing.T) {
+	router := newTestRouter(openapi.NewDocument(), model.Scopes...)
+
+	for _, path := range []string{"/users:batchGet", "/groups", "/graphql"} {
+		request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{}`))
+		request.Header.Set(controller.TenantHeader, "missing")
+
+		responseRecorder := httptest.NewRecorder()
+		router.ServeHTTP(responseRecorder, request)
+
+		// Every scope is granted anonymously here, so only the tenant middleware turns the request away.
+		assert.Equal(t, http.StatusUnauthorized, responseRecorder.Code, path)
+		assert.Contains(t, responseRecorder.Body.String(), "/problems/unauthorized", path)
+	}
+}
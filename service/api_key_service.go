@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	errs "user-service/error"
+	"user-service/model"
+	"user-service/repository"
+)
+
+const (
+	// apiKeyMarker starts every key, which lets secret scanners recognize leaked keys.
+	apiKeyMarker = "usk_"
+	// DefaultRotationOverlap is how long a rotated key keeps working when the caller does not say otherwise.
+	DefaultRotationOverlap = 24 * time.Hour
+	// lastUsedPrecision bounds how stale the recorded last use of a key may be.
+	lastUsedPrecision = time.Minute
+	// prefixAttempts is how often a key is drawn again when its prefix is already taken.
+	prefixAttempts = 3
+	// BootstrapApiKeyId is the id that requests made with the bootstrap key are attributed to.
+	BootstrapApiKeyId = "bootstrap"
+)
+
+type ApiKeyService struct {
+	apiKeyRepository repository.ApiKeyRepositoryInterface
+	tenantService    TenantServiceInterface
+	bootstrapHash    []byte
+	logger           *slog.Logger
+}
+
+// NewApiKeyService manages the keys in apiKeyRepository. A non-empty bootstrapKey is accepted as well but may only
+// manage keys and tenants, so that the first ones can be created.
+func NewApiKeyService(apiKeyRepository repository.ApiKeyRepositoryInterface, tenantService TenantServiceInterface, bootstrapKey string, logger *slog.Logger) *ApiKeyService {
+	service := &ApiKeyService{
+		apiKeyRepository: apiKeyRepository,
+		tenantService:    tenantService,
+		logger:           logger,
+	}
+	if bootstrapKey != "" {
+		service.bootstrapHash = hashApiKey(bootstrapKey)
+	}
+
+	return service
+}
+
+type ApiKeyServiceInterface interface {
+	Create(context.Context, model.CreateApiKeyDomainModel) (*model.ApiKeyDomainModel, error)
+	GetAll(context.Context) ([]*model.ApiKeyDomainModel, error)
+	Revoke(context.Context, string) error
+	Rotate(context.Context, string, time.Duration) (*model.ApiKeyDomainModel, error)
+	Authenticate(context.Context, string) (*model.Principal, error)
+}
+
+// Create issues a new key. Keys that belong to a tenant can only issue keys of that tenant, which makes those
+// with ScopeApiKeysManage the admins of their tenant, and no key can grant a scope it lacks. The returned domain
+// model is the only one that holds the key itself.
+func (s *ApiKeyService) Create(ctx context.Context, createModel model.CreateApiKeyDomainModel) (*model.ApiKeyDomainModel, error) {
+	if createModel.ExpiresAt != nil && !createModel.ExpiresAt.After(time.Now()) {
+		return nil, &errs.FieldErrors{Err: errs.ValidationError, Fields: []errs.FieldError{{Field: "expiresAt", Rule: "future"}}}
+	}
+
+	if principalTenant := tenantOfPrincipal(ctx); principalTenant != "" {
+		if createModel.TenantId != "" && createModel.TenantId != principalTenant {
+			return nil, errs.ForbiddenError
+		}
+		createModel.TenantId = principalTenant
+	}
+
+	if createModel.TenantId != "" {
+		for _, scope := range createModel.Scopes {
+			if slices.Contains(model.PlatformScopes, scope) {
+				return nil, &errs.FieldErrors{Err: errs.ValidationError, Fields: []errs.FieldError{{Field: "scopes", Rule: "platform", Param: scope}}}
+			}
+		}
+
+		_, err := s.tenantService.GetById(ctx, createModel.TenantId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := checkGrantable(ctx, createModel.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issue(ctx, model.ApiKeyEntity{
+		TenantId:  createModel.TenantId,
+		Name:      createModel.Name,
+		Scopes:    createModel.Scopes,
+		ExpiresAt: createModel.ExpiresAt,
+	})
+}
+
+// GetAll returns the keys of the tenant of the calling key, or every key for platform keys.
+func (s *ApiKeyService) GetAll(ctx context.Context) ([]*model.ApiKeyDomainModel, error) {
+	apiKeyEntities, err := s.apiKeyRepository.GetAll(ctx, tenantOfPrincipal(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	domainModels := make([]*model.ApiKeyDomainModel, len(apiKeyEntities))
+	for i, apiKeyEntity := range apiKeyEntities {
+		domainModels[i] = copyApiKeyEntityToDomainModel(apiKeyEntity)
+	}
+
+	return domainModels, nil
+}
+
+// Revoke makes the key stop working at once. It stays listed so its use can still be traced.
+func (s *ApiKeyService) Revoke(ctx context.Context, id string) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errs.BadRequestError
+	}
+
+	_, err = s.getManageable(ctx, objectId)
+	if err != nil {
+		return err
+	}
+
+	return s.apiKeyRepository.Revoke(ctx, objectId)
+}
+
+// Rotate issues a key with the name, scopes and expiry of the key with the given id, which keeps working for the
+// overlap so that its callers can switch over without downtime. The calling key needs every scope of the key.
+func (s *ApiKeyService) Rotate(ctx context.Context, id string, overlap time.Duration) (*model.ApiKeyDomainModel, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errs.BadRequestError
+	}
+
+	apiKeyEntity, err := s.getManageable(ctx, objectId)
+	if err != nil {
+		return nil, err
+	}
+	if !isApiKeyValid(apiKeyEntity, time.Now()) {
+		return nil, errs.ApiKeyNotFoundError
+	}
+
+	err = checkGrantable(ctx, apiKeyEntity.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := s.issue(ctx, model.ApiKeyEntity{
+		TenantId:    apiKeyEntity.TenantId,
+		Name:        apiKeyEntity.Name,
+		Scopes:      apiKeyEntity.Scopes,
+		ExpiresAt:   apiKeyEntity.ExpiresAt,
+		RotatedFrom: &apiKeyEntity.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.apiKeyRepository.ExpireBy(ctx, objectId, time.Now().Add(overlap))
+	if err != nil {
+		return nil, err
+	}
+
+	return rotated, nil
+}
+
+// Authenticate finds who a request presenting key was made by. Keys that are unknown, revoked or expired fail
+// with errs.UnauthorizedError.
+func (s *ApiKeyService) Authenticate(ctx context.Context, key string) (*model.Principal, error) {
+	hash := hashApiKey(key)
+	if s.bootstrapHash != nil && subtle.ConstantTimeCompare(hash, s.bootstrapHash) == 1 {
+		return &model.Principal{ApiKeyId: BootstrapApiKeyId, Scopes: []string{model.ScopeApiKeysManage, model.ScopeTenantsManage}}, nil
+	}
+
+	prefix, ok := apiKeyPrefixOf(key)
+	if !ok {
+		return nil, errs.UnauthorizedError
+	}
+
+	apiKeyEntity, err := s.apiKeyRepository.GetByPrefix(ctx, prefix)
+	if errors.Is(err, errs.ApiKeyNotFoundError) {
+		return nil, errs.UnauthorizedError
+	} else if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare(hash, apiKeyEntity.Hash) != 1 || !isApiKeyValid(apiKeyEntity, time.Now()) {
+		s.logger.DebugContext(ctx, "rejected an API key", "prefix", prefix)
+		return nil, errs.UnauthorizedError
+	}
+
+	// A failure to record the use is logged by the repository and does not fail the request.
+	s.apiKeyRepository.TouchLastUsed(ctx, apiKeyEntity.Id, lastUsedPrecision)
+
+	return &model.Principal{ApiKeyId: apiKeyEntity.Id.Hex(), TenantId: apiKeyEntity.TenantId, Scopes: apiKeyEntity.Scopes}, nil
+}
+
+type principalKey struct{}
+
+// WithPrincipal attributes the writes made with the returned context to principal.
+func WithPrincipal(ctx context.Context, principal *model.Principal) context.Context {
+	ctx = context.WithValue(ctx, principalKey{}, principal)
+	return repository.WithActor(ctx, "apiKey:"+principal.ApiKeyId)
+}
+
+// PrincipalFrom returns who the request of ctx was made by, or nil for anonymous requests.
+func PrincipalFrom(ctx context.Context) *model.Principal {
+	principal, _ := ctx.Value(principalKey{}).(*model.Principal)
+	return principal
+}
+
+func tenantOfPrincipal(ctx context.Context) string {
+	if principal := PrincipalFrom(ctx); principal != nil {
+		return principal.TenantId
+	}
+
+	return ""
+}
+
+// checkGrantable fails with ForbiddenError unless the calling key holds every one of scopes, so that keys cannot
+// issue keys more powerful than themselves. The bootstrap key, which issues the first keys, may grant any scope.
+func checkGrantable(ctx context.Context, scopes []string) error {
+	principal := PrincipalFrom(ctx)
+	if principal != nil && principal.ApiKeyId == BootstrapApiKeyId {
+		return nil
+	}
+
+	for _, scope := range scopes {
+		if principal == nil || !slices.Contains(principal.Scopes, scope) {
+			return errs.ForbiddenError
+		}
+	}
+
+	return nil
+}
+
+// getManageable returns the key with the given id if the calling key may manage it. Keys of other tenants are
+// reported as not found, so that their ids cannot be probed.
+func (s *ApiKeyService) getManageable(ctx context.Context, id primitive.ObjectID) (*model.ApiKeyEntity, error) {
+	apiKeyEntity, err := s.apiKeyRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if principalTenant := tenantOfPrincipal(ctx); principalTenant != "" && apiKeyEntity.TenantId != principalTenant {
+		return nil, errs.ApiKeyNotFoundError
+	}
+
+	return apiKeyEntity, nil
+}
+
+// issue draws a key and stores its hash along with the settings in apiKeyEntity.
+func (s *ApiKeyService) issue(ctx context.Context, apiKeyEntity model.ApiKeyEntity) (*model.ApiKeyDomainModel, error) {
+	for attempt := 1; ; attempt++ {
+		key, prefix, err := generateApiKey()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "generating an API key failed", "error", err)
+			return nil, errs.ServerError
+		}
+
+		apiKeyEntity.Prefix = prefix
+		apiKeyEntity.Hash = hashApiKey(key)
+
+		created, err := s.apiKeyRepository.Create(ctx, apiKeyEntity)
+		if errors.Is(err, errs.PreconditionFailedError) && attempt < prefixAttempts {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		domainModel := copyApiKeyEntityToDomainModel(created)
+		domainModel.Key = key
+
+		return domainModel, nil
+	}
+}
+
+// generateApiKey returns a key of the form usk_<prefix>_<secret> along with its public part usk_<prefix>.
+func generateApiKey() (key string, prefix string, err error) {
+	random := make([]byte, 6+32)
+	_, err = rand.Read(random)
+	if err != nil {
+		return "", "", err
+	}
+
+	prefix = apiKeyMarker + hex.EncodeToString(random[:6])
+
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(random[6:]), prefix, nil
+}
+
+func apiKeyPrefixOf(key string) (string, bool) {
+	if !strings.HasPrefix(key, apiKeyMarker) {
+		return "", false
+	}
+
+	id, _, found := strings.Cut(strings.TrimPrefix(key, apiKeyMarker), "_")
+	if !found || len(id) != 12 {
+		return "", false
+	}
+
+	return apiKeyMarker + id, true
+}
+
+// hashApiKey does not need a slow hash like bcrypt, since keys are random enough that guessing them is hopeless,
+// and it keeps authenticating every request cheap.
+func hashApiKey(key string) []byte {
+	hash := sha256.Sum256([]byte(key))
+	return hash[:]
+}
+
+func isApiKeyValid(apiKeyEntity *model.ApiKeyEntity, now time.Time) bool {
+	return apiKeyEntity.RevokedAt == nil && (apiKeyEntity.ExpiresAt == nil || now.Before(*apiKeyEntity.ExpiresAt))
+}
+
+func copyApiKeyEntityToDomainModel(apiKeyEntity *model.ApiKeyEntity) *model.ApiKeyDomainModel {
+	domainModel := &model.ApiKeyDomainModel{
+		Id:         apiKeyEntity.Id.Hex(),
+		TenantId:   apiKeyEntity.TenantId,
+		Name:       apiKeyEntity.Name,
+		Prefix:     apiKeyEntity.Prefix,
+		Scopes:     apiKeyEntity.Scopes,
+		CreatedAt:  apiKeyEntity.CreatedAt,
+		CreatedBy:  apiKeyEntity.CreatedBy,
+		ExpiresAt:  apiKeyEntity.ExpiresAt,
+		RevokedAt:  apiKeyEntity.RevokedAt,
+		LastUsedAt: apiKeyEntity.LastUsedAt,
+	}
+	if apiKeyEntity.RotatedFrom != nil {
+		domainModel.RotatedFrom = apiKeyEntity.RotatedFrom.Hex()
+	}
+
+	return domainModel
+}
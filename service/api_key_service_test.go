@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+	"testing"
+	"time"
+	errs "user-service/error"
+	"user-service/logging"
+	"user-service/model"
+	repositoryMock "user-service/repository/mock"
+	serviceMock "user-service/service/mock"
+)
+
+// withPlatformAdmin acts as a platform key holding every scope, which may grant all of them.
+func withPlatformAdmin(ctx context.Context) context.Context {
+	return WithPrincipal(ctx, &model.Principal{ApiKeyId: "admin", Scopes: model.Scopes})
+}
+
+func Test_ApiKeyCreate_Should_Store_Only_The_Hash_And_Return_The_Key_Once(t *testing.T) {
+	var stored model.ApiKeyEntity
+
+	apiKeyRepositoryMock := new(repositoryMock.ApiKeyRepositoryInterface)
+	apiKeyRepositoryMock.On("Create", mock.Anything, mock.MatchedBy(func(apiKeyEntity model.ApiKeyEntity) bool {
+		stored = apiKeyEntity
+		return true
+	})).Return(&model.ApiKeyEntity{Id: primitive.NewObjectID(), Name: "nightly export", Scopes: []string{model.ScopeUsersRead}}, nil).Once()
+
+	classUnderTest := NewApiKeyService(apiKeyRepositoryMock, new(serviceMock.TenantServiceInterface), "", logging.Discard())
+
+	domainModel, err := classUnderTest.Create(withPlatformAdmin(context.Background()), model.CreateApiKeyDomainModel{Name: "nightly export", Scopes: []string{model.ScopeUsersRead}})
+
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(domainModel.Key, stored.Prefix+"_"))
+	assert.Regexp(t, `^usk_[0-9a-f]{12}$`, stored.Prefix)
+	assert.Equal(t, hashApiKey(domainModel.Key), stored.Hash)
+	assert.NotContains(t, string(stored.Hash), domainModel.Key)
+	assert.Equal(t, []string{model.ScopeUsersRead}, domainModel.Scopes)
+}
+
+func Test_ApiKeyCreate_Should_Reject_An_Expiry_In_The_Past(t *testing.T) {
+	expiresAt := time.Now().Add(-time.Minute)
+	apiKeyRepositoryMock := new(repositoryMock.ApiKeyRepositoryInterface)
+
+	classUnderTest := NewApiKeyService(apiKeyRepositoryMock, new(serviceMock.TenantServiceInterface), "", logging.Discard())
+
+	_, err := classUnderTest.Create(context.Background(), model.CreateApiKeyDomainModel{Name: "old", Scopes: []string{model.ScopeUsersRead}, ExpiresAt: &expiresAt})
+
+	assert.ErrorIs(t, err, errs.ValidationError)
+	apiKeyRepositoryMock.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func Test_ApiKeyAuthenticate_Should_Return_The_Scopes_Of_A_Valid_Key(t *testing.T) {
+	key := "usk_0123456789ab_secret"
+	apiKeyEntity := &model.ApiKeyEntity{Id: primitive.NewObjectID(), Prefix: "usk_0123456789ab", Hash: hashApiKey(key), Scopes: []string{model.ScopeUsersWrite}}
+
+	apiKeyRepositoryMock := new(repositoryMock.ApiKeyRepositoryInterface)
+	apiKeyRepositoryMock.On("GetByPrefix", mock.Anything, "usk_0123456789ab").Return(apiKeyEntity, nil).Once()
+	apiKeyRepositoryMock.On("TouchLastUsed", mock.Anything, apiKeyEntity.Id, time.Minute).Return(nil).Once()
+
+	classUnderTest := NewApiKeyService(apiKeyRepositoryMock, new(serviceMock.TenantServiceInterface), "", logging.Discard())
+
+	principal, err := classUnderTest.Authenticate(context.Background(), key)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &model.Principal{ApiKeyId: apiKeyEntity.Id.Hex(), Scopes: []string{model.ScopeUsersWrite}}, principal)
+	apiKeyRepositoryMock.AssertExpectations(t)
+}
+
+func Test_ApiKeyAuthenticate_Should_Reject_Wrong_Revoked_And_Expired_Keys(t *testing.T) {
+	key := "usk_0123456789ab_secret"
+	past := time.Now().Add(-time.Second)
+
+	for name, apiKeyEntity := range map[string]*model.ApiKeyEntity{
+		"wrong secret": {Hash: hashApiKey("usk_0123456789ab_guess")},
+		"revoked":      {Hash: hashApiKey(key), RevokedAt: &past},
+		"expired":      {Hash: hashApiKey(key), ExpiresAt: &past},
+	} {
+		apiKeyRepositoryMock := new(repositoryMock.ApiKeyRepositoryInterface)
+		apiKeyRepositoryMock.On("GetByPrefix", mock.Anything, "usk_0123456789ab").Return(apiKeyEntity, nil).Once()
+
+		classUnderTest := NewApiKeyService(apiKeyRepositoryMock, new(serviceMock.TenantServiceInterface), "", logging.Discard())
+
+		_, err := classUnderTest.Authenticate(context.Background(), key)
+
+		assert.ErrorIs(t, err, errs.UnauthorizedError, name)
+		apiKeyRepositoryMock.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func Test_ApiKeyAuthenticate_Should_Reject_Malformed_And_Unknown_Keys(t *testing.T) {
+	apiKeyRepositoryMock := new(repositoryMock.ApiKeyRepositoryInterface)
+	apiKeyRepositoryMock.On("GetByPrefix", mock.Anything, "usk_0123456789ab").Return(nil, errs.ApiKeyNotFoundError).Once()
+
+	classUnderTest := NewApiKeyService(apiKeyRepositoryMock, new(serviceMock.TenantServiceInterface), "", logging.Discard())
+
+	_, err := classUnderTest.Authenticate(context.Background(), "not-a-key")
+	assert.ErrorIs(t, err, errs.UnauthorizedError)
+
+	_, err = classUnderTest.Authenticate(context.Background(), "usk_0123456789ab_secret")
+	assert.ErrorIs(t, err, errs.UnauthorizedError)
+}
+
+func Test_ApiKeyAuthenticate_Should_Only_Let_The_Bootstrap_Key_Manage_Keys_And_Tenants(t *testing.T) {
+	classUnderTest := NewApiKeyService(new(repositoryMock.ApiKeyRepositoryInterface), new(serviceMock.TenantServiceInterface), "a-bootstrap-key-of-at-least-32-characters", logging.Discard())
+
+	principal, err := classUnderTest.Authenticate(context.Background(), "a-bootstrap-key-of-at-least-32-characters")
+
+	assert.Nil(t, err)
+	assert.Equal(t, &model.Principal{ApiKeyId: BootstrapApiKeyId, Scopes: []string{model.ScopeApiKeysManage, model.ScopeTenantsManage}}, principal)
+}
+
+func Test_ApiKeyRotate_Should_Issue_A_Copy_And_Expire_The_Old_Key_After_The_Overlap(t *testing.T) {
+	id := primitive.NewObjectID()
+	oldKey := &model.ApiKeyEntity{Id: id, Name: "nightly export", Prefix: "usk_0123456789ab", Scopes: []string{model.ScopeUsersRead}}
+
+	apiKeyRepositoryMock := new(repositoryMock.ApiKeyRepositoryInterface)
+	apiKeyRepositoryMock.On("GetById", mock.Anything, id).Return(oldKey, nil).Once()
+	apiKeyRepositoryMock.On("Create", mock.Anything, mock.MatchedBy(func(apiKeyEntity model.ApiKeyEntity) bool {
+		return apiKeyEntity.Name == "nightly export" && *apiKeyEntity.RotatedFrom == id && apiKeyEntity.Prefix != oldKey.Prefix
+	})).Return(&model.ApiKeyEntity{Id: primitive.NewObjectID(), Name: "nightly export", RotatedFrom: &id}, nil).Once()
+	apiKeyRepositoryMock.On("ExpireBy", mock.Anything, id, mock.MatchedBy(func(at time.Time) bool {
+		return time.Until(at) > 59*time.Minute && time.Until(at) <= time.Hour
+	})).Return(nil).Once()
+
+	classUnderTest := NewApiKeyService(apiKeyRepositoryMock, new(serviceMock.TenantServiceInterface), "", logging.Discard())
+
+	rotated, err := classUnderTest.Rotate(withPlatformAdmin(context.Background()), id.Hex(), time.Hour)
+
+	assert.Nil(t, err)
+	assert.Equal(t, id.Hex(), rotated.RotatedFrom)
+	assert.NotEmpty(t, rotated.Key)
+	apiKeyRepositoryMock.AssertExpectations(t)
+}
+
+func Test_ApiKeyRotate_Should_Refuse_A_Revoked_Key(t *testing.T) {
+	id := primitive.NewObjectID()
+	revokedAt := time.Now()
+
+	apiKeyRepositoryMock := new(repositoryMock.ApiKeyRepositoryInterface)
+	apiKeyRepositoryMock.On("GetById", mock.Anything, id).Return(&model.ApiKeyEntity{Id: id, RevokedAt: &revokedAt}, nil).Once()
+
+	classUnderTest := NewApiKeyService(apiKeyRepositoryMock, new(serviceMock.TenantServiceInterface), "", logging.Discard())
+
+	_, err := classUnderTest.Rotate(context.Background(), id.Hex(), time.Hour)
+
+	assert.ErrorIs(t, err, errs.ApiKeyNotFoundError)
+	apiKeyRepositoryMock.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func Test_ApiKeyCreate_Should_Issue_Keys_Of_Its_Own_Tenant_For_A_Key_Of_A_Tenant(t *testing.T) {
+	ctx := WithPrincipal(context.Background(), &model.Principal{ApiKeyId: "admin", TenantId: "acme", Scopes: []string{model.ScopeApiKeysManage, model.ScopeUsersRead}})
+
+	tenantServiceMock := new(serviceMock.TenantServiceInterface)
+	tenantServiceMock.On("GetById", mock.Anything, "acme").Return(&model.TenantDomainModel{Id: "acme"}, nil).Once()
+
+	apiKeyRepositoryMock := new(repositoryMock.ApiKeyRepositoryInterface)
+	apiKeyRepositoryMock.On("Create", mock.Anything, mock.MatchedBy(func(apiKeyEntity model.ApiKeyEntity) bool {
+		return apiKeyEntity.TenantId == "acme"
+	})).Return(&model.ApiKeyEntity{Id: primitive.NewObjectID(), TenantId: "acme"}, nil).Once()
+
+	classUnderTest := NewApiKeyService(apiKeyRepositoryMock, tenantServiceMock, "", logging.Discard())
+
+	domainModel, err := classUnderTest.Create(ctx, model.CreateApiKeyDomainModel{Name: "sync", Scopes: []string{model.ScopeUsersRead}})
+	assert.Nil(t, err)
+	assert.Equal(t, "acme", domainModel.TenantId)
+
+	_, err = classUnderTest.Create(ctx, model.CreateApiKeyDomainModel{TenantId: "other", Name: "sync", Scopes: []string{model.ScopeUsersRead}})
+	assert.ErrorIs(t, err, errs.ForbiddenError)
+
+	_, err = classUnderTest.Create(ctx, model.CreateApiKeyDomainModel{Name: "schema", Scopes: []string{model.ScopeProfileSchemaWrite}})
+	assert.ErrorIs(t, err, errs.ValidationError)
+
+	apiKeyRepositoryMock.AssertExpectations(t)
+}
+
+func Test_ApiKeyCreate_Should_Fail_For_An_Unknown_Tenant(t *testing.T) {
+	tenantServiceMock := new(serviceMock.TenantServiceInterface)
+	tenantServiceMock.On("GetById", mock.Anything, "missing").Return(nil, errs.TenantNotFoundError).Once()
+
+	apiKeyRepositoryMock := new(repositoryMock.ApiKeyRepositoryInterface)
+
+	classUnderTest := NewApiKeyService(apiKeyRepositoryMock, tenantServiceMock, "", logging.Discard())
+
+	_, err := classUnderTest.Create(context.Background(), model.CreateApiKeyDomainModel{TenantId: "missing", Name: "sync", Scopes: []string{model.ScopeUsersRead}})
+
+	assert.ErrorIs(t, err, errs.TenantNotFoundError)
+	apiKeyRepositoryMock.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func Test_ApiKey_Management_Should_Not_Reach_Keys_Of_Other_Tenants(t *testing.T) {
+	ctx := WithPrincipal(context.Background(), &model.Principal{ApiKeyId: "admin", TenantId: "acme", Scopes: []string{model.ScopeApiKeysManage}})
+	id := primitive.NewObjectID()
+
+	apiKeyRepositoryMock := new(repositoryMock.ApiKeyRepositoryInterface)
+	apiKeyRepositoryMock.On("GetAll", mock.Anything, "acme").Return([]*model.ApiKeyEntity{}, nil).Once()
+	apiKeyRepositoryMock.On("GetById", mock.Anything, id).Return(&model.ApiKeyEntity{Id: id, TenantId: "other"}, nil)
+
+	classUnderTest := NewApiKeyService(apiKeyRepositoryMock, new(serviceMock.TenantServiceInterface), "", logging.Discard())
+
+	_, err := classUnderTest.GetAll(ctx)
+	assert.Nil(t, err)
+
+	err = classUnderTest.Revoke(ctx, id.Hex())
+	assert.ErrorIs(t, err, errs.ApiKeyNotFoundError)
+
+	_, err = classUnderTest.Rotate(ctx, id.Hex(), time.Hour)
+	assert.ErrorIs(t, err, errs.ApiKeyNotFoundError)
+
+	apiKeyRepositoryMock.AssertExpectations(t)
+	apiKeyRepositoryMock.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything)
+	apiKeyRepositoryMock.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func Test_ApiKey_Create_And_Rotate_Should_Not_Grant_Scopes_The_Calling_Key_Lacks(t *testing.T) {
+	ctx := WithPrincipal(context.Background(), &model.Principal{ApiKeyId: "admin", Scopes: []string{model.ScopeApiKeysManage}})
+	id := primitive.NewObjectID()
+
+	apiKeyRepositoryMock := new(repositoryMock.ApiKeyRepositoryInterface)
+	apiKeyRepositoryMock.On("GetById", mock.Anything, id).Return(&model.ApiKeyEntity{Id: id, Scopes: []string{model.ScopeUsersWrite}}, nil).Once()
+
+	classUnderTest := NewApiKeyService(apiKeyRepositoryMock, new(serviceMock.TenantServiceInterface), "", logging.Discard())
+
+	_, err := classUnderTest.Create(ctx, model.CreateApiKeyDomainModel{Name: "admin", Scopes: []string{model.ScopeTenantsManage}})
+	assert.ErrorIs(t, err, errs.ForbiddenError)
+
+	_, err = classUnderTest.Rotate(ctx, id.Hex(), time.Hour)
+	assert.ErrorIs(t, err, errs.ForbiddenError)
+
+	apiKeyRepositoryMock.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	apiKeyRepositoryMock.AssertNotCalled(t, "ExpireBy", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_ApiKeyCreate_Should_Let_The_Bootstrap_Key_Grant_Any_Scope(t *testing.T) {
+	ctx := WithPrincipal(context.Background(), &model.Principal{ApiKeyId: BootstrapApiKeyId, Scopes: []string{model.ScopeApiKeysManage, model.ScopeTenantsManage}})
+
+	apiKeyRepositoryMock := new(repositoryMock.ApiKeyRepositoryInterface)
+	apiKeyRepositoryMock.On("Create", mock.Anything, mock.Anything).Return(&model.ApiKeyEntity{Id: primitive.NewObjectID(), Scopes: model.Scopes}, nil).Once()
+
+	classUnderTest := NewApiKeyService(apiKeyRepositoryMock, new(serviceMock.TenantServiceInterface), "", logging.Discard())
+
+	_, err := classUnderTest.Create(ctx, model.CreateApiKeyDomainModel{Name: "admin", Scopes: model.Scopes})
+
+	assert.Nil(t, err)
+	apiKeyRepositoryMock.AssertExpectations(t)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"io/fs"
+	"log/slog"
+	"strconv"
+	"user-service/avatar"
+	errs "user-service/error"
+	"user-service/repository"
+)
+
+type AvatarService struct {
+	userRepository repository.UserRepositoryInterface
+	blobStore      repository.BlobStore
+	logger         *slog.Logger
+}
+
+func NewAvatarService(userRepository repository.UserRepositoryInterface, blobStore repository.BlobStore, logger *slog.Logger) *AvatarService {
+	return &AvatarService{
+		userRepository: userRepository,
+		blobStore:      blobStore,
+		logger:         logger,
+	}
+}
+
+type AvatarServiceInterface interface {
+	Upload(context.Context, string, io.Reader) error
+	Get(context.Context, string, int) (*repository.Blob, error)
+}
+
+// Upload replaces the avatar of a user with thumbnails of the given image in every size of avatar.Sizes. Each
+// size is replaced on its own, so a reader can briefly see the new avatar in one size and the old one in another.
+func (s *AvatarService) Upload(ctx context.Context, id string, content io.Reader) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		s.logger.DebugContext(ctx, "the id is not valid", "id", id, "error", err)
+		return errs.BadRequestError
+	}
+
+	_, err = s.userRepository.GetById(ctx, objectId)
+	if err != nil {
+		return err
+	}
+
+	image, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	thumbnails, err := avatar.Thumbnails(image)
+	if err != nil {
+		return err
+	}
+
+	for _, thumbnail := range thumbnails {
+		err = s.blobStore.Put(ctx, avatarKey(objectId, thumbnail.Size), bytes.NewReader(thumbnail.Content), thumbnail.ContentType)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "storing a thumbnail failed", "id", id, "size", thumbnail.Size, "error", err)
+			return errs.ServerError
+		}
+	}
+
+	return nil
+}
+
+// Get opens the thumbnail of a user's avatar in the given size, which must be one of avatar.Sizes. It fails with
+// AvatarNotFoundError for users without an avatar.
+func (s *AvatarService) Get(ctx context.Context, id string, size int) (*repository.Blob, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil || !avatar.IsSize(size) {
+		return nil, errs.BadRequestError
+	}
+
+	// Blobs are keyed by user id alone, so the user is looked up first to keep avatars within their tenant.
+	_, err = s.userRepository.GetById(ctx, objectId)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := s.blobStore.Get(ctx, avatarKey(objectId, size))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errs.AvatarNotFoundError
+	} else if err != nil {
+		s.logger.ErrorContext(ctx, "reading the avatar failed", "id", id, "size", size, "error", err)
+		return nil, errs.ServerError
+	}
+
+	return blob, nil
+}
+
+// userBlobPrefix is the prefix of the keys of every blob that belongs to a user, so that they can all be deleted
+// with the user. It takes the parsed id, as ids in upper and lower case name the same user.
+func userBlobPrefix(id primitive.ObjectID) string {
+	return "users/" + id.Hex() + "/"
+}
+
+func avatarKey(id primitive.ObjectID, size int) string {
+	return userBlobPrefix(id) + "avatar/" + strconv.Itoa(size)
+}
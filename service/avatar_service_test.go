@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"image"
+	"image/png"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"testing"
+	"user-service/avatar"
+	errs "user-service/error"
+	"user-service/logging"
+	"user-service/model"
+	"user-service/repository"
+	repositoryMock "user-service/repository/mock"
+)
+
+func Test_AvatarUpload_Should_Store_A_Thumbnail_In_Every_Size(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	var content bytes.Buffer
+	png.Encode(&content, image.NewGray(image.Rect(0, 0, 300, 200)))
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, id).Return(&model.UserEntity{Id: id}, nil).Once()
+
+	blobStoreMock := new(repositoryMock.BlobStore)
+	for _, size := range avatar.Sizes {
+		blobStoreMock.On("Put", mock.Anything, avatarKey(id, size), mock.Anything, "image/png").Return(nil).Once()
+	}
+
+	classUnderTest := NewAvatarService(userRepositoryMock, blobStoreMock, logging.Discard())
+
+	err := classUnderTest.Upload(context.Background(), id.Hex(), &content)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "users/"+id.Hex()+"/avatar/128", avatarKey(id, 128))
+	blobStoreMock.AssertExpectations(t)
+}
+
+func Test_AvatarUpload_Should_Store_Nothing_When_The_Image_Is_Rejected(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, id).Return(&model.UserEntity{Id: id}, nil).Once()
+
+	blobStoreMock := new(repositoryMock.BlobStore)
+
+	classUnderTest := NewAvatarService(userRepositoryMock, blobStoreMock, logging.Discard())
+
+	err := classUnderTest.Upload(context.Background(), id.Hex(), strings.NewReader("<svg></svg>"))
+
+	assert.ErrorIs(t, err, errs.UnsupportedMediaTypeError)
+	blobStoreMock.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_AvatarUpload_Should_Return_NotFoundError_When_User_Does_Not_Exist(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, id).Return(nil, errs.NotFoundError).Once()
+
+	classUnderTest := NewAvatarService(userRepositoryMock, new(repositoryMock.BlobStore), logging.Discard())
+
+	err := classUnderTest.Upload(context.Background(), id.Hex(), strings.NewReader(""))
+
+	assert.ErrorIs(t, err, errs.NotFoundError)
+}
+
+func Test_AvatarGet_Should_Return_BadRequestError_When_Size_Is_Not_Offered(t *testing.T) {
+	classUnderTest := NewAvatarService(new(repositoryMock.UserRepositoryInterface), new(repositoryMock.BlobStore), logging.Discard())
+
+	blob, err := classUnderTest.Get(context.Background(), primitive.NewObjectID().Hex(), 100)
+
+	assert.Nil(t, blob)
+	assert.ErrorIs(t, err, errs.BadRequestError)
+}
+
+func Test_AvatarGet_Should_Open_The_Thumbnail_Of_The_Requested_Size(t *testing.T) {
+	var id = primitive.NewObjectID()
+	stored := &repository.Blob{Content: io.NopCloser(strings.NewReader("thumbnail")), ContentType: "image/png", Size: 9}
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, id).Return(&model.UserEntity{Id: id}, nil).Once()
+
+	blobStoreMock := new(repositoryMock.BlobStore)
+	blobStoreMock.On("Get", mock.Anything, avatarKey(id, 64)).Return(stored, nil).Once()
+
+	classUnderTest := NewAvatarService(userRepositoryMock, blobStoreMock, logging.Discard())
+
+	blob, err := classUnderTest.Get(context.Background(), id.Hex(), 64)
+
+	assert.Nil(t, err)
+	assert.Equal(t, stored, blob)
+}
+
+func Test_AvatarGet_Should_Tell_Users_Without_Avatar_From_Missing_Users(t *testing.T) {
+	var withoutAvatarId = primitive.NewObjectID()
+	var missingId = primitive.NewObjectID()
+	var notExist = &fs.PathError{Op: "open", Path: "avatar", Err: fs.ErrNotExist}
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, withoutAvatarId).Return(&model.UserEntity{Id: withoutAvatarId}, nil).Once()
+	userRepositoryMock.On("GetById", mock.Anything, missingId).Return(nil, errs.NotFoundError).Once()
+
+	blobStoreMock := new(repositoryMock.BlobStore)
+	blobStoreMock.On("Get", mock.Anything, mock.Anything).Return(nil, notExist)
+
+	classUnderTest := NewAvatarService(userRepositoryMock, blobStoreMock, logging.Discard())
+
+	_, err := classUnderTest.Get(context.Background(), withoutAvatarId.Hex(), avatar.DefaultSize)
+	assert.True(t, errors.Is(err, errs.AvatarNotFoundError))
+
+	_, err = classUnderTest.Get(context.Background(), missingId.Hex(), avatar.DefaultSize)
+	assert.True(t, errors.Is(err, errs.NotFoundError))
+	blobStoreMock.AssertNumberOfCalls(t, "Get", 1)
+}
+
+func Test_AvatarUpload_Should_Key_The_Thumbnails_By_The_Id_In_Lower_Case(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	var content bytes.Buffer
+	png.Encode(&content, image.NewGray(image.Rect(0, 0, 300, 200)))
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, id).Return(&model.UserEntity{Id: id}, nil).Once()
+
+	blobStoreMock := new(repositoryMock.BlobStore)
+	for _, size := range avatar.Sizes {
+		blobStoreMock.On("Put", mock.Anything, "users/"+id.Hex()+"/avatar/"+strconv.Itoa(size), mock.Anything, "image/png").Return(nil).Once()
+	}
+
+	classUnderTest := NewAvatarService(userRepositoryMock, blobStoreMock, logging.Discard())
+
+	err := classUnderTest.Upload(context.Background(), strings.ToUpper(id.Hex()), &content)
+
+	assert.Nil(t, err)
+	blobStoreMock.AssertExpectations(t)
+}
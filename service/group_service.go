@@ -0,0 +1,467 @@
+package service
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log/slog"
+	errs "user-service/error"
+	"user-service/model"
+	"user-service/repository"
+)
+
+type GroupService struct {
+	groupRepository repository.GroupRepositoryInterface
+	userRepository  repository.UserRepositoryInterface
+	logger          *slog.Logger
+}
+
+func NewGroupService(groupRepository repository.GroupRepositoryInterface, userRepository repository.UserRepositoryInterface, logger *slog.Logger) *GroupService {
+	return &GroupService{
+		groupRepository: groupRepository,
+		userRepository:  userRepository,
+		logger:          logger,
+	}
+}
+
+type GroupServiceInterface interface {
+	Create(context.Context, model.CreateGroupDomainModel) (*model.GroupDomainModel, error)
+	GetById(context.Context, string) (*model.GroupDomainModel, error)
+	GetAll(context.Context, string, int) ([]*model.GroupDomainModel, string, error)
+	UpdateById(context.Context, string, model.UpdateGroupDomainModel) (*model.GroupDomainModel, error)
+	DeleteById(context.Context, string) error
+	AddMembers(context.Context, string, model.GroupMembersDomainModel) ([]*model.BatchResultDomainModel, error)
+	RemoveMembers(context.Context, string, model.GroupMembersDomainModel) error
+	GetMembers(context.Context, string, string, int) ([]*model.GroupMemberDomainModel, string, error)
+	GetGroupsOfUser(context.Context, string, string, int) ([]*model.GroupDomainModel, string, error)
+}
+
+func (s *GroupService) Create(ctx context.Context, createDomainModel model.CreateGroupDomainModel) (*model.GroupDomainModel, error) {
+	groupEntity, err := s.groupRepository.Create(ctx, model.GroupEntity{
+		Id:          primitive.NewObjectID(),
+		Name:        createDomainModel.Name,
+		Description: createDomainModel.Description,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyGroupEntityToDomainModel(groupEntity), nil
+}
+
+func (s *GroupService) GetById(ctx context.Context, id string) (*model.GroupDomainModel, error) {
+	groupEntity, err := s.getGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return copyGroupEntityToDomainModel(groupEntity), nil
+}
+
+// GetAll returns a page of groups ordered by id along with the cursor of the next page, which is empty on the
+// last page. Unlike the users, an empty list is no error.
+func (s *GroupService) GetAll(ctx context.Context, after string, limit int) ([]*model.GroupDomainModel, string, error) {
+	cursor, err := s.parseGroupCursor(ctx, after)
+	if err != nil {
+		return nil, "", err
+	}
+
+	groupEntities, err := s.groupRepository.GetAll(ctx, cursor, pageLimit(limit))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if limit > 0 && len(groupEntities) > limit {
+		groupEntities = groupEntities[:limit]
+		next = groupEntities[limit-1].Id.Hex()
+	}
+
+	domainModels := make([]*model.GroupDomainModel, len(groupEntities))
+	for i, groupEntity := range groupEntities {
+		domainModels[i] = copyGroupEntityToDomainModel(groupEntity)
+	}
+
+	return domainModels, next, nil
+}
+
+func (s *GroupService) UpdateById(ctx context.Context, id string, updateDomainModel model.UpdateGroupDomainModel) (*model.GroupDomainModel, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		s.logger.DebugContext(ctx, "the id is not valid", "id", id, "error", err)
+		return nil, errs.BadRequestError
+	}
+
+	groupEntity, err := s.groupRepository.UpdateById(ctx, objectId, updateDomainModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return copyGroupEntityToDomainModel(groupEntity), nil
+}
+
+// DeleteById deletes the group with its members and then takes it out of the groups it belonged to. The group is
+// gone by then, so failing to do so only leaves memberships behind that nothing can reach and is logged rather
+// than reported.
+func (s *GroupService) DeleteById(ctx context.Context, id string) error {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		s.logger.DebugContext(ctx, "the id is not valid", "id", id, "error", err)
+		return errs.BadRequestError
+	}
+
+	err = s.groupRepository.DeleteById(ctx, objectId)
+	if err != nil {
+		return err
+	}
+
+	err = s.groupRepository.DeleteMemberships(ctx, model.MemberTypeGroup, []primitive.ObjectID{objectId})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "deleting the memberships of the group failed", "id", id, "error", err)
+	}
+
+	return nil
+}
+
+// AddMembers adds the users and groups to the group and reports the outcome of each id in order, users first.
+// Malformed and repeated ids fail with BadRequestError, unknown users with NotFoundError and unknown groups with
+// GroupNotFoundError. Groups that contain the group, directly or through other groups, fail with GroupCycleError,
+// as does the group itself. Adding a member again succeeds without changing anything. The returned error is only
+// set when nothing could be added at all.
+func (s *GroupService) AddMembers(ctx context.Context, id string, members model.GroupMembersDomainModel) ([]*model.BatchResultDomainModel, error) {
+	groupEntity, err := s.getGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	userResults, userIds := newBatchResults(members.UserIds)
+	groupResults, groupIds := newBatchResults(members.GroupIds)
+
+	usersToAdd, err := s.failMissingUsers(ctx, userResults, userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	groupsToAdd, err := s.failMissingGroups(ctx, groupResults, groupIds)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(groupsToAdd) > 0 {
+		ancestors, err := s.getAncestors(ctx, groupEntity.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		groupsToAdd = nil
+		for i, result := range groupResults {
+			if result.Err == nil && ancestors[groupIds[i]] {
+				result.Err = errs.GroupCycleError
+			} else if result.Err == nil {
+				groupsToAdd = append(groupsToAdd, groupIds[i])
+			}
+		}
+	}
+
+	if len(usersToAdd) > 0 {
+		err = s.groupRepository.AddMembers(ctx, groupEntity.Id, model.MemberTypeUser, usersToAdd)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(groupsToAdd) > 0 {
+		err = s.groupRepository.AddMembers(ctx, groupEntity.Id, model.MemberTypeGroup, groupsToAdd)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return append(userResults, groupResults...), nil
+}
+
+// failMissingUsers fails the results of ids that do not belong to a user with NotFoundError and returns the ids
+// of the results that have not failed.
+func (s *GroupService) failMissingUsers(ctx context.Context, results []*model.BatchResultDomainModel, objectIds []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	idsToFind := pendingIds(results, objectIds)
+	if len(idsToFind) == 0 {
+		return nil, nil
+	}
+
+	userEntities, err := s.userRepository.GetByIds(ctx, idsToFind)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[primitive.ObjectID]bool{}
+	for _, userEntity := range userEntities {
+		existing[userEntity.Id] = true
+	}
+
+	return failUnknown(results, objectIds, existing, errs.NotFoundError), nil
+}
+
+// failMissingGroups is failMissingUsers for groups, failing with GroupNotFoundError.
+func (s *GroupService) failMissingGroups(ctx context.Context, results []*model.BatchResultDomainModel, objectIds []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	idsToFind := pendingIds(results, objectIds)
+	if len(idsToFind) == 0 {
+		return nil, nil
+	}
+
+	groupEntities, err := s.groupRepository.GetByIds(ctx, idsToFind)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[primitive.ObjectID]bool{}
+	for _, groupEntity := range groupEntities {
+		existing[groupEntity.Id] = true
+	}
+
+	return failUnknown(results, objectIds, existing, errs.GroupNotFoundError), nil
+}
+
+func pendingIds(results []*model.BatchResultDomainModel, objectIds []primitive.ObjectID) []primitive.ObjectID {
+	var ids []primitive.ObjectID
+	for i, result := range results {
+		if result.Err == nil {
+			ids = append(ids, objectIds[i])
+		}
+	}
+
+	return ids
+}
+
+func failUnknown(results []*model.BatchResultDomainModel, objectIds []primitive.ObjectID, existing map[primitive.ObjectID]bool, err error) []primitive.ObjectID {
+	var found []primitive.ObjectID
+	for i, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		if existing[objectIds[i]] {
+			found = append(found, objectIds[i])
+		} else {
+			result.Err = err
+		}
+	}
+
+	return found
+}
+
+// getAncestors returns the group and every group that contains it, directly or through other groups. Adding any
+// of them to the group would close a cycle. The walk goes up one level per query and stops at groups it has
+// already seen, so it ends even if a cycle slipped in through concurrent additions.
+func (s *GroupService) getAncestors(ctx context.Context, id primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	ancestors := map[primitive.ObjectID]bool{id: true}
+
+	for level := []primitive.ObjectID{id}; len(level) > 0; {
+		parentIds, err := s.groupRepository.GetParentGroupIds(ctx, level)
+		if err != nil {
+			return nil, err
+		}
+
+		level = nil
+		for _, parentId := range parentIds {
+			if !ancestors[parentId] {
+				ancestors[parentId] = true
+				level = append(level, parentId)
+			}
+		}
+	}
+
+	return ancestors, nil
+}
+
+// RemoveMembers takes the users and groups out of the group. Ids that are no members are ignored, but a malformed
+// one fails the whole request with BadRequestError before anything is removed.
+func (s *GroupService) RemoveMembers(ctx context.Context, id string, members model.GroupMembersDomainModel) error {
+	groupEntity, err := s.getGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	userIds, err := s.parseMemberIds(ctx, members.UserIds)
+	if err != nil {
+		return err
+	}
+
+	groupIds, err := s.parseMemberIds(ctx, members.GroupIds)
+	if err != nil {
+		return err
+	}
+
+	if len(userIds) > 0 {
+		err = s.groupRepository.RemoveMembers(ctx, groupEntity.Id, model.MemberTypeUser, userIds)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(groupIds) > 0 {
+		return s.groupRepository.RemoveMembers(ctx, groupEntity.Id, model.MemberTypeGroup, groupIds)
+	}
+
+	return nil
+}
+
+func (s *GroupService) parseMemberIds(ctx context.Context, ids []string) ([]primitive.ObjectID, error) {
+	objectIds := make([]primitive.ObjectID, len(ids))
+	for i, id := range ids {
+		objectId, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			s.logger.DebugContext(ctx, "the id is not valid", "id", id, "error", err)
+			return nil, errs.BadRequestError
+		}
+
+		objectIds[i] = objectId
+	}
+
+	return objectIds, nil
+}
+
+// GetMembers returns a page of the direct members of the group in the order they were added along with the
+// cursor of the next page, which is empty on the last page.
+func (s *GroupService) GetMembers(ctx context.Context, id string, after string, limit int) ([]*model.GroupMemberDomainModel, string, error) {
+	groupEntity, err := s.getGroup(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	cursor, err := s.parseGroupCursor(ctx, after)
+	if err != nil {
+		return nil, "", err
+	}
+
+	memberships, err := s.groupRepository.GetMembers(ctx, groupEntity.Id, cursor, pageLimit(limit))
+	if err != nil {
+		return nil, "", err
+	}
+
+	memberships, next := cutPage(memberships, limit)
+
+	domainModels := make([]*model.GroupMemberDomainModel, len(memberships))
+	for i, membership := range memberships {
+		domainModels[i] = &model.GroupMemberDomainModel{
+			Type:    membership.MemberType,
+			Id:      membership.MemberId.Hex(),
+			AddedAt: membership.CreatedAt,
+			AddedBy: membership.CreatedBy,
+		}
+	}
+
+	return domainModels, next, nil
+}
+
+// GetGroupsOfUser returns a page of the groups the user is a direct member of, in the order the user was added to
+// them, along with the cursor of the next page, which is empty on the last page.
+func (s *GroupService) GetGroupsOfUser(ctx context.Context, userId string, after string, limit int) ([]*model.GroupDomainModel, string, error) {
+	objectId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		s.logger.DebugContext(ctx, "the id is not valid", "id", userId, "error", err)
+		return nil, "", errs.BadRequestError
+	}
+
+	_, err = s.userRepository.GetById(ctx, objectId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	cursor, err := s.parseGroupCursor(ctx, after)
+	if err != nil {
+		return nil, "", err
+	}
+
+	memberships, err := s.groupRepository.GetMemberships(ctx, model.MemberTypeUser, objectId, cursor, pageLimit(limit))
+	if err != nil {
+		return nil, "", err
+	}
+
+	memberships, next := cutPage(memberships, limit)
+	if len(memberships) == 0 {
+		return []*model.GroupDomainModel{}, next, nil
+	}
+
+	groupIds := make([]primitive.ObjectID, len(memberships))
+	for i, membership := range memberships {
+		groupIds[i] = membership.GroupId
+	}
+
+	groupEntities, err := s.groupRepository.GetByIds(ctx, groupIds)
+	if err != nil {
+		return nil, "", err
+	}
+
+	groupsById := map[primitive.ObjectID]*model.GroupEntity{}
+	for _, groupEntity := range groupEntities {
+		groupsById[groupEntity.Id] = groupEntity
+	}
+
+	// Memberships left behind by a group deleted concurrently have no group to show and are skipped.
+	domainModels := []*model.GroupDomainModel{}
+	for _, groupId := range groupIds {
+		if groupEntity, ok := groupsById[groupId]; ok {
+			domainModels = append(domainModels, copyGroupEntityToDomainModel(groupEntity))
+		}
+	}
+
+	return domainModels, next, nil
+}
+
+func (s *GroupService) getGroup(ctx context.Context, id string) (*model.GroupEntity, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		s.logger.DebugContext(ctx, "the id is not valid", "id", id, "error", err)
+		return nil, errs.BadRequestError
+	}
+
+	return s.groupRepository.GetById(ctx, objectId)
+}
+
+// parseGroupCursor reads the page token of a list of groups or memberships, which is the id of the last entry of
+// the previous page. An empty token starts from the beginning.
+func (s *GroupService) parseGroupCursor(ctx context.Context, after string) (*primitive.ObjectID, error) {
+	if after == "" {
+		return nil, nil
+	}
+
+	objectId, err := primitive.ObjectIDFromHex(after)
+	if err != nil {
+		s.logger.DebugContext(ctx, "the page token is not valid", "error", err)
+		return nil, errs.BadRequestError
+	}
+
+	return &objectId, nil
+}
+
+// pageLimit asks for one entry more than the page holds, which tells whether another page follows without a
+// separate count. A zero limit asks for every entry.
+func pageLimit(limit int) int64 {
+	if limit <= 0 {
+		return 0
+	}
+
+	return int64(limit) + 1
+}
+
+// cutPage trims the extra membership pageLimit asked for and returns the cursor of the next page, if any.
+func cutPage(memberships []*model.GroupMembershipEntity, limit int) ([]*model.GroupMembershipEntity, string) {
+	if limit <= 0 || len(memberships) <= limit {
+		return memberships, ""
+	}
+
+	memberships = memberships[:limit]
+
+	return memberships, memberships[limit-1].Id.Hex()
+}
+
+func copyGroupEntityToDomainModel(groupEntity *model.GroupEntity) *model.GroupDomainModel {
+	return &model.GroupDomainModel{
+		Id:          groupEntity.Id.Hex(),
+		Name:        groupEntity.Name,
+		Description: groupEntity.Description,
+		CreatedAt:   groupEntity.CreatedAt,
+		UpdatedAt:   groupEntity.UpdatedAt,
+		CreatedBy:   groupEntity.CreatedBy,
+		UpdatedBy:   groupEntity.UpdatedBy,
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	errs "user-service/error"
+	"user-service/logging"
+	"user-service/model"
+	repositoryMock "user-service/repository/mock"
+)
+
+func Test_AddMembers_Should_Reject_Groups_That_Would_Close_A_Cycle(t *testing.T) {
+	// grandparent contains parent, which contains the group that members are added to.
+	var groupId = primitive.NewObjectID()
+	var parentId = primitive.NewObjectID()
+	var grandparentId = primitive.NewObjectID()
+	var siblingId = primitive.NewObjectID()
+
+	groupRepositoryMock := new(repositoryMock.GroupRepositoryInterface)
+	groupRepositoryMock.On("GetById", mock.Anything, groupId).Return(&model.GroupEntity{Id: groupId}, nil).Once()
+	groupRepositoryMock.On("GetByIds", mock.Anything, []primitive.ObjectID{groupId, grandparentId, siblingId}).
+		Return([]*model.GroupEntity{{Id: groupId}, {Id: grandparentId}, {Id: siblingId}}, nil).Once()
+	groupRepositoryMock.On("GetParentGroupIds", mock.Anything, []primitive.ObjectID{groupId}).Return([]primitive.ObjectID{parentId}, nil).Once()
+	groupRepositoryMock.On("GetParentGroupIds", mock.Anything, []primitive.ObjectID{parentId}).Return([]primitive.ObjectID{grandparentId}, nil).Once()
+	// A cycle that slipped in earlier must not keep the walk going forever.
+	groupRepositoryMock.On("GetParentGroupIds", mock.Anything, []primitive.ObjectID{grandparentId}).Return([]primitive.ObjectID{groupId}, nil).Once()
+	groupRepositoryMock.On("AddMembers", mock.Anything, groupId, model.MemberTypeGroup, []primitive.ObjectID{siblingId}).Return(nil).Once()
+
+	classUnderTest := NewGroupService(groupRepositoryMock, new(repositoryMock.UserRepositoryInterface), logging.Discard())
+
+	results, err := classUnderTest.AddMembers(context.Background(), groupId.Hex(), model.GroupMembersDomainModel{
+		GroupIds: []string{groupId.Hex(), grandparentId.Hex(), siblingId.Hex()},
+	})
+
+	assert.Nil(t, err)
+	assert.ErrorIs(t, results[0].Err, errs.GroupCycleError)
+	assert.ErrorIs(t, results[1].Err, errs.GroupCycleError)
+	assert.Nil(t, results[2].Err)
+	groupRepositoryMock.AssertExpectations(t)
+}
+
+func Test_AddMembers_Should_Report_Malformed_And_Missing_Members_Separately(t *testing.T) {
+	var groupId = primitive.NewObjectID()
+	var userId = primitive.NewObjectID()
+	var missingUserId = primitive.NewObjectID()
+	var missingGroupId = primitive.NewObjectID()
+
+	groupRepositoryMock := new(repositoryMock.GroupRepositoryInterface)
+	groupRepositoryMock.On("GetById", mock.Anything, groupId).Return(&model.GroupEntity{Id: groupId}, nil).Once()
+	groupRepositoryMock.On("GetByIds", mock.Anything, []primitive.ObjectID{missingGroupId}).Return([]*model.GroupEntity{}, nil).Once()
+	groupRepositoryMock.On("AddMembers", mock.Anything, groupId, model.MemberTypeUser, []primitive.ObjectID{userId}).Return(nil).Once()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetByIds", mock.Anything, []primitive.ObjectID{userId, missingUserId}).Return([]*model.UserEntity{{Id: userId}}, nil).Once()
+
+	classUnderTest := NewGroupService(groupRepositoryMock, userRepositoryMock, logging.Discard())
+
+	results, err := classUnderTest.AddMembers(context.Background(), groupId.Hex(), model.GroupMembersDomainModel{
+		UserIds:  []string{userId.Hex(), "malformed", missingUserId.Hex(), userId.Hex()},
+		GroupIds: []string{missingGroupId.Hex()},
+	})
+
+	assert.Nil(t, err)
+	assert.Nil(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, errs.BadRequestError)
+	assert.ErrorIs(t, results[2].Err, errs.NotFoundError)
+	assert.ErrorIs(t, results[3].Err, errs.BadRequestError)
+	assert.ErrorIs(t, results[4].Err, errs.GroupNotFoundError)
+	groupRepositoryMock.AssertNotCalled(t, "GetParentGroupIds", mock.Anything, mock.Anything)
+	groupRepositoryMock.AssertExpectations(t)
+}
+
+func Test_AddMembers_Should_Return_GroupNotFoundError_When_Group_Does_Not_Exist(t *testing.T) {
+	var groupId = primitive.NewObjectID()
+
+	groupRepositoryMock := new(repositoryMock.GroupRepositoryInterface)
+	groupRepositoryMock.On("GetById", mock.Anything, groupId).Return(nil, errs.GroupNotFoundError).Once()
+
+	classUnderTest := NewGroupService(groupRepositoryMock, new(repositoryMock.UserRepositoryInterface), logging.Discard())
+
+	_, err := classUnderTest.AddMembers(context.Background(), groupId.Hex(), model.GroupMembersDomainModel{UserIds: []string{primitive.NewObjectID().Hex()}})
+
+	assert.ErrorIs(t, err, errs.GroupNotFoundError)
+	groupRepositoryMock.AssertNotCalled(t, "AddMembers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_RemoveMembers_Should_Remove_Nothing_When_An_Id_Is_Malformed(t *testing.T) {
+	var groupId = primitive.NewObjectID()
+
+	groupRepositoryMock := new(repositoryMock.GroupRepositoryInterface)
+	groupRepositoryMock.On("GetById", mock.Anything, groupId).Return(&model.GroupEntity{Id: groupId}, nil).Once()
+
+	classUnderTest := NewGroupService(groupRepositoryMock, new(repositoryMock.UserRepositoryInterface), logging.Discard())
+
+	err := classUnderTest.RemoveMembers(context.Background(), groupId.Hex(), model.GroupMembersDomainModel{
+		UserIds:  []string{primitive.NewObjectID().Hex()},
+		GroupIds: []string{"malformed"},
+	})
+
+	assert.ErrorIs(t, err, errs.BadRequestError)
+	groupRepositoryMock.AssertNotCalled(t, "RemoveMembers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_DeleteById_Should_Take_The_Deleted_Group_Out_Of_Its_Parents(t *testing.T) {
+	var groupId = primitive.NewObjectID()
+
+	groupRepositoryMock := new(repositoryMock.GroupRepositoryInterface)
+	groupRepositoryMock.On("DeleteById", mock.Anything, groupId).Return(nil).Once()
+	groupRepositoryMock.On("DeleteMemberships", mock.Anything, model.MemberTypeGroup, []primitive.ObjectID{groupId}).Return(errs.ServerError).Once()
+
+	classUnderTest := NewGroupService(groupRepositoryMock, new(repositoryMock.UserRepositoryInterface), logging.Discard())
+
+	err := classUnderTest.DeleteById(context.Background(), groupId.Hex())
+
+	assert.Nil(t, err)
+	groupRepositoryMock.AssertExpectations(t)
+}
+
+func Test_GetGroupsOfUser_Should_Return_A_Page_And_The_Cursor_Of_The_Next(t *testing.T) {
+	var userId = primitive.NewObjectID()
+	var firstGroupId = primitive.NewObjectID()
+	var secondGroupId = primitive.NewObjectID()
+	var memberships = []*model.GroupMembershipEntity{
+		{Id: primitive.NewObjectID(), GroupId: secondGroupId},
+		{Id: primitive.NewObjectID(), GroupId: firstGroupId},
+		{Id: primitive.NewObjectID(), GroupId: primitive.NewObjectID()},
+	}
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, userId).Return(&model.UserEntity{Id: userId}, nil).Once()
+
+	groupRepositoryMock := new(repositoryMock.GroupRepositoryInterface)
+	groupRepositoryMock.On("GetMemberships", mock.Anything, model.MemberTypeUser, userId, (*primitive.ObjectID)(nil), int64(3)).Return(memberships, nil).Once()
+	groupRepositoryMock.On("GetByIds", mock.Anything, []primitive.ObjectID{secondGroupId, firstGroupId}).
+		Return([]*model.GroupEntity{{Id: firstGroupId}, {Id: secondGroupId}}, nil).Once()
+
+	classUnderTest := NewGroupService(groupRepositoryMock, userRepositoryMock, logging.Discard())
+
+	groups, next, err := classUnderTest.GetGroupsOfUser(context.Background(), userId.Hex(), "", 2)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{secondGroupId.Hex(), firstGroupId.Hex()}, []string{groups[0].Id, groups[1].Id})
+	assert.Equal(t, memberships[1].Id.Hex(), next)
+	groupRepositoryMock.AssertExpectations(t)
+}
+
+func Test_GetGroupsOfUser_Should_Return_NotFoundError_When_User_Does_Not_Exist(t *testing.T) {
+	var userId = primitive.NewObjectID()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, userId).Return(nil, errs.NotFoundError).Once()
+
+	groupRepositoryMock := new(repositoryMock.GroupRepositoryInterface)
+
+	classUnderTest := NewGroupService(groupRepositoryMock, userRepositoryMock, logging.Discard())
+
+	_, _, err := classUnderTest.GetGroupsOfUser(context.Background(), userId.Hex(), "", 0)
+
+	assert.ErrorIs(t, err, errs.NotFoundError)
+	groupRepositoryMock.AssertNotCalled(t, "GetMemberships", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
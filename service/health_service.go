@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"user-service/model"
+)
+
+// HealthCheck probes one dependency of the service. Check returns nil while the dependency is usable. A failing
+// optional check only degrades the health to warn, so it does not take the service out of rotation.
+type HealthCheck struct {
+	Name          string
+	ComponentType string
+	Optional      bool
+	Check         func(context.Context) error
+}
+
+type HealthService struct {
+	checks       []HealthCheck
+	timeout      time.Duration
+	logger       *slog.Logger
+	shuttingDown atomic.Bool
+}
+
+// NewHealthService returns a service that runs the given checks, each of which must finish within timeout.
+func NewHealthService(timeout time.Duration, logger *slog.Logger, checks ...HealthCheck) *HealthService {
+	return &HealthService{
+		checks:  checks,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+type HealthServiceInterface interface {
+	Ready(context.Context) model.HealthViewModel
+	Health(context.Context) model.HealthViewModel
+}
+
+// ShutDown marks the service as shutting down, which fails readiness from then on so that no new traffic is
+// routed to it while it drains.
+func (s *HealthService) ShutDown() {
+	s.shuttingDown.Store(true)
+}
+
+// Ready reports whether the service can take traffic. It fails while shutting down without running the checks,
+// and names the failing checks but leaves their errors to Health.
+func (s *HealthService) Ready(ctx context.Context) model.HealthViewModel {
+	if s.shuttingDown.Load() {
+		return model.HealthViewModel{Status: model.HealthFail, Output: "the service is shutting down"}
+	}
+
+	health := s.Health(ctx)
+
+	var failing []string
+	for _, check := range s.checks {
+		if !check.Optional && health.Checks[checkKey(check)][0].Status == model.HealthFail {
+			failing = append(failing, check.Name)
+		}
+	}
+
+	if len(failing) > 0 {
+		return model.HealthViewModel{Status: model.HealthFail, Output: strings.Join(failing, ", ") + " failed"}
+	}
+
+	return model.HealthViewModel{Status: health.Status}
+}
+
+// Health runs every check concurrently and reports the status and response time of each.
+func (s *HealthService) Health(ctx context.Context) model.HealthViewModel {
+	results := make([]model.HealthCheckViewModel, len(s.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			results[i] = s.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	health := model.HealthViewModel{Status: model.HealthPass, Checks: map[string][]model.HealthCheckViewModel{}}
+	for i, check := range s.checks {
+		health.Checks[checkKey(check)] = []model.HealthCheckViewModel{results[i]}
+
+		if results[i].Status == model.HealthFail && !check.Optional {
+			health.Status = model.HealthFail
+		} else if results[i].Status == model.HealthFail && health.Status == model.HealthPass {
+			health.Status = model.HealthWarn
+		}
+	}
+
+	if s.shuttingDown.Load() {
+		health.Status = model.HealthFail
+		health.Output = "the service is shutting down"
+	}
+
+	return health
+}
+
+func (s *HealthService) run(ctx context.Context, check HealthCheck) model.HealthCheckViewModel {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+	elapsed := time.Since(start)
+
+	result := model.HealthCheckViewModel{
+		ComponentType: check.ComponentType,
+		ObservedValue: float64(elapsed.Microseconds()) / 1000,
+		ObservedUnit:  "ms",
+		Status:        model.HealthPass,
+		Time:          start.UTC(),
+	}
+	if err != nil {
+		s.logger.WarnContext(ctx, "a health check failed", "check", check.Name, "error", err)
+		result.Status = model.HealthFail
+		result.Output = err.Error()
+	}
+
+	return result
+}
+
+func checkKey(check HealthCheck) string {
+	return check.Name + ":responseTime"
+}
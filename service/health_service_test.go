@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"user-service/logging"
+	"user-service/model"
+)
+
+func newHealthCheck(name string, optional bool, err error) HealthCheck {
+	return HealthCheck{
+		Name:          name,
+		ComponentType: "datastore",
+		Optional:      optional,
+		Check: func(context.Context) error {
+			return err
+		},
+	}
+}
+
+func Test_Health_Should_Report_Every_Check(t *testing.T) {
+	classUnderTest := NewHealthService(time.Second, logging.Discard(), newHealthCheck("mongodb", false, nil), newHealthCheck("blobStore", false, nil))
+
+	health := classUnderTest.Health(context.Background())
+
+	assert.Equal(t, model.HealthPass, health.Status)
+	assert.Len(t, health.Checks, 2)
+	assert.Equal(t, model.HealthPass, health.Checks["mongodb:responseTime"][0].Status)
+	assert.Equal(t, "ms", health.Checks["mongodb:responseTime"][0].ObservedUnit)
+	assert.Equal(t, "datastore", health.Checks["blobStore:responseTime"][0].ComponentType)
+}
+
+func Test_Health_Should_Warn_When_Only_Optional_Checks_Fail(t *testing.T) {
+	classUnderTest := NewHealthService(time.Second, logging.Discard(), newHealthCheck("mongodb", false, nil), newHealthCheck("blobStore", true, errors.New("read-only file system")))
+
+	health := classUnderTest.Health(context.Background())
+
+	assert.Equal(t, model.HealthWarn, health.Status)
+	assert.Equal(t, model.HealthFail, health.Checks["blobStore:responseTime"][0].Status)
+	assert.Equal(t, "read-only file system", health.Checks["blobStore:responseTime"][0].Output)
+}
+
+func Test_Health_Should_Fail_A_Check_That_Exceeds_The_Timeout(t *testing.T) {
+	classUnderTest := NewHealthService(10*time.Millisecond, logging.Discard(), HealthCheck{
+		Name: "mongodb",
+		Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	health := classUnderTest.Health(context.Background())
+
+	assert.Equal(t, model.HealthFail, health.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), health.Checks["mongodb:responseTime"][0].Output)
+}
+
+func Test_Ready_Should_Name_Failing_Checks_Without_Their_Errors(t *testing.T) {
+	classUnderTest := NewHealthService(time.Second, logging.Discard(), newHealthCheck("mongodb", false, errors.New("connection refused")), newHealthCheck("migrations", false, nil))
+
+	health := classUnderTest.Ready(context.Background())
+
+	assert.Equal(t, model.HealthFail, health.Status)
+	assert.Equal(t, "mongodb failed", health.Output)
+	assert.Nil(t, health.Checks)
+}
+
+func Test_Ready_Should_Fail_Once_Shutting_Down(t *testing.T) {
+	checked := false
+	classUnderTest := NewHealthService(time.Second, logging.Discard(), HealthCheck{
+		Name: "mongodb",
+		Check: func(context.Context) error {
+			checked = true
+			return nil
+		},
+	})
+
+	assert.Equal(t, model.HealthPass, classUnderTest.Ready(context.Background()).Status)
+
+	checked = false
+	classUnderTest.ShutDown()
+	health := classUnderTest.Ready(context.Background())
+
+	assert.Equal(t, model.HealthFail, health.Status)
+	assert.Equal(t, "the service is shutting down", health.Output)
+	assert.False(t, checked)
+}
@@ -0,0 +1,58 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"time"
+	"user-service/model"
+)
+
+type ApiKeyServiceInterface struct {
+	mock.Mock
+}
+
+func (_m *ApiKeyServiceInterface) Create(ctx context.Context, createModel model.CreateApiKeyDomainModel) (*model.ApiKeyDomainModel, error) {
+	args := _m.Called(ctx, createModel)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.ApiKeyDomainModel), args.Error(1)
+}
+
+func (_m *ApiKeyServiceInterface) GetAll(ctx context.Context) ([]*model.ApiKeyDomainModel, error) {
+	args := _m.Called(ctx)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.ApiKeyDomainModel), args.Error(1)
+}
+
+func (_m *ApiKeyServiceInterface) Revoke(ctx context.Context, id string) error {
+	args := _m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (_m *ApiKeyServiceInterface) Rotate(ctx context.Context, id string, overlap time.Duration) (*model.ApiKeyDomainModel, error) {
+	args := _m.Called(ctx, id, overlap)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.ApiKeyDomainModel), args.Error(1)
+}
+
+func (_m *ApiKeyServiceInterface) Authenticate(ctx context.Context, key string) (*model.Principal, error) {
+	args := _m.Called(ctx, key)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.Principal), args.Error(1)
+}
@@ -0,0 +1,28 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"user-service/repository"
+)
+
+type AvatarServiceInterface struct {
+	mock.Mock
+}
+
+func (_m *AvatarServiceInterface) Upload(ctx context.Context, id string, content io.Reader) error {
+	args := _m.Called(ctx, id, content)
+
+	return args.Error(0)
+}
+
+func (_m *AvatarServiceInterface) Get(ctx context.Context, id string, size int) (*repository.Blob, error) {
+	args := _m.Called(ctx, id, size)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*repository.Blob), args.Error(1)
+}
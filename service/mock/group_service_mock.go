@@ -0,0 +1,93 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"user-service/model"
+)
+
+type GroupServiceInterface struct {
+	mock.Mock
+}
+
+func (_m *GroupServiceInterface) Create(ctx context.Context, createDomainModel model.CreateGroupDomainModel) (*model.GroupDomainModel, error) {
+	args := _m.Called(ctx, createDomainModel)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.GroupDomainModel), args.Error(1)
+}
+
+func (_m *GroupServiceInterface) GetById(ctx context.Context, id string) (*model.GroupDomainModel, error) {
+	args := _m.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.GroupDomainModel), args.Error(1)
+}
+
+func (_m *GroupServiceInterface) GetAll(ctx context.Context, after string, limit int) ([]*model.GroupDomainModel, string, error) {
+	args := _m.Called(ctx, after, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+
+	return args.Get(0).([]*model.GroupDomainModel), args.String(1), args.Error(2)
+}
+
+func (_m *GroupServiceInterface) UpdateById(ctx context.Context, id string, updateDomainModel model.UpdateGroupDomainModel) (*model.GroupDomainModel, error) {
+	args := _m.Called(ctx, id, updateDomainModel)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.GroupDomainModel), args.Error(1)
+}
+
+func (_m *GroupServiceInterface) DeleteById(ctx context.Context, id string) error {
+	args := _m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (_m *GroupServiceInterface) AddMembers(ctx context.Context, id string, members model.GroupMembersDomainModel) ([]*model.BatchResultDomainModel, error) {
+	args := _m.Called(ctx, id, members)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.BatchResultDomainModel), args.Error(1)
+}
+
+func (_m *GroupServiceInterface) RemoveMembers(ctx context.Context, id string, members model.GroupMembersDomainModel) error {
+	args := _m.Called(ctx, id, members)
+
+	return args.Error(0)
+}
+
+func (_m *GroupServiceInterface) GetMembers(ctx context.Context, id string, after string, limit int) ([]*model.GroupMemberDomainModel, string, error) {
+	args := _m.Called(ctx, id, after, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+
+	return args.Get(0).([]*model.GroupMemberDomainModel), args.String(1), args.Error(2)
+}
+
+func (_m *GroupServiceInterface) GetGroupsOfUser(ctx context.Context, userId string, after string, limit int) ([]*model.GroupDomainModel, string, error) {
+	args := _m.Called(ctx, userId, after, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+
+	return args.Get(0).([]*model.GroupDomainModel), args.String(1), args.Error(2)
+}
@@ -0,0 +1,23 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"user-service/model"
+)
+
+type HealthServiceInterface struct {
+	mock.Mock
+}
+
+func (_m *HealthServiceInterface) Ready(ctx context.Context) model.HealthViewModel {
+	args := _m.Called(ctx)
+
+	return args.Get(0).(model.HealthViewModel)
+}
+
+func (_m *HealthServiceInterface) Health(ctx context.Context) model.HealthViewModel {
+	args := _m.Called(ctx)
+
+	return args.Get(0).(model.HealthViewModel)
+}
@@ -0,0 +1,43 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/mock"
+	"user-service/model"
+	"user-service/profile"
+)
+
+type ProfileSchemaServiceInterface struct {
+	mock.Mock
+}
+
+func (_m *ProfileSchemaServiceInterface) GetCurrent(ctx context.Context) (*model.ProfileSchemaDomainModel, error) {
+	args := _m.Called(ctx)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.ProfileSchemaDomainModel), args.Error(1)
+}
+
+func (_m *ProfileSchemaServiceInterface) Publish(ctx context.Context, document json.RawMessage, precondition *model.Precondition) (*model.ProfileSchemaDomainModel, error) {
+	args := _m.Called(ctx, document, precondition)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.ProfileSchemaDomainModel), args.Error(1)
+}
+
+func (_m *ProfileSchemaServiceInterface) Current(ctx context.Context) (*profile.Schema, error) {
+	args := _m.Called(ctx)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*profile.Schema), args.Error(1)
+}
@@ -0,0 +1,21 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"user-service/model"
+)
+
+type RateLimitServiceInterface struct {
+	mock.Mock
+}
+
+func (_m *RateLimitServiceInterface) Take(ctx context.Context, route string, keys []string, client model.RateLimitClient) (*model.RateLimitViewModel, error) {
+	args := _m.Called(ctx, route, keys, client)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.RateLimitViewModel), args.Error(1)
+}
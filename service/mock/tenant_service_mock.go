@@ -0,0 +1,57 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"user-service/model"
+)
+
+type TenantServiceInterface struct {
+	mock.Mock
+}
+
+func (_m *TenantServiceInterface) Create(ctx context.Context, createModel model.CreateTenantDomainModel) (*model.TenantDomainModel, error) {
+	args := _m.Called(ctx, createModel)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.TenantDomainModel), args.Error(1)
+}
+
+func (_m *TenantServiceInterface) GetAll(ctx context.Context) ([]*model.TenantDomainModel, error) {
+	args := _m.Called(ctx)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.TenantDomainModel), args.Error(1)
+}
+
+func (_m *TenantServiceInterface) GetById(ctx context.Context, id string) (*model.TenantDomainModel, error) {
+	args := _m.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.TenantDomainModel), args.Error(1)
+}
+
+func (_m *TenantServiceInterface) UpdateById(ctx context.Context, id string, updateModel model.UpdateTenantDomainModel) (*model.TenantDomainModel, error) {
+	args := _m.Called(ctx, id, updateModel)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.TenantDomainModel), args.Error(1)
+}
+
+func (_m *TenantServiceInterface) Resolve(ctx context.Context, id string) error {
+	args := _m.Called(ctx, id)
+
+	return args.Error(0)
+}
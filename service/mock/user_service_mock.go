@@ -1,7 +1,7 @@
 package mock
 
 import (
-	"github.com/gin-gonic/gin"
+	"context"
 	"github.com/stretchr/testify/mock"
 	"user-service/model"
 )
@@ -10,7 +10,7 @@ type UserServiceInterface struct {
 	mock.Mock
 }
 
-func (_m *UserServiceInterface) Create(ctx *gin.Context, createModel model.CreateUserDomainModel) (*model.UserDomainModel, error) {
+func (_m *UserServiceInterface) Create(ctx context.Context, createModel model.CreateUserDomainModel) (*model.UserDomainModel, error) {
 	args := _m.Called(ctx, createModel)
 
 	if args.Get(0) == nil {
@@ -20,7 +20,7 @@ func (_m *UserServiceInterface) Create(ctx *gin.Context, createModel model.Creat
 	return args.Get(0).(*model.UserDomainModel), args.Error(1)
 }
 
-func (_m *UserServiceInterface) GetById(ctx *gin.Context, id string) (*model.UserDomainModel, error) {
+func (_m *UserServiceInterface) GetById(ctx context.Context, id string) (*model.UserDomainModel, error) {
 	args := _m.Called(ctx, id)
 
 	if args.Get(0) == nil {
@@ -30,23 +30,23 @@ func (_m *UserServiceInterface) GetById(ctx *gin.Context, id string) (*model.Use
 	return args.Get(0).(*model.UserDomainModel), args.Error(1)
 }
 
-func (_m *UserServiceInterface) GetAll(ctx *gin.Context) ([]*model.UserDomainModel, error) {
-	args := _m.Called(ctx)
+func (_m *UserServiceInterface) GetAll(ctx context.Context, page model.PageDomainModel) ([]*model.UserDomainModel, string, error) {
+	args := _m.Called(ctx, page)
 
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, args.String(1), args.Error(2)
 	}
 
-	return args.Get(0).([]*model.UserDomainModel), args.Error(1)
+	return args.Get(0).([]*model.UserDomainModel), args.String(1), args.Error(2)
 }
 
-func (_m *UserServiceInterface) DeleteById(ctx *gin.Context, id string) error {
+func (_m *UserServiceInterface) DeleteById(ctx context.Context, id string) error {
 	args := _m.Called(ctx, id)
 
 	return args.Error(0)
 }
 
-func (_m *UserServiceInterface) UpdateById(ctx *gin.Context, id string, updateModel model.UpdateUserDomainModel) (*model.UserDomainModel, error) {
+func (_m *UserServiceInterface) UpdateById(ctx context.Context, id string, updateModel model.UpdateUserDomainModel) (*model.UserDomainModel, error) {
 	args := _m.Called(ctx, id, updateModel)
 
 	if args.Get(0) == nil {
@@ -55,3 +55,95 @@ func (_m *UserServiceInterface) UpdateById(ctx *gin.Context, id string, updateMo
 
 	return args.Get(0).(*model.UserDomainModel), args.Error(1)
 }
+
+func (_m *UserServiceInterface) ReplaceById(ctx context.Context, id string, replaceModel model.ReplaceUserDomainModel, precondition *model.Precondition) (*model.UserDomainModel, bool, error) {
+	args := _m.Called(ctx, id, replaceModel, precondition)
+
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+
+	return args.Get(0).(*model.UserDomainModel), args.Bool(1), args.Error(2)
+}
+
+func (_m *UserServiceInterface) GetByIds(ctx context.Context, ids []string) ([]*model.UserDomainModel, error) {
+	args := _m.Called(ctx, ids)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.UserDomainModel), args.Error(1)
+}
+
+func (_m *UserServiceInterface) ValidateCreate(ctx context.Context, createModel model.CreateUserDomainModel) error {
+	args := _m.Called(ctx, createModel)
+
+	return args.Error(0)
+}
+
+func (_m *UserServiceInterface) Export(ctx context.Context, filter model.UserFilterDomainModel, fn func(*model.UserDomainModel) error) error {
+	args := _m.Called(ctx, filter, fn)
+
+	return args.Error(0)
+}
+
+func (_m *UserServiceInterface) DeleteByIds(ctx context.Context, ids []string) ([]*model.BatchResultDomainModel, error) {
+	args := _m.Called(ctx, ids)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.BatchResultDomainModel), args.Error(1)
+}
+
+func (_m *UserServiceInterface) UpdateByIds(ctx context.Context, updates []model.BatchUpdateUserDomainModel) ([]*model.BatchResultDomainModel, error) {
+	args := _m.Called(ctx, updates)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.BatchResultDomainModel), args.Error(1)
+}
+
+func (_m *UserServiceInterface) GetRevisions(ctx context.Context, id string) ([]*model.UserRevisionDomainModel, error) {
+	args := _m.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.UserRevisionDomainModel), args.Error(1)
+}
+
+func (_m *UserServiceInterface) GetRevision(ctx context.Context, id string, revision int64) (*model.UserRevisionDomainModel, error) {
+	args := _m.Called(ctx, id, revision)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.UserRevisionDomainModel), args.Error(1)
+}
+
+func (_m *UserServiceInterface) DiffRevisions(ctx context.Context, id string, from int64, to int64) (*model.RevisionDiffDomainModel, error) {
+	args := _m.Called(ctx, id, from, to)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.RevisionDiffDomainModel), args.Error(1)
+}
+
+func (_m *UserServiceInterface) RevertToRevision(ctx context.Context, id string, revision int64) (*model.UserDomainModel, error) {
+	args := _m.Called(ctx, id, revision)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.UserDomainModel), args.Error(1)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	errs "user-service/error"
+	"user-service/model"
+	"user-service/profile"
+	"user-service/repository"
+)
+
+type ProfileSchemaService struct {
+	profileSchemaRepository repository.ProfileSchemaRepositoryInterface
+	userRepository          repository.UserRepositoryInterface
+	logger                  *slog.Logger
+
+	mutex    sync.Mutex
+	compiled *profile.Schema
+}
+
+func NewProfileSchemaService(profileSchemaRepository repository.ProfileSchemaRepositoryInterface, userRepository repository.UserRepositoryInterface, logger *slog.Logger) *ProfileSchemaService {
+	return &ProfileSchemaService{
+		profileSchemaRepository: profileSchemaRepository,
+		userRepository:          userRepository,
+		logger:                  logger,
+	}
+}
+
+type ProfileSchemaServiceInterface interface {
+	GetCurrent(context.Context) (*model.ProfileSchemaDomainModel, error)
+	Publish(context.Context, json.RawMessage, *model.Precondition) (*model.ProfileSchemaDomainModel, error)
+	Current(context.Context) (*profile.Schema, error)
+}
+
+func (s *ProfileSchemaService) GetCurrent(ctx context.Context) (*model.ProfileSchemaDomainModel, error) {
+	schemaEntity, err := s.profileSchemaRepository.GetLatest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := s.compile(ctx, schemaEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	return copyProfileSchemaEntityToDomainModel(schemaEntity, schema), nil
+}
+
+// Publish stores document as the next version of the profile schema and indexes the attributes it marks as
+// indexed. Profiles already stored are not checked against it; each one is validated against the schema in force
+// the next time it is written, so a schema can evolve without rewriting users.
+func (s *ProfileSchemaService) Publish(ctx context.Context, document json.RawMessage, precondition *model.Precondition) (*model.ProfileSchemaDomainModel, error) {
+	var version int64
+
+	latest, err := s.profileSchemaRepository.GetLatest(ctx)
+	if err == nil {
+		version = latest.Version
+	} else if !errors.Is(err, errs.ProfileSchemaNotFoundError) {
+		return nil, err
+	}
+
+	if precondition != nil && (latest == nil || !isPreconditionSatisfied(precondition, version)) {
+		return nil, errs.PreconditionFailedError
+	}
+
+	schema, err := profile.Compile(document, version+1)
+	if err != nil {
+		return nil, &errs.FieldErrors{
+			Err:    errs.InvalidProfileSchemaError,
+			Fields: []errs.FieldError{{Field: "schema", Rule: "schema", Param: err.Error()}},
+		}
+	}
+
+	var compacted bytes.Buffer
+	err = json.Compact(&compacted, document)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "compacting the profile schema failed", "error", err)
+		return nil, errs.ServerError
+	}
+
+	schemaEntity, err := s.profileSchemaRepository.Create(ctx, model.ProfileSchemaEntity{
+		Version:  version + 1,
+		Document: compacted.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	s.compiled = schema
+	s.mutex.Unlock()
+
+	// The schema is in force by now. Filters on an attribute whose index failed only run slower, and the indexes
+	// are brought in line again with the next version.
+	err = s.userRepository.SyncProfileIndexes(ctx, schema.Indexed())
+	if err != nil {
+		s.logger.ErrorContext(ctx, "syncing the profile indexes failed", "error", err)
+	}
+
+	return copyProfileSchemaEntityToDomainModel(schemaEntity, schema), nil
+}
+
+// Current returns the schema in force, or nil when none was published yet. The latest version is looked up on
+// every call so that schemas published through other instances take effect at once, but each version is only
+// compiled once.
+func (s *ProfileSchemaService) Current(ctx context.Context) (*profile.Schema, error) {
+	schemaEntity, err := s.profileSchemaRepository.GetLatest(ctx)
+	if errors.Is(err, errs.ProfileSchemaNotFoundError) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return s.compile(ctx, schemaEntity)
+}
+
+func (s *ProfileSchemaService) compile(ctx context.Context, schemaEntity *model.ProfileSchemaEntity) (*profile.Schema, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.compiled != nil && s.compiled.Version() == schemaEntity.Version {
+		return s.compiled, nil
+	}
+
+	schema, err := profile.Compile([]byte(schemaEntity.Document), schemaEntity.Version)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "compiling the stored profile schema failed", "version", schemaEntity.Version, "error", err)
+		return nil, errs.ServerError
+	}
+
+	s.compiled = schema
+	return schema, nil
+}
+
+func copyProfileSchemaEntityToDomainModel(schemaEntity *model.ProfileSchemaEntity, schema *profile.Schema) *model.ProfileSchemaDomainModel {
+	return &model.ProfileSchemaDomainModel{
+		Version:   schemaEntity.Version,
+		Document:  json.RawMessage(schemaEntity.Document),
+		Indexed:   schema.Indexed(),
+		CreatedAt: schemaEntity.CreatedAt,
+		CreatedBy: schemaEntity.CreatedBy,
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	errs "user-service/error"
+	"user-service/logging"
+	"user-service/model"
+	repositoryMock "user-service/repository/mock"
+)
+
+const departmentSchema = `{"type":"object","properties":{"department":{"type":"string","x-indexed":true}},"required":["department"]}`
+
+func Test_Publish_Should_Store_The_Next_Version_And_Sync_Indexes(t *testing.T) {
+	profileSchemaRepositoryMock := new(repositoryMock.ProfileSchemaRepositoryInterface)
+	profileSchemaRepositoryMock.On("GetLatest", mock.Anything).Return(&model.ProfileSchemaEntity{Version: 2, Document: `{"type":"object"}`}, nil).Once()
+	profileSchemaRepositoryMock.On("Create", mock.Anything, model.ProfileSchemaEntity{Version: 3, Document: departmentSchema}).Return(&model.ProfileSchemaEntity{Version: 3, Document: departmentSchema}, nil).Once()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("SyncProfileIndexes", mock.Anything, []string{"department"}).Return(nil).Once()
+
+	classUnderTest := NewProfileSchemaService(profileSchemaRepositoryMock, userRepositoryMock, logging.Discard())
+
+	published, err := classUnderTest.Publish(context.Background(), json.RawMessage(" "+departmentSchema+"\n"), &model.Precondition{Versions: []int64{2}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), published.Version)
+	assert.Equal(t, []string{"department"}, published.Indexed)
+	profileSchemaRepositoryMock.AssertExpectations(t)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_Publish_Should_Return_InvalidProfileSchemaError_When_Schema_Does_Not_Compile(t *testing.T) {
+	profileSchemaRepositoryMock := new(repositoryMock.ProfileSchemaRepositoryInterface)
+	profileSchemaRepositoryMock.On("GetLatest", mock.Anything).Return(nil, errs.ProfileSchemaNotFoundError).Once()
+
+	classUnderTest := NewProfileSchemaService(profileSchemaRepositoryMock, new(repositoryMock.UserRepositoryInterface), logging.Discard())
+
+	published, err := classUnderTest.Publish(context.Background(), json.RawMessage(`{"type":"string"}`), nil)
+
+	var fieldErrors *errs.FieldErrors
+	assert.Nil(t, published)
+	assert.True(t, errors.Is(err, errs.InvalidProfileSchemaError))
+	assert.True(t, errors.As(err, &fieldErrors))
+	assert.Equal(t, "schema", fieldErrors.Fields[0].Field)
+	profileSchemaRepositoryMock.AssertExpectations(t)
+}
+
+func Test_Publish_Should_Return_PreconditionFailedError_When_Another_Version_Is_In_Force(t *testing.T) {
+	profileSchemaRepositoryMock := new(repositoryMock.ProfileSchemaRepositoryInterface)
+	profileSchemaRepositoryMock.On("GetLatest", mock.Anything).Return(&model.ProfileSchemaEntity{Version: 4, Document: `{"type":"object"}`}, nil).Once()
+
+	classUnderTest := NewProfileSchemaService(profileSchemaRepositoryMock, new(repositoryMock.UserRepositoryInterface), logging.Discard())
+
+	published, err := classUnderTest.Publish(context.Background(), json.RawMessage(departmentSchema), &model.Precondition{Versions: []int64{3}})
+
+	assert.Nil(t, published)
+	assert.Equal(t, errs.PreconditionFailedError, err)
+	profileSchemaRepositoryMock.AssertExpectations(t)
+}
+
+func Test_Current_Should_Compile_Each_Version_Once(t *testing.T) {
+	profileSchemaRepositoryMock := new(repositoryMock.ProfileSchemaRepositoryInterface)
+	profileSchemaRepositoryMock.On("GetLatest", mock.Anything).Return(&model.ProfileSchemaEntity{Version: 1, Document: departmentSchema}, nil).Twice()
+
+	classUnderTest := NewProfileSchemaService(profileSchemaRepositoryMock, new(repositoryMock.UserRepositoryInterface), logging.Discard())
+
+	first, err := classUnderTest.Current(context.Background())
+	assert.Nil(t, err)
+	second, err := classUnderTest.Current(context.Background())
+	assert.Nil(t, err)
+
+	assert.Same(t, first, second)
+	profileSchemaRepositoryMock.AssertExpectations(t)
+}
+
+func Test_Current_Should_Return_Nil_When_No_Schema_Was_Published(t *testing.T) {
+	profileSchemaRepositoryMock := new(repositoryMock.ProfileSchemaRepositoryInterface)
+	profileSchemaRepositoryMock.On("GetLatest", mock.Anything).Return(nil, errs.ProfileSchemaNotFoundError).Once()
+
+	classUnderTest := NewProfileSchemaService(profileSchemaRepositoryMock, new(repositoryMock.UserRepositoryInterface), logging.Discard())
+
+	schema, err := classUnderTest.Current(context.Background())
+
+	assert.Nil(t, schema)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"user-service/config"
+	errs "user-service/error"
+	"user-service/model"
+	"user-service/repository"
+)
+
+type RateLimitService struct {
+	store    repository.RateLimitStore
+	policies map[string][]config.RateLimitPolicy
+	logger   *slog.Logger
+}
+
+// NewRateLimitService applies the given policies with buckets kept in store.
+func NewRateLimitService(store repository.RateLimitStore, policies []config.RateLimitPolicy, logger *slog.Logger) *RateLimitService {
+	byRoute := map[string][]config.RateLimitPolicy{}
+	for _, policy := range policies {
+		byRoute[policy.Route] = append(byRoute[policy.Route], policy)
+	}
+
+	return &RateLimitService{
+		store:    store,
+		policies: byRoute,
+		logger:   logger,
+	}
+}
+
+type RateLimitServiceInterface interface {
+	Take(ctx context.Context, route string, keys []string, client model.RateLimitClient) (*model.RateLimitViewModel, error)
+}
+
+// Take takes a token for a request of client to route, given as a method and route template, from the bucket of
+// every policy of the route that is keyed by one of keys. It fails with errs.TooManyRequestsError when any of them
+// is empty, and returns the bucket closest to rejecting the client, or nil when no policy applies. A store that
+// fails lets the request through, so an outage of the database does not take down the routes that work without it.
+func (s *RateLimitService) Take(ctx context.Context, route string, keys []string, client model.RateLimitClient) (*model.RateLimitViewModel, error) {
+	policies, ok := s.policies[route]
+	if !ok {
+		policies = s.policies[config.AnyRoute]
+	}
+
+	var tightest *model.RateLimitViewModel
+	rejected := false
+	for _, policy := range policies {
+		if !slices.Contains(keys, policy.Key) {
+			continue
+		}
+
+		bucket, err := s.store.Take(ctx, policy.String()+" "+clientKey(policy.Key, client), policy.Requests, policy.Period)
+		if err != nil {
+			s.logger.WarnContext(ctx, "letting a request through without rate limiting", "policy", policy.String(), "error", err)
+			continue
+		}
+
+		limit := &model.RateLimitViewModel{
+			Limit:      policy.Requests,
+			Remaining:  bucket.Remaining,
+			Period:     policy.Period,
+			Reset:      bucket.Reset,
+			RetryAfter: bucket.RetryAfter,
+		}
+		rejected = rejected || !bucket.Allowed
+		if tightest == nil || limit.Remaining < tightest.Remaining ||
+			limit.Remaining == tightest.Remaining && limit.RetryAfter > tightest.RetryAfter {
+			tightest = limit
+		}
+	}
+
+	if rejected {
+		return tightest, errs.TooManyRequestsError
+	}
+
+	return tightest, nil
+}
+
+// clientKey names the bucket of client for a policy keyed by key. Clients without the user or API key the policy
+// is keyed by share the buckets of their IP address.
+func clientKey(key string, client model.RateLimitClient) string {
+	switch {
+	case key == config.RateLimitKeyUser && client.UserId != "":
+		return "user:" + client.UserId
+	case key == config.RateLimitKeyApiKey && client.ApiKeyId != "":
+		return "apiKey:" + client.ApiKeyId
+	default:
+		return "ip:" + client.IP
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+	"user-service/config"
+	errs "user-service/error"
+	"user-service/logging"
+	"user-service/model"
+	"user-service/repository"
+	repositoryMock "user-service/repository/mock"
+)
+
+func parsePolicies(policies ...string) []config.RateLimitPolicy {
+	return config.RateLimit{Policies: policies}.ParsedPolicies()
+}
+
+func Test_RateLimitTake_Should_Reject_Once_The_Bucket_Of_The_Route_Is_Empty(t *testing.T) {
+	classUnderTest := NewRateLimitService(repository.NewMemoryRateLimitStore(), parsePolicies("POST /users 2/1h per ip"), logging.Discard())
+	client := model.RateLimitClient{IP: "203.0.113.7"}
+
+	limit, err := classUnderTest.Take(context.Background(), "POST /users", config.RateLimitKeys, client)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, limit.Limit)
+	assert.Equal(t, 1, limit.Remaining)
+	assert.Equal(t, time.Hour, limit.Period)
+	assert.InDelta(t, 30*time.Minute, limit.Reset, float64(time.Second))
+
+	_, err = classUnderTest.Take(context.Background(), "POST /users", config.RateLimitKeys, client)
+	assert.Nil(t, err)
+
+	limit, err = classUnderTest.Take(context.Background(), "POST /users", config.RateLimitKeys, client)
+	assert.ErrorIs(t, err, errs.TooManyRequestsError)
+	assert.Equal(t, 0, limit.Remaining)
+	assert.InDelta(t, 30*time.Minute, limit.RetryAfter, float64(time.Second))
+
+	limit, err = classUnderTest.Take(context.Background(), "POST /users", config.RateLimitKeys, model.RateLimitClient{IP: "203.0.113.8"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, limit.Remaining)
+}
+
+func Test_RateLimitTake_Should_Fall_Back_To_The_Policies_For_Any_Route(t *testing.T) {
+	classUnderTest := NewRateLimitService(repository.NewMemoryRateLimitStore(), parsePolicies("POST /users 2/1h per ip", "* 100/1m per ip"), logging.Discard())
+
+	limit, err := classUnderTest.Take(context.Background(), "GET /users/:id", config.RateLimitKeys, model.RateLimitClient{IP: "203.0.113.7"})
+	assert.Nil(t, err)
+	assert.Equal(t, 100, limit.Limit)
+
+	limit, err = classUnderTest.Take(context.Background(), "POST /users", config.RateLimitKeys, model.RateLimitClient{IP: "203.0.113.7"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, limit.Limit)
+}
+
+func Test_RateLimitTake_Should_Return_Nil_When_No_Policy_Applies(t *testing.T) {
+	classUnderTest := NewRateLimitService(repository.NewMemoryRateLimitStore(), parsePolicies("POST /users 2/1h per ip"), logging.Discard())
+
+	limit, err := classUnderTest.Take(context.Background(), "GET /users", config.RateLimitKeys, model.RateLimitClient{IP: "203.0.113.7"})
+
+	assert.Nil(t, err)
+	assert.Nil(t, limit)
+}
+
+func Test_RateLimitTake_Should_Key_Buckets_By_Api_Key_And_Fall_Back_To_The_IP(t *testing.T) {
+	policy := parsePolicies("* 10/1m per apiKey")[0]
+
+	rateLimitStoreMock := new(repositoryMock.RateLimitStore)
+	rateLimitStoreMock.On("Take", mock.Anything, "* 10/1m0s per apiKey apiKey:key-1", 10, time.Minute).Return(repository.RateLimitBucket{Allowed: true, Remaining: 9}, nil).Once()
+	rateLimitStoreMock.On("Take", mock.Anything, "* 10/1m0s per apiKey ip:203.0.113.7", 10, time.Minute).Return(repository.RateLimitBucket{Allowed: true, Remaining: 9}, nil).Once()
+
+	classUnderTest := NewRateLimitService(rateLimitStoreMock, []config.RateLimitPolicy{policy}, logging.Discard())
+
+	classUnderTest.Take(context.Background(), "GET /users", config.RateLimitKeys, model.RateLimitClient{IP: "203.0.113.7", ApiKeyId: "key-1"})
+	classUnderTest.Take(context.Background(), "GET /users", config.RateLimitKeys, model.RateLimitClient{IP: "203.0.113.7"})
+
+	rateLimitStoreMock.AssertExpectations(t)
+}
+
+func Test_RateLimitTake_Should_Key_Buckets_By_User_And_Fall_Back_To_The_IP(t *testing.T) {
+	policy := parsePolicies("PUT /users/:id/avatar 5/1h per user")[0]
+
+	rateLimitStoreMock := new(repositoryMock.RateLimitStore)
+	rateLimitStoreMock.On("Take", mock.Anything, "PUT /users/:id/avatar 5/1h0m0s per user user:user-1", 5, time.Hour).Return(repository.RateLimitBucket{Allowed: true, Remaining: 4}, nil).Once()
+	rateLimitStoreMock.On("Take", mock.Anything, "PUT /users/:id/avatar 5/1h0m0s per user ip:203.0.113.7", 5, time.Hour).Return(repository.RateLimitBucket{Allowed: true, Remaining: 4}, nil).Once()
+
+	classUnderTest := NewRateLimitService(rateLimitStoreMock, []config.RateLimitPolicy{policy}, logging.Discard())
+
+	classUnderTest.Take(context.Background(), "PUT /users/:id/avatar", config.RateLimitKeys, model.RateLimitClient{IP: "203.0.113.7", UserId: "user-1", ApiKeyId: "key-1"})
+	classUnderTest.Take(context.Background(), "PUT /users/:id/avatar", config.RateLimitKeys, model.RateLimitClient{IP: "203.0.113.7"})
+
+	rateLimitStoreMock.AssertExpectations(t)
+}
+
+func Test_RateLimitTake_Should_Only_Apply_The_Policies_Keyed_By_The_Given_Keys(t *testing.T) {
+	rateLimitStoreMock := new(repositoryMock.RateLimitStore)
+	rateLimitStoreMock.On("Take", mock.Anything, "POST /users 2/1h0m0s per ip ip:203.0.113.7", 2, time.Hour).Return(repository.RateLimitBucket{Allowed: true, Remaining: 1}, nil).Once()
+
+	classUnderTest := NewRateLimitService(rateLimitStoreMock, parsePolicies("POST /users 2/1h per ip", "POST /users 10/1h per apiKey"), logging.Discard())
+
+	limit, err := classUnderTest.Take(context.Background(), "POST /users", []string{config.RateLimitKeyIP}, model.RateLimitClient{IP: "203.0.113.7"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, limit.Limit)
+	rateLimitStoreMock.AssertExpectations(t)
+}
+
+func Test_RateLimitTake_Should_Report_The_Rejecting_Policy_Of_Several(t *testing.T) {
+	rateLimitStoreMock := new(repositoryMock.RateLimitStore)
+	rateLimitStoreMock.On("Take", mock.Anything, mock.Anything, 100, time.Hour).Return(repository.RateLimitBucket{Allowed: true, Remaining: 50}, nil).Once()
+	rateLimitStoreMock.On("Take", mock.Anything, mock.Anything, 5, time.Minute).Return(repository.RateLimitBucket{Allowed: false, RetryAfter: 12 * time.Second}, nil).Once()
+
+	classUnderTest := NewRateLimitService(rateLimitStoreMock, parsePolicies("* 100/1h per ip", "* 5/1m per ip"), logging.Discard())
+
+	limit, err := classUnderTest.Take(context.Background(), "GET /users", config.RateLimitKeys, model.RateLimitClient{IP: "203.0.113.7"})
+
+	assert.ErrorIs(t, err, errs.TooManyRequestsError)
+	assert.Equal(t, 5, limit.Limit)
+	assert.Equal(t, 12*time.Second, limit.RetryAfter)
+}
+
+func Test_RateLimitTake_Should_Let_Requests_Through_When_The_Store_Fails(t *testing.T) {
+	rateLimitStoreMock := new(repositoryMock.RateLimitStore)
+	rateLimitStoreMock.On("Take", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(repository.RateLimitBucket{}, errs.ServerError).Once()
+
+	classUnderTest := NewRateLimitService(rateLimitStoreMock, parsePolicies("POST /users 2/1h per ip"), logging.Discard())
+
+	limit, err := classUnderTest.Take(context.Background(), "POST /users", config.RateLimitKeys, model.RateLimitClient{IP: "203.0.113.7"})
+
+	assert.Nil(t, err)
+	assert.Nil(t, limit)
+}
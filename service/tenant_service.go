@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+	errs "user-service/error"
+	"user-service/model"
+	"user-service/repository"
+)
+
+// tenantCacheTTL bounds how long Resolve may keep serving a tenant that another instance disabled.
+const tenantCacheTTL = 30 * time.Second
+
+type TenantService struct {
+	tenantRepository repository.TenantRepositoryInterface
+	logger           *slog.Logger
+
+	mutex sync.Mutex
+	cache map[string]cachedTenant
+}
+
+type cachedTenant struct {
+	tenant    *model.TenantEntity
+	expiresAt time.Time
+}
+
+func NewTenantService(tenantRepository repository.TenantRepositoryInterface, logger *slog.Logger) *TenantService {
+	return &TenantService{
+		tenantRepository: tenantRepository,
+		logger:           logger,
+		cache:            map[string]cachedTenant{},
+	}
+}
+
+type TenantServiceInterface interface {
+	Create(context.Context, model.CreateTenantDomainModel) (*model.TenantDomainModel, error)
+	GetAll(context.Context) ([]*model.TenantDomainModel, error)
+	GetById(context.Context, string) (*model.TenantDomainModel, error)
+	UpdateById(context.Context, string, model.UpdateTenantDomainModel) (*model.TenantDomainModel, error)
+	Resolve(context.Context, string) error
+}
+
+func (s *TenantService) Create(ctx context.Context, createModel model.CreateTenantDomainModel) (*model.TenantDomainModel, error) {
+	if !model.IsTenantId(createModel.Id) {
+		return nil, &errs.FieldErrors{Err: errs.ValidationError, Fields: []errs.FieldError{{Field: "id", Rule: "tenantId"}}}
+	}
+
+	tenantEntity, err := s.tenantRepository.Create(ctx, model.TenantEntity{
+		Id:   createModel.Id,
+		Name: createModel.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyTenantEntityToDomainModel(tenantEntity), nil
+}
+
+func (s *TenantService) GetAll(ctx context.Context) ([]*model.TenantDomainModel, error) {
+	tenantEntities, err := s.tenantRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	domainModels := make([]*model.TenantDomainModel, len(tenantEntities))
+	for i, tenantEntity := range tenantEntities {
+		domainModels[i] = copyTenantEntityToDomainModel(tenantEntity)
+	}
+
+	return domainModels, nil
+}
+
+func (s *TenantService) GetById(ctx context.Context, id string) (*model.TenantDomainModel, error) {
+	tenantEntity, err := s.tenantRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return copyTenantEntityToDomainModel(tenantEntity), nil
+}
+
+// UpdateById renames or disables the tenant. Disabling takes effect at once on this instance and within
+// tenantCacheTTL on the others.
+func (s *TenantService) UpdateById(ctx context.Context, id string, updateModel model.UpdateTenantDomainModel) (*model.TenantDomainModel, error) {
+	tenantEntity, err := s.tenantRepository.UpdateById(ctx, id, updateModel)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	delete(s.cache, id)
+	s.mutex.Unlock()
+
+	return copyTenantEntityToDomainModel(tenantEntity), nil
+}
+
+// Resolve checks that requests may be served for the tenant with the given id. Unknown tenants fail with
+// TenantNotFoundError and disabled ones with ForbiddenError. Tenants that were found are cached for
+// tenantCacheTTL, since every request resolves one.
+func (s *TenantService) Resolve(ctx context.Context, id string) error {
+	now := time.Now()
+
+	s.mutex.Lock()
+	cached, ok := s.cache[id]
+	s.mutex.Unlock()
+
+	tenantEntity := cached.tenant
+	if !ok || !now.Before(cached.expiresAt) {
+		var err error
+		tenantEntity, err = s.tenantRepository.GetById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		s.mutex.Lock()
+		s.cache[id] = cachedTenant{tenant: tenantEntity, expiresAt: now.Add(tenantCacheTTL)}
+		s.mutex.Unlock()
+	}
+
+	if tenantEntity.Disabled {
+		s.logger.DebugContext(ctx, "rejected a request for a disabled tenant", "tenant", id)
+		return errs.ForbiddenError
+	}
+
+	return nil
+}
+
+// WithTenant confines the users read and written with the returned context to the tenant with the given id.
+func WithTenant(ctx context.Context, tenantId string) context.Context {
+	return repository.WithTenant(ctx, tenantId)
+}
+
+func copyTenantEntityToDomainModel(tenantEntity *model.TenantEntity) *model.TenantDomainModel {
+	return &model.TenantDomainModel{
+		Id:        tenantEntity.Id,
+		Name:      tenantEntity.Name,
+		Disabled:  tenantEntity.Disabled,
+		CreatedAt: tenantEntity.CreatedAt,
+		UpdatedAt: tenantEntity.UpdatedAt,
+		CreatedBy: tenantEntity.CreatedBy,
+		UpdatedBy: tenantEntity.UpdatedBy,
+	}
+}
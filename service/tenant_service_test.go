@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	errs "user-service/error"
+	"user-service/logging"
+	"user-service/model"
+	repositoryMock "user-service/repository/mock"
+)
+
+func Test_TenantCreate_Should_Reject_Ids_That_Are_No_DNS_Labels(t *testing.T) {
+	tenantRepositoryMock := new(repositoryMock.TenantRepositoryInterface)
+
+	classUnderTest := NewTenantService(tenantRepositoryMock, logging.Discard())
+
+	for _, id := range []string{"Acme", "-acme", "acme-", "acme.corp", ""} {
+		_, err := classUnderTest.Create(context.Background(), model.CreateTenantDomainModel{Id: id, Name: "Acme"})
+
+		assert.ErrorIs(t, err, errs.ValidationError, id)
+	}
+	tenantRepositoryMock.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func Test_TenantResolve_Should_Cache_Tenants_And_Reject_Disabled_Ones(t *testing.T) {
+	tenantRepositoryMock := new(repositoryMock.TenantRepositoryInterface)
+	tenantRepositoryMock.On("GetById", mock.Anything, "acme").Return(&model.TenantEntity{Id: "acme"}, nil).Once()
+	tenantRepositoryMock.On("GetById", mock.Anything, "missing").Return(nil, errs.TenantNotFoundError).Twice()
+
+	classUnderTest := NewTenantService(tenantRepositoryMock, logging.Discard())
+
+	assert.Nil(t, classUnderTest.Resolve(context.Background(), "acme"))
+	assert.Nil(t, classUnderTest.Resolve(context.Background(), "acme"))
+	assert.ErrorIs(t, classUnderTest.Resolve(context.Background(), "missing"), errs.TenantNotFoundError)
+	assert.ErrorIs(t, classUnderTest.Resolve(context.Background(), "missing"), errs.TenantNotFoundError)
+
+	disabled := true
+	tenantRepositoryMock.On("UpdateById", mock.Anything, "acme", model.UpdateTenantDomainModel{Disabled: &disabled}).Return(&model.TenantEntity{Id: "acme", Disabled: true}, nil).Once()
+	tenantRepositoryMock.On("GetById", mock.Anything, "acme").Return(&model.TenantEntity{Id: "acme", Disabled: true}, nil).Once()
+
+	_, err := classUnderTest.UpdateById(context.Background(), "acme", model.UpdateTenantDomainModel{Disabled: &disabled})
+	assert.Nil(t, err)
+
+	assert.ErrorIs(t, classUnderTest.Resolve(context.Background(), "acme"), errs.ForbiddenError)
+	tenantRepositoryMock.AssertExpectations(t)
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"time"
+	errs "user-service/error"
+	"user-service/model"
+	"user-service/profile"
+)
+
+// recordRevisions snapshots users right after a write. The write has already happened by then, so a failure is
+// only logged rather than reported as a failed write.
+func (s *UserService) recordRevisions(ctx context.Context, userEntities ...*model.UserEntity) {
+	if len(userEntities) == 0 {
+		return
+	}
+
+	createdAt := time.Now().UTC()
+	revisions := make([]model.UserRevisionEntity, len(userEntities))
+	for i, userEntity := range userEntities {
+		revisions[i] = model.UserRevisionEntity{
+			Id:        primitive.NewObjectID(),
+			UserId:    userEntity.Id,
+			Revision:  userEntity.Version,
+			Name:      userEntity.Name,
+			Email:     userEntity.Email,
+			Profile:   userEntity.Profile,
+			CreatedAt: createdAt,
+		}
+	}
+
+	err := s.revisionRepository.Create(ctx, revisions)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "recording revisions failed", "error", err)
+	}
+}
+
+// GetRevisions returns every recorded revision of the user, oldest first. Users written before revisions were
+// recorded have none.
+func (s *UserService) GetRevisions(ctx context.Context, id string) ([]*model.UserRevisionDomainModel, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		s.logger.DebugContext(ctx, "the id is not valid", "id", id, "error", err)
+		return nil, errs.BadRequestError
+	}
+
+	revisionEntities, err := s.revisionRepository.GetAll(ctx, objectId)
+	if err != nil {
+		return nil, err
+	}
+
+	// An empty history only means something when the user exists.
+	if len(revisionEntities) == 0 {
+		_, err := s.userRepository.GetById(ctx, objectId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	domainModels := make([]*model.UserRevisionDomainModel, len(revisionEntities))
+	for i, revisionEntity := range revisionEntities {
+		domainModels[i] = copyRevisionEntityToDomainModel(revisionEntity)
+	}
+
+	return domainModels, nil
+}
+
+func (s *UserService) GetRevision(ctx context.Context, id string, revision int64) (*model.UserRevisionDomainModel, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		s.logger.DebugContext(ctx, "the id is not valid", "id", id, "error", err)
+		return nil, errs.BadRequestError
+	}
+
+	revisionEntity, err := s.revisionRepository.GetByRevision(ctx, objectId, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return copyRevisionEntityToDomainModel(revisionEntity), nil
+}
+
+// DiffRevisions lists the fields that changed from one revision of the user to another.
+func (s *UserService) DiffRevisions(ctx context.Context, id string, from int64, to int64) (*model.RevisionDiffDomainModel, error) {
+	fromRevision, err := s.GetRevision(ctx, id, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toRevision, err := s.GetRevision(ctx, id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &model.RevisionDiffDomainModel{From: from, To: to, Changes: []model.FieldChangeDomainModel{}}
+	if fromRevision.Name != toRevision.Name {
+		diff.Changes = append(diff.Changes, model.FieldChangeDomainModel{Field: "name", From: fromRevision.Name, To: toRevision.Name})
+	}
+	if fromRevision.Email != toRevision.Email {
+		diff.Changes = append(diff.Changes, model.FieldChangeDomainModel{Field: "email", From: fromRevision.Email, To: toRevision.Email})
+	}
+
+	for _, attribute := range profileAttributes(fromRevision.Profile, toRevision.Profile) {
+		fromValue, inFrom := fromRevision.Profile[attribute]
+		toValue, inTo := toRevision.Profile[attribute]
+		if inFrom && inTo && profile.Equal(fromValue, toValue) {
+			continue
+		}
+
+		diff.Changes = append(diff.Changes, model.FieldChangeDomainModel{
+			Field: "profile." + attribute,
+			From:  s.encodeProfileValue(ctx, fromValue, inFrom),
+			To:    s.encodeProfileValue(ctx, toValue, inTo),
+		})
+	}
+
+	return diff, nil
+}
+
+// profileAttributes returns the attributes that appear in either profile in alphabetical order.
+func profileAttributes(profiles ...map[string]interface{}) []string {
+	seen := map[string]bool{}
+	var attributes []string
+	for _, userProfile := range profiles {
+		for attribute := range userProfile {
+			if !seen[attribute] {
+				seen[attribute] = true
+				attributes = append(attributes, attribute)
+			}
+		}
+	}
+	sort.Strings(attributes)
+
+	return attributes
+}
+
+func (s *UserService) encodeProfileValue(ctx context.Context, value interface{}, present bool) string {
+	if !present {
+		return ""
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "encoding a profile value failed", "error", err)
+		return ""
+	}
+
+	return string(encoded)
+}
+
+// RevertToRevision restores the fields of the given revision through UpdateById, so it is checked like any
+// other update and is itself recorded as a new revision. Only fields that differ are written, which keeps an
+// unchanged email from failing the uniqueness check against the user itself.
+func (s *UserService) RevertToRevision(ctx context.Context, id string, revision int64) (*model.UserDomainModel, error) {
+	revisionModel, err := s.GetRevision(ctx, id, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var update model.UpdateUserDomainModel
+	if current.Name != revisionModel.Name {
+		update.Name = &revisionModel.Name
+	}
+	if current.Email != revisionModel.Email {
+		update.Email = &revisionModel.Email
+	}
+	for _, attribute := range profileAttributes(current.Profile, revisionModel.Profile) {
+		currentValue, inCurrent := current.Profile[attribute]
+		revisionValue, inRevision := revisionModel.Profile[attribute]
+		if inCurrent && inRevision && profile.Equal(currentValue, revisionValue) {
+			continue
+		}
+
+		if update.Profile == nil {
+			update.Profile = map[string]interface{}{}
+		}
+		// A nil value removes the attributes that were added after the revision.
+		update.Profile[attribute] = revisionValue
+	}
+
+	if update.Name == nil && update.Email == nil && update.Profile == nil {
+		return current, nil
+	}
+
+	return s.UpdateById(ctx, id, update)
+}
+
+func copyRevisionEntityToDomainModel(revisionEntity *model.UserRevisionEntity) *model.UserRevisionDomainModel {
+	return &model.UserRevisionDomainModel{
+		UserId:    revisionEntity.UserId.Hex(),
+		Revision:  revisionEntity.Revision,
+		Name:      revisionEntity.Name,
+		Email:     revisionEntity.Email,
+		Profile:   revisionEntity.Profile,
+		CreatedAt: revisionEntity.CreatedAt,
+	}
+}
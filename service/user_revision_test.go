@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	errs "user-service/error"
+	"user-service/logging"
+	"user-service/model"
+	repositoryMock "user-service/repository/mock"
+)
+
+func Test_UpdateById_Should_Record_Revision_Without_Password_When_Update_Succeeds(t *testing.T) {
+	var id = primitive.NewObjectID()
+	var name = "Batuhan"
+	var password = "secret"
+	var userEntity = model.UserEntity{Id: id, Name: name, Email: "batuhan@site.com", Password: "hash", Version: 3}
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("UpdateById", mock.Anything, id, mock.Anything).Return(&userEntity, nil).Once()
+
+	revisionRepositoryMock := new(repositoryMock.UserRevisionRepositoryInterface)
+	revisionRepositoryMock.On("Create", mock.Anything, mock.MatchedBy(func(revisions []model.UserRevisionEntity) bool {
+		return len(revisions) == 1 && revisions[0].UserId == id && revisions[0].Revision == 3 &&
+			revisions[0].Name == name && revisions[0].Email == userEntity.Email
+	})).Return(nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, revisionRepositoryMock, newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	_, err := classUnderTest.UpdateById(context.Background(), id.Hex(), model.UpdateUserDomainModel{Name: &name, Password: &password})
+
+	assert.Nil(t, err)
+	revisionRepositoryMock.AssertExpectations(t)
+}
+
+func Test_UpdateById_Should_Succeed_When_Recording_Revision_Fails(t *testing.T) {
+	var id = primitive.NewObjectID()
+	var name = "Batuhan"
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("UpdateById", mock.Anything, id, mock.Anything).Return(&model.UserEntity{Id: id, Name: name}, nil).Once()
+
+	revisionRepositoryMock := new(repositoryMock.UserRevisionRepositoryInterface)
+	revisionRepositoryMock.On("Create", mock.Anything, mock.Anything).Return(errs.ServerError).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, revisionRepositoryMock, newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	user, err := classUnderTest.UpdateById(context.Background(), id.Hex(), model.UpdateUserDomainModel{Name: &name})
+
+	assert.Nil(t, err)
+	assert.Equal(t, name, user.Name)
+}
+
+func Test_GetRevisions_Should_Return_NotFoundError_When_User_Has_No_Revisions_And_Does_Not_Exist(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, id).Return(nil, errs.NotFoundError).Once()
+
+	revisionRepositoryMock := new(repositoryMock.UserRevisionRepositoryInterface)
+	revisionRepositoryMock.On("GetAll", mock.Anything, id).Return([]*model.UserRevisionEntity{}, nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, revisionRepositoryMock, newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	revisions, err := classUnderTest.GetRevisions(context.Background(), id.Hex())
+
+	assert.Nil(t, revisions)
+	assert.ErrorIs(t, err, errs.NotFoundError)
+}
+
+func Test_DiffRevisions_Should_List_Changed_Fields_Only(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	revisionRepositoryMock := new(repositoryMock.UserRevisionRepositoryInterface)
+	revisionRepositoryMock.On("GetByRevision", mock.Anything, id, int64(1)).Return(&model.UserRevisionEntity{UserId: id, Revision: 1, Name: "Old", Email: "same@site.com"}, nil).Once()
+	revisionRepositoryMock.On("GetByRevision", mock.Anything, id, int64(4)).Return(&model.UserRevisionEntity{UserId: id, Revision: 4, Name: "New", Email: "same@site.com"}, nil).Once()
+
+	classUnderTest := NewUserService(new(repositoryMock.UserRepositoryInterface), revisionRepositoryMock, newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	diff, err := classUnderTest.DiffRevisions(context.Background(), id.Hex(), 1, 4)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []model.FieldChangeDomainModel{{Field: "name", From: "Old", To: "New"}}, diff.Changes)
+}
+
+func Test_RevertToRevision_Should_Only_Update_Changed_Fields_Through_UpdateById(t *testing.T) {
+	var id = primitive.NewObjectID()
+	var oldName = "Old"
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, id).Return(&model.UserEntity{Id: id, Name: "New", Email: "same@site.com", Version: 4}, nil).Once()
+	userRepositoryMock.On("UpdateById", mock.Anything, id, model.UpdateUserDomainModel{Name: &oldName}).Return(&model.UserEntity{Id: id, Name: oldName, Email: "same@site.com", Version: 5}, nil).Once()
+
+	revisionRepositoryMock := new(repositoryMock.UserRevisionRepositoryInterface)
+	revisionRepositoryMock.On("GetByRevision", mock.Anything, id, int64(1)).Return(&model.UserRevisionEntity{UserId: id, Revision: 1, Name: oldName, Email: "same@site.com"}, nil).Once()
+	revisionRepositoryMock.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, revisionRepositoryMock, newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	user, err := classUnderTest.RevertToRevision(context.Background(), id.Hex(), 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), user.Version)
+	userRepositoryMock.AssertNotCalled(t, "CheckIfEmailAlreadyInUse", mock.Anything, mock.Anything)
+	userRepositoryMock.AssertExpectations(t)
+	revisionRepositoryMock.AssertExpectations(t)
+}
+
+func Test_DiffRevisions_Should_List_Changed_Profile_Attributes_As_JSON(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	revisionRepositoryMock := new(repositoryMock.UserRevisionRepositoryInterface)
+	revisionRepositoryMock.On("GetByRevision", mock.Anything, id, int64(1)).Return(&model.UserRevisionEntity{UserId: id, Revision: 1, Profile: map[string]interface{}{"floor": int32(2), "team": "red"}}, nil).Once()
+	revisionRepositoryMock.On("GetByRevision", mock.Anything, id, int64(2)).Return(&model.UserRevisionEntity{UserId: id, Revision: 2, Profile: map[string]interface{}{"floor": 2.0, "remote": true}}, nil).Once()
+
+	classUnderTest := NewUserService(new(repositoryMock.UserRepositoryInterface), revisionRepositoryMock, newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	diff, err := classUnderTest.DiffRevisions(context.Background(), id.Hex(), 1, 2)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []model.FieldChangeDomainModel{
+		{Field: "profile.remote", From: "", To: "true"},
+		{Field: "profile.team", From: `"red"`, To: ""},
+	}, diff.Changes)
+}
+
+func Test_RevertToRevision_Should_Restore_The_Profile_Of_The_Revision(t *testing.T) {
+	var id = primitive.NewObjectID()
+	var current = &model.UserEntity{Id: id, Version: 4, Profile: map[string]interface{}{"team": "blue", "remote": true, "floor": int32(2)}}
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, id).Return(current, nil).Twice()
+	userRepositoryMock.On("UpdateById", mock.Anything, id, model.UpdateUserDomainModel{Profile: map[string]interface{}{"team": "red", "remote": nil}}).Return(&model.UserEntity{Id: id, Version: 5}, nil).Once()
+
+	revisionRepositoryMock := new(repositoryMock.UserRevisionRepositoryInterface)
+	revisionRepositoryMock.On("GetByRevision", mock.Anything, id, int64(1)).Return(&model.UserRevisionEntity{UserId: id, Revision: 1, Profile: map[string]interface{}{"team": "red", "floor": 2.0}}, nil).Once()
+	revisionRepositoryMock.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, revisionRepositoryMock, newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	user, err := classUnderTest.RevertToRevision(context.Background(), id.Hex(), 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), user.Version)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_RevertToRevision_Should_Return_RevisionNotFoundError_When_Revision_Does_Not_Exist(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	revisionRepositoryMock := new(repositoryMock.UserRevisionRepositoryInterface)
+	revisionRepositoryMock.On("GetByRevision", mock.Anything, id, int64(9)).Return(nil, errs.RevisionNotFoundError).Once()
+
+	classUnderTest := NewUserService(new(repositoryMock.UserRepositoryInterface), revisionRepositoryMock, newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	user, err := classUnderTest.RevertToRevision(context.Background(), id.Hex(), 9)
+
+	assert.Nil(t, user)
+	assert.ErrorIs(t, err, errs.RevisionNotFoundError)
+}
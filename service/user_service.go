@@ -1,44 +1,78 @@
 package service
 
 import (
-	"github.com/gin-gonic/gin"
+	"context"
+	"errors"
+	"fmt"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/bcrypt"
-	"log"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 	errs "user-service/error"
+	"user-service/metrics"
 	"user-service/model"
+	"user-service/profile"
 	"user-service/repository"
+	"user-service/tracing"
 )
 
+// PasswordCost is the bcrypt cost passwords are hashed with. It is configurable and is set at startup.
+var PasswordCost = bcrypt.DefaultCost
+
 type UserService struct {
-	userRepository repository.UserRepositoryInterface
+	userRepository       repository.UserRepositoryInterface
+	revisionRepository   repository.UserRevisionRepositoryInterface
+	profileSchemaService ProfileSchemaServiceInterface
+	blobStore            repository.BlobStore
+	groupRepository      repository.GroupRepositoryInterface
+	logger               *slog.Logger
 }
 
-func NewUserService(userRepository repository.UserRepositoryInterface) *UserService {
+func NewUserService(userRepository repository.UserRepositoryInterface, revisionRepository repository.UserRevisionRepositoryInterface, profileSchemaService ProfileSchemaServiceInterface, blobStore repository.BlobStore, groupRepository repository.GroupRepositoryInterface, logger *slog.Logger) *UserService {
 	return &UserService{
-		userRepository: userRepository,
+		userRepository:       userRepository,
+		revisionRepository:   revisionRepository,
+		profileSchemaService: profileSchemaService,
+		blobStore:            blobStore,
+		groupRepository:      groupRepository,
+		logger:               logger,
 	}
 }
 
 type UserServiceInterface interface {
-	Create(*gin.Context, model.CreateUserDomainModel) (*model.UserDomainModel, error)
-	GetById(*gin.Context, string) (*model.UserDomainModel, error)
-	GetAll(*gin.Context) ([]*model.UserDomainModel, error)
-	DeleteById(*gin.Context, string) error
-	UpdateById(*gin.Context, string, model.UpdateUserDomainModel) (*model.UserDomainModel, error)
+	Create(context.Context, model.CreateUserDomainModel) (*model.UserDomainModel, error)
+	ValidateCreate(context.Context, model.CreateUserDomainModel) error
+	GetById(context.Context, string) (*model.UserDomainModel, error)
+	GetAll(context.Context, model.PageDomainModel) ([]*model.UserDomainModel, string, error)
+	GetByIds(context.Context, []string) ([]*model.UserDomainModel, error)
+	Export(context.Context, model.UserFilterDomainModel, func(*model.UserDomainModel) error) error
+	DeleteById(context.Context, string) error
+	DeleteByIds(context.Context, []string) ([]*model.BatchResultDomainModel, error)
+	UpdateById(context.Context, string, model.UpdateUserDomainModel) (*model.UserDomainModel, error)
+	UpdateByIds(context.Context, []model.BatchUpdateUserDomainModel) ([]*model.BatchResultDomainModel, error)
+	ReplaceById(context.Context, string, model.ReplaceUserDomainModel, *model.Precondition) (*model.UserDomainModel, bool, error)
+	GetRevisions(context.Context, string) ([]*model.UserRevisionDomainModel, error)
+	GetRevision(context.Context, string, int64) (*model.UserRevisionDomainModel, error)
+	DiffRevisions(context.Context, string, int64, int64) (*model.RevisionDiffDomainModel, error)
+	RevertToRevision(context.Context, string, int64) (*model.UserDomainModel, error)
 }
 
-func (s *UserService) Create(ctx *gin.Context, createDomainModel model.CreateUserDomainModel) (*model.UserDomainModel, error) {
-	isEmailInUse, err := s.userRepository.CheckIfEmailAlreadyInUse(ctx, createDomainModel.Email)
-	if isEmailInUse {
-		return nil, errs.EmailAlreadyInUseError
-	} else if err != nil {
+func (s *UserService) Create(ctx context.Context, createDomainModel model.CreateUserDomainModel) (*model.UserDomainModel, error) {
+	createDomainModel.Email = model.NormalizeEmail(createDomainModel.Email)
+	createDomainModel.Profile = profile.Merge(nil, createDomainModel.Profile)
+
+	err := s.ValidateCreate(ctx, createDomainModel)
+	if err != nil {
 		return nil, err
 	}
 
-	hashedPasswordInBytes, err := bcrypt.GenerateFromPassword([]byte(createDomainModel.Password), bcrypt.MinCost)
+	hashedPasswordInBytes, err := hashPassword(ctx, createDomainModel.Password)
 	if err != nil {
-		log.Println(err)
+		s.logger.ErrorContext(ctx, "hashing the password failed", "error", err)
 		return nil, errs.ServerError
 	}
 
@@ -47,26 +81,41 @@ func (s *UserService) Create(ctx *gin.Context, createDomainModel model.CreateUse
 		Name:     createDomainModel.Name,
 		Email:    createDomainModel.Email,
 		Password: string(hashedPasswordInBytes),
+		Version:  1,
+		Profile:  createDomainModel.Profile,
 	}
 
-	err = s.userRepository.Create(ctx, entity)
+	createdEntity, err := s.userRepository.Create(ctx, entity)
 	if err != nil {
 		return nil, err
 	}
 
-	domainModel := model.UserDomainModel{
-		Id:    entity.Id.Hex(),
-		Email: entity.Email,
-		Name:  entity.Name,
+	s.recordRevisions(ctx, createdEntity)
+
+	return copyEntityToDomainModel(createdEntity), nil
+}
+
+// ValidateCreate applies the rules Create enforces without creating the user.
+func (s *UserService) ValidateCreate(ctx context.Context, createDomainModel model.CreateUserDomainModel) error {
+	err := s.validateProfile(ctx, profile.Merge(nil, createDomainModel.Profile))
+	if err != nil {
+		return err
+	}
+
+	isEmailInUse, err := s.userRepository.CheckIfEmailAlreadyInUse(ctx, model.NormalizeEmail(createDomainModel.Email))
+	if isEmailInUse {
+		return errs.EmailAlreadyInUseError
+	} else if err != nil {
+		return err
 	}
 
-	return &domainModel, nil
+	return nil
 }
 
-func (s *UserService) GetById(ctx *gin.Context, id string) (user *model.UserDomainModel, err error) {
+func (s *UserService) GetById(ctx context.Context, id string) (user *model.UserDomainModel, err error) {
 	objectId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Println(err)
+		s.logger.DebugContext(ctx, "the id is not valid", "id", id, "error", err)
 		return nil, errs.BadRequestError
 	}
 
@@ -75,42 +124,137 @@ func (s *UserService) GetById(ctx *gin.Context, id string) (user *model.UserDoma
 		return nil, err
 	}
 
-	user = &model.UserDomainModel{
-		Id:    userEntity.Id.Hex(),
-		Name:  userEntity.Name,
-		Email: userEntity.Email,
+	return copyEntityToDomainModel(userEntity), nil
+}
+
+// GetAll returns the requested page of users along with the cursor of the next page, which is empty on the
+// last page.
+func (s *UserService) GetAll(ctx context.Context, page model.PageDomainModel) (userViews []*model.UserDomainModel, next string, err error) {
+	var after *model.UserCursor
+	if page.After != "" {
+		after, err = decodeCursor(page.After, page.Sort)
+		if err != nil {
+			s.logger.DebugContext(ctx, "the page token is not valid", "error", err)
+			return nil, "", errs.BadRequestError
+		}
+	}
+
+	page.Filter, err = s.resolveProfileFilter(ctx, page.Filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// One extra user is fetched to find out whether another page follows without a separate count.
+	var limit int64
+	if page.Limit > 0 {
+		limit = int64(page.Limit) + 1
+	}
+
+	userEntities, err := s.userRepository.GetAll(ctx, page.Filter, page.Sort, after, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(userEntities) == 0 {
+		return nil, "", errs.NotFoundError
+	}
+
+	if page.Limit > 0 && len(userEntities) > page.Limit {
+		userEntities = userEntities[:page.Limit]
+		next = encodeCursor(userEntities[page.Limit-1], page.Sort)
+	}
+
+	for i := 0; i < len(userEntities); i++ {
+		userViews = append(userViews, copyEntityToDomainModel(userEntities[i]))
 	}
 
 	return
 }
 
-func (s *UserService) GetAll(ctx *gin.Context) (userViews []*model.UserDomainModel, err error) {
-	userEntities, err := s.userRepository.GetAll(ctx)
+// encodeCursor turns the position of a user into an opaque page token. Sorting by id keeps the plain hex id
+// used before other sort orders existed, so older next links still work.
+func encodeCursor(userEntity *model.UserEntity, sort model.UserSort) string {
+	switch sort.Field {
+	case model.SortByCreatedAt:
+		return userEntity.Id.Hex() + "_" + strconv.FormatInt(userEntity.CreatedAt.UnixMilli(), 10)
+	case model.SortByUpdatedAt:
+		return userEntity.Id.Hex() + "_" + strconv.FormatInt(userEntity.UpdatedAt.UnixMilli(), 10)
+	default:
+		return userEntity.Id.Hex()
+	}
+}
+
+func decodeCursor(cursor string, sort model.UserSort) (*model.UserCursor, error) {
+	id, millis, hasTime := strings.Cut(cursor, "_")
+	if hasTime != (sort.Field == model.SortByCreatedAt || sort.Field == model.SortByUpdatedAt) {
+		return nil, fmt.Errorf("cursor %q does not belong to sort order %q", cursor, sort.Field)
+	}
+
+	objectId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(userEntities) == 0 {
-		return nil, errs.NotFoundError
+	result := &model.UserCursor{Id: objectId}
+	if hasTime {
+		unixMilli, err := strconv.ParseInt(millis, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Time = time.UnixMilli(unixMilli).UTC()
+	}
+
+	return result, nil
+}
+
+// GetByIds returns the users with the given ids in a single round trip. Ids that are malformed or do not belong
+// to a user are left out, so callers can tell which ones are missing.
+func (s *UserService) GetByIds(ctx context.Context, ids []string) ([]*model.UserDomainModel, error) {
+	var objectIds []primitive.ObjectID
+	for _, id := range ids {
+		objectId, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+
+		objectIds = append(objectIds, objectId)
 	}
 
+	if len(objectIds) == 0 {
+		return nil, nil
+	}
+
+	userEntities, err := s.userRepository.GetByIds(ctx, objectIds)
+	if err != nil {
+		return nil, err
+	}
+
+	var domainModels []*model.UserDomainModel
 	for i := 0; i < len(userEntities); i++ {
-		var domainModel model.UserDomainModel
+		domainModels = append(domainModels, copyEntityToDomainModel(userEntities[i]))
+	}
 
-		domainModel.Id = userEntities[i].Id.Hex()
-		domainModel.Name = userEntities[i].Name
-		domainModel.Email = userEntities[i].Email
+	return domainModels, nil
+}
 
-		userViews = append(userViews, &domainModel)
+// Export calls fn with every user matching the filter ordered by id, without loading them all into memory.
+// Unlike GetAll it does not treat an empty result as an error.
+func (s *UserService) Export(ctx context.Context, filter model.UserFilterDomainModel, fn func(*model.UserDomainModel) error) error {
+	filter, err := s.resolveProfileFilter(ctx, filter)
+	if err != nil {
+		return err
 	}
 
-	return
+	return s.userRepository.Stream(ctx, filter, func(userEntity *model.UserEntity) error {
+		return fn(copyEntityToDomainModel(userEntity))
+	})
 }
 
-func (s *UserService) DeleteById(ctx *gin.Context, id string) error {
+func (s *UserService) DeleteById(ctx context.Context, id string) error {
 	objectId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Println(err)
+		s.logger.DebugContext(ctx, "the id is not valid", "id", id, "error", err)
 		return errs.BadRequestError
 	}
 
@@ -119,18 +263,280 @@ func (s *UserService) DeleteById(ctx *gin.Context, id string) error {
 		return err
 	}
 
+	s.deleteBlobs(ctx, objectId)
+	s.deleteMemberships(ctx, []primitive.ObjectID{objectId})
+	s.deleteRevisions(ctx, []primitive.ObjectID{objectId})
+
 	return nil
 }
 
-func (s *UserService) UpdateById(ctx *gin.Context, id string, updateDomainModel model.UpdateUserDomainModel) (*model.UserDomainModel, error) {
+// DeleteByIds deletes the users with the given ids and reports the outcome of each id in order. Malformed ids
+// fail with BadRequestError and unknown ones with NotFoundError without affecting the rest. The returned error
+// is only set when nothing could be deleted at all.
+func (s *UserService) DeleteByIds(ctx context.Context, ids []string) ([]*model.BatchResultDomainModel, error) {
+	results, objectIds := newBatchResults(ids)
+
+	_, err := s.failMissing(ctx, results, objectIds)
+	if err != nil {
+		return nil, err
+	}
+
+	var idsToDelete []primitive.ObjectID
+	for i, result := range results {
+		if result.Err == nil {
+			idsToDelete = append(idsToDelete, objectIds[i])
+		}
+	}
+
+	if len(idsToDelete) > 0 {
+		deletedCount, err := s.userRepository.DeleteByIds(ctx, idsToDelete)
+		if err != nil {
+			return nil, err
+		}
+
+		// Users deleted concurrently between the lookup and the delete make the count fall short. They are
+		// gone either way, so this is only logged.
+		if deletedCount != int64(len(idsToDelete)) {
+			s.logger.WarnContext(ctx, "fewer users were deleted than found", "deleted", deletedCount, "found", len(idsToDelete))
+		}
+
+		for _, objectId := range idsToDelete {
+			s.deleteBlobs(ctx, objectId)
+		}
+		s.deleteMemberships(ctx, idsToDelete)
+		s.deleteRevisions(ctx, idsToDelete)
+	}
+
+	return results, nil
+}
+
+// deleteBlobs deletes the avatar and any other blob of a deleted user. The user is gone by then, so a failure
+// only leaves unreachable blobs behind and is logged rather than reported.
+func (s *UserService) deleteBlobs(ctx context.Context, id primitive.ObjectID) {
+	err := s.blobStore.DeleteAll(ctx, userBlobPrefix(id))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "deleting the blobs of the user failed", "id", id.Hex(), "error", err)
+	}
+}
+
+// deleteMemberships takes deleted users out of every group they belonged to. Like deleteBlobs, a failure only
+// leaves memberships behind and is logged rather than reported.
+func (s *UserService) deleteMemberships(ctx context.Context, ids []primitive.ObjectID) {
+	err := s.groupRepository.DeleteMemberships(ctx, model.MemberTypeUser, ids)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "deleting the group memberships of the users failed", "count", len(ids), "error", err)
+	}
+}
+
+// deleteRevisions deletes the history of deleted users, which holds everything they ever were. Like deleteBlobs, a
+// failure is logged rather than reported.
+func (s *UserService) deleteRevisions(ctx context.Context, ids []primitive.ObjectID) {
+	err := s.revisionRepository.DeleteByUserIds(ctx, ids)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "deleting the revisions of the users failed", "count", len(ids), "error", err)
+	}
+}
+
+// UpdateByIds applies every update in a single bulk write and reports the outcome of each item in order, with
+// the updated user on success. Items fail on their own with the errors UpdateById would return. The returned
+// error is only set when nothing could be updated at all.
+func (s *UserService) UpdateByIds(ctx context.Context, updates []model.BatchUpdateUserDomainModel) ([]*model.BatchResultDomainModel, error) {
+	ids := make([]string, len(updates))
+	for i, update := range updates {
+		ids[i] = update.Id
+	}
+
+	results, objectIds := newBatchResults(ids)
+
+	existing, err := s.failMissing(ctx, results, objectIds)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := s.profileSchemaService.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var userUpdates []model.UserUpdateEntity
+	var positions []int
+	emails := map[string]bool{}
+
+	for i, update := range updates {
+		if results[i].Err != nil {
+			continue
+		}
+
+		if update.Update.Profile != nil {
+			err := schema.Validate(profile.Merge(existing[objectIds[i]].Profile, update.Update.Profile))
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+		}
+
+		if update.Update.Email != nil {
+			email := model.NormalizeEmail(*update.Update.Email)
+			isEmailInUse, err := s.userRepository.CheckIfEmailAlreadyInUse(ctx, email)
+			if err != nil {
+				return nil, err
+			} else if isEmailInUse || emails[email] {
+				results[i].Err = errs.EmailAlreadyInUseError
+				continue
+			}
+
+			emails[email] = true
+			update.Update.Email = &email
+		}
+
+		if update.Update.Password != nil {
+			hashedPasswordInBytes, err := hashPassword(ctx, *update.Update.Password)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "hashing the password failed", "id", update.Id, "error", err)
+				results[i].Err = errs.ServerError
+				continue
+			}
+
+			var password = string(hashedPasswordInBytes)
+			update.Update.Password = &password
+		}
+
+		userUpdates = append(userUpdates, model.UserUpdateEntity{Id: objectIds[i], Update: update.Update})
+		positions = append(positions, i)
+	}
+
+	if len(userUpdates) == 0 {
+		return results, nil
+	}
+
+	itemErrors, err := s.userRepository.UpdateByIds(ctx, userUpdates)
+	if err != nil {
+		return nil, err
+	}
+
+	var updatedIds []primitive.ObjectID
+	for i, itemError := range itemErrors {
+		results[positions[i]].Err = itemError
+		if itemError == nil {
+			updatedIds = append(updatedIds, userUpdates[i].Id)
+		}
+	}
+
+	if len(updatedIds) == 0 {
+		return results, nil
+	}
+
+	userEntities, err := s.userRepository.GetByIds(ctx, updatedIds)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordRevisions(ctx, userEntities...)
+
+	updated := map[primitive.ObjectID]*model.UserEntity{}
+	for _, userEntity := range userEntities {
+		updated[userEntity.Id] = userEntity
+	}
+
+	for _, position := range positions {
+		if results[position].Err != nil {
+			continue
+		}
+
+		userEntity, ok := updated[objectIds[position]]
+		if !ok {
+			results[position].Err = errs.NotFoundError
+			continue
+		}
+
+		results[position].User = copyEntityToDomainModel(userEntity)
+	}
+
+	return results, nil
+}
+
+// newBatchResults starts a result for every id, failing the malformed and repeated ones with BadRequestError.
+// The parsed ids are returned by position.
+func newBatchResults(ids []string) ([]*model.BatchResultDomainModel, []primitive.ObjectID) {
+	results := make([]*model.BatchResultDomainModel, len(ids))
+	objectIds := make([]primitive.ObjectID, len(ids))
+	seen := map[primitive.ObjectID]bool{}
+
+	for i, id := range ids {
+		results[i] = &model.BatchResultDomainModel{Id: id}
+
+		objectId, err := primitive.ObjectIDFromHex(id)
+		if err != nil || seen[objectId] {
+			results[i].Err = errs.BadRequestError
+			continue
+		}
+
+		seen[objectId] = true
+		objectIds[i] = objectId
+	}
+
+	return results, objectIds
+}
+
+// failMissing looks up the ids of the results that have not failed yet with one $in query and fails the ones
+// that do not belong to a user with NotFoundError. The users found are returned by id.
+func (s *UserService) failMissing(ctx context.Context, results []*model.BatchResultDomainModel, objectIds []primitive.ObjectID) (map[primitive.ObjectID]*model.UserEntity, error) {
+	existing := map[primitive.ObjectID]*model.UserEntity{}
+
+	var idsToFind []primitive.ObjectID
+	for i, result := range results {
+		if result.Err == nil {
+			idsToFind = append(idsToFind, objectIds[i])
+		}
+	}
+
+	if len(idsToFind) == 0 {
+		return existing, nil
+	}
+
+	userEntities, err := s.userRepository.GetByIds(ctx, idsToFind)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userEntity := range userEntities {
+		existing[userEntity.Id] = userEntity
+	}
+
+	for i, result := range results {
+		if result.Err == nil && existing[objectIds[i]] == nil {
+			result.Err = errs.NotFoundError
+		}
+	}
+
+	return existing, nil
+}
+
+func (s *UserService) UpdateById(ctx context.Context, id string, updateDomainModel model.UpdateUserDomainModel) (*model.UserDomainModel, error) {
 	objectId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Println(err)
+		s.logger.DebugContext(ctx, "the id is not valid", "id", id, "error", err)
 		return nil, errs.BadRequestError
 	}
 
+	// The patch is checked merged into the stored profile, since the schema applies to the profile as a whole.
+	if updateDomainModel.Profile != nil {
+		existingEntity, err := s.userRepository.GetById(ctx, objectId)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.validateProfile(ctx, profile.Merge(existingEntity.Profile, updateDomainModel.Profile))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if updateDomainModel.Email != nil {
-		isEmailInUse, err := s.userRepository.CheckIfEmailAlreadyInUse(ctx, *(updateDomainModel.Email))
+		email := model.NormalizeEmail(*updateDomainModel.Email)
+		updateDomainModel.Email = &email
+
+		isEmailInUse, err := s.userRepository.CheckIfEmailAlreadyInUse(ctx, email)
 		if isEmailInUse {
 			return nil, errs.EmailAlreadyInUseError
 		} else if err != nil {
@@ -139,9 +545,9 @@ func (s *UserService) UpdateById(ctx *gin.Context, id string, updateDomainModel
 	}
 
 	if updateDomainModel.Password != nil {
-		hashedPasswordInBytes, err := bcrypt.GenerateFromPassword([]byte(*updateDomainModel.Password), bcrypt.MinCost)
+		hashedPasswordInBytes, err := hashPassword(ctx, *updateDomainModel.Password)
 		if err != nil {
-			log.Println(err)
+			s.logger.ErrorContext(ctx, "hashing the password failed", "id", id, "error", err)
 			return nil, errs.ServerError
 		}
 
@@ -154,11 +560,151 @@ func (s *UserService) UpdateById(ctx *gin.Context, id string, updateDomainModel
 		return nil, err
 	}
 
-	domainModel := &model.UserDomainModel{
-		Id:    userEntity.Id.Hex(),
-		Name:  userEntity.Name,
-		Email: userEntity.Email,
+	s.recordRevisions(ctx, userEntity)
+
+	return copyEntityToDomainModel(userEntity), nil
+}
+
+// ReplaceById overwrites every mutable field of the user with the given id, creating the user when the id is
+// not taken yet. The returned bool reports whether the user was created.
+func (s *UserService) ReplaceById(ctx context.Context, id string, replaceDomainModel model.ReplaceUserDomainModel, precondition *model.Precondition) (*model.UserDomainModel, bool, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		s.logger.DebugContext(ctx, "the id is not valid", "id", id, "error", err)
+		return nil, false, errs.BadRequestError
+	}
+
+	existingEntity, err := s.userRepository.GetById(ctx, objectId)
+	if errors.Is(err, errs.NotFoundError) {
+		existingEntity = nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	if precondition != nil && (existingEntity == nil || !isPreconditionSatisfied(precondition, existingEntity.Version)) {
+		return nil, false, errs.PreconditionFailedError
+	}
+
+	replaceDomainModel.Email = model.NormalizeEmail(replaceDomainModel.Email)
+	replaceDomainModel.Profile = profile.Merge(nil, replaceDomainModel.Profile)
+
+	err = s.validateProfile(ctx, replaceDomainModel.Profile)
+	if err != nil {
+		return nil, false, err
 	}
 
-	return domainModel, nil
+	if existingEntity == nil || existingEntity.Email != replaceDomainModel.Email {
+		isEmailInUse, err := s.userRepository.CheckIfEmailAlreadyInUse(ctx, replaceDomainModel.Email)
+		if isEmailInUse {
+			return nil, false, errs.EmailAlreadyInUseError
+		} else if err != nil {
+			return nil, false, err
+		}
+	}
+
+	hashedPasswordInBytes, err := hashPassword(ctx, replaceDomainModel.Password)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "hashing the password failed", "id", id, "error", err)
+		return nil, false, errs.ServerError
+	}
+
+	entity := model.UserEntity{
+		Id:       objectId,
+		Name:     replaceDomainModel.Name,
+		Email:    replaceDomainModel.Email,
+		Password: string(hashedPasswordInBytes),
+		Version:  1,
+		Profile:  replaceDomainModel.Profile,
+	}
+
+	var storedEntity *model.UserEntity
+	created := existingEntity == nil
+	if created {
+		storedEntity, err = s.userRepository.Create(ctx, entity)
+	} else {
+		entity.Version = existingEntity.Version + 1
+		storedEntity, err = s.userRepository.ReplaceById(ctx, entity, existingEntity.Version)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.recordRevisions(ctx, storedEntity)
+
+	return copyEntityToDomainModel(storedEntity), created, nil
+}
+
+func copyEntityToDomainModel(userEntity *model.UserEntity) *model.UserDomainModel {
+	return &model.UserDomainModel{
+		Id:        userEntity.Id.Hex(),
+		Name:      userEntity.Name,
+		Email:     userEntity.Email,
+		Version:   userEntity.Version,
+		CreatedAt: userEntity.CreatedAt,
+		UpdatedAt: userEntity.UpdatedAt,
+		CreatedBy: userEntity.CreatedBy,
+		UpdatedBy: userEntity.UpdatedBy,
+		Profile:   userEntity.Profile,
+	}
+}
+
+// validateProfile checks a whole profile against the profile schema in force.
+func (s *UserService) validateProfile(ctx context.Context, userProfile map[string]interface{}) error {
+	schema, err := s.profileSchemaService.Current(ctx)
+	if err != nil {
+		return err
+	}
+
+	return schema.Validate(userProfile)
+}
+
+// resolveProfileFilter normalizes the email filter and converts the raw profile filters to the types the schema in
+// force gives the attributes.
+func (s *UserService) resolveProfileFilter(ctx context.Context, filter model.UserFilterDomainModel) (model.UserFilterDomainModel, error) {
+	filter.Email = model.NormalizeEmail(filter.Email)
+
+	if len(filter.Profile) == 0 {
+		return filter, nil
+	}
+
+	schema, err := s.profileSchemaService.Current(ctx)
+	if err != nil {
+		return filter, err
+	}
+
+	raw := map[string]string{}
+	for attribute, value := range filter.Profile {
+		raw[attribute] = fmt.Sprint(value)
+	}
+
+	filter.Profile, err = schema.ParseFilter(raw)
+	return filter, err
+}
+
+// isPreconditionSatisfied reports whether an existing resource at the given version meets the precondition.
+func isPreconditionSatisfied(precondition *model.Precondition, version int64) bool {
+	if precondition.AnyVersion {
+		return true
+	}
+
+	for _, expected := range precondition.Versions {
+		if expected == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hashPassword hashes a password with PasswordCost in a span of its own and records how long that took.
+func hashPassword(ctx context.Context, password string) ([]byte, error) {
+	_, span := tracing.Tracer.Start(ctx, "bcrypt.GenerateFromPassword", trace.WithAttributes(attribute.Int("bcrypt.cost", PasswordCost)))
+	start := time.Now()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), PasswordCost)
+
+	metrics.PasswordHashDuration.Observe(time.Since(start).Seconds())
+	tracing.End(span, err)
+
+	return hashedPassword, err
 }
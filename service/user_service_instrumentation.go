@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"user-service/metrics"
+	"user-service/model"
+	"user-service/tracing"
+)
+
+// instrumentedUserService traces every operation of a user service and counts the errors it returns, by error
+// package sentinel.
+type instrumentedUserService struct {
+	next UserServiceInterface
+}
+
+func NewInstrumentedUserService(next UserServiceInterface) UserServiceInterface {
+	return &instrumentedUserService{
+		next: next,
+	}
+}
+
+func (s *instrumentedUserService) Create(ctx context.Context, createDomainModel model.CreateUserDomainModel) (*model.UserDomainModel, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.Create")
+	user, err := s.next.Create(ctx, createDomainModel)
+	record(span, "Create", err)
+	return user, err
+}
+
+func (s *instrumentedUserService) ValidateCreate(ctx context.Context, createDomainModel model.CreateUserDomainModel) error {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.ValidateCreate")
+	err := s.next.ValidateCreate(ctx, createDomainModel)
+	record(span, "ValidateCreate", err)
+	return err
+}
+
+func (s *instrumentedUserService) GetById(ctx context.Context, id string) (*model.UserDomainModel, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.GetById")
+	user, err := s.next.GetById(ctx, id)
+	record(span, "GetById", err)
+	return user, err
+}
+
+func (s *instrumentedUserService) GetAll(ctx context.Context, page model.PageDomainModel) ([]*model.UserDomainModel, string, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.GetAll")
+	users, nextPageToken, err := s.next.GetAll(ctx, page)
+	record(span, "GetAll", err)
+	return users, nextPageToken, err
+}
+
+func (s *instrumentedUserService) GetByIds(ctx context.Context, ids []string) ([]*model.UserDomainModel, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.GetByIds")
+	users, err := s.next.GetByIds(ctx, ids)
+	record(span, "GetByIds", err)
+	return users, err
+}
+
+func (s *instrumentedUserService) Export(ctx context.Context, filter model.UserFilterDomainModel, write func(*model.UserDomainModel) error) error {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.Export")
+	err := s.next.Export(ctx, filter, write)
+	record(span, "Export", err)
+	return err
+}
+
+func (s *instrumentedUserService) DeleteById(ctx context.Context, id string) error {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.DeleteById")
+	err := s.next.DeleteById(ctx, id)
+	record(span, "DeleteById", err)
+	return err
+}
+
+func (s *instrumentedUserService) DeleteByIds(ctx context.Context, ids []string) ([]*model.BatchResultDomainModel, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.DeleteByIds")
+	results, err := s.next.DeleteByIds(ctx, ids)
+	record(span, "DeleteByIds", err)
+	countResultErrors("DeleteByIds", results)
+	return results, err
+}
+
+func (s *instrumentedUserService) UpdateById(ctx context.Context, id string, updateDomainModel model.UpdateUserDomainModel) (*model.UserDomainModel, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.UpdateById")
+	user, err := s.next.UpdateById(ctx, id, updateDomainModel)
+	record(span, "UpdateById", err)
+	return user, err
+}
+
+func (s *instrumentedUserService) UpdateByIds(ctx context.Context, updates []model.BatchUpdateUserDomainModel) ([]*model.BatchResultDomainModel, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.UpdateByIds")
+	results, err := s.next.UpdateByIds(ctx, updates)
+	record(span, "UpdateByIds", err)
+	countResultErrors("UpdateByIds", results)
+	return results, err
+}
+
+func (s *instrumentedUserService) ReplaceById(ctx context.Context, id string, replaceDomainModel model.ReplaceUserDomainModel, precondition *model.Precondition) (*model.UserDomainModel, bool, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.ReplaceById")
+	user, created, err := s.next.ReplaceById(ctx, id, replaceDomainModel, precondition)
+	record(span, "ReplaceById", err)
+	return user, created, err
+}
+
+func (s *instrumentedUserService) GetRevisions(ctx context.Context, id string) ([]*model.UserRevisionDomainModel, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.GetRevisions")
+	revisions, err := s.next.GetRevisions(ctx, id)
+	record(span, "GetRevisions", err)
+	return revisions, err
+}
+
+func (s *instrumentedUserService) GetRevision(ctx context.Context, id string, revision int64) (*model.UserRevisionDomainModel, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.GetRevision")
+	userRevision, err := s.next.GetRevision(ctx, id, revision)
+	record(span, "GetRevision", err)
+	return userRevision, err
+}
+
+func (s *instrumentedUserService) DiffRevisions(ctx context.Context, id string, from int64, to int64) (*model.RevisionDiffDomainModel, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.DiffRevisions")
+	diff, err := s.next.DiffRevisions(ctx, id, from, to)
+	record(span, "DiffRevisions", err)
+	return diff, err
+}
+
+func (s *instrumentedUserService) RevertToRevision(ctx context.Context, id string, revision int64) (*model.UserDomainModel, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "UserService.RevertToRevision")
+	user, err := s.next.RevertToRevision(ctx, id, revision)
+	record(span, "RevertToRevision", err)
+	return user, err
+}
+
+// record counts the error an operation returned and ends its span. Only server errors mark the span as failed,
+// since the other sentinels answer requests that were wrong.
+func record(span trace.Span, operation string, err error) {
+	if err != nil {
+		label := metrics.ErrorLabel(err)
+		metrics.UserServiceErrors.WithLabelValues(operation, label).Inc()
+		span.SetAttributes(attribute.String("error.type", label))
+
+		if label == "server_error" || label == "other" {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+
+	span.End()
+}
+
+func countError(operation string, err error) {
+	if err != nil {
+		metrics.UserServiceErrors.WithLabelValues(operation, metrics.ErrorLabel(err)).Inc()
+	}
+}
+
+// countResultErrors counts the errors of the single items of a batch, which the batch itself does not fail with.
+func countResultErrors(operation string, results []*model.BatchResultDomainModel) {
+	for _, result := range results {
+		countError(operation, result.Err)
+	}
+}
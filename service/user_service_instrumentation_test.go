@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"testing"
+	errs "user-service/error"
+	"user-service/metrics"
+	"user-service/model"
+	serviceMock "user-service/service/mock"
+)
+
+func Test_InstrumentedUserService_Should_Count_Errors_By_Sentinel(t *testing.T) {
+	notFound := metrics.UserServiceErrors.WithLabelValues("GetById", "not_found")
+	before := testutil.ToFloat64(notFound)
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("GetById", mock.Anything, "1").Return(nil, fmt.Errorf("looking up 1: %w", errs.NotFoundError)).Once()
+	userServiceMock.On("GetById", mock.Anything, "2").Return(&model.UserDomainModel{}, nil).Once()
+
+	classUnderTest := NewInstrumentedUserService(userServiceMock)
+
+	_, err := classUnderTest.GetById(context.Background(), "1")
+	assert.ErrorIs(t, err, errs.NotFoundError)
+	_, err = classUnderTest.GetById(context.Background(), "2")
+	assert.Nil(t, err)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(notFound))
+}
+
+func Test_InstrumentedUserService_Should_Count_The_Errors_Of_Batch_Items(t *testing.T) {
+	serverError := metrics.UserServiceErrors.WithLabelValues("DeleteByIds", "server_error")
+	before := testutil.ToFloat64(serverError)
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("DeleteByIds", mock.Anything, []string{"1", "2"}).Return([]*model.BatchResultDomainModel{
+		{Id: "1"},
+		{Id: "2", Err: errs.ServerError},
+	}, nil).Once()
+
+	classUnderTest := NewInstrumentedUserService(userServiceMock)
+
+	_, err := classUnderTest.DeleteByIds(context.Background(), []string{"1", "2"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, before+1, testutil.ToFloat64(serverError))
+}
+
+func Test_InstrumentedUserService_Should_Only_Fail_Spans_On_Server_Errors(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	userServiceMock := new(serviceMock.UserServiceInterface)
+	userServiceMock.On("DeleteById", mock.Anything, "1").Return(errs.NotFoundError).Once()
+	userServiceMock.On("DeleteById", mock.Anything, "2").Return(errs.ServerError).Once()
+
+	classUnderTest := NewInstrumentedUserService(userServiceMock)
+	classUnderTest.DeleteById(context.Background(), "1")
+	classUnderTest.DeleteById(context.Background(), "2")
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "UserService.DeleteById", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
@@ -1,16 +1,54 @@
 package service
 
 import (
-	"github.com/gin-gonic/gin"
+	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
 	"testing"
+	"time"
 	errs "user-service/error"
+	"user-service/logging"
 	"user-service/model"
+	"user-service/profile"
 	repositoryMock "user-service/repository/mock"
+	serviceMock "user-service/service/mock"
 )
 
+// newRevisionRepositoryMock accepts any revision, for tests that are not about revisions.
+func newRevisionRepositoryMock() *repositoryMock.UserRevisionRepositoryInterface {
+	revisionRepositoryMock := new(repositoryMock.UserRevisionRepositoryInterface)
+	revisionRepositoryMock.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	revisionRepositoryMock.On("DeleteByUserIds", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	return revisionRepositoryMock
+}
+
+// newProfileSchemaServiceMock stands for a service without a published profile schema, which accepts any profile.
+func newProfileSchemaServiceMock() *serviceMock.ProfileSchemaServiceInterface {
+	profileSchemaServiceMock := new(serviceMock.ProfileSchemaServiceInterface)
+	profileSchemaServiceMock.On("Current", mock.Anything).Return(nil, nil).Maybe()
+
+	return profileSchemaServiceMock
+}
+
+// newBlobStoreMock accepts deleting the blobs of any user, for tests that are not about blobs.
+func newBlobStoreMock() *repositoryMock.BlobStore {
+	blobStoreMock := new(repositoryMock.BlobStore)
+	blobStoreMock.On("DeleteAll", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	return blobStoreMock
+}
+
+func newGroupRepositoryMock() *repositoryMock.GroupRepositoryInterface {
+	groupRepositoryMock := new(repositoryMock.GroupRepositoryInterface)
+	groupRepositoryMock.On("DeleteMemberships", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	return groupRepositoryMock
+}
+
 func Test_Create_Should_Return_EmailAlreadyInUseError_When_Email_Belongs_To_A_User(t *testing.T) {
 	request := model.CreateUserDomainModel{
 		Email: "existing@email.com",
@@ -19,9 +57,9 @@ func Test_Create_Should_Return_EmailAlreadyInUseError_When_Email_Belongs_To_A_Us
 	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
 	userRepositoryMock.On("CheckIfEmailAlreadyInUse", mock.Anything, request.Email).Return(true, nil).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	createdUser, err := classUnderTest.Create(&gin.Context{}, request)
+	createdUser, err := classUnderTest.Create(context.Background(), request)
 
 	assert.Nil(t, createdUser)
 	assert.NotNil(t, err)
@@ -37,9 +75,9 @@ func Test_Create_Should_Return_ServerError_When_Email_Check_Fails(t *testing.T)
 	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
 	userRepositoryMock.On("CheckIfEmailAlreadyInUse", mock.Anything, request.Email).Return(false, errs.ServerError).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	createdUser, err := classUnderTest.Create(&gin.Context{}, request)
+	createdUser, err := classUnderTest.Create(context.Background(), request)
 
 	assert.Nil(t, createdUser)
 	assert.NotNil(t, err)
@@ -58,11 +96,11 @@ func Test_Create_Should_Return_ServerError_When_Database_Create_Operation_Fails(
 	userRepositoryMock.On("CheckIfEmailAlreadyInUse", mock.Anything, request.Email).Return(false, nil).Once()
 	userRepositoryMock.On("Create", mock.Anything, mock.MatchedBy(func(i interface{}) bool {
 		return i.(model.UserEntity).Name == request.Name && i.(model.UserEntity).Email == request.Email
-	})).Return(errs.ServerError).Once()
+	})).Return(nil, errs.ServerError).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	createdUser, err := classUnderTest.Create(&gin.Context{}, request)
+	createdUser, err := classUnderTest.Create(context.Background(), request)
 
 	assert.Nil(t, createdUser)
 	assert.NotNil(t, err)
@@ -81,11 +119,11 @@ func Test_Create_Should_Return_User_When_Nothing_Fails(t *testing.T) {
 	userRepositoryMock.On("CheckIfEmailAlreadyInUse", mock.Anything, request.Email).Return(false, nil).Once()
 	userRepositoryMock.On("Create", mock.Anything, mock.MatchedBy(func(i interface{}) bool {
 		return i.(model.UserEntity).Name == request.Name && i.(model.UserEntity).Email == request.Email
-	})).Return(nil).Once()
+	})).Return(&model.UserEntity{Id: primitive.NewObjectID(), Name: request.Name, Email: request.Email, Version: 1}, nil).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	createdUser, err := classUnderTest.Create(&gin.Context{}, request)
+	createdUser, err := classUnderTest.Create(context.Background(), request)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, createdUser)
@@ -100,9 +138,9 @@ func Test_GetById_Should_Return_BadRequestError_When_Id_Is_Invalid(t *testing.T)
 	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
 	userRepositoryMock.On("GetById", mock.Anything, mock.Anything).Maybe().Times(0)
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	user, err := classUnderTest.GetById(&gin.Context{}, id)
+	user, err := classUnderTest.GetById(context.Background(), id)
 
 	assert.Nil(t, user)
 	assert.NotNil(t, err)
@@ -116,9 +154,9 @@ func Test_GetById_Should_Return_ServerError_When_Id_Is_Valid_But_Find_Operation_
 	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
 	userRepositoryMock.On("GetById", mock.Anything, id).Return(nil, errs.ServerError).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	user, err := classUnderTest.GetById(&gin.Context{}, id.Hex())
+	user, err := classUnderTest.GetById(context.Background(), id.Hex())
 
 	assert.Nil(t, user)
 	assert.NotNil(t, err)
@@ -132,9 +170,9 @@ func Test_GetById_Should_Return_User_When_Id_Is_Valid_And_Belongs_To_User(t *tes
 	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
 	userRepositoryMock.On("GetById", mock.Anything, id).Return(&model.UserEntity{Id: id}, nil).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	user, err := classUnderTest.GetById(&gin.Context{}, id.Hex())
+	user, err := classUnderTest.GetById(context.Background(), id.Hex())
 
 	assert.Nil(t, err)
 	assert.NotNil(t, user)
@@ -148,9 +186,9 @@ func Test_DeleteById_Should_Return_BadRequestError_When_Id_Is_Invalid(t *testing
 	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
 	userRepositoryMock.On("DeleteById", mock.Anything, mock.Anything).Maybe().Times(0)
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	err := classUnderTest.DeleteById(&gin.Context{}, id)
+	err := classUnderTest.DeleteById(context.Background(), id)
 
 	assert.NotNil(t, err)
 	assert.Equal(t, errs.BadRequestError, err)
@@ -163,9 +201,9 @@ func Test_DeleteById_Should_Return_ServerError_When_Id_Is_Valid_But_Database_Fai
 	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
 	userRepositoryMock.On("DeleteById", mock.Anything, id).Return(errs.ServerError).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	err := classUnderTest.DeleteById(&gin.Context{}, id.Hex())
+	err := classUnderTest.DeleteById(context.Background(), id.Hex())
 
 	assert.NotNil(t, err)
 	assert.Equal(t, errs.ServerError, err)
@@ -178,21 +216,91 @@ func Test_DeleteById_Should_Not_Return_Error_When_Id_Is_Valid_And_Belongs_To_Use
 	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
 	userRepositoryMock.On("DeleteById", mock.Anything, id).Return(nil).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	err := classUnderTest.DeleteById(&gin.Context{}, id.Hex())
+	err := classUnderTest.DeleteById(context.Background(), id.Hex())
 
 	assert.Nil(t, err)
 	userRepositoryMock.AssertExpectations(t)
 }
 
+func Test_DeleteById_Should_Delete_The_Blobs_Of_The_Deleted_User(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("DeleteById", mock.Anything, id).Return(nil).Once()
+
+	blobStoreMock := new(repositoryMock.BlobStore)
+	blobStoreMock.On("DeleteAll", mock.Anything, "users/"+id.Hex()+"/").Return(errs.ServerError).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), blobStoreMock, newGroupRepositoryMock(), logging.Discard())
+
+	// The id names the same user in upper case, whose blobs are still kept under the id in lower case.
+	err := classUnderTest.DeleteById(context.Background(), strings.ToUpper(id.Hex()))
+
+	assert.Nil(t, err)
+	blobStoreMock.AssertExpectations(t)
+}
+
+func Test_DeleteById_Should_Take_The_Deleted_User_Out_Of_Its_Groups(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("DeleteById", mock.Anything, id).Return(nil).Once()
+
+	groupRepositoryMock := new(repositoryMock.GroupRepositoryInterface)
+	groupRepositoryMock.On("DeleteMemberships", mock.Anything, model.MemberTypeUser, []primitive.ObjectID{id}).Return(errs.ServerError).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), groupRepositoryMock, logging.Discard())
+
+	err := classUnderTest.DeleteById(context.Background(), id.Hex())
+
+	assert.Nil(t, err)
+	groupRepositoryMock.AssertExpectations(t)
+}
+
+func Test_DeleteById_Should_Delete_The_Revisions_Of_The_Deleted_User(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("DeleteById", mock.Anything, id).Return(nil).Once()
+
+	revisionRepositoryMock := new(repositoryMock.UserRevisionRepositoryInterface)
+	revisionRepositoryMock.On("DeleteByUserIds", mock.Anything, []primitive.ObjectID{id}).Return(nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, revisionRepositoryMock, newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	err := classUnderTest.DeleteById(context.Background(), id.Hex())
+
+	assert.Nil(t, err)
+	revisionRepositoryMock.AssertExpectations(t)
+}
+
+func Test_DeleteById_Should_Keep_The_Blobs_When_The_User_Was_Not_Deleted(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("DeleteById", mock.Anything, id).Return(errs.NotFoundError).Once()
+
+	blobStoreMock := new(repositoryMock.BlobStore)
+	groupRepositoryMock := new(repositoryMock.GroupRepositoryInterface)
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), blobStoreMock, groupRepositoryMock, logging.Discard())
+
+	err := classUnderTest.DeleteById(context.Background(), id.Hex())
+
+	assert.ErrorIs(t, err, errs.NotFoundError)
+	blobStoreMock.AssertNotCalled(t, "DeleteAll", mock.Anything, mock.Anything)
+	groupRepositoryMock.AssertNotCalled(t, "DeleteMemberships", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func Test_GetAll_Should_Return_NotFoundError_When_No_Users_Exist(t *testing.T) {
 	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
-	userRepositoryMock.On("GetAll", mock.Anything).Return([]*model.UserEntity{}, errs.NotFoundError).Once()
+	userRepositoryMock.On("GetAll", mock.Anything, model.UserFilterDomainModel{}, model.UserSort{}, (*model.UserCursor)(nil), int64(0)).Return([]*model.UserEntity{}, errs.NotFoundError).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	users, err := classUnderTest.GetAll(&gin.Context{})
+	users, _, err := classUnderTest.GetAll(context.Background(), model.PageDomainModel{})
 
 	assert.Nil(t, users)
 	assert.NotNil(t, err)
@@ -202,11 +310,11 @@ func Test_GetAll_Should_Return_NotFoundError_When_No_Users_Exist(t *testing.T) {
 
 func Test_GetAll_Should_Return_ServerError_When_Database_Fails(t *testing.T) {
 	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
-	userRepositoryMock.On("GetAll", mock.Anything).Return([]*model.UserEntity{}, errs.ServerError).Once()
+	userRepositoryMock.On("GetAll", mock.Anything, model.UserFilterDomainModel{}, model.UserSort{}, (*model.UserCursor)(nil), int64(0)).Return([]*model.UserEntity{}, errs.ServerError).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	users, err := classUnderTest.GetAll(&gin.Context{})
+	users, _, err := classUnderTest.GetAll(context.Background(), model.PageDomainModel{})
 
 	assert.Nil(t, users)
 	assert.NotNil(t, err)
@@ -228,11 +336,11 @@ func Test_GetAll_Should_Return_Users_When_Nothing_Fails(t *testing.T) {
 	var userEntities = []*model.UserEntity{&firstUser, &secondUser}
 
 	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
-	userRepositoryMock.On("GetAll", mock.Anything).Return(userEntities, nil).Once()
+	userRepositoryMock.On("GetAll", mock.Anything, model.UserFilterDomainModel{}, model.UserSort{}, (*model.UserCursor)(nil), int64(0)).Return(userEntities, nil).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	users, err := classUnderTest.GetAll(&gin.Context{})
+	users, _, err := classUnderTest.GetAll(context.Background(), model.PageDomainModel{})
 
 	assert.Nil(t, err)
 	assert.NotNil(t, users)
@@ -243,15 +351,141 @@ func Test_GetAll_Should_Return_Users_When_Nothing_Fails(t *testing.T) {
 	userRepositoryMock.AssertExpectations(t)
 }
 
+func Test_GetAll_Should_Return_Next_Cursor_When_More_Users_Exist_Than_The_Limit(t *testing.T) {
+	var after = primitive.NewObjectID()
+	var userEntities = []*model.UserEntity{
+		{Id: primitive.NewObjectID()},
+		{Id: primitive.NewObjectID()},
+		{Id: primitive.NewObjectID()},
+	}
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetAll", mock.Anything, model.UserFilterDomainModel{}, model.UserSort{}, &model.UserCursor{Id: after}, int64(3)).Return(userEntities, nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	users, next, err := classUnderTest.GetAll(context.Background(), model.PageDomainModel{After: after.Hex(), Limit: 2})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(users))
+	assert.Equal(t, userEntities[1].Id.Hex(), next)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_GetAll_Should_Return_Empty_Cursor_When_Last_Page_Is_Reached(t *testing.T) {
+	var userEntities = []*model.UserEntity{{Id: primitive.NewObjectID()}}
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetAll", mock.Anything, model.UserFilterDomainModel{}, model.UserSort{}, (*model.UserCursor)(nil), int64(3)).Return(userEntities, nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	users, next, err := classUnderTest.GetAll(context.Background(), model.PageDomainModel{Limit: 2})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(users))
+	assert.Equal(t, "", next)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_GetAll_Should_Return_BadRequestError_When_Cursor_Is_Invalid(t *testing.T) {
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	users, _, err := classUnderTest.GetAll(context.Background(), model.PageDomainModel{After: "not an object id"})
+
+	assert.Nil(t, users)
+	assert.Equal(t, errs.BadRequestError, err)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_GetAll_Should_Round_Trip_Cursor_When_Sorted_By_Time(t *testing.T) {
+	var sort = model.UserSort{Field: model.SortByCreatedAt, Descending: true}
+	var createdAt = time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	var userEntities = []*model.UserEntity{
+		{Id: primitive.NewObjectID()},
+		{Id: primitive.NewObjectID(), CreatedAt: createdAt},
+		{Id: primitive.NewObjectID()},
+	}
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetAll", mock.Anything, model.UserFilterDomainModel{}, sort, (*model.UserCursor)(nil), int64(3)).Return(userEntities, nil).Once()
+	userRepositoryMock.On("GetAll", mock.Anything, model.UserFilterDomainModel{}, sort, &model.UserCursor{Id: userEntities[1].Id, Time: createdAt}, int64(3)).Return(userEntities[2:], nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	_, next, err := classUnderTest.GetAll(context.Background(), model.PageDomainModel{Sort: sort, Limit: 2})
+	assert.Nil(t, err)
+
+	_, _, err = classUnderTest.GetAll(context.Background(), model.PageDomainModel{Sort: sort, After: next, Limit: 2})
+	assert.Nil(t, err)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_GetAll_Should_Return_BadRequestError_When_Cursor_Belongs_To_Another_Sort(t *testing.T) {
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	users, _, err := classUnderTest.GetAll(context.Background(), model.PageDomainModel{
+		Sort:  model.UserSort{Field: model.SortByUpdatedAt},
+		After: primitive.NewObjectID().Hex(),
+	})
+
+	assert.Nil(t, users)
+	assert.Equal(t, errs.BadRequestError, err)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_GetAll_Should_Convert_Profile_Filters_To_The_Attribute_Types(t *testing.T) {
+	var userEntities = []*model.UserEntity{{Id: primitive.NewObjectID()}}
+	var schema, _ = profile.Compile([]byte(`{"type":"object","properties":{"level":{"type":"integer","x-indexed":true}}}`), 1)
+
+	profileSchemaServiceMock := new(serviceMock.ProfileSchemaServiceInterface)
+	profileSchemaServiceMock.On("Current", mock.Anything).Return(schema, nil).Once()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetAll", mock.Anything, model.UserFilterDomainModel{Profile: map[string]interface{}{"level": int64(3)}}, model.UserSort{}, (*model.UserCursor)(nil), int64(0)).Return(userEntities, nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), profileSchemaServiceMock, newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	users, _, err := classUnderTest.GetAll(context.Background(), model.PageDomainModel{Filter: model.UserFilterDomainModel{Profile: map[string]interface{}{"level": "3"}}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(users))
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_UpdateById_Should_Validate_The_Profile_Patch_Merged_Into_The_Stored_Profile(t *testing.T) {
+	var id = primitive.NewObjectID()
+	var schema, _ = profile.Compile([]byte(`{"type":"object","required":["department"]}`), 1)
+
+	profileSchemaServiceMock := new(serviceMock.ProfileSchemaServiceInterface)
+	profileSchemaServiceMock.On("Current", mock.Anything).Return(schema, nil).Once()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, id).Return(&model.UserEntity{Id: id, Profile: map[string]interface{}{"department": "sales", "floor": int32(2)}}, nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), profileSchemaServiceMock, newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	user, err := classUnderTest.UpdateById(context.Background(), id.Hex(), model.UpdateUserDomainModel{Profile: map[string]interface{}{"department": nil}})
+
+	assert.Nil(t, user)
+	assert.True(t, errors.Is(err, errs.ValidationError))
+	userRepositoryMock.AssertNotCalled(t, "UpdateById", mock.Anything, mock.Anything, mock.Anything)
+	userRepositoryMock.AssertExpectations(t)
+}
+
 func Test_UpdateById_Should_Return_BadRequestError_When_Id_Is_Invalid(t *testing.T) {
 	var id = "not an object id"
 
 	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
 	userRepositoryMock.On("UpdateById", mock.Anything, mock.Anything).Maybe().Times(0)
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	updatedUser, err := classUnderTest.UpdateById(&gin.Context{}, id, model.UpdateUserDomainModel{})
+	updatedUser, err := classUnderTest.UpdateById(context.Background(), id, model.UpdateUserDomainModel{})
 
 	assert.Nil(t, updatedUser)
 	assert.NotNil(t, err)
@@ -271,9 +505,9 @@ func Test_UpdateById_Should_Return_EmailAlreadyInUseError_When_Email_Is_Sent_And
 	userRepositoryMock.On("UpdateById", mock.Anything, mock.Anything).Maybe().Times(0)
 	userRepositoryMock.On("CheckIfEmailAlreadyInUse", mock.Anything, *(updateModel.Email)).Return(true, nil).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	updatedUser, err := classUnderTest.UpdateById(&gin.Context{}, id.Hex(), updateModel)
+	updatedUser, err := classUnderTest.UpdateById(context.Background(), id.Hex(), updateModel)
 
 	assert.Nil(t, updatedUser)
 	assert.NotNil(t, err)
@@ -293,9 +527,9 @@ func Test_UpdateById_Should_Return_ServerError_When_Email_Check_Fails(t *testing
 	userRepositoryMock.On("UpdateById", mock.Anything, mock.Anything).Maybe().Times(0)
 	userRepositoryMock.On("CheckIfEmailAlreadyInUse", mock.Anything, *(updateModel.Email)).Return(false, errs.ServerError).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	updatedUser, err := classUnderTest.UpdateById(&gin.Context{}, id.Hex(), updateModel)
+	updatedUser, err := classUnderTest.UpdateById(context.Background(), id.Hex(), updateModel)
 
 	assert.Nil(t, updatedUser)
 	assert.NotNil(t, err)
@@ -316,9 +550,9 @@ func Test_UpdateById_Should_Return_ServerError_When_Update_Operation_Fails(t *te
 	userRepositoryMock.On("CheckIfEmailAlreadyInUse", mock.Anything, *(updateModel.Email)).Return(false, nil).Once()
 	userRepositoryMock.On("UpdateById", mock.Anything, id, updateModel).Return(nil, errs.ServerError).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	updatedUser, err := classUnderTest.UpdateById(&gin.Context{}, id.Hex(), updateModel)
+	updatedUser, err := classUnderTest.UpdateById(context.Background(), id.Hex(), updateModel)
 
 	assert.Nil(t, updatedUser)
 	assert.NotNil(t, err)
@@ -343,12 +577,299 @@ func Test_UpdateById_Should_Return_ServerError_When_Nothing_Fails(t *testing.T)
 	userRepositoryMock.On("CheckIfEmailAlreadyInUse", mock.Anything, *(updateModel.Email)).Return(false, nil).Once()
 	userRepositoryMock.On("UpdateById", mock.Anything, id, updateModel).Return(userEntity, nil).Once()
 
-	classUnderTest := NewUserService(userRepositoryMock)
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
 
-	updatedUser, err := classUnderTest.UpdateById(&gin.Context{}, id.Hex(), updateModel)
+	updatedUser, err := classUnderTest.UpdateById(context.Background(), id.Hex(), updateModel)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, updatedUser)
 	assert.Equal(t, updatedUser.Email, email)
 	userRepositoryMock.AssertExpectations(t)
 }
+
+func Test_ReplaceById_Should_Create_User_When_Id_Does_Not_Exist(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	var replaceModel = model.ReplaceUserDomainModel{
+		Name:     "Batuhan",
+		Email:    "non_existing@email.com",
+		Password: "123456",
+	}
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, id).Return(nil, errs.NotFoundError).Once()
+	userRepositoryMock.On("CheckIfEmailAlreadyInUse", mock.Anything, replaceModel.Email).Return(false, nil).Once()
+	userRepositoryMock.On("Create", mock.Anything, mock.MatchedBy(func(i interface{}) bool {
+		return i.(model.UserEntity).Id == id && i.(model.UserEntity).Version == 1
+	})).Return(&model.UserEntity{Id: id, Name: replaceModel.Name, Email: replaceModel.Email, Version: 1}, nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	user, created, err := classUnderTest.ReplaceById(context.Background(), id.Hex(), replaceModel, nil)
+
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.Equal(t, id.Hex(), user.Id)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_ReplaceById_Should_Return_NotFoundError_When_Id_Is_Taken_In_Another_Tenant(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	var replaceModel = model.ReplaceUserDomainModel{
+		Name:     "Batuhan",
+		Email:    "non_existing@email.com",
+		Password: "123456",
+	}
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, id).Return(nil, errs.NotFoundError).Once()
+	userRepositoryMock.On("CheckIfEmailAlreadyInUse", mock.Anything, replaceModel.Email).Return(false, nil).Once()
+	userRepositoryMock.On("Create", mock.Anything, mock.Anything).Return(nil, errs.NotFoundError).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	user, created, err := classUnderTest.ReplaceById(context.Background(), id.Hex(), replaceModel, nil)
+
+	assert.Nil(t, user)
+	assert.False(t, created)
+	assert.ErrorIs(t, err, errs.NotFoundError)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_ReplaceById_Should_Replace_User_And_Increment_Version_When_Id_Exists(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	var replaceModel = model.ReplaceUserDomainModel{
+		Name:     "New Name",
+		Email:    "existing@email.com",
+		Password: "123456",
+	}
+
+	var existingEntity = &model.UserEntity{
+		Id:      id,
+		Name:    "Old Name",
+		Email:   replaceModel.Email,
+		Version: 4,
+	}
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, id).Return(existingEntity, nil).Once()
+	userRepositoryMock.On("ReplaceById", mock.Anything, mock.MatchedBy(func(i interface{}) bool {
+		return i.(model.UserEntity).Name == replaceModel.Name && i.(model.UserEntity).Version == 5
+	}), int64(4)).Return(&model.UserEntity{Id: id, Name: replaceModel.Name, Email: replaceModel.Email, Version: 5}, nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	user, created, err := classUnderTest.ReplaceById(context.Background(), id.Hex(), replaceModel, &model.Precondition{Versions: []int64{4}})
+
+	assert.Nil(t, err)
+	assert.False(t, created)
+	assert.Equal(t, int64(5), user.Version)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_ReplaceById_Should_Return_PreconditionFailedError_When_Version_Does_Not_Match(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, id).Return(&model.UserEntity{Id: id, Version: 2}, nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	user, created, err := classUnderTest.ReplaceById(context.Background(), id.Hex(), model.ReplaceUserDomainModel{}, &model.Precondition{Versions: []int64{1}})
+
+	assert.Nil(t, user)
+	assert.False(t, created)
+	assert.Equal(t, errs.PreconditionFailedError, err)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_ReplaceById_Should_Return_PreconditionFailedError_When_If_Match_Is_Sent_And_User_Does_Not_Exist(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetById", mock.Anything, id).Return(nil, errs.NotFoundError).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	user, created, err := classUnderTest.ReplaceById(context.Background(), id.Hex(), model.ReplaceUserDomainModel{}, &model.Precondition{AnyVersion: true})
+
+	assert.Nil(t, user)
+	assert.False(t, created)
+	assert.Equal(t, errs.PreconditionFailedError, err)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_GetByIds_Should_Skip_Malformed_Ids_And_Fetch_The_Rest_At_Once(t *testing.T) {
+	var firstId = primitive.NewObjectID()
+	var secondId = primitive.NewObjectID()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetByIds", mock.Anything, []primitive.ObjectID{firstId, secondId}).Return([]*model.UserEntity{{Id: secondId}}, nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	users, err := classUnderTest.GetByIds(context.Background(), []string{firstId.Hex(), "not an object id", secondId.Hex()})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(users))
+	assert.Equal(t, secondId.Hex(), users[0].Id)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_Export_Should_Pass_Every_Streamed_User_To_Callback_Without_Password(t *testing.T) {
+	var filter = model.UserFilterDomainModel{Name: "bat"}
+	var userEntity = model.UserEntity{Id: primitive.NewObjectID(), Name: "Batuhan", Email: "batuhan@site.com", Version: 2}
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("Stream", mock.Anything, filter, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(2).(func(*model.UserEntity) error)(&userEntity)
+	}).Return(nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	var exported []*model.UserDomainModel
+	err := classUnderTest.Export(context.Background(), filter, func(domainModel *model.UserDomainModel) error {
+		exported = append(exported, domainModel)
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*model.UserDomainModel{{Id: userEntity.Id.Hex(), Name: "Batuhan", Email: "batuhan@site.com", Version: 2}}, exported)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_DeleteByIds_Should_Report_Malformed_Missing_And_Deleted_Ids_Separately(t *testing.T) {
+	var existingId = primitive.NewObjectID()
+	var missingId = primitive.NewObjectID()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetByIds", mock.Anything, []primitive.ObjectID{existingId, missingId}).Return([]*model.UserEntity{{Id: existingId}}, nil).Once()
+	userRepositoryMock.On("DeleteByIds", mock.Anything, []primitive.ObjectID{existingId}).Return(int64(1), nil).Once()
+
+	blobStoreMock := new(repositoryMock.BlobStore)
+	blobStoreMock.On("DeleteAll", mock.Anything, "users/"+existingId.Hex()+"/").Return(nil).Once()
+
+	revisionRepositoryMock := new(repositoryMock.UserRevisionRepositoryInterface)
+	revisionRepositoryMock.On("DeleteByUserIds", mock.Anything, []primitive.ObjectID{existingId}).Return(nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, revisionRepositoryMock, newProfileSchemaServiceMock(), blobStoreMock, newGroupRepositoryMock(), logging.Discard())
+
+	results, err := classUnderTest.DeleteByIds(context.Background(), []string{existingId.Hex(), "malformed", missingId.Hex(), existingId.Hex()})
+
+	assert.Nil(t, err)
+	assert.Nil(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, errs.BadRequestError)
+	assert.ErrorIs(t, results[2].Err, errs.NotFoundError)
+	assert.ErrorIs(t, results[3].Err, errs.BadRequestError)
+	userRepositoryMock.AssertExpectations(t)
+	blobStoreMock.AssertExpectations(t)
+	revisionRepositoryMock.AssertExpectations(t)
+}
+
+func Test_DeleteByIds_Should_Return_ServerError_When_Lookup_Fails(t *testing.T) {
+	var id = primitive.NewObjectID()
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetByIds", mock.Anything, []primitive.ObjectID{id}).Return(nil, errs.ServerError).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	results, err := classUnderTest.DeleteByIds(context.Background(), []string{id.Hex()})
+
+	assert.Nil(t, results)
+	assert.ErrorIs(t, err, errs.ServerError)
+	userRepositoryMock.AssertNotCalled(t, "DeleteByIds", mock.Anything, mock.Anything)
+}
+
+func Test_UpdateByIds_Should_Apply_Valid_Updates_In_One_Bulk_Write_And_Report_The_Rest(t *testing.T) {
+	var firstId = primitive.NewObjectID()
+	var secondId = primitive.NewObjectID()
+	var thirdId = primitive.NewObjectID()
+	var name = "Batuhan"
+	var takenEmail = "taken@site.com"
+	var password = "secret"
+
+	updates := []model.BatchUpdateUserDomainModel{
+		{Id: firstId.Hex(), Update: model.UpdateUserDomainModel{Name: &name}},
+		{Id: secondId.Hex(), Update: model.UpdateUserDomainModel{Email: &takenEmail}},
+		{Id: thirdId.Hex(), Update: model.UpdateUserDomainModel{Password: &password}},
+	}
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetByIds", mock.Anything, []primitive.ObjectID{firstId, secondId, thirdId}).Return([]*model.UserEntity{{Id: firstId}, {Id: secondId}, {Id: thirdId}}, nil).Once()
+	userRepositoryMock.On("CheckIfEmailAlreadyInUse", mock.Anything, takenEmail).Return(true, nil).Once()
+	userRepositoryMock.On("UpdateByIds", mock.Anything, mock.MatchedBy(func(userUpdates []model.UserUpdateEntity) bool {
+		return len(userUpdates) == 2 &&
+			userUpdates[0].Id == firstId && *userUpdates[0].Update.Name == name &&
+			userUpdates[1].Id == thirdId && *userUpdates[1].Update.Password != password
+	})).Return([]error{nil, errs.ServerError}, nil).Once()
+	userRepositoryMock.On("GetByIds", mock.Anything, []primitive.ObjectID{firstId}).Return([]*model.UserEntity{{Id: firstId, Name: name, Version: 2}}, nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	results, err := classUnderTest.UpdateByIds(context.Background(), updates)
+
+	assert.Nil(t, err)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, &model.UserDomainModel{Id: firstId.Hex(), Name: name, Version: 2}, results[0].User)
+	assert.ErrorIs(t, results[1].Err, errs.EmailAlreadyInUseError)
+	assert.ErrorIs(t, results[2].Err, errs.ServerError)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_UpdateById_And_UpdateByIds_Should_Check_And_Store_The_Same_Normalized_Email(t *testing.T) {
+	var id = primitive.NewObjectID()
+	var email = "Batuhan@Site.com"
+	var normalized = "batuhan@site.com"
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("CheckIfEmailAlreadyInUse", mock.Anything, normalized).Return(false, nil).Twice()
+	userRepositoryMock.On("UpdateById", mock.Anything, id, model.UpdateUserDomainModel{Email: &normalized}).Return(&model.UserEntity{Id: id, Email: normalized}, nil).Once()
+	userRepositoryMock.On("GetByIds", mock.Anything, []primitive.ObjectID{id}).Return([]*model.UserEntity{{Id: id, Email: normalized}}, nil).Twice()
+	userRepositoryMock.On("UpdateByIds", mock.Anything, []model.UserUpdateEntity{{Id: id, Update: model.UpdateUserDomainModel{Email: &normalized}}}).Return([]error{nil}, nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	patched, patchErr := classUnderTest.UpdateById(context.Background(), id.Hex(), model.UpdateUserDomainModel{Email: &email})
+	results, batchErr := classUnderTest.UpdateByIds(context.Background(), []model.BatchUpdateUserDomainModel{
+		{Id: id.Hex(), Update: model.UpdateUserDomainModel{Email: &email}},
+	})
+
+	assert.Nil(t, patchErr)
+	assert.Nil(t, batchErr)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, normalized, patched.Email)
+	assert.Equal(t, normalized, results[0].User.Email)
+	userRepositoryMock.AssertExpectations(t)
+}
+
+func Test_UpdateByIds_Should_Reject_Emails_Of_The_Batch_Differing_Only_In_Case(t *testing.T) {
+	var firstId = primitive.NewObjectID()
+	var secondId = primitive.NewObjectID()
+	var firstEmail = "Batuhan@Site.com"
+	var secondEmail = "batuhan@site.com"
+
+	updates := []model.BatchUpdateUserDomainModel{
+		{Id: firstId.Hex(), Update: model.UpdateUserDomainModel{Email: &firstEmail}},
+		{Id: secondId.Hex(), Update: model.UpdateUserDomainModel{Email: &secondEmail}},
+	}
+
+	userRepositoryMock := new(repositoryMock.UserRepositoryInterface)
+	userRepositoryMock.On("GetByIds", mock.Anything, []primitive.ObjectID{firstId, secondId}).Return([]*model.UserEntity{{Id: firstId}, {Id: secondId}}, nil).Once()
+	userRepositoryMock.On("CheckIfEmailAlreadyInUse", mock.Anything, "batuhan@site.com").Return(false, nil).Twice()
+	userRepositoryMock.On("UpdateByIds", mock.Anything, mock.MatchedBy(func(userUpdates []model.UserUpdateEntity) bool {
+		return len(userUpdates) == 1 && userUpdates[0].Id == firstId
+	})).Return([]error{nil}, nil).Once()
+	userRepositoryMock.On("GetByIds", mock.Anything, []primitive.ObjectID{firstId}).Return([]*model.UserEntity{{Id: firstId, Email: "batuhan@site.com"}}, nil).Once()
+
+	classUnderTest := NewUserService(userRepositoryMock, newRevisionRepositoryMock(), newProfileSchemaServiceMock(), newBlobStoreMock(), newGroupRepositoryMock(), logging.Discard())
+
+	results, err := classUnderTest.UpdateByIds(context.Background(), updates)
+
+	assert.Nil(t, err)
+	assert.Nil(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, errs.EmailAlreadyInUseError)
+	userRepositoryMock.AssertExpectations(t)
+}
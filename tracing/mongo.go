@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+)
+
+// CommandMonitor starts a client span for every command the driver sends, as a child of the span in the context
+// of the operation. The command itself is left out of the span, since it holds the data of users.
+func CommandMonitor() *event.CommandMonitor {
+	var spans sync.Map
+
+	end := func(requestId int64, failure string) {
+		span, ok := spans.LoadAndDelete(requestId)
+		if !ok {
+			return
+		}
+
+		if failure != "" {
+			span.(trace.Span).SetStatus(codes.Error, failure)
+		}
+		span.(trace.Span).End()
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, started *event.CommandStartedEvent) {
+			// Commands on a collection name it as their value, commands such as ping do not.
+			namespace := started.DatabaseName
+			collection, ok := started.Command.Lookup(started.CommandName).StringValueOK()
+			if ok {
+				namespace += "." + collection
+			}
+
+			_, span := Tracer.Start(ctx, started.CommandName+" "+namespace,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("db.system", "mongodb"),
+					attribute.String("db.namespace", started.DatabaseName),
+					attribute.String("db.collection.name", collection),
+					attribute.String("db.operation.name", started.CommandName),
+				),
+			)
+			if !span.IsRecording() {
+				return
+			}
+
+			spans.Store(started.RequestID, span)
+		},
+		Succeeded: func(_ context.Context, succeeded *event.CommandSucceededEvent) {
+			end(succeeded.RequestID, "")
+		},
+		Failed: func(_ context.Context, failed *event.CommandFailedEvent) {
+			end(failed.RequestID, failed.Failure)
+		},
+	}
+}
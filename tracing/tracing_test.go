@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"testing"
+	"user-service/config"
+)
+
+func Test_Setup_Should_Write_Spans_To_Stdout_Exporter(t *testing.T) {
+	var output bytes.Buffer
+	shutdown, err := Setup(context.Background(), config.Tracing{Exporter: "stdout", SampleRatio: 1}, &output)
+	assert.Nil(t, err)
+
+	_, span := Tracer.Start(context.Background(), "UserService.GetById")
+	span.End()
+
+	assert.Nil(t, shutdown(context.Background()))
+	assert.Contains(t, output.String(), `"Name":"UserService.GetById"`)
+	assert.Contains(t, output.String(), `"Value":"user-service"`)
+}
+
+func Test_Setup_Should_Reject_Unknown_Exporter(t *testing.T) {
+	_, err := Setup(context.Background(), config.Tracing{Exporter: "zipkin"}, nil)
+
+	assert.EqualError(t, err, `unknown exporter "zipkin"`)
+}
+
+func Test_UnaryServerInterceptor_Should_Continue_The_Trace_Of_The_Caller(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+
+	var traceId trace.TraceID
+	_, err := UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUser"}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		traceId = trace.SpanContextFromContext(ctx).TraceID()
+		return nil, nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceId.String())
+}
+
+func Test_StreamServerInterceptor_Should_Continue_The_Trace_Of_The_Caller(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+
+	var traceId trace.TraceID
+	err := StreamServerInterceptor()(nil, &contextServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/user.UserService/ListUsers"}, func(_ interface{}, stream grpc.ServerStream) error {
+		traceId = trace.SpanContextFromContext(stream.Context()).TraceID()
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceId.String())
+}